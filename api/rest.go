@@ -15,6 +15,7 @@ import (
 	"github.com/Fisch-Labs/FishDB/ecal"
 	"github.com/Fisch-Labs/FishDB/graph"
 	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+	"github.com/Fisch-Labs/FishDB/webhook"
 	"github.com/Fisch-Labs/Toolkit/datautil"
 )
 
@@ -60,6 +61,12 @@ SI is the ScriptingInterpreter instance which is working with the api.GM GraphMa
 */
 var SI *ecal.ScriptingInterpreter
 
+/*
+WH is the webhook Dispatcher instance which is working with the api.GM GraphManager instance.
+(Only available if webhooks are enabled.)
+*/
+var WH *webhook.Dispatcher
+
 /*
 GS is the GraphStorage instance which should be used by the REST API.
 */
@@ -0,0 +1,207 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Fisch-Labs/FishDB/api"
+	"github.com/Fisch-Labs/FishDB/graph"
+	"github.com/Fisch-Labs/FishDB/graph/algo"
+)
+
+/*
+EndpointAlgo is the graph algorithm endpoint URL (rooted). Handles everything under algo/...
+*/
+const EndpointAlgo = api.APIRoot + APIv1 + "/algo/"
+
+/*
+AlgoEndpointInst creates a new endpoint handler.
+*/
+func AlgoEndpointInst() api.RestEndpointHandler {
+	return &algoEndpoint{}
+}
+
+/*
+Handler object for graph algorithms.
+*/
+type algoEndpoint struct {
+	*api.DefaultEndpointHandler
+}
+
+/*
+HandleGET handles a REST call to run a graph algorithm. Returns all available
+algorithms if no algorithm is given.
+*/
+func (ae *algoEndpoint) HandleGET(w http.ResponseWriter, r *http.Request, resources []string) {
+
+	if len(resources) == 0 {
+		w.Header().Set("content-type", "application/json; charset=utf-8")
+
+		ret := json.NewEncoder(w)
+		ret.Encode(algo.Algorithms)
+
+		return
+	}
+
+	ae.run(w, r, resources, false)
+}
+
+/*
+HandlePOST handles a REST call to run a graph algorithm and to write its
+results as node attributes.
+*/
+func (ae *algoEndpoint) HandlePOST(w http.ResponseWriter, r *http.Request, resources []string) {
+	ae.run(w, r, resources, true)
+}
+
+/*
+run runs a graph algorithm. The results are written in a transaction if
+requested.
+*/
+func (ae *algoEndpoint) run(w http.ResponseWriter, r *http.Request, resources []string, write bool) {
+	var trans graph.Trans
+
+	// Check parameters
+
+	if !checkResources(w, resources, 2, 2, "Need a partition and an algorithm") {
+		return
+	}
+
+	params := make(map[string]string)
+
+	for k, v := range r.URL.Query() {
+		params[k] = v[0]
+	}
+
+	if write {
+		if params["attr"] == "" {
+			http.Error(w, "Query string for attr is required", http.StatusBadRequest)
+			return
+		}
+
+		trans = graph.NewGraphTrans(api.GM)
+
+	} else if params["attr"] != "" {
+		http.Error(w, "Results can only be written with a POST request", http.StatusBadRequest)
+		return
+	}
+
+	res, err := algo.Run(api.GM, resources[0], resources[1], params, trans)
+
+	if err == nil && trans != nil {
+		err = trans.Commit()
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Write data
+
+	w.Header().Set("content-type", "application/json; charset=utf-8")
+
+	ret := json.NewEncoder(w)
+	ret.Encode(res)
+}
+
+/*
+SwaggerDefs is used to describe the endpoint in swagger.
+*/
+func (ae *algoEndpoint) SwaggerDefs(s map[string]interface{}) {
+
+	queryParam := func(name string, description string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":        name,
+			"in":          "query",
+			"description": description,
+			"required":    false,
+			"type":        "string",
+		}
+	}
+
+	params := []map[string]interface{}{
+		{
+			"name":        "partition",
+			"in":          "path",
+			"description": "Partition of the graph.",
+			"required":    true,
+			"type":        "string",
+		},
+		{
+			"name":        "algorithm",
+			"in":          "path",
+			"description": "Name of the algorithm (bfs, dfs, shortestpath, components, pagerank, personalizedpagerank, degree or communities).",
+			"required":    true,
+			"type":        "string",
+		},
+		queryParam("spec", "Traversal spec which defines the edges of the graph (default is all edges)."),
+		queryParam("kinds", "Comma separated list of node kinds of the graph (default is all node kinds)."),
+		queryParam("edgekinds", "Comma separated list of edge kinds which are followed (default is all edge kinds)."),
+		queryParam("key", "Key of the start node (bfs, dfs and shortestpath)."),
+		queryParam("kind", "Kind of the start node (bfs, dfs and shortestpath)."),
+		queryParam("tokey", "Key of the end node (shortestpath)."),
+		queryParam("tokind", "Kind of the end node (shortestpath)."),
+		queryParam("weight", "Numeric edge attribute which contains the edge weight (shortestpath)."),
+		queryParam("seeds", "Comma separated list of seed nodes as kind:key pairs (personalizedpagerank)."),
+		queryParam("maxdepth", "Maximal distance of nodes from the seed nodes (personalizedpagerank - default is no limit)."),
+		queryParam("damping", "Damping factor (pagerank and personalizedpagerank - default is 0.85)."),
+		queryParam("iterations", "Maximal number of iterations (pagerank, personalizedpagerank and communities - default is 100)."),
+		queryParam("top", "Only return the nodes with the highest scores (pagerank, personalizedpagerank and degree)."),
+	}
+
+	responses := map[string]interface{}{
+		"200": map[string]interface{}{
+			"description": "A list of nodes (key and kind) with the result of the algorithm for each node (depth, distance, score or label). The nodes of the personalized PageRank also contain the path (nodes and edges) from a seed node.",
+		},
+		"default": map[string]interface{}{
+			"description": "Error response",
+			"schema": map[string]interface{}{
+				"$ref": "#/definitions/Error",
+			},
+		},
+	}
+
+	s["paths"].(map[string]interface{})["/v1/algo/{partition}/{algorithm}"] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary":     "Run a graph algorithm.",
+			"description": "The algorithm runs on all nodes of a partition which are connected by edges matching the traversal spec.",
+			"produces": []string{
+				"text/plain",
+				"application/json",
+			},
+			"parameters": params,
+			"responses":  responses,
+		},
+		"post": map[string]interface{}{
+			"summary":     "Run a graph algorithm and write its results as node attributes.",
+			"description": "The results of the components, pagerank, personalizedpagerank, degree and communities algorithms are written to a node attribute in a single transaction.",
+			"produces": []string{
+				"text/plain",
+				"application/json",
+			},
+			"parameters": append(params, map[string]interface{}{
+				"name":        "attr",
+				"in":          "query",
+				"description": "Node attribute which should contain the result.",
+				"required":    true,
+				"type":        "string",
+			}),
+			"responses": responses,
+		},
+	}
+
+	// Add generic error object to definition
+
+	s["definitions"].(map[string]interface{})["Error"] = map[string]interface{}{
+		"description": "A human readable error mesage.",
+		"type":        "string",
+	}
+}
@@ -0,0 +1,183 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package v1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/api"
+	"github.com/Fisch-Labs/FishDB/graph/data"
+)
+
+func TestAlgo(t *testing.T) {
+	queryURL := "http://localhost" + TESTPORT + EndpointAlgo
+
+	for _, key := range []string{"a", "b", "c"} {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "algotest")
+		api.GM.StoreNode("main", node)
+	}
+
+	for _, link := range [][]string{{"a", "b"}, {"b", "c"}} {
+		edge := data.NewGraphEdge()
+		edge.SetAttr("key", link[0]+link[1])
+		edge.SetAttr("kind", "algolink")
+		edge.SetAttr(data.EdgeEnd1Key, link[0])
+		edge.SetAttr(data.EdgeEnd1Kind, "algotest")
+		edge.SetAttr(data.EdgeEnd1Role, "From")
+		edge.SetAttr(data.EdgeEnd1Cascading, false)
+		edge.SetAttr(data.EdgeEnd2Key, link[1])
+		edge.SetAttr(data.EdgeEnd2Kind, "algotest")
+		edge.SetAttr(data.EdgeEnd2Role, "To")
+		edge.SetAttr(data.EdgeEnd2Cascading, false)
+		api.GM.StoreEdge("main", edge)
+	}
+
+	defer func() {
+		for _, key := range []string{"a", "b", "c"} {
+			api.GM.RemoveNode("main", key, "algotest")
+		}
+	}()
+
+	st, _, res := sendTestRequest(queryURL, "GET", nil)
+	if st != "200 OK" || res != `
+{
+  "bfs": "Breadth-first traversal (key, kind)",
+  "communities": "Label propagation communities (iterations, attr)",
+  "components": "Connected components (attr)",
+  "degree": "Degree centrality (top, attr)",
+  "dfs": "Depth-first traversal (key, kind)",
+  "pagerank": "PageRank (damping, iterations, top, attr)",
+  "personalizedpagerank": "Personalized PageRank from seed nodes (seeds, damping, iterations, maxdepth, top, attr)",
+  "shortestpath": "Weighted shortest path (key, kind, tokey, tokind, weight)"
+}`[1:] {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"main/bfs?kinds=algotest&key=c&kind=algotest", "GET", nil)
+	if st != "200 OK" || res != `
+[
+  {
+    "depth": 0,
+    "key": "c",
+    "kind": "algotest"
+  },
+  {
+    "depth": 1,
+    "key": "b",
+    "kind": "algotest"
+  },
+  {
+    "depth": 2,
+    "key": "a",
+    "kind": "algotest"
+  }
+]`[1:] {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"main/degree?kinds=algotest&top=1", "GET", nil)
+	if st != "200 OK" || res != `
+[
+  {
+    "key": "b",
+    "kind": "algotest",
+    "score": 1
+  }
+]`[1:] {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	// Retrieve the neighbourhood of seed nodes
+
+	st, _, res = sendTestRequest(queryURL+"main/personalizedpagerank?seeds=algotest:a&edgekinds=algolink&top=1", "GET", nil)
+	if st != "200 OK" || !strings.Contains(res, `
+    "edges": [
+      {
+        "key": "ab",
+        "kind": "algolink"
+      }
+    ],
+    "key": "b",
+    "kind": "algotest",
+    "path": [
+      {
+        "key": "a",
+        "kind": "algotest"
+      },
+      {
+        "key": "b",
+        "kind": "algotest"
+      }
+    ],
+    "score":`) {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	// Write the results to the nodes
+
+	st, _, res = sendTestRequest(queryURL+"main/components?kinds=algotest&attr=component", "POST", nil)
+	if st != "200 OK" || res != `
+[
+  {
+    "key": "a",
+    "kind": "algotest",
+    "label": 0
+  },
+  {
+    "key": "b",
+    "kind": "algotest",
+    "label": 0
+  },
+  {
+    "key": "c",
+    "kind": "algotest",
+    "label": 0
+  }
+]`[1:] {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	if node, err := api.GM.FetchNode("main", "c", "algotest"); err != nil || node.Attr("component") != 0 {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	// Test error cases
+
+	st, _, res = sendTestRequest(queryURL+"main", "GET", nil)
+	if st != "400 Bad Request" || res != "Need a partition and an algorithm" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"main/degree?attr=x", "GET", nil)
+	if st != "400 Bad Request" || res != "Results can only be written with a POST request" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"main/degree", "POST", nil)
+	if st != "400 Bad Request" || res != "Query string for attr is required" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"main/foo", "GET", nil)
+	if st != "400 Bad Request" || res != "GraphError: Invalid data (Unknown algorithm: foo)" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+}
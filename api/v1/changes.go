@@ -0,0 +1,373 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/Fisch-Labs/FishDB/api"
+	"github.com/Fisch-Labs/FishDB/graph"
+)
+
+/*
+EndpointChanges is the change log endpoint URL (rooted). Handles everything under changes/...
+*/
+const EndpointChanges = api.APIRoot + APIv1 + "/changes/"
+
+/*
+EndpointChangesFeed is the change feed endpoint URL (rooted). Handles websockets under changes-feed/
+*/
+const EndpointChangesFeed = api.APIRoot + APIv1 + "/changes-feed/"
+
+/*
+changesFeedBatchSize is the number of change sets which are read at once by
+the change feed.
+*/
+var changesFeedBatchSize = 100
+
+/*
+changesUpgrader can upgrade normal requests to websocket communications
+*/
+var changesUpgrader = websocket.Upgrader{
+	Subprotocols:    []string{"changes-feed"},
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+/*
+ChangesEndpointInst creates a new endpoint handler.
+*/
+func ChangesEndpointInst() api.RestEndpointHandler {
+	return &changesEndpoint{}
+}
+
+/*
+Handler object for change log queries.
+*/
+type changesEndpoint struct {
+	*api.DefaultEndpointHandler
+}
+
+/*
+HandleGET handles a change log query REST call.
+*/
+func (ce *changesEndpoint) HandleGET(w http.ResponseWriter, r *http.Request, resources []string) {
+
+	// Check parameters
+
+	if len(resources) > 0 {
+		http.Error(w, "Invalid resource specification: "+strings.Join(resources, "/"), http.StatusBadRequest)
+		return
+	}
+
+	// Get after parameter; -1 if not set
+
+	after, ok := queryParamPosNum(w, r, "after")
+	if !ok {
+		return
+	} else if after == -1 {
+		after = 0
+	}
+
+	// Get limit parameter; -1 if not set - the graph manager uses its
+	// default limit in this case
+
+	limit, ok := queryParamPosNum(w, r, "limit")
+	if !ok {
+		return
+	}
+
+	first, err := api.GM.FirstChangeSeq()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	last, err := api.GM.LastChangeSeq()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	changeSets, err := api.GM.FetchChanges(uint64(after), limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The cursor for the next call is the sequence number of the last
+	// returned change set
+
+	next := uint64(after)
+
+	res := make([]map[string]interface{}, 0, len(changeSets))
+
+	for _, cs := range changeSets {
+		res = append(res, changeSetData(cs))
+		next = cs.Seq
+	}
+
+	// Write data
+
+	w.Header().Set("content-type", "application/json; charset=utf-8")
+
+	ret := json.NewEncoder(w)
+	ret.Encode(map[string]interface{}{
+		"first_seq":   first,
+		"last_seq":    last,
+		"next":        next,
+		"change_sets": res,
+	})
+}
+
+/*
+SwaggerDefs is used to describe the endpoint in swagger.
+*/
+func (ce *changesEndpoint) SwaggerDefs(s map[string]interface{}) {
+
+	s["paths"].(map[string]interface{})["/v1/changes"] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary":     "Return change sets from the change log.",
+			"description": "The changes endpoint returns committed change sets in the order they were appended to the change log. Each change set contains the before and after image of all changed nodes and edges. The next value of the response is the cursor for the following call. Old change sets are removed according to the retention of the change log - change sets before first_seq are no longer available.",
+			"produces": []string{
+				"text/plain",
+				"application/json",
+			},
+			"parameters": []map[string]interface{}{
+				{
+					"name":        "after",
+					"in":          "query",
+					"description": "Sequence number after which change sets should be returned (default is 0).",
+					"required":    false,
+					"type":        "integer",
+				},
+				{
+					"name":        "limit",
+					"in":          "query",
+					"description": fmt.Sprintf("Maximal number of returned change sets (default is %v, at most %v change sets are returned).", graph.DefaultChangeLimit, graph.MaxChangeLimit),
+					"required":    false,
+					"type":        "integer",
+				},
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "The first and the last sequence number in the change log (first_seq and last_seq), the cursor for the next call (next) and a list of change sets (change_sets).",
+				},
+				"default": map[string]interface{}{
+					"description": "Error response",
+					"schema": map[string]interface{}{
+						"$ref": "#/definitions/Error",
+					},
+				},
+			},
+		},
+	}
+
+	// Add generic error object to definition
+
+	s["definitions"].(map[string]interface{})["Error"] = map[string]interface{}{
+		"description": "A human readable error mesage.",
+		"type":        "string",
+	}
+}
+
+/*
+ChangesFeedEndpointInst creates a new endpoint handler.
+*/
+func ChangesFeedEndpointInst() api.RestEndpointHandler {
+	return &changesFeedEndpoint{}
+}
+
+/*
+Handler object for change feed operations.
+*/
+type changesFeedEndpoint struct {
+	*api.DefaultEndpointHandler
+}
+
+/*
+HandleGET handles a change feed. All change sets after a given sequence
+number are sent to the client - afterwards new change sets are sent once
+they are committed.
+*/
+func (ce *changesFeedEndpoint) HandleGET(w http.ResponseWriter, r *http.Request, resources []string) {
+
+	// Update the incomming connection to a websocket
+	// If the upgrade fails then the client gets an HTTP error response.
+
+	conn, err := changesUpgrader.Upgrade(w, r, nil)
+
+	if err != nil {
+
+		// We give details here on what went wrong
+
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	var after uint64
+
+	if val := r.URL.Query().Get("after"); val != "" {
+		num, err := strconv.Atoi(val)
+
+		if err != nil || num < 0 {
+			ce.WriteError(conn, "Invalid parameter value: after should be a positive integer number")
+			return
+		}
+
+		after = uint64(num)
+	}
+
+	last, err := api.GM.LastChangeSeq()
+	if err != nil {
+		ce.WriteError(conn, err.Error())
+		return
+	}
+
+	conn.WriteJSON(map[string]interface{}{
+		"type": "init_success",
+		"payload": map[string]interface{}{
+			"last_seq": last,
+		},
+	})
+
+	// Read messages in the background to detect when the client hangs up -
+	// the client is not expected to send anything
+
+	closed := make(chan struct{})
+
+	go func() {
+		defer close(closed)
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+
+		// Get the notification channel before reading the change log so no
+		// change set is missed
+
+		notify := api.GM.ChangeNotify()
+
+		changeSets, err := api.GM.FetchChanges(after, changesFeedBatchSize)
+		if err != nil {
+			ce.WriteError(conn, err.Error())
+			return
+		}
+
+		for _, cs := range changeSets {
+
+			if err := conn.WriteJSON(map[string]interface{}{
+				"type":    "change_set",
+				"payload": changeSetData(cs),
+			}); err != nil {
+				conn.Close()
+				return
+			}
+
+			after = cs.Seq
+		}
+
+		if len(changeSets) > 0 {
+
+			// Send the remaining change sets of the backlog
+
+			continue
+		}
+
+		select {
+		case <-notify:
+		case <-closed:
+			conn.Close()
+			return
+		}
+	}
+}
+
+/*
+WriteError writes an error message to the websocket and closes it.
+*/
+func (ce *changesFeedEndpoint) WriteError(conn *websocket.Conn, msg string) {
+
+	// Write the error as cleartext message
+
+	conn.WriteJSON(map[string]interface{}{
+		"type": "feed_fail",
+		"payload": map[string]interface{}{
+			"errors": []string{msg},
+		},
+	})
+
+	// Write error as closing control message
+
+	conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(
+			websocket.CloseUnsupportedData, msg), time.Now().Add(10*time.Second))
+
+	conn.Close()
+}
+
+/*
+SwaggerDefs is used to describe the endpoint in swagger.
+*/
+func (ce *changesFeedEndpoint) SwaggerDefs(s map[string]interface{}) {
+	// No swagger definitions for this endpoint as it only handles websocket requests
+}
+
+/*
+changeSetData converts a change set into a JSON compatible data structure.
+*/
+func changeSetData(cs *graph.ChangeSet) map[string]interface{} {
+
+	changes := make([]map[string]interface{}, 0, len(cs.Changes))
+
+	for _, change := range cs.Changes {
+		var before, after map[string]interface{}
+
+		if change.Before != nil {
+			before = change.Before.Data()
+		}
+		if change.After != nil {
+			after = change.After.Data()
+		}
+
+		entityType := "n"
+		if change.IsEdge {
+			entityType = "e"
+		}
+
+		changes = append(changes, map[string]interface{}{
+			"op":          change.Op,
+			"part":        change.Part,
+			"entity_type": entityType,
+			"kind":        change.Kind,
+			"key":         change.Key,
+			"before":      before,
+			"after":       after,
+		})
+	}
+
+	return map[string]interface{}{
+		"seq":      cs.Seq,
+		"version":  cs.Version,
+		"time":     cs.Time.UTC().Format(time.RFC3339Nano),
+		"trans_id": cs.TransID,
+		"changes":  changes,
+	}
+}
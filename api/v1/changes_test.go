@@ -0,0 +1,201 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/api"
+	"github.com/Fisch-Labs/FishDB/graph"
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/gorilla/websocket"
+)
+
+func TestChangesQuery(t *testing.T) {
+	queryURL := "http://localhost" + TESTPORT + EndpointChanges
+
+	st, _, res := sendTestRequest(queryURL+"foo", "GET", nil)
+	if st != "400 Bad Request" || res != "Invalid resource specification: foo" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"?after=x", "GET", nil)
+	if st != "400 Bad Request" || res != "Invalid parameter value: after should be a positive integer number" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	last, err := api.GM.LastChangeSeq()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Change a node and restore it afterwards
+
+	node := data.NewGraphNode()
+	node.SetAttr("key", "19")
+	node.SetAttr("kind", "filtertest")
+	node.SetAttr("val1", "test18")
+	node.SetAttr("val2", "X5")
+	node.SetAttr("val3", "foo")
+
+	changedNode := data.CopyNode(node)
+	changedNode.SetAttr("val3", "bar")
+
+	trans := graph.NewGraphTrans(api.GM)
+	trans.StoreNode("main", changedNode)
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := api.GM.StoreNode("main", node); err != nil {
+		t.Error(err)
+		return
+	}
+
+	var ret map[string]interface{}
+
+	st, _, res = sendTestRequest(queryURL+fmt.Sprintf("?after=%v&limit=1", last), "GET", nil)
+	if err := json.Unmarshal([]byte(res), &ret); st != "200 OK" || err != nil {
+		t.Error("Unexpected response:", st, res, err)
+		return
+	}
+
+	if ret["first_seq"] != float64(1) || ret["last_seq"] != float64(last+2) || ret["next"] != float64(last+1) ||
+		len(ret["change_sets"].([]interface{})) != 1 {
+		t.Error("Unexpected response:", res)
+		return
+	}
+
+	cs := ret["change_sets"].([]interface{})[0].(map[string]interface{})
+	change := cs["changes"].([]interface{})[0].(map[string]interface{})
+
+	if cs["seq"] != float64(last+1) || cs["trans_id"] != trans.ID() || change["op"] != graph.ChangeUpsert ||
+		change["entity_type"] != "n" || change["kind"] != "filtertest" || change["key"] != "19" ||
+		change["before"].(map[string]interface{})["val3"] != "foo" ||
+		change["after"].(map[string]interface{})["val3"] != "bar" {
+		t.Error("Unexpected response:", res)
+		return
+	}
+
+	// Continue with the returned cursor
+
+	st, _, res = sendTestRequest(queryURL+fmt.Sprintf("?after=%v", ret["next"]), "GET", nil)
+	if err := json.Unmarshal([]byte(res), &ret); st != "200 OK" || err != nil ||
+		ret["next"] != float64(last+2) || len(ret["change_sets"].([]interface{})) != 1 {
+		t.Error("Unexpected response:", st, res, err)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+fmt.Sprintf("?after=%v", ret["next"]), "GET", nil)
+	if err := json.Unmarshal([]byte(res), &ret); st != "200 OK" || err != nil ||
+		ret["next"] != float64(last+2) || len(ret["change_sets"].([]interface{})) != 0 {
+		t.Error("Unexpected response:", st, res, err)
+		return
+	}
+}
+
+func TestChangesFeed(t *testing.T) {
+	queryURL := "ws://localhost" + TESTPORT + EndpointChangesFeed
+
+	_, _, res := sendTestRequest("http://localhost"+TESTPORT+EndpointChangesFeed, "GET", nil)
+
+	if res != `Bad Request
+websocket: the client is not using the websocket protocol: 'upgrade' token not found in 'Connection' header` {
+		t.Error("Unexpected response:", res)
+		return
+	}
+
+	// Test invalid sequence number
+
+	c, _, err := websocket.DefaultDialer.Dial(queryURL+"?after=x", nil)
+	if err != nil {
+		t.Error("Could not open websocket:", err)
+		return
+	}
+
+	_, message, err := c.ReadMessage()
+	if msg := formatJSONString(string(message)); err != nil || msg != `{
+  "payload": {
+    "errors": [
+      "Invalid parameter value: after should be a positive integer number"
+    ]
+  },
+  "type": "feed_fail"
+}` {
+		t.Error("Unexpected response:", msg, err)
+		return
+	}
+
+	c.Close()
+
+	last, err := api.GM.LastChangeSeq()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	node := data.NewGraphNode()
+	node.SetAttr("key", "feedtest")
+	node.SetAttr("kind", "feedtest")
+
+	if err := api.GM.StoreNode("main", node); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Resume after the last sequence number before the change
+
+	c, _, err = websocket.DefaultDialer.Dial(queryURL+fmt.Sprintf("?after=%v", last), nil)
+	if err != nil {
+		t.Error("Could not open websocket:", err)
+		return
+	}
+	defer c.Close()
+
+	var msg map[string]interface{}
+
+	if err := c.ReadJSON(&msg); err != nil || msg["type"] != "init_success" ||
+		msg["payload"].(map[string]interface{})["last_seq"] != float64(last+1) {
+		t.Error("Unexpected response:", msg, err)
+		return
+	}
+
+	if err := c.ReadJSON(&msg); err != nil || msg["type"] != "change_set" ||
+		msg["payload"].(map[string]interface{})["seq"] != float64(last+1) {
+		t.Error("Unexpected response:", msg, err)
+		return
+	}
+
+	// New changes are sent once they are committed
+
+	if _, err := api.GM.RemoveNode("main", "feedtest", "feedtest"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := c.ReadJSON(&msg); err != nil || msg["type"] != "change_set" {
+		t.Error("Unexpected response:", msg, err)
+		return
+	}
+
+	cs := msg["payload"].(map[string]interface{})
+	change := cs["changes"].([]interface{})[0].(map[string]interface{})
+
+	if cs["seq"] != float64(last+2) || change["op"] != graph.ChangeDelete ||
+		change["before"] == nil || change["after"] != nil {
+		t.Error("Unexpected response:", msg)
+		return
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 
 	"github.com/Fisch-Labs/FishDB/api"
@@ -57,6 +58,7 @@ func (ie *findEndpoint) HandleGET(w http.ResponseWriter, r *http.Request, resour
 	}
 
 	lookup := stringutil.IsTrueValue(r.URL.Query().Get("lookup"))
+	score := stringutil.IsTrueValue(r.URL.Query().Get("score"))
 	part := r.URL.Query().Get("part")
 
 	parts := api.GM.Partitions()
@@ -88,8 +90,10 @@ func (ie *findEndpoint) HandleGET(w http.ResponseWriter, r *http.Request, resour
 			for _, k := range kinds {
 				var iq graph.IndexQuery
 				var nodes []interface{}
+				var nodeKeys []string
 
 				nodeMap := make(map[string]interface{})
+				scoreMap := make(map[string]float64)
 
 				// NodeIndexQuery may return nil nil if the node kind does not exist
 				// in a partition
@@ -114,8 +118,20 @@ func (ie *findEndpoint) HandleGET(w http.ResponseWriter, r *http.Request, resour
 						for _, key := range keys {
 							var node data.Node
 
+							// Text matches are ranked by the sum of their
+							// relevance scores in all attributes
+
+							if text != "" && err == nil {
+								var s float64
+
+								s, err = iq.Score(attr, text, key)
+								scoreMap[key] += s
+							}
+
 							if _, ok := nodeMap[key]; !ok && err == nil {
 
+								nodeKeys = append(nodeKeys, key)
+
 								if lookup {
 									if node, err = api.GM.FetchNode(p, key, k); node != nil {
 										nodeMap[key] = node.Data()
@@ -131,7 +147,22 @@ func (ie *findEndpoint) HandleGET(w http.ResponseWriter, r *http.Request, resour
 					}
 				}
 
-				for _, n := range nodeMap {
+				sort.SliceStable(nodeKeys, func(i, j int) bool {
+					return scoreMap[nodeKeys[i]] > scoreMap[nodeKeys[j]]
+				})
+
+				for _, key := range nodeKeys {
+					n, ok := nodeMap[key]
+					if !ok {
+						continue
+					}
+
+					if score && text != "" {
+						if nm, ok := n.(map[string]interface{}); ok {
+							nm["score"] = scoreMap[key]
+						}
+					}
+
 					nodes = append(nodes, n)
 				}
 
@@ -165,7 +196,7 @@ func (ie *findEndpoint) SwaggerDefs(s map[string]interface{}) {
 	s["paths"].(map[string]interface{})["/v1/find"] = map[string]interface{}{
 		"get": map[string]interface{}{
 			"summary":     "Run index searches on the FishDB datastore.",
-			"description": "The find endpoint should be used to run simple index searches for either a value or a phrase.",
+			"description": "The find endpoint should be used to run simple index searches for either a value or a phrase. Results of a phrase search are ordered by their relevance score.",
 			"produces": []string{
 				"text/plain",
 				"application/json",
@@ -192,6 +223,13 @@ func (ie *findEndpoint) SwaggerDefs(s map[string]interface{}) {
 					"required":    false,
 					"type":        "boolean",
 				},
+				{
+					"name":        "score",
+					"in":          "query",
+					"description": "Flag if the relevance score of a phrase search should be added to every found node.",
+					"required":    false,
+					"type":        "boolean",
+				},
 				{
 					"name":        "part",
 					"in":          "query",
@@ -9,6 +9,9 @@ package v1
 
 import (
 	"testing"
+
+	"github.com/Fisch-Labs/FishDB/api"
+	"github.com/Fisch-Labs/FishDB/graph/data"
 )
 
 func TestFindQuery(t *testing.T) {
@@ -61,6 +64,7 @@ func TestFindQuery(t *testing.T) {
   "test": {
     "Author": [
       {
+        "_rev": 1,
         "desc": "One of the most popular acoustic artists of the decade and one of its best-selling artists.",
         "key": "000",
         "kind": "Author",
@@ -73,6 +77,62 @@ func TestFindQuery(t *testing.T) {
 		return
 	}
 
+	// Phrase search results are ordered by relevance
+
+	for key, desc := range map[string]string{
+		"001": "Popular artists",
+		"002": "A list of artists",
+	} {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "Author")
+		node.SetAttr("desc", desc)
+		api.GM.StoreNode("test", node)
+
+		defer api.GM.RemoveNode("test", key, "Author")
+	}
+
+	_, _, res = sendTestRequest(queryURL+"?text=artists&part=test", "GET", nil)
+	if res != `
+{
+  "test": {
+    "Author": [
+      {
+        "key": "001",
+        "kind": "Author"
+      },
+      {
+        "key": "002",
+        "kind": "Author"
+      },
+      {
+        "key": "000",
+        "kind": "Author"
+      }
+    ]
+  }
+}`[1:] {
+		t.Error("Unexpected response:", res)
+		return
+	}
+
+	_, _, res = sendTestRequest(queryURL+"?text=popular+artists&part=test&score=1", "GET", nil)
+	if res != `
+{
+  "test": {
+    "Author": [
+      {
+        "key": "001",
+        "kind": "Author",
+        "score": 0.8651238557120415
+      }
+    ]
+  }
+}`[1:] {
+		t.Error("Unexpected response:", res)
+		return
+	}
+
 	_, _, res = sendTestRequest(queryURL+"?tuxt=best-selling", "GET", nil)
 	if res != "Query string for text (word or phrase) or value (exact match) is required" {
 		t.Error("Unexpected response:", res)
@@ -13,10 +13,12 @@ import (
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/Fisch-Labs/FishDB/api"
 	"github.com/Fisch-Labs/FishDB/graph"
 	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/util"
 )
 
 /*
@@ -173,6 +175,10 @@ func (ge *graphEndpoint) HandleGET(w http.ResponseWriter, r *http.Request, resou
 
 			data = node.Data()
 
+			// The revision is read together with the node data
+
+			w.Header().Set("ETag", revisionETag(node))
+
 		} else {
 
 			edge, err := api.GM.FetchEdge(resources[0], resources[3], resources[2])
@@ -186,6 +192,8 @@ func (ge *graphEndpoint) HandleGET(w http.ResponseWriter, r *http.Request, resou
 			}
 
 			data = edge.Data()
+
+			w.Header().Set("ETag", revisionETag(edge))
 		}
 
 		// Write data
@@ -255,7 +263,8 @@ func (ge *graphEndpoint) HandleGET(w http.ResponseWriter, r *http.Request, resou
 /*
 HandlePUT handles a REST call to insert new elements into the graph or update
 existing elements. Nodes are updated if they already exist. Edges are replaced
-if they already exist.
+if they already exist. An If-Match header can contain the expected revision
+if a single node is sent.
 */
 func (ge *graphEndpoint) HandlePUT(w http.ResponseWriter, r *http.Request, resources []string) {
 	ge.handleGraphRequest(w, r, resources,
@@ -344,6 +353,20 @@ func (ge *graphEndpoint) handleGraphRequest(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
+	// An If-Match header contains the expected revision of a single node
+
+	ifMatch := r.Header.Get("If-Match")
+
+	if ifMatch != "" && r.Method != "DELETE" {
+
+		if len(nDataList) != 1 || nDataList[0] == nil || len(eDataList) != 0 {
+			http.Error(w, "If-Match header can only be used with a single node", http.StatusBadRequest)
+			return
+		}
+
+		nDataList[0][data.NodeRevision] = strings.Trim(ifMatch, `"`)
+	}
+
 	// Create a transaction
 
 	trans := graph.NewGraphTrans(api.GM)
@@ -379,9 +402,32 @@ func (ge *graphEndpoint) handleGraphRequest(w http.ResponseWriter, r *http.Reque
 	// Commit transaction
 
 	if err := trans.Commit(); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		if gerr, ok := err.(*util.GraphError); ok && gerr.Type == util.ErrConflict {
+			http.Error(w, err.Error(), http.StatusConflict)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+
 		return
 	}
+
+	// Return the new revision of the node - the commit succeeded so the node
+	// had the expected revision and was written once
+
+	if ifMatch != "" && r.Method != "DELETE" {
+		if rev, err := strconv.ParseUint(strings.Trim(ifMatch, `"`), 10, 64); err == nil {
+			w.Header().Set("ETag", fmt.Sprintf(`"%v"`, rev+1))
+		}
+	}
+}
+
+/*
+revisionETag returns the ETag header value for the revision of a fetched node
+or edge.
+*/
+func revisionETag(node data.Node) string {
+	return fmt.Sprintf(`"%v"`, node.Attr(data.NodeRevision))
 }
 
 /*
@@ -539,7 +585,8 @@ func (ge *graphEndpoint) SwaggerDefs(s map[string]interface{}) {
 		"put": map[string]interface{}{
 			"summary": "Data can be send by using PUT requests.",
 			"description": "A whole graph can be send. " +
-				"PUT will store data in the datastore and update existing data.",
+				"PUT will store data in the datastore and update existing data. " +
+				"An If-Match header can contain the expected revision if a single node is sent.",
 			"consumes": []string{
 				"application/json",
 			},
@@ -601,7 +648,8 @@ func (ge *graphEndpoint) SwaggerDefs(s map[string]interface{}) {
 		"put": map[string]interface{}{
 			"summary": "Data can be send by using PUT requests.",
 			"description": "A list of nodes / edges can be send. " +
-				"PUT will store data in the datastore and update existing data.",
+				"PUT will store data in the datastore and update existing data. " +
+				"An If-Match header can contain the expected revision if a single node is sent.",
 			"consumes": []string{
 				"application/json",
 			},
@@ -8,8 +8,12 @@
 package v1
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/Fisch-Labs/FishDB/api"
@@ -20,6 +24,58 @@ import (
 	"github.com/Fisch-Labs/Toolkit/datautil"
 )
 
+func TestVectorStorage(t *testing.T) {
+	queryURL := "http://localhost" + TESTPORT + EndpointGraph
+
+	api.GM.SetSchema("VectorTest", &graph.KindSchema{Attrs: map[string]*graph.AttrSchema{
+		"embedding": {Type: "vector"},
+	}})
+
+	defer func() {
+		api.GM.RemoveNode("main", "vec1", "VectorTest")
+		api.GM.RemoveNode("main", "vec2", "VectorTest")
+		api.GM.SetSchema("VectorTest", nil)
+	}()
+
+	// Store nodes with a declared vector attribute
+
+	st, _, res := sendTestRequest(queryURL+"main/n", "POST", []byte(`
+[{
+	"key":"vec1",
+	"kind":"VectorTest",
+	"embedding":[1, 0, 0]
+},{
+	"key":"vec2",
+	"kind":"VectorTest",
+	"embedding":[0, 0.5, 1]
+}]
+`[1:]))
+
+	if st != "200 OK" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	n, err := api.GM.FetchNode("main", "vec2", "VectorTest")
+	if vec, ok := n.Attr("embedding").([]float32); err != nil || !ok || fmt.Sprint(vec) != "[0 0.5 1]" {
+		t.Error("Unexpected result:", n, err)
+		return
+	}
+
+	// The vectors can be found with a vector search
+
+	vq, err := api.GM.VectorQuery("main", "VectorTest", "embedding")
+	if err != nil || vq == nil {
+		t.Error("Unexpected result:", vq, err)
+		return
+	}
+
+	if keys, _, err := vq.Nearest([]float32{0, 0.4, 1}, 1); err != nil || fmt.Sprint(keys) != "[vec2]" {
+		t.Error("Unexpected result:", keys, err)
+		return
+	}
+}
+
 func TestNestedStorage(t *testing.T) {
 	queryURL := "http://localhost" + TESTPORT + EndpointGraph
 
@@ -85,6 +141,7 @@ func TestNestedStorage(t *testing.T) {
 {
   "float": 3.1415926,
   "int": 42,
+  "_rev": 1,
   "key": "nestedtest",
   "kind": "Test",
   "nested": {
@@ -152,54 +209,63 @@ func TestGraphQuery(t *testing.T) {
 	if st != "200 OK" || res != `
 [
   {
+    "_rev": 1,
     "key": "StrangeSong1",
     "kind": "Song",
     "name": "StrangeSong1",
     "ranking": 5
   },
   {
+    "_rev": 1,
     "key": "FightSong4",
     "kind": "Song",
     "name": "FightSong4",
     "ranking": 3
   },
   {
+    "_rev": 1,
     "key": "DeadSong2",
     "kind": "Song",
     "name": "DeadSong2",
     "ranking": 6
   },
   {
+    "_rev": 1,
     "key": "LoveSong3",
     "kind": "Song",
     "name": "LoveSong3",
     "ranking": 1
   },
   {
+    "_rev": 1,
     "key": "MyOnlySong3",
     "kind": "Song",
     "name": "MyOnlySong3",
     "ranking": 19
   },
   {
+    "_rev": 1,
     "key": "Aria1",
     "kind": "Song",
     "name": "Aria1",
     "ranking": 8
   },
   {
+    "_rev": 1,
     "key": "Aria2",
     "kind": "Song",
     "name": "Aria2",
     "ranking": 2
   },
   {
+    "_rev": 1,
     "key": "Aria3",
     "kind": "Song",
     "name": "Aria3",
     "ranking": 4
   },
   {
+    "_rev": 1,
     "key": "Aria4",
     "kind": "Song",
     "name": "Aria4",
@@ -216,12 +282,14 @@ func TestGraphQuery(t *testing.T) {
 	if st != "200 OK" || res != `
 [
   {
+    "_rev": 1,
     "key": "LoveSong3",
     "kind": "Song",
     "name": "LoveSong3",
     "ranking": 1
   },
   {
+    "_rev": 1,
     "key": "MyOnlySong3",
     "kind": "Song",
     "name": "MyOnlySong3",
@@ -236,12 +304,14 @@ func TestGraphQuery(t *testing.T) {
 	if st != "200 OK" || res != `
 [
   {
+    "_rev": 1,
     "key": "Aria3",
     "kind": "Song",
     "name": "Aria3",
     "ranking": 4
   },
   {
+    "_rev": 1,
     "key": "Aria4",
     "kind": "Song",
     "name": "Aria4",
@@ -335,6 +405,7 @@ func TestGraphQuerySingleItem(t *testing.T) {
 
 	if st != "200 OK" || res != `
 {
+  "_rev": 1,
   "key": "123",
   "kind": "Author",
   "name": "Mike"
@@ -347,6 +418,7 @@ func TestGraphQuerySingleItem(t *testing.T) {
 
 	if st != "200 OK" || res != `
 {
+  "_rev": 1,
   "end1cascading": true,
   "end1key": "123",
   "end1kind": "Author",
@@ -854,3 +926,75 @@ func TestGraphOperation(t *testing.T) {
 		return
 	}
 }
+
+func TestGraphRevisions(t *testing.T) {
+	queryURL := "http://localhost" + TESTPORT + EndpointGraph
+
+	sendRevisionRequest := func(method string, ifMatch string, content string) (string, http.Header, string) {
+		req, err := http.NewRequest(method, queryURL+"revtest/n", bytes.NewBufferString(content))
+		if err != nil {
+			panic(err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			panic(err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := ioutil.ReadAll(resp.Body)
+
+		return resp.Status, resp.Header, strings.TrimSpace(string(body))
+	}
+
+	st, _, res := sendRevisionRequest("POST", "", `[{"key":"rev1","kind":"Doc","text":"first"}]`)
+
+	if st != "200 OK" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, header, res := sendTestRequest(queryURL+"revtest/n/Doc/rev1", "GET", nil)
+
+	if st != "200 OK" || header.Get("ETag") != `"1"` {
+		t.Error("Unexpected response:", st, header, res)
+		return
+	}
+
+	// Update with the current revision
+
+	st, header, res = sendRevisionRequest("PUT", `"1"`, `[{"key":"rev1","kind":"Doc","text":"second"}]`)
+
+	if st != "200 OK" || header.Get("ETag") != `"2"` {
+		t.Error("Unexpected response:", st, header, res)
+		return
+	}
+
+	// Update with an outdated revision
+
+	st, _, res = sendRevisionRequest("PUT", `"1"`, `[{"key":"rev1","kind":"Doc","text":"third"}]`)
+
+	if st != "409 Conflict" || res != "GraphError: Revision conflict (rev1 of kind Doc has revision 2 but revision 1 was expected)" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	if n, err := api.GM.FetchNode("revtest", "rev1", "Doc"); err != nil || n.Attr("text") != "second" {
+		t.Error("Unexpected result:", n, err)
+		return
+	}
+
+	// The If-Match header only works with a single node
+
+	st, _, res = sendRevisionRequest("PUT", `"2"`, `[{"key":"rev1","kind":"Doc"},{"key":"rev2","kind":"Doc"}]`)
+
+	if st != "400 Bad Request" || res != "If-Match header can only be used with a single node" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+}
@@ -0,0 +1,232 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Fisch-Labs/FishDB/api"
+	"github.com/Fisch-Labs/FishDB/graph"
+)
+
+/*
+EndpointHistory is the history endpoint URL (rooted). Handles everything under history/...
+*/
+const EndpointHistory = api.APIRoot + APIv1 + "/history/"
+
+/*
+HistoryEndpointInst creates a new endpoint handler.
+*/
+func HistoryEndpointInst() api.RestEndpointHandler {
+	return &historyEndpoint{}
+}
+
+/*
+Handler object for history queries.
+*/
+type historyEndpoint struct {
+	*api.DefaultEndpointHandler
+}
+
+/*
+HandleGET handles a history query REST call.
+*/
+func (he *historyEndpoint) HandleGET(w http.ResponseWriter, r *http.Request, resources []string) {
+	var data interface{}
+
+	// Check parameters
+
+	if !checkResources(w, resources, 4, 4, "Need a partition, entity type (n or e), a kind and a key") {
+		return
+	}
+
+	if resources[1] != "n" && resources[1] != "e" {
+		http.Error(w, "Entity type must be n (nodes) or e (edges)", http.StatusBadRequest)
+		return
+	}
+
+	// Get from parameter; -1 if not set
+
+	from, ok := queryParamPosNum(w, r, "from")
+	if !ok {
+		return
+	}
+
+	// Get to parameter; -1 if not set
+
+	to, ok := queryParamPosNum(w, r, "to")
+	if !ok {
+		return
+	}
+
+	if (from == -1) != (to == -1) {
+		http.Error(w, "Query string for both from and to is required", http.StatusBadRequest)
+		return
+	}
+
+	if from != -1 {
+
+		// Compute the changes between two versions
+
+		var changes []*graph.AttrChange
+		var err error
+
+		if resources[1] == "n" {
+			changes, err = api.GM.DiffNodeVersions(resources[0], resources[3], resources[2], uint64(from), uint64(to))
+		} else {
+			changes, err = api.GM.DiffEdgeVersions(resources[0], resources[3], resources[2], uint64(from), uint64(to))
+		}
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		res := make([]map[string]interface{}, 0, len(changes))
+
+		for _, change := range changes {
+			res = append(res, map[string]interface{}{
+				"attr": change.Attr,
+				"old":  change.Old,
+				"new":  change.New,
+			})
+		}
+
+		data = res
+
+	} else {
+
+		// List all versions
+
+		var hist []*graph.HistoryEntry
+		var err error
+
+		if resources[1] == "n" {
+			hist, err = api.GM.NodeHistory(resources[0], resources[3], resources[2])
+		} else {
+			hist, err = api.GM.EdgeHistory(resources[0], resources[3], resources[2])
+		}
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		} else if hist == nil {
+			http.Error(w, "Unknown partition, kind or key", http.StatusBadRequest)
+			return
+		}
+
+		res := make([]map[string]interface{}, 0, len(hist))
+
+		for _, entry := range hist {
+			var itemData map[string]interface{}
+
+			if entry.Node != nil {
+				itemData = entry.Node.Data()
+			}
+
+			res = append(res, map[string]interface{}{
+				"version":  entry.Version,
+				"time":     entry.Time.UTC().Format(time.RFC3339Nano),
+				"trans_id": entry.TransID,
+				"deleted":  entry.Deleted,
+				"data":     itemData,
+			})
+		}
+
+		data = res
+	}
+
+	// Write data
+
+	w.Header().Set("content-type", "application/json; charset=utf-8")
+
+	ret := json.NewEncoder(w)
+	ret.Encode(data)
+}
+
+/*
+SwaggerDefs is used to describe the endpoint in swagger.
+*/
+func (he *historyEndpoint) SwaggerDefs(s map[string]interface{}) {
+
+	s["paths"].(map[string]interface{})["/v1/history/{partition}/{entity_type}/{kind}/{key}"] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary":     "Return the history of a node or edge.",
+			"description": "The history endpoint lists all versions of a node or edge. If a from and a to version are given then the attribute changes between these two versions are returned.",
+			"produces": []string{
+				"text/plain",
+				"application/json",
+			},
+			"parameters": []map[string]interface{}{
+				{
+					"name":        "partition",
+					"in":          "path",
+					"description": "Partition to query.",
+					"required":    true,
+					"type":        "string",
+				},
+				{
+					"name": "entity_type",
+					"in":   "path",
+					"description": "Datastore entity type which should selected. " +
+						"Either n for nodes or e for edges.",
+					"required": true,
+					"type":     "string",
+				},
+				{
+					"name":        "kind",
+					"in":          "path",
+					"description": "Node or edge kind to be queried.",
+					"required":    true,
+					"type":        "string",
+				},
+				{
+					"name":        "key",
+					"in":          "path",
+					"description": "Node or edge key to be queried.",
+					"required":    true,
+					"type":        "string",
+				},
+				{
+					"name":        "from",
+					"in":          "query",
+					"description": "Older graph version for computing attribute changes.",
+					"required":    false,
+					"type":        "integer",
+				},
+				{
+					"name":        "to",
+					"in":          "query",
+					"description": "Newer graph version for computing attribute changes.",
+					"required":    false,
+					"type":        "integer",
+				},
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "A list of versions (version, time, trans_id, deleted and data) or a list of attribute changes (attr, old and new).",
+				},
+				"default": map[string]interface{}{
+					"description": "Error response",
+					"schema": map[string]interface{}{
+						"$ref": "#/definitions/Error",
+					},
+				},
+			},
+		},
+	}
+
+	// Add generic error object to definition
+
+	s["definitions"].(map[string]interface{})["Error"] = map[string]interface{}{
+		"description": "A human readable error mesage.",
+		"type":        "string",
+	}
+}
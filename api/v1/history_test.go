@@ -0,0 +1,138 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/api"
+	"github.com/Fisch-Labs/FishDB/graph"
+	"github.com/Fisch-Labs/FishDB/graph/data"
+)
+
+func TestHistoryQuery(t *testing.T) {
+	queryURL := "http://localhost" + TESTPORT + EndpointHistory
+
+	st, _, res := sendTestRequest(queryURL+"main/n/filtertest", "GET", nil)
+	if st != "400 Bad Request" || res != "Need a partition, entity type (n or e), a kind and a key" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"main/x/filtertest/19", "GET", nil)
+	if st != "400 Bad Request" || res != "Entity type must be n (nodes) or e (edges)" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"main/n/filtertest/19?from=1", "GET", nil)
+	if st != "400 Bad Request" || res != "Query string for both from and to is required" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"main/n/filtertest/19?from=1&to=x", "GET", nil)
+	if st != "400 Bad Request" || res != "Invalid parameter value: to should be a positive integer number" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"main/n/filtertest/xxx", "GET", nil)
+	if st != "400 Bad Request" || res != "Unknown partition, kind or key" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	// Change a node and restore it afterwards
+
+	node := data.NewGraphNode()
+	node.SetAttr("key", "19")
+	node.SetAttr("kind", "filtertest")
+	node.SetAttr("val1", "test18")
+	node.SetAttr("val2", "X5")
+	node.SetAttr("val3", "foo")
+
+	changedNode := data.CopyNode(node)
+	changedNode.SetAttr("val3", "bar")
+
+	trans := graph.NewGraphTrans(api.GM)
+	trans.StoreNode("main", changedNode)
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := api.GM.StoreNode("main", node); err != nil {
+		t.Error(err)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"main/n/filtertest/19", "GET", nil)
+	if st != "200 OK" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	var hist []map[string]interface{}
+
+	if err := json.Unmarshal([]byte(res), &hist); err != nil || len(hist) != 3 {
+		t.Error("Unexpected response:", res, err)
+		return
+	}
+
+	if hist[1]["trans_id"] != trans.ID() || hist[1]["deleted"] != false ||
+		hist[1]["data"].(map[string]interface{})["val3"] != "bar" ||
+		hist[2]["trans_id"] != "" || hist[2]["data"].(map[string]interface{})["val3"] != "foo" {
+		t.Error("Unexpected response:", res)
+		return
+	}
+
+	v1 := uint64(hist[0]["version"].(float64))
+	v2 := uint64(hist[1]["version"].(float64))
+	v3 := uint64(hist[2]["version"].(float64))
+
+	st, _, res = sendTestRequest(queryURL+fmt.Sprintf("main/n/filtertest/19?from=%v&to=%v", v1, v2), "GET", nil)
+	if st != "200 OK" || res != `
+[
+  {
+    "attr": "val3",
+    "new": "bar",
+    "old": "foo"
+  }
+]`[1:] {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+fmt.Sprintf("main/n/filtertest/19?from=%v&to=%v", v1, v3), "GET", nil)
+	if st != "200 OK" || res != "[]" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	// Query edge history
+
+	st, _, res = sendTestRequest(queryURL+"main/e/Wrote/Aria1", "GET", nil)
+	if err := json.Unmarshal([]byte(res), &hist); st != "200 OK" || err != nil || len(hist) != 1 ||
+		hist[0]["data"].(map[string]interface{})["end2key"] != "Aria1" {
+		t.Error("Unexpected response:", st, res, err)
+		return
+	}
+
+	v1 = uint64(hist[0]["version"].(float64))
+
+	st, _, res = sendTestRequest(queryURL+fmt.Sprintf("main/e/Wrote/Aria1?from=%v&to=%v", v1-1, v1), "GET", nil)
+	if err := json.Unmarshal([]byte(res), &hist); st != "200 OK" || err != nil || len(hist) != 11 ||
+		hist[0]["attr"] != "end1cascading" || hist[0]["old"] != nil {
+		t.Error("Unexpected response:", st, res, err)
+		return
+	}
+}
@@ -0,0 +1,270 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/Fisch-Labs/FishDB/api"
+)
+
+/*
+EndpointIndexSchema is the index schema endpoint URL (rooted). Handles everything under indexschema/...
+*/
+const EndpointIndexSchema = api.APIRoot + APIv1 + "/indexschema/"
+
+/*
+IndexSchemaEndpointInst creates a new endpoint handler.
+*/
+func IndexSchemaEndpointInst() api.RestEndpointHandler {
+	return &indexSchemaEndpoint{}
+}
+
+/*
+Handler object for index schema operations.
+*/
+type indexSchemaEndpoint struct {
+	*api.DefaultEndpointHandler
+}
+
+/*
+indexRebuilds holds the state of all index rebuilds which were started (key is
+kind#partition).
+*/
+var indexRebuilds = make(map[string]string)
+
+/*
+indexRebuildsLock is the lock for the indexRebuilds map.
+*/
+var indexRebuildsLock = &sync.Mutex{}
+
+/*
+HandleGET handles a REST call to return the index schema of a kind.
+*/
+func (ie *indexSchemaEndpoint) HandleGET(w http.ResponseWriter, r *http.Request, resources []string) {
+
+	// Check parameters
+
+	if !checkResources(w, resources, 1, 1, "Need a kind") {
+		return
+	}
+
+	kind := resources[0]
+
+	rebuilds := make(map[string]string)
+
+	indexRebuildsLock.Lock()
+
+	for _, part := range api.GM.Partitions() {
+		if state, ok := indexRebuilds[kind+"#"+part]; ok {
+			rebuilds[part] = state
+		}
+	}
+
+	indexRebuildsLock.Unlock()
+
+	data := map[string]interface{}{
+		"schema":   api.GM.IndexSchema(kind),
+		"rebuilds": rebuilds,
+	}
+
+	// Write data
+
+	w.Header().Set("content-type", "application/json; charset=utf-8")
+
+	ret := json.NewEncoder(w)
+	ret.Encode(data)
+}
+
+/*
+HandlePUT handles a REST call to change the index schema of a kind.
+*/
+func (ie *indexSchemaEndpoint) HandlePUT(w http.ResponseWriter, r *http.Request, resources []string) {
+
+	// Check parameters
+
+	if !checkResources(w, resources, 1, 1, "Need a kind") {
+		return
+	}
+
+	dec := json.NewDecoder(r.Body)
+
+	modes := make(map[string]string)
+
+	if err := dec.Decode(&modes); err != nil {
+		http.Error(w, "Could not decode request body as map of attributes to index modes: "+
+			err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := api.GM.SetIndexModes(resources[0], modes); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+}
+
+/*
+HandlePOST handles a REST call to rebuild the index of a kind in a partition.
+The index is rebuilt in the background.
+*/
+func (ie *indexSchemaEndpoint) HandlePOST(w http.ResponseWriter, r *http.Request, resources []string) {
+
+	// Check parameters
+
+	if !checkResources(w, resources, 2, 2, "Need a kind and a partition") {
+		return
+	}
+
+	kind, part := resources[0], resources[1]
+	rkey := kind + "#" + part
+
+	indexRebuildsLock.Lock()
+	defer indexRebuildsLock.Unlock()
+
+	if indexRebuilds[rkey] == "running" {
+		http.Error(w, fmt.Sprintf("Index of %v in partition %v is already being rebuilt", kind, part),
+			http.StatusConflict)
+		return
+	}
+
+	indexRebuilds[rkey] = "running"
+
+	go func() {
+		var state string
+
+		count, err := api.GM.RebuildIndex(part, kind)
+
+		if err != nil {
+			state = "error: " + err.Error()
+		} else {
+			state = fmt.Sprintf("finished (%v items)", count)
+		}
+
+		indexRebuildsLock.Lock()
+		indexRebuilds[rkey] = state
+		indexRebuildsLock.Unlock()
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+/*
+SwaggerDefs is used to describe the endpoint in swagger.
+*/
+func (ie *indexSchemaEndpoint) SwaggerDefs(s map[string]interface{}) {
+
+	kindParam := map[string]interface{}{
+		"name":        "kind",
+		"in":          "path",
+		"description": "Node or edge kind.",
+		"required":    true,
+		"type":        "string",
+	}
+
+	s["paths"].(map[string]interface{})["/v1/indexschema/{kind}"] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary":     "Return the index schema of a kind.",
+			"description": "The index schema defines for every attribute of a node or edge kind how it is indexed (none, value, fulltext or range). The mode of all attributes without a mode of their own is stored under the empty attribute name. The state of all started index rebuilds is also returned.",
+			"produces": []string{
+				"text/plain",
+				"application/json",
+			},
+			"parameters": []map[string]interface{}{
+				kindParam,
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "An object with the schema (map of attribute to index mode) and the rebuilds (map of partition to rebuild state).",
+				},
+				"default": map[string]interface{}{
+					"description": "Error response",
+					"schema": map[string]interface{}{
+						"$ref": "#/definitions/Error",
+					},
+				},
+			},
+		},
+		"put": map[string]interface{}{
+			"summary":     "Change the index schema of a kind.",
+			"description": "The body must be a map of attribute to index mode. An empty mode removes the attribute from the schema. Existing index entries are not changed until the index is rebuilt.",
+			"consumes": []string{
+				"application/json",
+			},
+			"produces": []string{
+				"text/plain",
+			},
+			"parameters": []map[string]interface{}{
+				kindParam,
+				{
+					"name":        "modes",
+					"in":          "body",
+					"description": "Map of attribute to index mode.",
+					"required":    true,
+					"schema": map[string]interface{}{
+						"type": "object",
+						"additionalProperties": map[string]interface{}{
+							"type": "string",
+						},
+					},
+				},
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "No data is returned when the schema was changed.",
+				},
+				"default": map[string]interface{}{
+					"description": "Error response",
+					"schema": map[string]interface{}{
+						"$ref": "#/definitions/Error",
+					},
+				},
+			},
+		},
+	}
+
+	s["paths"].(map[string]interface{})["/v1/indexschema/{kind}/{partition}"] = map[string]interface{}{
+		"post": map[string]interface{}{
+			"summary":     "Rebuild the index of a kind in a partition.",
+			"description": "The index of all nodes and edges of the kind is rebuilt in the background using the current index schema. The existing index is used until the new index is complete. The partition can be written to while the index is rebuilt. The state of the rebuild can be queried with a GET request.",
+			"produces": []string{
+				"text/plain",
+			},
+			"parameters": []map[string]interface{}{
+				kindParam,
+				{
+					"name":        "partition",
+					"in":          "path",
+					"description": "Partition which should be reindexed.",
+					"required":    true,
+					"type":        "string",
+				},
+			},
+			"responses": map[string]interface{}{
+				"202": map[string]interface{}{
+					"description": "The rebuild was started.",
+				},
+				"default": map[string]interface{}{
+					"description": "Error response",
+					"schema": map[string]interface{}{
+						"$ref": "#/definitions/Error",
+					},
+				},
+			},
+		},
+	}
+
+	// Add generic error object to definition
+
+	s["definitions"].(map[string]interface{})["Error"] = map[string]interface{}{
+		"description": "A human readable error mesage.",
+		"type":        "string",
+	}
+}
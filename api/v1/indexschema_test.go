@@ -0,0 +1,136 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package v1
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Fisch-Labs/FishDB/api"
+	"github.com/Fisch-Labs/FishDB/graph/data"
+)
+
+func TestIndexSchema(t *testing.T) {
+	queryURL := "http://localhost" + TESTPORT + EndpointIndexSchema
+
+	node := data.NewGraphNode()
+	node.SetAttr("key", "1")
+	node.SetAttr("kind", "schematest")
+	node.SetAttr("text", "Some long text")
+
+	api.GM.StoreNode("main", node)
+
+	defer func() {
+		api.GM.RemoveNode("main", "1", "schematest")
+		api.GM.SetIndexMode("schematest", "text", "")
+	}()
+
+	st, _, res := sendTestRequest(queryURL+"schematest", "GET", nil)
+	if st != "200 OK" || res != `
+{
+  "rebuilds": {},
+  "schema": {}
+}`[1:] {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"schematest", "PUT", []byte(`{"text": "value"}`))
+	if st != "200 OK" || res != "" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	// Existing index entries stay until the index is rebuilt
+
+	iq, _ := api.GM.NodeIndexQuery("main", "schematest")
+
+	if res, err := iq.LookupWord("text", "long"); err != nil || len(res) != 1 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"schematest/main", "POST", nil)
+	if st != "202 Accepted" || res != "" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	for i := 0; i < 100; i++ {
+		if _, _, res = sendTestRequest(queryURL+"schematest", "GET", nil); res != `
+{
+  "rebuilds": {
+    "main": "running"
+  },
+  "schema": {
+    "text": "value"
+  }
+}`[1:] {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if res != `
+{
+  "rebuilds": {
+    "main": "finished (1 items)"
+  },
+  "schema": {
+    "text": "value"
+  }
+}`[1:] {
+		t.Error("Unexpected response:", res)
+		return
+	}
+
+	if res, err := iq.LookupWord("text", "long"); err != nil || res != nil {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := iq.LookupValue("text", "Some long text"); err != nil || len(res) != 1 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Test error cases
+
+	st, _, res = sendTestRequest(queryURL, "GET", nil)
+	if st != "400 Bad Request" || res != "Need a kind" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"schematest", "POST", nil)
+	if st != "400 Bad Request" || res != "Need a kind and a partition" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"schematest", "PUT", []byte(`{"name": "none", "text": "foo"}`))
+	if st != "400 Bad Request" || res != "GraphError: Invalid data (Unknown index mode: foo)" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	// The schema is not changed if a mode is invalid
+
+	if res := api.GM.IndexSchema("schematest"); fmt.Sprint(res) != "map[text:value]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"schematest", "PUT", []byte(`[1]`))
+	if st != "400 Bad Request" || res != "Could not decode request body as map of attributes to index modes: "+
+		"json: cannot unmarshal array into Go value of type map[string]string" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+}
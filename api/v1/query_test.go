@@ -8,7 +8,13 @@
 package v1
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/url"
 	"testing"
+
+	"github.com/Fisch-Labs/FishDB/api"
+	"github.com/Fisch-Labs/FishDB/graph"
 )
 
 func TestQueryPagination(t *testing.T) {
@@ -592,3 +598,66 @@ func TestGroupingInfo(t *testing.T) {
 		return
 	}
 }
+
+func TestQueryNearest(t *testing.T) {
+	graphURL := "http://localhost" + TESTPORT + EndpointGraph
+	queryURL := "http://localhost" + TESTPORT + EndpointQuery
+
+	api.GM.SetSchema("NearestTest", &graph.KindSchema{Attrs: map[string]*graph.AttrSchema{
+		"embedding": {Type: "vector"},
+	}})
+
+	defer func() {
+		for _, key := range []string{"a", "b", "c"} {
+			api.GM.RemoveNode("main", key, "NearestTest")
+		}
+		api.GM.SetSchema("NearestTest", nil)
+	}()
+
+	// Ingest nodes with vectors over the REST API
+
+	st, _, res := sendTestRequest(graphURL+"main/n", "POST", []byte(`
+[{
+	"key":"a",
+	"kind":"NearestTest",
+	"embedding":[1, 0, 0]
+},{
+	"key":"b",
+	"kind":"NearestTest",
+	"embedding":[0, 1, 0]
+},{
+	"key":"c",
+	"kind":"NearestTest",
+	"embedding":[0.9, 0.1, 0]
+}]
+`[1:]))
+
+	if st != "200 OK" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	// Search the nearest nodes with a query
+
+	q := url.QueryEscape("get NearestTest nearest 2 to [1, 0, 0] on embedding")
+
+	st, _, res = sendTestRequest(queryURL+"//main?q="+q, "GET", nil)
+
+	var ret map[string]interface{}
+
+	if err := json.Unmarshal([]byte(res), &ret); st != "200 OK" || err != nil {
+		t.Error("Unexpected response:", st, res, err)
+		return
+	}
+
+	var keys []interface{}
+
+	for _, row := range ret["rows"].([]interface{}) {
+		keys = append(keys, row.([]interface{})[0])
+	}
+
+	if fmt.Sprint(keys) != "[a c]" {
+		t.Error("Unexpected result:", keys, res)
+		return
+	}
+}
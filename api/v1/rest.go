@@ -30,24 +30,38 @@ HTTPHeaderCacheID is a special header value containing a cache ID for a quick fo
 */
 const HTTPHeaderCacheID = "X-Cache-Id"
 
+/*
+HTTPHeaderTruncated is a special header value which is set if a result was truncated.
+*/
+const HTTPHeaderTruncated = "X-Truncated"
+
 /*
 V1EndpointMap is a map of urls to endpoints for version 1 of the API
 */
 var V1EndpointMap = map[string]api.RestEndpointInst{
+	EndpointAlgo:                 AlgoEndpointInst,
 	EndpointBlob:                 BlobEndpointInst,
+	EndpointChanges:              ChangesEndpointInst,
+	EndpointChangesFeed:          ChangesFeedEndpointInst,
 	EndpointClusterQuery:         ClusterEndpointInst,
 	EndpointEql:                  EqlEndpointInst,
 	EndpointGraph:                GraphEndpointInst,
 	EndpointGraphQL:              GraphQLEndpointInst,
 	EndpointGraphQLQuery:         GraphQLQueryEndpointInst,
 	EndpointGraphQLSubscriptions: GraphQLSubscriptionsEndpointInst,
+	EndpointHistory:              HistoryEndpointInst,
 	EndpointIndexQuery:           IndexEndpointInst,
+	EndpointIndexSchema:          IndexSchemaEndpointInst,
 	EndpointFindQuery:            FindEndpointInst,
 	EndpointInfoQuery:            InfoEndpointInst,
 	EndpointQuery:                QueryEndpointInst,
 	EndpointQueryResult:          QueryResultEndpointInst,
+	EndpointSchema:               SchemaEndpointInst,
+	EndpointSubgraph:             SubgraphEndpointInst,
+	EndpointTriggers:             TriggersEndpointInst,
 	EndpointECALInternal:         ECALEndpointInst,
 	EndpointECALSock:             ECALSockEndpointInst,
+	EndpointWebhooks:             WebhooksEndpointInst,
 }
 
 /*
@@ -0,0 +1,278 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Fisch-Labs/FishDB/api"
+	"github.com/Fisch-Labs/FishDB/graph"
+)
+
+/*
+EndpointSchema is the schema endpoint URL (rooted). Handles everything under schema/...
+*/
+const EndpointSchema = api.APIRoot + APIv1 + "/schema/"
+
+/*
+SchemaEndpointInst creates a new endpoint handler.
+*/
+func SchemaEndpointInst() api.RestEndpointHandler {
+	return &schemaEndpoint{}
+}
+
+/*
+Handler object for schema operations.
+*/
+type schemaEndpoint struct {
+	*api.DefaultEndpointHandler
+}
+
+/*
+HandleGET handles a REST call to return the schemas of all kinds or the schema
+of a single kind.
+*/
+func (se *schemaEndpoint) HandleGET(w http.ResponseWriter, r *http.Request, resources []string) {
+	var data interface{}
+
+	// Check parameters
+
+	if !checkResources(w, resources, 0, 1, "") {
+		return
+	}
+
+	if len(resources) == 0 {
+		schemas := make(map[string]*graph.KindSchema)
+
+		for _, kind := range api.GM.SchemaKinds() {
+			schemas[kind] = api.GM.Schema(kind)
+		}
+
+		data = schemas
+
+	} else {
+		schema := api.GM.Schema(resources[0])
+
+		if schema == nil {
+			http.Error(w, fmt.Sprintf("Kind %v has no schema", resources[0]), http.StatusNotFound)
+			return
+		}
+
+		data = schema
+	}
+
+	// Write data
+
+	w.Header().Set("content-type", "application/json; charset=utf-8")
+
+	ret := json.NewEncoder(w)
+	ret.Encode(data)
+}
+
+/*
+HandlePUT handles a REST call to set the schema of a kind.
+*/
+func (se *schemaEndpoint) HandlePUT(w http.ResponseWriter, r *http.Request, resources []string) {
+
+	// Check parameters
+
+	if !checkResources(w, resources, 1, 1, "Need a kind") {
+		return
+	}
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	schema := &graph.KindSchema{}
+
+	if err := dec.Decode(schema); err != nil {
+		http.Error(w, "Could not decode request body as schema: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := api.GM.SetSchema(resources[0], schema); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+/*
+HandleDELETE handles a REST call to remove the schema of a kind.
+*/
+func (se *schemaEndpoint) HandleDELETE(w http.ResponseWriter, r *http.Request, resources []string) {
+
+	// Check parameters
+
+	if !checkResources(w, resources, 1, 1, "Need a kind") {
+		return
+	}
+
+	if err := api.GM.SetSchema(resources[0], nil); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+/*
+SwaggerDefs is used to describe the endpoint in swagger.
+*/
+func (se *schemaEndpoint) SwaggerDefs(s map[string]interface{}) {
+
+	kindParam := map[string]interface{}{
+		"name":        "kind",
+		"in":          "path",
+		"description": "Node or edge kind.",
+		"required":    true,
+		"type":        "string",
+	}
+
+	errorResponse := map[string]interface{}{
+		"description": "Error response",
+		"schema": map[string]interface{}{
+			"$ref": "#/definitions/Error",
+		},
+	}
+
+	s["paths"].(map[string]interface{})["/v1/schema"] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary":     "Return the schemas of all kinds.",
+			"description": "Returns a map of node and edge kinds to their schema. Kinds without a schema are not part of the map.",
+			"produces": []string{
+				"text/plain",
+				"application/json",
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "A map of kinds to schemas.",
+					"schema": map[string]interface{}{
+						"type": "object",
+						"additionalProperties": map[string]interface{}{
+							"$ref": "#/definitions/KindSchema",
+						},
+					},
+				},
+				"default": errorResponse,
+			},
+		},
+	}
+
+	s["paths"].(map[string]interface{})["/v1/schema/{kind}"] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary":     "Return the schema of a kind.",
+			"description": "Returns the schema of a node or edge kind.",
+			"produces": []string{
+				"text/plain",
+				"application/json",
+			},
+			"parameters": []map[string]interface{}{
+				kindParam,
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "The schema of the kind.",
+					"schema": map[string]interface{}{
+						"$ref": "#/definitions/KindSchema",
+					},
+				},
+				"default": errorResponse,
+			},
+		},
+		"put": map[string]interface{}{
+			"summary":     "Set the schema of a kind.",
+			"description": "Nodes and edges of the kind are checked against the schema whenever they are stored. Nodes and edges which were stored before are not checked.",
+			"consumes": []string{
+				"application/json",
+			},
+			"produces": []string{
+				"text/plain",
+			},
+			"parameters": []map[string]interface{}{
+				kindParam,
+				{
+					"name":        "schema",
+					"in":          "body",
+					"description": "Schema of the kind.",
+					"required":    true,
+					"schema": map[string]interface{}{
+						"$ref": "#/definitions/KindSchema",
+					},
+				},
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "No data is returned when the schema was set.",
+				},
+				"default": errorResponse,
+			},
+		},
+		"delete": map[string]interface{}{
+			"summary":     "Remove the schema of a kind.",
+			"description": "Nodes and edges of the kind are no longer checked when they are stored.",
+			"produces": []string{
+				"text/plain",
+			},
+			"parameters": []map[string]interface{}{
+				kindParam,
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "No data is returned when the schema was removed.",
+				},
+				"default": errorResponse,
+			},
+		},
+	}
+
+	// Add schema object to definition
+
+	s["definitions"].(map[string]interface{})["KindSchema"] = map[string]interface{}{
+		"description": "Schema of a node or edge kind. Ends and cardinality are only used for edge kinds.",
+		"type":        "object",
+		"properties": map[string]interface{}{
+			"attrs": map[string]interface{}{
+				"description": "Map of attribute names to attribute schemas with a type (string, number, bool, list, map or vector) and a required flag.",
+				"type":        "object",
+				"additionalProperties": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"type": map[string]interface{}{
+							"type": "string",
+						},
+						"required": map[string]interface{}{
+							"type": "boolean",
+						},
+					},
+				},
+			},
+			"ends": map[string]interface{}{
+				"description": "List of allowed end kinds and roles of an edge kind (end1kind, end1role, end2kind, end2role). Empty values match all kinds or roles.",
+				"type":        "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"additionalProperties": map[string]interface{}{
+						"type": "string",
+					},
+				},
+			},
+			"cardinality": map[string]interface{}{
+				"description": "Map of roles to the maximal number of edges of the kind which a node may have in the role.",
+				"type":        "object",
+				"additionalProperties": map[string]interface{}{
+					"type": "integer",
+				},
+			},
+		},
+	}
+
+	// Add generic error object to definition
+
+	s["definitions"].(map[string]interface{})["Error"] = map[string]interface{}{
+		"description": "A human readable error mesage.",
+		"type":        "string",
+	}
+}
@@ -0,0 +1,113 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/api"
+	"github.com/Fisch-Labs/FishDB/graph/data"
+)
+
+func TestSchema(t *testing.T) {
+	queryURL := "http://localhost" + TESTPORT + EndpointSchema
+
+	defer func() {
+		api.GM.SetSchema("schemakind", nil)
+		api.GM.RemoveNode("main", "1", "schemakind")
+	}()
+
+	st, _, res := sendTestRequest(queryURL+"schemakind", "PUT", []byte(`{
+  "attrs": {
+    "name": {"type": "string", "required": true}
+  }
+}`))
+	if st != "200 OK" || res != "" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL, "GET", nil)
+	if st != "200 OK" || res != `
+{
+  "schemakind": {
+    "attrs": {
+      "name": {
+        "type": "string",
+        "required": true
+      }
+    }
+  }
+}`[1:] {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"schemakind", "GET", nil)
+	if st != "200 OK" || res != `
+{
+  "attrs": {
+    "name": {
+      "type": "string",
+      "required": true
+    }
+  }
+}`[1:] {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	// Nodes are checked against the schema
+
+	node := data.NewGraphNode()
+	node.SetAttr("key", "1")
+	node.SetAttr("kind", "schemakind")
+
+	if err := api.GM.StoreNode("main", node); err == nil ||
+		err.Error() != "GraphError: Graph rule error (Node 1 of kind schemakind is missing required attribute name)" {
+		t.Error(err)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"schemakind", "DELETE", nil)
+	if st != "200 OK" || res != "" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	if err := api.GM.StoreNode("main", node); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Test error cases
+
+	st, _, res = sendTestRequest(queryURL+"schemakind", "GET", nil)
+	if st != "404 Not Found" || res != "Kind schemakind has no schema" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL, "PUT", []byte("{}"))
+	if st != "400 Bad Request" || res != "Need a kind" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"schemakind", "PUT", []byte(`{"attr": {}}`))
+	if st != "400 Bad Request" || res != `Could not decode request body as schema: json: unknown field "attr"` {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"schemakind", "PUT", []byte(`{"attrs": {"name": {"type": "foo"}}}`))
+	if st != "400 Bad Request" || res != "GraphError: Invalid data (Unknown type of attribute name: foo)" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+}
@@ -0,0 +1,222 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package v1
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Fisch-Labs/FishDB/api"
+	"github.com/Fisch-Labs/FishDB/api/ac"
+	"github.com/Fisch-Labs/FishDB/graph/algo"
+	"github.com/Fisch-Labs/Toolkit/httputil/access"
+)
+
+/*
+EndpointSubgraph is the subgraph endpoint URL (rooted). Handles everything under subgraph/...
+*/
+const EndpointSubgraph = api.APIRoot + APIv1 + "/subgraph/"
+
+/*
+SubgraphEndpointInst creates a new endpoint handler.
+*/
+func SubgraphEndpointInst() api.RestEndpointHandler {
+	return &subgraphEndpoint{}
+}
+
+/*
+Handler object for subgraph extraction.
+*/
+type subgraphEndpoint struct {
+	*api.DefaultEndpointHandler
+}
+
+/*
+graphReadAccess checks if the user of a request may read a resource of the
+graph endpoint. Access is always granted if access control is disabled.
+*/
+var graphReadAccess = func(r *http.Request, resource string) bool {
+
+	if ac.ACL == nil || ac.AuthHandler == nil {
+		return true
+	}
+
+	user, _ := ac.AuthHandler.CheckAuth(r)
+
+	res, _, err := ac.ACL.IsPermitted(user, resource, &access.Rights{Read: true})
+
+	return res && err == nil
+}
+
+/*
+HandleGET handles a REST call to extract a subgraph around a set of seed nodes.
+*/
+func (se *subgraphEndpoint) HandleGET(w http.ResponseWriter, r *http.Request, resources []string) {
+
+	// Check parameters
+
+	if !checkResources(w, resources, 1, 1, "Need a partition") {
+		return
+	}
+
+	part := resources[0]
+	params := r.URL.Query()
+
+	if params.Get("seeds") == "" {
+		http.Error(w, "Query string for seeds is required", http.StatusBadRequest)
+		return
+	}
+
+	seeds, err := algo.ParseNodeIDs(params.Get("seeds"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hops, ok := queryParamPosNum(w, r, "hops")
+	if !ok {
+		return
+	} else if hops == -1 {
+		hops = 1
+	}
+
+	maxTokens, ok := queryParamPosNum(w, r, "maxtokens")
+	if !ok {
+		return
+	}
+
+	format := params.Get("format")
+	if format == "" {
+		format = algo.FormatJSON
+	}
+
+	// Only kinds which the user may read are part of the subgraph
+
+	nodeKinds := api.GM.NodeKinds()
+	if params.Get("kinds") != "" {
+		nodeKinds = strings.Split(params.Get("kinds"), ",")
+	}
+
+	edgeKinds := api.GM.EdgeKinds()
+	if params.Get("edgekinds") != "" {
+		edgeKinds = strings.Split(params.Get("edgekinds"), ",")
+	}
+
+	allowed := func(kinds []string, entityType string) []string {
+		res := make([]string, 0, len(kinds))
+
+		for _, kind := range kinds {
+			if graphReadAccess(r, EndpointGraph+part+"/"+entityType+"/"+kind) {
+				res = append(res, kind)
+			}
+		}
+
+		return res
+	}
+
+	g, err := algo.NewGraph(api.GM, part, params.Get("spec"), allowed(nodeKinds, "n"))
+
+	if err == nil {
+		var sg *algo.Subgraph
+		var res string
+
+		g.SetEdgeKinds(allowed(edgeKinds, "e"))
+
+		if sg, err = g.Subgraph(seeds, hops); err == nil {
+			if sg, err = sg.Limit(format, maxTokens); err == nil {
+				if res, err = sg.Format(format); err == nil {
+
+					// Write data
+
+					if format == algo.FormatJSON {
+						w.Header().Set("content-type", "application/json; charset=utf-8")
+					} else {
+						w.Header().Set("content-type", "text/plain; charset=utf-8")
+					}
+
+					if sg.Truncated {
+						w.Header().Set(HTTPHeaderTruncated, "true")
+					}
+
+					w.Write([]byte(res))
+				}
+			}
+		}
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+/*
+SwaggerDefs is used to describe the endpoint in swagger.
+*/
+func (se *subgraphEndpoint) SwaggerDefs(s map[string]interface{}) {
+
+	queryParam := func(name string, description string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":        name,
+			"in":          "query",
+			"description": description,
+			"required":    false,
+			"type":        "string",
+		}
+	}
+
+	s["paths"].(map[string]interface{})["/v1/subgraph/{partition}"] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary":     "Extract the subgraph around a set of seed nodes.",
+			"description": "The subgraph contains all nodes which can be reached from the seed nodes within a number of hops and all edges between them. Only node and edge kinds which the user may read are part of the subgraph. The subgraph can be returned as JSON or in a compact text format (triples or markdown) which fits into a given token budget.",
+			"produces": []string{
+				"text/plain",
+				"application/json",
+			},
+			"parameters": []map[string]interface{}{
+				{
+					"name":        "partition",
+					"in":          "path",
+					"description": "Partition of the graph.",
+					"required":    true,
+					"type":        "string",
+				},
+				{
+					"name":        "seeds",
+					"in":          "query",
+					"description": "Comma separated list of seed nodes as kind:key pairs.",
+					"required":    true,
+					"type":        "string",
+				},
+				queryParam("hops", "Maximal distance of nodes from the seed nodes (default is 1)."),
+				queryParam("spec", "Traversal spec which defines the followed edges (default is all edges)."),
+				queryParam("kinds", "Comma separated list of node kinds of the subgraph (default is all node kinds)."),
+				queryParam("edgekinds", "Comma separated list of edge kinds which are followed (default is all edge kinds)."),
+				queryParam("format", "Format of the subgraph: json, triples or markdown (default is json)."),
+				queryParam("maxtokens", "Maximal number of tokens of the formatted subgraph (about 4 characters per token). Nodes which are further away from the seed nodes are left out first."),
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "The formatted subgraph. The X-Truncated header is set if nodes were left out because of the token budget.",
+				},
+				"default": map[string]interface{}{
+					"description": "Error response",
+					"schema": map[string]interface{}{
+						"$ref": "#/definitions/Error",
+					},
+				},
+			},
+		},
+	}
+
+	// Add generic error object to definition
+
+	s["definitions"].(map[string]interface{})["Error"] = map[string]interface{}{
+		"description": "A human readable error mesage.",
+		"type":        "string",
+	}
+}
@@ -0,0 +1,155 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package v1
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/api"
+	"github.com/Fisch-Labs/FishDB/graph/data"
+)
+
+func TestSubgraph(t *testing.T) {
+	queryURL := "http://localhost" + TESTPORT + EndpointSubgraph
+
+	for _, node := range [][]string{{"a", "subgraphtest"}, {"b", "subgraphtest"}, {"c", "subgraphsecret"}} {
+		n := data.NewGraphNode()
+		n.SetAttr("key", node[0])
+		n.SetAttr("kind", node[1])
+		n.SetAttr("name", "Node "+node[0])
+		api.GM.StoreNode("main", n)
+	}
+
+	for _, link := range [][]string{{"a", "subgraphtest", "b", "subgraphtest"}, {"b", "subgraphtest", "c", "subgraphsecret"}} {
+		edge := data.NewGraphEdge()
+		edge.SetAttr("key", link[0]+link[2])
+		edge.SetAttr("kind", "subgraphlink")
+		edge.SetAttr(data.EdgeEnd1Key, link[0])
+		edge.SetAttr(data.EdgeEnd1Kind, link[1])
+		edge.SetAttr(data.EdgeEnd1Role, "From")
+		edge.SetAttr(data.EdgeEnd1Cascading, false)
+		edge.SetAttr(data.EdgeEnd2Key, link[2])
+		edge.SetAttr(data.EdgeEnd2Kind, link[3])
+		edge.SetAttr(data.EdgeEnd2Role, "To")
+		edge.SetAttr(data.EdgeEnd2Cascading, false)
+		api.GM.StoreEdge("main", edge)
+	}
+
+	defer func() {
+		api.GM.RemoveNode("main", "a", "subgraphtest")
+		api.GM.RemoveNode("main", "b", "subgraphtest")
+		api.GM.RemoveNode("main", "c", "subgraphsecret")
+	}()
+
+	st, _, res := sendTestRequest(queryURL+"main?seeds=subgraphtest:a&hops=2&format=triples", "GET", nil)
+	if st != "200 OK" || res != `
+subgraphtest:a name "Node a"
+subgraphtest:b name "Node b"
+subgraphtest:a subgraphlink subgraphtest:b
+subgraphsecret:c name "Node c"
+subgraphtest:b subgraphlink subgraphsecret:c`[1:] {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	// Apply a token budget
+
+	st, h, res := sendTestRequest(queryURL+"main?seeds=subgraphtest:a&hops=2&format=markdown&maxtokens=40", "GET", nil)
+	if st != "200 OK" || h.Get(HTTPHeaderTruncated) != "true" || res != `
+## subgraphtest a
+- name: "Node a"
+
+## subgraphtest b
+- name: "Node b"
+- subgraphtest a -[subgraphlink]-> subgraphtest b`[1:] {
+		t.Error("Unexpected response:", st, h, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"main?seeds=subgraphtest:b&edgekinds=subgraphlink&hops=0", "GET", nil)
+	if st != "200 OK" || res != `
+{
+  "edges": [],
+  "nodes": [
+    {
+      "key": "b",
+      "kind": "subgraphtest",
+      "name": "Node b"
+    }
+  ],
+  "truncated": false
+}`[1:] {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	// Node kinds which the user may not read are left out
+
+	oldGraphReadAccess := graphReadAccess
+	graphReadAccess = func(r *http.Request, resource string) bool {
+		return !strings.HasSuffix(resource, "/n/subgraphsecret")
+	}
+	defer func() {
+		graphReadAccess = oldGraphReadAccess
+	}()
+
+	st, _, res = sendTestRequest(queryURL+"main?seeds=subgraphtest:b,subgraphsecret:c&format=triples", "GET", nil)
+	if st != "200 OK" || res != `
+subgraphtest:b name "Node b"
+subgraphtest:a name "Node a"
+subgraphtest:a subgraphlink subgraphtest:b`[1:] {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	// Test error cases
+
+	st, _, res = sendTestRequest(queryURL, "GET", nil)
+	if st != "400 Bad Request" || res != "Need a partition" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"main", "GET", nil)
+	if st != "400 Bad Request" || res != "Query string for seeds is required" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"main?seeds=a", "GET", nil)
+	if st != "400 Bad Request" || res != "GraphError: Invalid data (Node must be given as kind:key: a)" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"main?seeds=subgraphtest:a&hops=x", "GET", nil)
+	if st != "400 Bad Request" || res != "Invalid parameter value: hops should be a positive integer number" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"main?seeds=subgraphtest:a&maxtokens=x", "GET", nil)
+	if st != "400 Bad Request" || res != "Invalid parameter value: maxtokens should be a positive integer number" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"main?seeds=subgraphtest:a&format=foo", "GET", nil)
+	if st != "400 Bad Request" || res != "GraphError: Invalid data (Unknown subgraph format: foo)" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"main?seeds=subgraphtest:a&spec=x", "GET", nil)
+	if st != "400 Bad Request" || res != "GraphError: Invalid data (Invalid spec: x)" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+}
@@ -0,0 +1,396 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Fisch-Labs/FishDB/api"
+	"github.com/Fisch-Labs/FishDB/ecal"
+)
+
+/*
+EndpointTriggers is the triggers endpoint URL (rooted). Handles everything under triggers/...
+*/
+const EndpointTriggers = api.APIRoot + APIv1 + "/triggers/"
+
+/*
+TriggersEndpointInst creates a new endpoint handler.
+*/
+func TriggersEndpointInst() api.RestEndpointHandler {
+	return &triggersEndpoint{}
+}
+
+/*
+Handler object for trigger operations.
+*/
+type triggersEndpoint struct {
+	*api.DefaultEndpointHandler
+}
+
+/*
+HandleGET handles a REST call to return all triggers, a single trigger or all
+versions of a trigger.
+*/
+func (te *triggersEndpoint) HandleGET(w http.ResponseWriter, r *http.Request, resources []string) {
+	var data interface{}
+
+	// Check parameters
+
+	if !te.checkScripting(w) || !checkResources(w, resources, 0, 2, "") {
+		return
+	}
+
+	if len(resources) == 0 {
+		data = api.SI.Triggers()
+
+	} else if trigger := api.SI.Trigger(resources[0]); trigger == nil {
+		http.Error(w, fmt.Sprintf("Unknown trigger %v", resources[0]), http.StatusNotFound)
+		return
+
+	} else if len(resources) == 1 {
+		data = trigger
+
+	} else if resources[1] != "versions" {
+		http.Error(w, "Invalid resource specification: "+resources[1], http.StatusBadRequest)
+		return
+
+	} else {
+		versions, err := api.SI.TriggerVersions(resources[0])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		data = versions
+	}
+
+	// Write data
+
+	w.Header().Set("content-type", "application/json; charset=utf-8")
+
+	ret := json.NewEncoder(w)
+	ret.Encode(data)
+}
+
+/*
+HandlePUT handles a REST call to define, enable, disable or revert a trigger.
+*/
+func (te *triggersEndpoint) HandlePUT(w http.ResponseWriter, r *http.Request, resources []string) {
+	var err error
+
+	// Check parameters
+
+	if !te.checkScripting(w) || !checkResources(w, resources, 1, 3, "Need a trigger name") {
+		return
+	}
+
+	name := resources[0]
+
+	if len(resources) == 1 {
+
+		// Define a new version of a trigger - triggers are enabled by default
+
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		trigger := &ecal.Trigger{Enabled: true}
+
+		if err := dec.Decode(trigger); err != nil {
+			http.Error(w, "Could not decode request body as trigger: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if trigger.Name != "" && trigger.Name != name {
+			http.Error(w, fmt.Sprintf("Trigger name %v does not match resource name %v",
+				trigger.Name, name), http.StatusBadRequest)
+			return
+		}
+
+		trigger.Name = name
+
+		err = api.SI.DefineTrigger(trigger)
+
+	} else if len(resources) == 2 && (resources[1] == "enable" || resources[1] == "disable") {
+
+		err = api.SI.EnableTrigger(name, resources[1] == "enable")
+
+	} else if len(resources) == 3 && resources[1] == "versions" {
+
+		version, verr := strconv.Atoi(resources[2])
+		if verr != nil {
+			http.Error(w, "Invalid version: "+resources[2], http.StatusBadRequest)
+			return
+		}
+
+		err = api.SI.RevertTrigger(name, version)
+
+	} else {
+
+		http.Error(w, "Invalid resource specification: "+strings.Join(resources[1:], "/"), http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+/*
+HandleDELETE handles a REST call to remove a trigger.
+*/
+func (te *triggersEndpoint) HandleDELETE(w http.ResponseWriter, r *http.Request, resources []string) {
+
+	// Check parameters
+
+	if !te.checkScripting(w) || !checkResources(w, resources, 1, 1, "Need a trigger name") {
+		return
+	}
+
+	if err := api.SI.RemoveTrigger(resources[0]); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+/*
+checkScripting checks if ECAL scripting is enabled.
+*/
+func (te *triggersEndpoint) checkScripting(w http.ResponseWriter) bool {
+	if api.SI == nil {
+		http.Error(w, "ECAL scripting is not enabled", http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+/*
+SwaggerDefs is used to describe the endpoint in swagger.
+*/
+func (te *triggersEndpoint) SwaggerDefs(s map[string]interface{}) {
+
+	nameParam := map[string]interface{}{
+		"name":        "name",
+		"in":          "path",
+		"description": "Name of the trigger.",
+		"required":    true,
+		"type":        "string",
+	}
+
+	errorResponse := map[string]interface{}{
+		"description": "Error response",
+		"schema": map[string]interface{}{
+			"$ref": "#/definitions/Error",
+		},
+	}
+
+	triggerList := map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"$ref": "#/definitions/Trigger",
+		},
+	}
+
+	s["paths"].(map[string]interface{})["/v1/triggers"] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary":     "Return all triggers.",
+			"description": "Triggers are ECAL code which is stored in the database and run for graph events of certain kinds. ECAL scripting needs to be enabled in the server configuration.",
+			"produces": []string{
+				"text/plain",
+				"application/json",
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "A list of triggers.",
+					"schema":      triggerList,
+				},
+				"default": errorResponse,
+			},
+		},
+	}
+
+	s["paths"].(map[string]interface{})["/v1/triggers/{name}"] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary":     "Return a trigger.",
+			"description": "Returns the current version of a trigger.",
+			"produces": []string{
+				"text/plain",
+				"application/json",
+			},
+			"parameters": []map[string]interface{}{
+				nameParam,
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "The trigger.",
+					"schema": map[string]interface{}{
+						"$ref": "#/definitions/Trigger",
+					},
+				},
+				"default": errorResponse,
+			},
+		},
+		"put": map[string]interface{}{
+			"summary":     "Define a new version of a trigger.",
+			"description": "The trigger is loaded into the ECAL interpreter once it was stored. New triggers are enabled unless enabled is set to false.",
+			"consumes": []string{
+				"application/json",
+			},
+			"produces": []string{
+				"text/plain",
+			},
+			"parameters": []map[string]interface{}{
+				nameParam,
+				{
+					"name":        "trigger",
+					"in":          "body",
+					"description": "Trigger definition (the version is ignored).",
+					"required":    true,
+					"schema": map[string]interface{}{
+						"$ref": "#/definitions/Trigger",
+					},
+				},
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "No data is returned when the trigger was defined.",
+				},
+				"default": errorResponse,
+			},
+		},
+		"delete": map[string]interface{}{
+			"summary":     "Remove a trigger.",
+			"description": "The trigger is unloaded from the ECAL interpreter.",
+			"produces": []string{
+				"text/plain",
+			},
+			"parameters": []map[string]interface{}{
+				nameParam,
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "No data is returned when the trigger was removed.",
+				},
+				"default": errorResponse,
+			},
+		},
+	}
+
+	for action, summary := range map[string]string{
+		"enable":  "Enable a trigger.",
+		"disable": "Disable a trigger.",
+	} {
+		s["paths"].(map[string]interface{})["/v1/triggers/{name}/"+action] = map[string]interface{}{
+			"put": map[string]interface{}{
+				"summary":     summary,
+				"description": "Disabled triggers are kept in the database but are not run.",
+				"produces": []string{
+					"text/plain",
+				},
+				"parameters": []map[string]interface{}{
+					nameParam,
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": fmt.Sprintf("No data is returned when the trigger was %vd.", action),
+					},
+					"default": errorResponse,
+				},
+			},
+		}
+	}
+
+	s["paths"].(map[string]interface{})["/v1/triggers/{name}/versions"] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary":     "Return all versions of a trigger.",
+			"description": "Returns all versions of a trigger from the oldest to the newest version.",
+			"produces": []string{
+				"text/plain",
+				"application/json",
+			},
+			"parameters": []map[string]interface{}{
+				nameParam,
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "A list of trigger versions.",
+					"schema":      triggerList,
+				},
+				"default": errorResponse,
+			},
+		},
+	}
+
+	s["paths"].(map[string]interface{})["/v1/triggers/{name}/versions/{version}"] = map[string]interface{}{
+		"put": map[string]interface{}{
+			"summary":     "Revert a trigger to a previous version.",
+			"description": "Defines a new version of the trigger with the event kinds and the body of a previous version.",
+			"produces": []string{
+				"text/plain",
+			},
+			"parameters": []map[string]interface{}{
+				nameParam,
+				{
+					"name":        "version",
+					"in":          "path",
+					"description": "Version which should be restored.",
+					"required":    true,
+					"type":        "integer",
+				},
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "No data is returned when the trigger was reverted.",
+				},
+				"default": errorResponse,
+			},
+		},
+	}
+
+	// Add trigger object to definition
+
+	s["definitions"].(map[string]interface{})["Trigger"] = map[string]interface{}{
+		"description": "ECAL trigger which is run for graph events.",
+		"type":        "object",
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{
+				"description": "Name of the trigger.",
+				"type":        "string",
+			},
+			"kinds": map[string]interface{}{
+				"description": "Event kinds which run the trigger (e.g. db.node.created).",
+				"type":        "array",
+				"items": map[string]interface{}{
+					"type": "string",
+				},
+			},
+			"body": map[string]interface{}{
+				"description": "ECAL code of the trigger. The event is available in the variable event.",
+				"type":        "string",
+			},
+			"enabled": map[string]interface{}{
+				"description": "Flag if the trigger is enabled.",
+				"type":        "boolean",
+			},
+			"version": map[string]interface{}{
+				"description": "Version of the trigger definition.",
+				"type":        "integer",
+			},
+		},
+	}
+
+	// Add generic error object to definition
+
+	s["definitions"].(map[string]interface{})["Error"] = map[string]interface{}{
+		"description": "A human readable error mesage.",
+		"type":        "string",
+	}
+}
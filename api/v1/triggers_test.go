@@ -0,0 +1,170 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/api"
+	"github.com/Fisch-Labs/FishDB/graph/data"
+)
+
+func TestTriggers(t *testing.T) {
+	queryURL := "http://localhost" + TESTPORT + EndpointTriggers
+
+	oldSI := api.SI
+	api.SI = nil
+
+	st, _, res := sendTestRequest(queryURL, "GET", nil)
+	if st != "404 Not Found" || res != "ECAL scripting is not enabled" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	api.SI = oldSI
+
+	writeScript(`
+log("test triggers")
+`)
+
+	if err := api.SI.Run(); err != nil {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"logger", "PUT", []byte(`{
+  "kinds": ["db.node.moved"],
+  "body": "log(1)"
+}`))
+	if st != "400 Bad Request" || res != "Unknown event kind for trigger logger: db.node.moved" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"logger", "PUT", []byte(`{
+  "kinds": ["db.node.created"],
+  "body": "log(\"Created: \", event.state.node.key)"
+}`))
+	if st != "200 OK" || res != "" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	defer api.SI.RemoveTrigger("logger")
+
+	st, _, res = sendTestRequest(queryURL, "GET", nil)
+	if st != "200 OK" || res != `
+[
+  {
+    "name": "logger",
+    "kinds": [
+      "db.node.created"
+    ],
+    "body": "log(\"Created: \", event.state.node.key)",
+    "enabled": true,
+    "version": 1
+  }
+]`[1:] {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	node := data.NewGraphNode()
+	node.SetAttr("key", "triggertest")
+	node.SetAttr("kind", "triggertest")
+
+	if err := api.GM.StoreNode("main", node); err != nil {
+		t.Error(err)
+		return
+	}
+
+	defer api.GM.RemoveNode("main", "triggertest", "triggertest")
+
+	if err := checkLog(`test triggers
+Created: triggertest
+`); err != nil {
+		t.Error(err)
+	}
+
+	// Disable the trigger and define a new version
+
+	st, _, res = sendTestRequest(queryURL+"logger/disable", "PUT", nil)
+	if st != "200 OK" || res != "" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"logger", "PUT", []byte(`{
+  "kinds": ["db.node.updated"],
+  "body": "log(\"Updated: \", event.state.node.key)",
+  "enabled": false
+}`))
+	if st != "200 OK" || res != "" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	var versions []map[string]interface{}
+
+	st, _, res = sendTestRequest(queryURL+"logger/versions", "GET", nil)
+	if err := json.Unmarshal([]byte(res), &versions); st != "200 OK" || err != nil || len(versions) != 2 ||
+		versions[0]["version"] != float64(1) || versions[1]["version"] != float64(2) ||
+		versions[1]["enabled"] != false {
+		t.Error("Unexpected response:", st, res, err)
+		return
+	}
+
+	// Revert to the first version and enable the trigger again
+
+	st, _, res = sendTestRequest(queryURL+"logger/versions/1", "PUT", nil)
+	if st != "200 OK" || res != "" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"logger/enable", "PUT", nil)
+	if st != "200 OK" || res != "" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	var trigger map[string]interface{}
+
+	st, _, res = sendTestRequest(queryURL+"logger", "GET", nil)
+	if err := json.Unmarshal([]byte(res), &trigger); st != "200 OK" || err != nil ||
+		trigger["version"] != float64(3) || trigger["enabled"] != true ||
+		trigger["kinds"].([]interface{})[0] != "db.node.created" {
+		t.Error("Unexpected response:", st, res, err)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"logger/foo", "PUT", nil)
+	if st != "400 Bad Request" || res != "Invalid resource specification: foo" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"logger/versions/x", "PUT", nil)
+	if st != "400 Bad Request" || res != "Invalid version: x" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"logger", "DELETE", nil)
+	if st != "200 OK" || res != "" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"logger", "GET", nil)
+	if st != "404 Not Found" || res != "Unknown trigger logger" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+}
@@ -0,0 +1,368 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Fisch-Labs/FishDB/api"
+	"github.com/Fisch-Labs/FishDB/webhook"
+)
+
+/*
+EndpointWebhooks is the webhooks endpoint URL (rooted). Handles everything under webhooks/...
+*/
+const EndpointWebhooks = api.APIRoot + APIv1 + "/webhooks/"
+
+/*
+WebhooksEndpointInst creates a new endpoint handler.
+*/
+func WebhooksEndpointInst() api.RestEndpointHandler {
+	return &webhooksEndpoint{}
+}
+
+/*
+Handler object for webhook operations.
+*/
+type webhooksEndpoint struct {
+	*api.DefaultEndpointHandler
+}
+
+/*
+HandleGET handles a REST call to return all webhooks, a single webhook or the
+dead letters of a webhook.
+*/
+func (we *webhooksEndpoint) HandleGET(w http.ResponseWriter, r *http.Request, resources []string) {
+	var data interface{}
+
+	// Check parameters
+
+	if !we.checkWebhooks(w) || !we.checkResources(w, resources, 0, "") {
+		return
+	}
+
+	if len(resources) == 0 {
+		data = api.WH.Webhooks()
+
+	} else if hook := api.WH.Webhook(resources[0]); hook == nil {
+		http.Error(w, fmt.Sprintf("Unknown webhook %v", resources[0]), http.StatusNotFound)
+		return
+
+	} else if len(resources) == 1 {
+		data = hook
+
+	} else {
+		letters, err := api.WH.DeadLetters(resources[0])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		data = letters
+	}
+
+	// Write data
+
+	w.Header().Set("content-type", "application/json; charset=utf-8")
+
+	ret := json.NewEncoder(w)
+	ret.Encode(data)
+}
+
+/*
+HandlePUT handles a REST call to register or replace a webhook.
+*/
+func (we *webhooksEndpoint) HandlePUT(w http.ResponseWriter, r *http.Request, resources []string) {
+
+	// Check parameters
+
+	if !we.checkWebhooks(w) || !checkResources(w, resources, 1, 1, "Need a webhook name") {
+		return
+	}
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	hook := &webhook.Webhook{}
+
+	if err := dec.Decode(hook); err != nil {
+		http.Error(w, "Could not decode request body as webhook: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if hook.Name != "" && hook.Name != resources[0] {
+		http.Error(w, fmt.Sprintf("Webhook name %v does not match resource name %v",
+			hook.Name, resources[0]), http.StatusBadRequest)
+		return
+	}
+
+	hook.Name = resources[0]
+
+	if err := api.WH.Register(hook); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+/*
+HandleDELETE handles a REST call to remove a webhook or the dead letters of
+a webhook.
+*/
+func (we *webhooksEndpoint) HandleDELETE(w http.ResponseWriter, r *http.Request, resources []string) {
+
+	// Check parameters
+
+	if !we.checkWebhooks(w) || !we.checkResources(w, resources, 1, "Need a webhook name") {
+		return
+	}
+
+	if len(resources) == 1 {
+		if err := api.WH.Remove(resources[0]); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	removed, err := api.WH.RemoveDeadLetters(resources[0])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Write data
+
+	w.Header().Set("content-type", "application/json; charset=utf-8")
+
+	ret := json.NewEncoder(w)
+	ret.Encode(map[string]interface{}{
+		"removed": removed,
+	})
+}
+
+/*
+checkWebhooks checks if webhooks are enabled.
+*/
+func (we *webhooksEndpoint) checkWebhooks(w http.ResponseWriter) bool {
+	if api.WH == nil {
+		http.Error(w, "Webhooks are not enabled", http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+/*
+checkResources checks the resources of a request. Valid resources are a
+webhook name optionally followed by deadletters.
+*/
+func (we *webhooksEndpoint) checkResources(w http.ResponseWriter, resources []string,
+	requiredMin int, errorMsg string) bool {
+
+	if len(resources) < requiredMin {
+		http.Error(w, errorMsg, http.StatusBadRequest)
+		return false
+	} else if len(resources) > 2 || (len(resources) == 2 && resources[1] != "deadletters") {
+		http.Error(w, "Invalid resource specification: "+strings.Join(resources[1:], "/"), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+/*
+SwaggerDefs is used to describe the endpoint in swagger.
+*/
+func (we *webhooksEndpoint) SwaggerDefs(s map[string]interface{}) {
+
+	nameParam := map[string]interface{}{
+		"name":        "name",
+		"in":          "path",
+		"description": "Name of the webhook.",
+		"required":    true,
+		"type":        "string",
+	}
+
+	errorResponse := map[string]interface{}{
+		"description": "Error response",
+		"schema": map[string]interface{}{
+			"$ref": "#/definitions/Error",
+		},
+	}
+
+	s["paths"].(map[string]interface{})["/v1/webhooks"] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary":     "Return all registered webhooks.",
+			"description": "Webhooks forward node and edge events as batched JSON payloads to external URLs. Webhooks need to be enabled in the server configuration.",
+			"produces": []string{
+				"text/plain",
+				"application/json",
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "A list of webhooks.",
+					"schema": map[string]interface{}{
+						"type": "array",
+						"items": map[string]interface{}{
+							"$ref": "#/definitions/Webhook",
+						},
+					},
+				},
+				"default": errorResponse,
+			},
+		},
+	}
+
+	s["paths"].(map[string]interface{})["/v1/webhooks/{name}"] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary":     "Return a webhook.",
+			"description": "Returns a single registered webhook.",
+			"produces": []string{
+				"text/plain",
+				"application/json",
+			},
+			"parameters": []map[string]interface{}{
+				nameParam,
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "The webhook.",
+					"schema": map[string]interface{}{
+						"$ref": "#/definitions/Webhook",
+					},
+				},
+				"default": errorResponse,
+			},
+		},
+		"put": map[string]interface{}{
+			"summary":     "Register or replace a webhook.",
+			"description": "Events of matching nodes and edges are sent to the URL of the webhook. Empty filters match all kinds, partitions or actions.",
+			"consumes": []string{
+				"application/json",
+			},
+			"produces": []string{
+				"text/plain",
+			},
+			"parameters": []map[string]interface{}{
+				nameParam,
+				{
+					"name":        "webhook",
+					"in":          "body",
+					"description": "Webhook definition.",
+					"required":    true,
+					"schema": map[string]interface{}{
+						"$ref": "#/definitions/Webhook",
+					},
+				},
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "No data is returned when the webhook was registered.",
+				},
+				"default": errorResponse,
+			},
+		},
+		"delete": map[string]interface{}{
+			"summary":     "Remove a webhook.",
+			"description": "Events which were not yet delivered are stored as dead letters.",
+			"produces": []string{
+				"text/plain",
+			},
+			"parameters": []map[string]interface{}{
+				nameParam,
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "No data is returned when the webhook was removed.",
+				},
+				"default": errorResponse,
+			},
+		},
+	}
+
+	s["paths"].(map[string]interface{})["/v1/webhooks/{name}/deadletters"] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary":     "Return the dead letters of a webhook.",
+			"description": "Dead letters are batches of events which could not be delivered after all retries.",
+			"produces": []string{
+				"text/plain",
+				"application/json",
+			},
+			"parameters": []map[string]interface{}{
+				nameParam,
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "A list of dead letters with the webhook name, the URL, the undelivered JSON payload, the last error, the number of attempts and the time.",
+				},
+				"default": errorResponse,
+			},
+		},
+		"delete": map[string]interface{}{
+			"summary":     "Remove the dead letters of a webhook.",
+			"description": "Removes all dead letters of a webhook.",
+			"produces": []string{
+				"text/plain",
+				"application/json",
+			},
+			"parameters": []map[string]interface{}{
+				nameParam,
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "The number of removed dead letters.",
+				},
+				"default": errorResponse,
+			},
+		},
+	}
+
+	// Add webhook object to definition
+
+	s["definitions"].(map[string]interface{})["Webhook"] = map[string]interface{}{
+		"description": "Webhook which forwards node and edge events.",
+		"type":        "object",
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{
+				"description": "Name of the webhook.",
+				"type":        "string",
+			},
+			"url": map[string]interface{}{
+				"description": "HTTP or HTTPS URL which receives the events.",
+				"type":        "string",
+			},
+			"kinds": map[string]interface{}{
+				"description": "Node and edge kinds which are forwarded (all kinds if empty).",
+				"type":        "array",
+				"items": map[string]interface{}{
+					"type": "string",
+				},
+			},
+			"parts": map[string]interface{}{
+				"description": "Partitions which are forwarded (all partitions if empty).",
+				"type":        "array",
+				"items": map[string]interface{}{
+					"type": "string",
+				},
+			},
+			"actions": map[string]interface{}{
+				"description": "Actions which are forwarded: created, updated or deleted (all actions if empty).",
+				"type":        "array",
+				"items": map[string]interface{}{
+					"type": "string",
+				},
+			},
+		},
+	}
+
+	// Add generic error object to definition
+
+	s["definitions"].(map[string]interface{})["Error"] = map[string]interface{}{
+		"description": "A human readable error mesage.",
+		"type":        "string",
+	}
+}
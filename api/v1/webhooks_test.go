@@ -0,0 +1,124 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/api"
+	"github.com/Fisch-Labs/FishDB/webhook"
+)
+
+func TestWebhooks(t *testing.T) {
+	queryURL := "http://localhost" + TESTPORT + EndpointWebhooks
+
+	st, _, res := sendTestRequest(queryURL, "GET", nil)
+	if st != "404 Not Found" || res != "Webhooks are not enabled" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	api.WH = webhook.NewDispatcher(api.GM)
+
+	if err := api.WH.Run(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	defer func() {
+		api.WH.Close()
+		api.WH = nil
+	}()
+
+	st, _, res = sendTestRequest(queryURL+"test", "PUT", []byte(`{
+  "url": "`+srv.URL+`",
+  "kinds": ["Person"],
+  "foo": 1
+}`))
+	if st != "400 Bad Request" || res != `Could not decode request body as webhook: json: unknown field "foo"` {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"test", "PUT", []byte(`{
+  "url": "`+srv.URL+`",
+  "actions": ["moved"]
+}`))
+	if st != "400 Bad Request" || res != "GraphError: Invalid data (Invalid action for webhook test: moved)" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"test", "PUT", []byte(`{
+  "url": "`+srv.URL+`",
+  "kinds": ["Person"]
+}`))
+	if st != "200 OK" || res != "" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL, "GET", nil)
+	if st != "200 OK" || res != `
+[
+  {
+    "name": "test",
+    "url": "`[1:]+srv.URL+`",
+    "kinds": [
+      "Person"
+    ]
+  }
+]` {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"foo", "GET", nil)
+	if st != "404 Not Found" || res != "Unknown webhook foo" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"test/foo", "GET", nil)
+	if st != "400 Bad Request" || res != "Invalid resource specification: foo" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"test/deadletters", "GET", nil)
+	if st != "200 OK" || res != "[]" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"test/deadletters", "DELETE", nil)
+	if st != "200 OK" || res != `
+{
+  "removed": 0
+}`[1:] {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"test", "DELETE", nil)
+	if st != "200 OK" || res != "" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+
+	st, _, res = sendTestRequest(queryURL+"test", "DELETE", nil)
+	if st != "400 Bad Request" || res != "GraphError: Invalid data (Unknown webhook test)" {
+		t.Error("Unexpected response:", st, res)
+		return
+	}
+}
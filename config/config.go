@@ -65,6 +65,15 @@ const (
 	ECALLogFile              = "ECALLogFile"
 	ECALDebugServerHost      = "ECALDebugServerHost"
 	ECALDebugServerPort      = "ECALDebugServerPort"
+	EnableWebhooks           = "EnableWebhooks"
+	WebhookBatchSize         = "WebhookBatchSize"
+	WebhookBatchDelayMillis  = "WebhookBatchDelayMillis"
+	WebhookMaxRetries        = "WebhookMaxRetries"
+	WebhookRetryDelayMillis  = "WebhookRetryDelayMillis"
+	WebhookTimeoutSeconds    = "WebhookTimeoutSeconds"
+	WebhookMaxQueueSize      = "WebhookMaxQueueSize"
+	ChangeLogMaxAgeSeconds   = "ChangeLogMaxAgeSeconds"
+	ChangeLogMaxSize         = "ChangeLogMaxSize"
 )
 
 /*
@@ -103,6 +112,15 @@ var DefaultConfig = map[string]interface{}{
 	ECALLogFile:              "",
 	ECALDebugServerHost:      "127.0.0.1",
 	ECALDebugServerPort:      "33274",
+	EnableWebhooks:           false,
+	WebhookBatchSize:         100,
+	WebhookBatchDelayMillis:  1000,
+	WebhookMaxRetries:        5,
+	WebhookRetryDelayMillis:  500,
+	WebhookTimeoutSeconds:    10,
+	WebhookMaxQueueSize:      10000,
+	ChangeLogMaxAgeSeconds:   604800,
+	ChangeLogMaxSize:         0,
 }
 
 /*
@@ -8,6 +8,7 @@
 package console
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"sort"
@@ -233,3 +234,428 @@ func (c *CmdFind) Run(args []string, capi CommandConsoleAPI) error {
 
 	return nil
 }
+
+// Command: index
+// ==============
+
+/*
+CommandIndex is a command name.
+*/
+const CommandIndex = "index"
+
+/*
+CmdIndex displays or changes the index schema of a kind.
+*/
+type CmdIndex struct {
+}
+
+/*
+Name returns the command name (as it should be typed)
+*/
+func (c *CmdIndex) Name() string {
+	return CommandIndex
+}
+
+/*
+ShortDescription returns a short description of the command (single line)
+*/
+func (c *CmdIndex) ShortDescription() string {
+	return "Displays or changes the index schema of a kind."
+}
+
+/*
+LongDescription returns an extensive description of the command (can be multiple lines)
+*/
+func (c *CmdIndex) LongDescription() string {
+	return "Displays or changes the index schema of a kind. Use index <kind> <attr>=<mode> ... " +
+		"to change the index mode of attributes (modes: none, value, fulltext, range - an empty " +
+		"attribute sets the mode of all attributes and an empty mode removes a setting). Use " +
+		"index <kind> rebuild to rebuild the index of the kind in the current partition in the background."
+}
+
+/*
+Run executes the command.
+*/
+func (c *CmdIndex) Run(args []string, capi CommandConsoleAPI) error {
+
+	if len(args) < 1 {
+		return fmt.Errorf("Please specify a kind")
+	}
+
+	kind := url.PathEscape(args[0])
+
+	if len(args) == 2 && args[1] == "rebuild" {
+
+		_, err := capi.Req(fmt.Sprintf("%s%s/%s", v1.EndpointIndexSchema, kind,
+			url.PathEscape(capi.Partition())), "POST", nil)
+
+		if err == nil {
+			fmt.Fprintln(capi.Out(), fmt.Sprintf("Rebuilding index of %s in partition %s",
+				args[0], capi.Partition()))
+		}
+
+		return err
+
+	} else if len(args) > 1 {
+
+		modes := make(map[string]string)
+
+		for _, arg := range args[1:] {
+			i := strings.Index(arg, "=")
+			if i == -1 {
+				return fmt.Errorf("Index mode must be given as <attr>=<mode>: %s", arg)
+			}
+			modes[arg[:i]] = arg[i+1:]
+		}
+
+		content, _ := json.Marshal(modes)
+
+		_, err := capi.Req(v1.EndpointIndexSchema+kind, "PUT", content)
+
+		if err == nil {
+			fmt.Fprintln(capi.Out(), fmt.Sprintf("Index schema of %s changed", args[0]))
+		}
+
+		return err
+	}
+
+	res, err := capi.Req(v1.EndpointIndexSchema+kind, "GET", nil)
+
+	if err == nil {
+		data := res.(map[string]interface{})
+		schema := data["schema"].(map[string]interface{})
+		rebuilds := data["rebuilds"].(map[string]interface{})
+
+		tab := []string{"Attribute", "Mode"}
+
+		for _, attr := range stringutil.MapKeys(schema) {
+			name := attr
+			if name == "" {
+				name = "(all)"
+			}
+			tab = append(tab, name, fmt.Sprint(schema[attr]))
+		}
+
+		capi.ExportBuffer().WriteString(stringutil.PrintCSVTable(tab, 2))
+
+		fmt.Fprint(capi.Out(), stringutil.PrintGraphicStringTable(tab, 2, 1,
+			stringutil.SingleLineTable))
+
+		for _, part := range stringutil.MapKeys(rebuilds) {
+			fmt.Fprintln(capi.Out(), fmt.Sprintf("Rebuild in partition %s: %v", part, rebuilds[part]))
+		}
+	}
+
+	return err
+}
+
+// Command: schema
+// ===============
+
+/*
+CommandSchema is a command name.
+*/
+const CommandSchema = "schema"
+
+/*
+CmdSchema displays or changes the schema of a kind.
+*/
+type CmdSchema struct {
+}
+
+/*
+Name returns the command name (as it should be typed)
+*/
+func (c *CmdSchema) Name() string {
+	return CommandSchema
+}
+
+/*
+ShortDescription returns a short description of the command (single line)
+*/
+func (c *CmdSchema) ShortDescription() string {
+	return "Displays or changes the schema of a kind."
+}
+
+/*
+LongDescription returns an extensive description of the command (can be multiple lines)
+*/
+func (c *CmdSchema) LongDescription() string {
+	return "Displays all kinds which have a schema or the schema of a kind. Use schema <kind> set <json> " +
+		"to set the schema of a kind (e.g. schema Person set {\"attrs\": {\"name\": {\"type\": \"string\", " +
+		"\"required\": true}}}). Use schema <kind> remove to remove the schema of a kind."
+}
+
+/*
+Run executes the command.
+*/
+func (c *CmdSchema) Run(args []string, capi CommandConsoleAPI) error {
+
+	if len(args) == 0 {
+
+		res, err := capi.Req(v1.EndpointSchema, "GET", nil)
+
+		if err == nil {
+			tab := []string{"Kind"}
+			tab = append(tab, stringutil.MapKeys(res.(map[string]interface{}))...)
+
+			capi.ExportBuffer().WriteString(stringutil.PrintCSVTable(tab, 1))
+
+			fmt.Fprint(capi.Out(), stringutil.PrintGraphicStringTable(tab, 1, 1,
+				stringutil.SingleLineTable))
+		}
+
+		return err
+	}
+
+	kind := url.PathEscape(args[0])
+
+	if len(args) > 2 && args[1] == "set" {
+
+		_, err := capi.Req(v1.EndpointSchema+kind, "PUT", []byte(strings.Join(args[2:], " ")))
+
+		if err == nil {
+			fmt.Fprintln(capi.Out(), fmt.Sprintf("Schema of %s changed", args[0]))
+		}
+
+		return err
+
+	} else if len(args) == 2 && args[1] == "remove" {
+
+		_, err := capi.Req(v1.EndpointSchema+kind, "DELETE", nil)
+
+		if err == nil {
+			fmt.Fprintln(capi.Out(), fmt.Sprintf("Schema of %s removed", args[0]))
+		}
+
+		return err
+
+	} else if len(args) > 1 {
+
+		return fmt.Errorf("Use schema <kind> set <json> or schema <kind> remove")
+	}
+
+	res, err := capi.Req(v1.EndpointSchema+kind, "GET", nil)
+
+	if err == nil {
+		schema := res.(map[string]interface{})
+
+		tab := []string{"Attribute", "Type", "Required"}
+
+		if attrs, ok := schema["attrs"].(map[string]interface{}); ok {
+			for _, attr := range stringutil.MapKeys(attrs) {
+				as := attrs[attr].(map[string]interface{})
+
+				typ, required := "(any)", false
+				if t, ok := as["type"]; ok {
+					typ = fmt.Sprint(t)
+				}
+				if r, ok := as["required"]; ok {
+					required = r == true
+				}
+
+				tab = append(tab, attr, typ, fmt.Sprint(required))
+			}
+		}
+
+		capi.ExportBuffer().WriteString(stringutil.PrintCSVTable(tab, 3))
+
+		fmt.Fprint(capi.Out(), stringutil.PrintGraphicStringTable(tab, 3, 1,
+			stringutil.SingleLineTable))
+
+		end := func(ends map[string]interface{}, name string) string {
+			kind, role := "(any)", "(any)"
+			if k, ok := ends[name+"kind"]; ok {
+				kind = fmt.Sprint(k)
+			}
+			if r, ok := ends[name+"role"]; ok {
+				role = fmt.Sprint(r)
+			}
+			return fmt.Sprintf("%s as %s", kind, role)
+		}
+
+		if ends, ok := schema["ends"].([]interface{}); ok {
+			for _, e := range ends {
+				e := e.(map[string]interface{})
+				fmt.Fprintln(capi.Out(), fmt.Sprintf("Allowed ends: %s - %s", end(e, "end1"), end(e, "end2")))
+			}
+		}
+
+		if cardinality, ok := schema["cardinality"].(map[string]interface{}); ok {
+			for _, role := range stringutil.MapKeys(cardinality) {
+				fmt.Fprintln(capi.Out(), fmt.Sprintf("Maximal number of edges in role %s: %v",
+					role, cardinality[role]))
+			}
+		}
+	}
+
+	return err
+}
+
+// Command: triggers
+// =================
+
+/*
+CommandTriggers is a command name.
+*/
+const CommandTriggers = "triggers"
+
+/*
+CmdTriggers displays or changes ECAL triggers.
+*/
+type CmdTriggers struct {
+}
+
+/*
+Name returns the command name (as it should be typed)
+*/
+func (c *CmdTriggers) Name() string {
+	return CommandTriggers
+}
+
+/*
+ShortDescription returns a short description of the command (single line)
+*/
+func (c *CmdTriggers) ShortDescription() string {
+	return "Displays or changes ECAL triggers."
+}
+
+/*
+LongDescription returns an extensive description of the command (can be multiple lines)
+*/
+func (c *CmdTriggers) LongDescription() string {
+	return "Displays all ECAL triggers or a single trigger. Use triggers <name> set <event kinds> <ECAL code> " +
+		"to define a new version of a trigger (e.g. triggers logger set db.node.created,db.node.updated " +
+		"log(event.state.node.key)). Use triggers <name> enable, disable or remove to change a trigger. " +
+		"Use triggers <name> versions to display all versions and triggers <name> revert <version> to " +
+		"restore a previous version."
+}
+
+/*
+Run executes the command.
+*/
+func (c *CmdTriggers) Run(args []string, capi CommandConsoleAPI) error {
+
+	if len(args) == 0 {
+
+		res, err := capi.Req(v1.EndpointTriggers, "GET", nil)
+
+		if err == nil {
+			tab := []string{"Name", "Event kinds", "Enabled", "Version"}
+
+			for _, t := range res.([]interface{}) {
+				t := t.(map[string]interface{})
+				tab = append(tab, fmt.Sprint(t["name"]), triggerKinds(t), fmt.Sprint(t["enabled"]),
+					fmt.Sprint(t["version"]))
+			}
+
+			capi.ExportBuffer().WriteString(stringutil.PrintCSVTable(tab, 4))
+
+			fmt.Fprint(capi.Out(), stringutil.PrintGraphicStringTable(tab, 4, 1,
+				stringutil.SingleLineTable))
+		}
+
+		return err
+	}
+
+	name := url.PathEscape(args[0])
+
+	if len(args) > 3 && args[1] == "set" {
+
+		trigger, err := json.Marshal(map[string]interface{}{
+			"kinds": strings.Split(args[2], ","),
+			"body":  strings.Join(args[3:], " "),
+		})
+
+		if err == nil {
+			if _, err = capi.Req(v1.EndpointTriggers+name, "PUT", trigger); err == nil {
+				fmt.Fprintln(capi.Out(), fmt.Sprintf("Trigger %s defined", args[0]))
+			}
+		}
+
+		return err
+
+	} else if len(args) == 2 && (args[1] == "enable" || args[1] == "disable") {
+
+		_, err := capi.Req(v1.EndpointTriggers+name+"/"+args[1], "PUT", nil)
+
+		if err == nil {
+			fmt.Fprintln(capi.Out(), fmt.Sprintf("Trigger %s %sd", args[0], args[1]))
+		}
+
+		return err
+
+	} else if len(args) == 2 && args[1] == "remove" {
+
+		_, err := capi.Req(v1.EndpointTriggers+name, "DELETE", nil)
+
+		if err == nil {
+			fmt.Fprintln(capi.Out(), fmt.Sprintf("Trigger %s removed", args[0]))
+		}
+
+		return err
+
+	} else if len(args) == 3 && args[1] == "revert" {
+
+		_, err := capi.Req(v1.EndpointTriggers+name+"/versions/"+url.PathEscape(args[2]), "PUT", nil)
+
+		if err == nil {
+			fmt.Fprintln(capi.Out(), fmt.Sprintf("Trigger %s reverted to version %s", args[0], args[2]))
+		}
+
+		return err
+
+	} else if len(args) == 2 && args[1] == "versions" {
+
+		res, err := capi.Req(v1.EndpointTriggers+name+"/versions", "GET", nil)
+
+		if err == nil {
+			tab := []string{"Version", "Event kinds", "Body"}
+
+			for _, t := range res.([]interface{}) {
+				t := t.(map[string]interface{})
+				tab = append(tab, fmt.Sprint(t["version"]), triggerKinds(t), fmt.Sprint(t["body"]))
+			}
+
+			capi.ExportBuffer().WriteString(stringutil.PrintCSVTable(tab, 3))
+
+			fmt.Fprint(capi.Out(), stringutil.PrintGraphicStringTable(tab, 3, 1,
+				stringutil.SingleLineTable))
+		}
+
+		return err
+
+	} else if len(args) > 1 {
+
+		return fmt.Errorf("Use triggers <name> set <event kinds> <ECAL code>, triggers <name> " +
+			"enable|disable|remove|versions or triggers <name> revert <version>")
+	}
+
+	res, err := capi.Req(v1.EndpointTriggers+name, "GET", nil)
+
+	if err == nil {
+		t := res.(map[string]interface{})
+
+		fmt.Fprintln(capi.Out(), fmt.Sprintf("Event kinds: %s", triggerKinds(t)))
+		fmt.Fprintln(capi.Out(), fmt.Sprintf("Enabled: %v", t["enabled"]))
+		fmt.Fprintln(capi.Out(), fmt.Sprintf("Version: %v", t["version"]))
+		fmt.Fprintln(capi.Out(), t["body"])
+	}
+
+	return err
+}
+
+/*
+triggerKinds returns the event kinds of a trigger as a comma separated list.
+*/
+func triggerKinds(trigger map[string]interface{}) string {
+	var kinds []string
+
+	if list, ok := trigger["kinds"].([]interface{}); ok {
+		for _, k := range list {
+			kinds = append(kinds, fmt.Sprint(k))
+		}
+	}
+
+	return strings.Join(kinds, ",")
+}
@@ -196,4 +196,160 @@ Kind      Producer
 
 	out.Reset()
 
+	if ok, err := c.Run("index"); ok || err == nil || err.Error() != "Please specify a kind" {
+		t.Error(ok, err)
+		return
+	}
+
+	out.Reset()
+
+	if ok, err := c.Run("index Writer text=value =fulltext"); !ok || err != nil {
+		t.Error(ok, err)
+		return
+	}
+
+	if res := out.String(); res != "Index schema of Writer changed\n" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	out.Reset()
+
+	if ok, err := c.Run("index Writer"); !ok || err != nil {
+		t.Error(ok, err)
+		return
+	}
+
+	if res := out.String(); res != `
+┌──────────┬─────────┐
+│Attribute │Mode     │
+├──────────┼─────────┤
+│(all)     │fulltext │
+│text      │value    │
+└──────────┴─────────┘
+`[1:] {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	out.Reset()
+
+	if ok, err := c.Run("index Writer text"); ok || err == nil ||
+		err.Error() != "Index mode must be given as <attr>=<mode>: text" {
+		t.Error(ok, err)
+		return
+	}
+
+	out.Reset()
+
+	if ok, err := c.Run("index Writer rebuild"); !ok || err != nil {
+		t.Error(ok, err)
+		return
+	}
+
+	if res := out.String(); res != "Rebuilding index of Writer in partition main\n" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	out.Reset()
+
+	if ok, err := c.Run(`schema Writer set {"attrs": {"name": {"type": "string", "required": true}, "text": {}}}`); !ok || err != nil {
+		t.Error(ok, err)
+		return
+	}
+
+	if res := out.String(); res != "Schema of Writer changed\n" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	out.Reset()
+
+	if ok, err := c.Run(`schema Wrote set {"attrs": {"weight": {"type": "number"}}, "ends": [{"end1kind": "Writer"}], "cardinality": {"Song": 1}}`); !ok || err != nil {
+		t.Error(ok, err)
+		return
+	}
+
+	out.Reset()
+
+	if ok, err := c.Run("schema"); !ok || err != nil {
+		t.Error(ok, err)
+		return
+	}
+
+	if res := out.String(); res != `
+┌───────┐
+│Kind   │
+├───────┤
+│Wrote  │
+│Writer │
+└───────┘
+`[1:] {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	out.Reset()
+
+	if ok, err := c.Run("schema Writer"); !ok || err != nil {
+		t.Error(ok, err)
+		return
+	}
+
+	if res := out.String(); res != `
+┌──────────┬───────┬─────────┐
+│Attribute │Type   │Required │
+├──────────┼───────┼─────────┤
+│name      │string │true     │
+│text      │(any)  │false    │
+└──────────┴───────┴─────────┘
+`[1:] {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	out.Reset()
+
+	if ok, err := c.Run("schema Wrote"); !ok || err != nil {
+		t.Error(ok, err)
+		return
+	}
+
+	if res := out.String(); res != `
+┌──────────┬───────┬─────────┐
+│Attribute │Type   │Required │
+├──────────┼───────┼─────────┤
+│weight    │number │false    │
+└──────────┴───────┴─────────┘
+Allowed ends: Writer as (any) - (any) as (any)
+Maximal number of edges in role Song: 1
+`[1:] {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	out.Reset()
+
+	if ok, err := c.Run("schema Writer foo"); ok || err == nil ||
+		err.Error() != "Use schema <kind> set <json> or schema <kind> remove" {
+		t.Error(ok, err)
+		return
+	}
+
+	for _, kind := range []string{"Writer", "Wrote"} {
+		out.Reset()
+
+		if ok, err := c.Run("schema " + kind + " remove"); !ok || err != nil {
+			t.Error(ok, err)
+			return
+		}
+
+		if res := out.String(); res != "Schema of "+kind+" removed\n" {
+			t.Error("Unexpected result:", res)
+			return
+		}
+	}
+
+	out.Reset()
 }
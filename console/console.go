@@ -62,6 +62,9 @@ func NewConsole(url string, out io.Writer, getCredentials func() (string, string
 	cmdMap[CommandInfo] = &CmdInfo{}
 	cmdMap[CommandPart] = &CmdPart{}
 	cmdMap[CommandFind] = &CmdFind{}
+	cmdMap[CommandIndex] = &CmdIndex{}
+	cmdMap[CommandSchema] = &CmdSchema{}
+	cmdMap[CommandTriggers] = &CmdTriggers{}
 
 	// Add export if we got an export function
 
@@ -464,13 +464,16 @@ Writer, 1
 	}
 
 	if res := out.String(); res != `
-Command Description
-export  Exports the last output.
-find    Do a full-text search of the database.
-help    Display descriptions for all available commands.
-info    Returns general database information.
-part    Displays or sets the current partition.
-ver     Displays server version information.
+Command  Description
+export   Exports the last output.
+find     Do a full-text search of the database.
+help     Display descriptions for all available commands.
+index    Displays or changes the index schema of a kind.
+info     Returns general database information.
+part     Displays or sets the current partition.
+schema   Displays or changes the schema of a kind.
+triggers Displays or changes ECAL triggers.
+ver      Displays server version information.
 `[1:] {
 		t.Error("Unexpected result:", res)
 		return
@@ -484,13 +487,16 @@ ver     Displays server version information.
 	}
 
 	if res := out.String(); res != `
-Command Description
-export  Exports the last output.
-find    Do a full-text search of the database.
-help    Display descriptions for all available commands.
-info    Returns general database information.
-part    Displays or sets the current partition.
-ver     Displays server version information.
+Command  Description
+export   Exports the last output.
+find     Do a full-text search of the database.
+help     Display descriptions for all available commands.
+index    Displays or changes the index schema of a kind.
+info     Returns general database information.
+part     Displays or sets the current partition.
+schema   Displays or changes the schema of a kind.
+triggers Displays or changes ECAL triggers.
+ver      Displays server version information.
 `[1:] {
 		t.Error("Unexpected result:", res)
 		return
@@ -570,6 +576,7 @@ groupadd   Adds a group to the system.
 groupdel   Removes a group from the system.
 groups     Returns a list of all groups and their permissions.
 help       Display descriptions for all available commands.
+index      Displays or changes the index schema of a kind.
 info       Returns general database information.
 joingroup  Joins a user to a group.
 leavegroup Removes a user from a group.
@@ -578,6 +585,8 @@ logout     Log out the current user.
 newpass    Changes the password of a user.
 part       Displays or sets the current partition.
 revokeperm Revokes permissions to a resource for a group.
+schema     Displays or changes the schema of a kind.
+triggers   Displays or changes ECAL triggers.
 useradd    Adds a user to the system.
 userdel    Removes a user from the system.
 users      Returns a list of all users.
@@ -28,7 +28,7 @@ type EQLConsole struct {
 /*
 eqlConsoleKeywords are all keywords which this console can process.
 */
-var eqlConsoleKeywords = []string{"part", "get", "lookup"}
+var eqlConsoleKeywords = []string{"part", "get", "lookup", "explain", "profile"}
 
 /*
 Run executes one or more commands. It returns an error if the command
@@ -0,0 +1,130 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package dbfunc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Fisch-Labs/FishDB/graph"
+	"github.com/Fisch-Labs/FishDB/graph/algo"
+	"github.com/Fisch-Labs/Tide/parser"
+)
+
+/*
+AlgorithmFunc runs a graph algorithm on a partition of FishDB.
+*/
+type AlgorithmFunc struct {
+	GM        *graph.Manager
+	Algorithm string // Name of the algorithm (see algo.Algorithms)
+}
+
+/*
+Run executes the ECAL function.
+*/
+func (f *AlgorithmFunc) Run(instanceID string, vs parser.Scope, is map[string]interface{}, tid uint64, args []interface{}) (interface{}, error) {
+	var res interface{}
+	var err error
+
+	if arglen := len(args); arglen < 1 || arglen > 3 {
+		err = fmt.Errorf("Function requires 1 to 3 parameters: partition, optionally" +
+			" a parameter map and optionally a transaction")
+	}
+
+	if err == nil {
+		var trans graph.Trans
+		var rows []map[string]interface{}
+
+		part := fmt.Sprint(args[0])
+		params := make(map[string]string)
+
+		// Check parameters
+
+		if len(args) > 1 {
+			paramMap, ok := args[1].(map[interface{}]interface{})
+
+			if !ok {
+				err = fmt.Errorf("Second parameter must be a map")
+			}
+
+			for k, v := range paramMap {
+				if l, ok := v.([]interface{}); ok {
+
+					// Lists (e.g. seed nodes) are given as comma separated values
+
+					var vals []string
+					for _, lv := range l {
+						vals = append(vals, fmt.Sprint(lv))
+					}
+
+					params[fmt.Sprint(k)] = strings.Join(vals, ",")
+
+				} else {
+					params[fmt.Sprint(k)] = fmt.Sprint(v)
+				}
+			}
+		}
+
+		if err == nil && len(args) > 2 {
+			var ok bool
+
+			if trans, ok = args[2].(graph.Trans); !ok {
+				err = fmt.Errorf("Third parameter must be a transaction")
+			}
+		}
+
+		// Run the algorithm
+
+		if err == nil {
+			if rows, err = algo.Run(f.GM, part, f.Algorithm, params, trans); err == nil {
+
+				resRows := make([]interface{}, len(rows))
+				for i, row := range rows {
+					resRows[i] = convertAlgorithmResult(row)
+				}
+
+				res = resRows
+			}
+		}
+	}
+
+	return res, err
+}
+
+/*
+DocString returns a descriptive string.
+*/
+func (f *AlgorithmFunc) DocString() (string, error) {
+	return fmt.Sprintf("Runs a graph algorithm on a partition of FishDB: %v.",
+		algo.Algorithms[f.Algorithm]), nil
+}
+
+/*
+convertAlgorithmResult converts all maps of an algorithm result into ECAL maps.
+*/
+func convertAlgorithmResult(val interface{}) interface{} {
+
+	switch v := val.(type) {
+
+	case map[string]interface{}:
+		res := make(map[interface{}]interface{})
+		for k, mv := range v {
+			res[k] = convertAlgorithmResult(mv)
+		}
+		return res
+
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, lv := range v {
+			res[i] = convertAlgorithmResult(lv)
+		}
+		return res
+	}
+
+	return val
+}
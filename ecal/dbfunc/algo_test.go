@@ -0,0 +1,120 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package dbfunc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/graph"
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+)
+
+func TestAlgorithm(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := graph.NewGraphManager(mgs)
+
+	for _, key := range []string{"a", "b", "c"} {
+		gm.StoreNode("main", data.NewGraphNodeFromMap(map[string]interface{}{
+			"key":  key,
+			"kind": "Page",
+		}))
+	}
+
+	gm.StoreEdge("main", data.NewGraphEdgeFromNode(data.NewGraphNodeFromMap(map[string]interface{}{
+		"key":           "ab",
+		"kind":          "Link",
+		"end1cascading": false,
+		"end1key":       "a",
+		"end1kind":      "Page",
+		"end1role":      "From",
+		"end2cascading": false,
+		"end2key":       "b",
+		"end2kind":      "Page",
+		"end2role":      "To",
+	})))
+
+	af := &AlgorithmFunc{gm, "components"}
+
+	if res, err := af.DocString(); err != nil || res != "Runs a graph algorithm on a partition of FishDB: Connected components (attr)." {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if _, err := af.Run("", nil, nil, 0, []interface{}{}); err == nil ||
+		err.Error() != "Function requires 1 to 3 parameters: partition, optionally a parameter map and optionally a transaction" {
+		t.Error(err)
+		return
+	}
+
+	if _, err := af.Run("", nil, nil, 0, []interface{}{"main", "x"}); err == nil ||
+		err.Error() != "Second parameter must be a map" {
+		t.Error(err)
+		return
+	}
+
+	if _, err := af.Run("", nil, nil, 0, []interface{}{"main", map[interface{}]interface{}{}, "x"}); err == nil ||
+		err.Error() != "Third parameter must be a transaction" {
+		t.Error(err)
+		return
+	}
+
+	if _, err := af.Run("", nil, nil, 0, []interface{}{"main", map[interface{}]interface{}{"attr": "component"}}); err == nil ||
+		err.Error() != "GraphError: Invalid data (Writing results requires a transaction)" {
+		t.Error(err)
+		return
+	}
+
+	if res, err := af.Run("", nil, nil, 0, []interface{}{"main"}); err != nil ||
+		fmt.Sprint(res) != "[map[key:a kind:Page label:0] map[key:b kind:Page label:0] map[key:c kind:Page label:1]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Write the results in a transaction
+
+	trans := graph.NewGraphTrans(gm)
+
+	if _, err := af.Run("", nil, nil, 0, []interface{}{"main",
+		map[interface{}]interface{}{"attr": "component"}, trans}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if node, err := gm.FetchNode("main", "c", "Page"); err != nil || node.Attr("component") != 1 {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	// Parameters are given as a map
+
+	af = &AlgorithmFunc{gm, "shortestpath"}
+
+	if res, err := af.Run("", nil, nil, 0, []interface{}{"main", map[interface{}]interface{}{
+		"key": "b", "kind": "Page", "tokey": "a", "tokind": "Page"}}); err != nil ||
+		fmt.Sprint(res) != "[map[distance:0 key:b kind:Page] map[distance:1 key:a kind:Page]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	af = &AlgorithmFunc{gm, "personalizedpagerank"}
+
+	if res, err := af.Run("", nil, nil, 0, []interface{}{"main", map[interface{}]interface{}{
+		"seeds": []interface{}{"Page:a", "Page:c"}}}); err != nil || len(res.([]interface{})) != 1 ||
+		fmt.Sprint(res.([]interface{})[0].(map[interface{}]interface{})["path"]) !=
+			"[map[key:a kind:Page] map[key:b kind:Page]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+}
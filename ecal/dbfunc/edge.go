@@ -151,18 +151,28 @@ func (f *FetchEdgeFunc) Run(instanceID string, vs parser.Scope, is map[string]in
 	var res interface{}
 	var err error
 
-	if arglen := len(args); arglen != 3 {
-		err = fmt.Errorf("Function requires 3 parameters: partition, edge key and" +
-			" edge kind")
+	if arglen := len(args); arglen != 3 && arglen != 4 {
+		err = fmt.Errorf("Function requires 3 or 4 parameters: partition, edge key," +
+			" edge kind and optionally a transaction")
 	}
 
 	if err == nil {
-		var node data.Node
+		var edge data.Edge
+		var trans graph.Trans
+		var ok bool
 
 		part := fmt.Sprint(args[0])
 		key := fmt.Sprint(args[1])
 		kind := fmt.Sprint(args[2])
 
+		// Check parameters
+
+		if len(args) > 3 {
+			if trans, ok = args[3].(graph.Trans); !ok {
+				err = fmt.Errorf("Fourth parameter must be a transaction")
+			}
+		}
+
 		conv := func(m map[string]interface{}) map[interface{}]interface{} {
 			c := make(map[interface{}]interface{})
 			for k, v := range m {
@@ -171,10 +181,18 @@ func (f *FetchEdgeFunc) Run(instanceID string, vs parser.Scope, is map[string]in
 			return c
 		}
 
-		// Fetch the node
+		// Fetch the edge - a transaction also returns its pending changes
 
-		if node, err = f.GM.FetchEdge(part, key, kind); node != nil {
-			res = conv(node.Data())
+		if err == nil {
+			if trans != nil {
+				edge, err = trans.FetchEdge(part, key, kind)
+			} else {
+				edge, err = f.GM.FetchEdge(part, key, kind)
+			}
+
+			if edge != nil {
+				res = conv(edge.Data())
+			}
 		}
 	}
 
@@ -202,20 +220,30 @@ func (f *TraverseFunc) Run(instanceID string, vs parser.Scope, is map[string]int
 	var res interface{}
 	var err error
 
-	if arglen := len(args); arglen != 4 {
-		err = fmt.Errorf("Function requires 4 parameters: partition, node key," +
-			" node kind and a traversal spec")
+	if arglen := len(args); arglen != 4 && arglen != 5 {
+		err = fmt.Errorf("Function requires 4 or 5 parameters: partition, node key," +
+			" node kind, a traversal spec and optionally a transaction")
 	}
 
 	if err == nil {
 		var nodes []data.Node
 		var edges []data.Edge
+		var trans graph.Trans
+		var ok bool
 
 		part := fmt.Sprint(args[0])
 		key := fmt.Sprint(args[1])
 		kind := fmt.Sprint(args[2])
 		spec := fmt.Sprint(args[3])
 
+		// Check parameters
+
+		if len(args) > 4 {
+			if trans, ok = args[4].(graph.Trans); !ok {
+				err = fmt.Errorf("Fifth parameter must be a transaction")
+			}
+		}
+
 		conv := func(m map[string]interface{}) map[interface{}]interface{} {
 			c := make(map[interface{}]interface{})
 			for k, v := range m {
@@ -224,9 +252,17 @@ func (f *TraverseFunc) Run(instanceID string, vs parser.Scope, is map[string]int
 			return c
 		}
 
-		// Do the traversal
+		// Do the traversal - a transaction also follows its pending changes
 
-		if nodes, edges, err = f.GM.TraverseMulti(part, key, kind, spec, true); err == nil {
+		if err == nil {
+			if trans != nil {
+				nodes, edges, err = trans.Traverse(part, key, kind, spec, true)
+			} else {
+				nodes, edges, err = f.GM.TraverseMulti(part, key, kind, spec, true)
+			}
+		}
+
+		if err == nil {
 
 			resNodes := make([]interface{}, len(nodes))
 			for i, n := range nodes {
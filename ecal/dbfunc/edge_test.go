@@ -106,7 +106,7 @@ func TestStoreAndRemoveEdge(t *testing.T) {
 	}
 
 	if _, err := fe.Run("", nil, nil, 0, []interface{}{""}); err == nil ||
-		err.Error() != "Function requires 3 parameters: partition, edge key and edge kind" {
+		err.Error() != "Function requires 3 or 4 parameters: partition, edge key, edge kind and optionally a transaction" {
 		t.Error(err)
 		return
 	}
@@ -123,6 +123,7 @@ func TestStoreAndRemoveEdge(t *testing.T) {
 GraphEdge:
               key : 123
              kind : e
+             _rev : 1
     end1cascading : true
           end1key : a
          end1kind : b
@@ -144,7 +145,7 @@ GraphEdge:
 	}
 
 	if _, err := tr.Run("", nil, nil, 0, []interface{}{""}); err == nil ||
-		err.Error() != "Function requires 4 parameters: partition, node key, node kind and a traversal spec" {
+		err.Error() != "Function requires 4 or 5 parameters: partition, node key, node kind, a traversal spec and optionally a transaction" {
 		t.Error(err)
 		return
 	}
@@ -278,6 +279,42 @@ func TestStoreEdgeTrans(t *testing.T) {
 		t.Error("Unexpected result:", res)
 		return
 	}
+
+	// Pending edges can be fetched and traversed through the transaction
+
+	fe := &FetchEdgeFunc{gm}
+	tr := &TraverseFunc{gm}
+
+	if res, err := fe.Run("", nil, nil, 0, []interface{}{"main", "123", "e", trans}); err != nil ||
+		res.(map[interface{}]interface{})["end2key"] != "c" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := fe.Run("", nil, nil, 0, []interface{}{"main", "123", "e", nil}); err == nil ||
+		err.Error() != "Fourth parameter must be a transaction" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := tr.Run("", nil, nil, 0, []interface{}{"main", "a", "b", ":::", trans}); err != nil ||
+		fmt.Sprint(res.([]interface{})[0]) != "[map[key:c kind:d]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := tr.Run("", nil, nil, 0, []interface{}{"main", "a", "b", ":::"}); err != nil ||
+		fmt.Sprint(res) != "[[] []]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := tr.Run("", nil, nil, 0, []interface{}{"main", "a", "b", ":::", nil}); err == nil ||
+		err.Error() != "Fifth parameter must be a transaction" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
 	if _, err := tc.Run("", nil, nil, 0, []interface{}{trans}); err != nil {
 		t.Error(err)
 		return
@@ -204,18 +204,28 @@ func (f *FetchNodeFunc) Run(instanceID string, vs parser.Scope, is map[string]in
 	var res interface{}
 	var err error
 
-	if arglen := len(args); arglen != 3 {
-		err = fmt.Errorf("Function requires 3 parameters: partition, node key" +
-			" node kind")
+	if arglen := len(args); arglen != 3 && arglen != 4 {
+		err = fmt.Errorf("Function requires 3 or 4 parameters: partition, node key" +
+			" node kind and optionally a transaction")
 	}
 
 	if err == nil {
 		var node data.Node
+		var trans graph.Trans
+		var ok bool
 
 		part := fmt.Sprint(args[0])
 		key := fmt.Sprint(args[1])
 		kind := fmt.Sprint(args[2])
 
+		// Check parameters
+
+		if len(args) > 3 {
+			if trans, ok = args[3].(graph.Trans); !ok {
+				err = fmt.Errorf("Fourth parameter must be a transaction")
+			}
+		}
+
 		conv := func(m map[string]interface{}) map[interface{}]interface{} {
 			c := make(map[interface{}]interface{})
 			for k, v := range m {
@@ -224,10 +234,18 @@ func (f *FetchNodeFunc) Run(instanceID string, vs parser.Scope, is map[string]in
 			return c
 		}
 
-		// Fetch the node
+		// Fetch the node - a transaction also returns its pending changes
 
-		if node, err = f.GM.FetchNode(part, key, kind); node != nil {
-			res = conv(node.Data())
+		if err == nil {
+			if trans != nil {
+				node, err = trans.FetchNode(part, key, kind)
+			} else {
+				node, err = f.GM.FetchNode(part, key, kind)
+			}
+
+			if node != nil {
+				res = conv(node.Data())
+			}
 		}
 	}
 
@@ -123,7 +123,7 @@ func TestStoreAndRemoveNode(t *testing.T) {
 	}
 
 	if _, err := fn.Run("", nil, nil, 0, []interface{}{""}); err == nil ||
-		err.Error() != "Function requires 3 parameters: partition, node key node kind" {
+		err.Error() != "Function requires 3 or 4 parameters: partition, node key node kind and optionally a transaction" {
 		t.Error(err)
 		return
 	}
@@ -140,6 +140,7 @@ func TestStoreAndRemoveNode(t *testing.T) {
 GraphNode:
       key : foo
      kind : bar
+     _rev : 2
      data : 1234
     data2 : 1234
 `[1:] || err != nil {
@@ -370,3 +371,130 @@ func TestStoreNodeTrans(t *testing.T) {
 		return
 	}
 }
+
+func TestRollbackTrans(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := graph.NewGraphManager(mgs)
+
+	trans := graph.NewConcurrentGraphTrans(gm)
+
+	tr := &RollbackTransFunc{gm}
+	ts := &SavepointTransFunc{gm}
+	tt := &RollbackToTransFunc{gm}
+
+	for _, f := range []interface {
+		DocString() (string, error)
+	}{tr, ts, tt} {
+		if _, err := f.DocString(); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	sn := &StoreNodeFunc{gm}
+	fn := &FetchNodeFunc{gm}
+
+	storeNode := func(key string) {
+		sn.Run("", nil, nil, 0, []interface{}{"main", map[interface{}]interface{}{
+			"key":  key,
+			"kind": "bar",
+		}, trans})
+	}
+
+	storeNode("foo1")
+
+	if _, err := ts.Run("", nil, nil, 0, []interface{}{trans, "sp1"}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	storeNode("foo2")
+
+	// Pending nodes can be fetched through the transaction
+
+	if res, err := fn.Run("", nil, nil, 0, []interface{}{"main", "foo2", "bar", trans}); err != nil ||
+		fmt.Sprint(res) != "map[key:foo2 kind:bar]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := fn.Run("", nil, nil, 0, []interface{}{"main", "foo2", "bar"}); err != nil || res != nil {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if _, err := tt.Run("", nil, nil, 0, []interface{}{trans, "sp1"}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res, err := fn.Run("", nil, nil, 0, []interface{}{"main", "foo2", "bar", trans}); err != nil || res != nil {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res := fmt.Sprint(trans.Counts()); res != "1 0 0 0" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if _, err := tr.Run("", nil, nil, 0, []interface{}{trans}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := fmt.Sprint(trans.Counts()); res != "0 0 0 0" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// Test error cases
+
+	if _, err := tr.Run("", nil, nil, 0, []interface{}{}); err == nil ||
+		err.Error() != "Function requires the transaction to rollback as parameter" {
+		t.Error(err)
+		return
+	}
+
+	if _, err := tr.Run("", nil, nil, 0, []interface{}{""}); err == nil ||
+		err.Error() != "Parameter must be a transaction" {
+		t.Error(err)
+		return
+	}
+
+	if _, err := ts.Run("", nil, nil, 0, []interface{}{trans}); err == nil ||
+		err.Error() != "Function requires 2 parameters: transaction and savepoint name" {
+		t.Error(err)
+		return
+	}
+
+	if _, err := ts.Run("", nil, nil, 0, []interface{}{"", "sp1"}); err == nil ||
+		err.Error() != "First parameter must be a transaction" {
+		t.Error(err)
+		return
+	}
+
+	if _, err := tt.Run("", nil, nil, 0, []interface{}{trans}); err == nil ||
+		err.Error() != "Function requires 2 parameters: transaction and savepoint name" {
+		t.Error(err)
+		return
+	}
+
+	if _, err := tt.Run("", nil, nil, 0, []interface{}{"", "sp1"}); err == nil ||
+		err.Error() != "First parameter must be a transaction" {
+		t.Error(err)
+		return
+	}
+
+	if _, err := tt.Run("", nil, nil, 0, []interface{}{trans, "sp1"}); err == nil ||
+		err.Error() != "GraphError: Invalid data (Unknown savepoint: sp1)" {
+		t.Error(err)
+		return
+	}
+
+	if _, err := fn.Run("", nil, nil, 0, []interface{}{"main", "foo2", "bar", "x"}); err == nil ||
+		err.Error() != "Fourth parameter must be a transaction" {
+		t.Error(err)
+		return
+	}
+}
@@ -122,3 +122,126 @@ DocString returns a descriptive string.
 func (f *CommitTransFunc) DocString() (string, error) {
 	return "Commits an existing transaction for FishDB.", nil
 }
+
+/*
+RollbackTransFunc discards all pending operations of an existing transaction
+for FishDB.
+*/
+type RollbackTransFunc struct {
+	GM *graph.Manager
+}
+
+/*
+Run executes the ECAL function.
+*/
+func (f *RollbackTransFunc) Run(instanceID string, vs parser.Scope, is map[string]interface{}, tid uint64, args []interface{}) (interface{}, error) {
+	var err error
+
+	if arglen := len(args); arglen != 1 {
+		err = fmt.Errorf(
+			"Function requires the transaction to rollback as parameter")
+	}
+
+	if err == nil {
+		trans, ok := args[0].(graph.Trans)
+
+		// Check parameters
+
+		if !ok {
+			err = fmt.Errorf("Parameter must be a transaction")
+		} else {
+			err = trans.Rollback()
+		}
+	}
+
+	return nil, err
+}
+
+/*
+DocString returns a descriptive string.
+*/
+func (f *RollbackTransFunc) DocString() (string, error) {
+	return "Discards all pending operations of an existing transaction for FishDB.", nil
+}
+
+/*
+SavepointTransFunc records a named savepoint in an existing transaction for
+FishDB.
+*/
+type SavepointTransFunc struct {
+	GM *graph.Manager
+}
+
+/*
+Run executes the ECAL function.
+*/
+func (f *SavepointTransFunc) Run(instanceID string, vs parser.Scope, is map[string]interface{}, tid uint64, args []interface{}) (interface{}, error) {
+	var err error
+
+	if arglen := len(args); arglen != 2 {
+		err = fmt.Errorf(
+			"Function requires 2 parameters: transaction and savepoint name")
+	}
+
+	if err == nil {
+		trans, ok := args[0].(graph.Trans)
+
+		// Check parameters
+
+		if !ok {
+			err = fmt.Errorf("First parameter must be a transaction")
+		} else {
+			err = trans.Savepoint(fmt.Sprint(args[1]))
+		}
+	}
+
+	return nil, err
+}
+
+/*
+DocString returns a descriptive string.
+*/
+func (f *SavepointTransFunc) DocString() (string, error) {
+	return "Records a named savepoint in an existing transaction for FishDB.", nil
+}
+
+/*
+RollbackToTransFunc discards all operations of an existing transaction for
+FishDB which were added after a given savepoint.
+*/
+type RollbackToTransFunc struct {
+	GM *graph.Manager
+}
+
+/*
+Run executes the ECAL function.
+*/
+func (f *RollbackToTransFunc) Run(instanceID string, vs parser.Scope, is map[string]interface{}, tid uint64, args []interface{}) (interface{}, error) {
+	var err error
+
+	if arglen := len(args); arglen != 2 {
+		err = fmt.Errorf(
+			"Function requires 2 parameters: transaction and savepoint name")
+	}
+
+	if err == nil {
+		trans, ok := args[0].(graph.Trans)
+
+		// Check parameters
+
+		if !ok {
+			err = fmt.Errorf("First parameter must be a transaction")
+		} else {
+			err = trans.RollbackTo(fmt.Sprint(args[1]))
+		}
+	}
+
+	return nil, err
+}
+
+/*
+DocString returns a descriptive string.
+*/
+func (f *RollbackToTransFunc) DocString() (string, error) {
+	return "Discards all operations of an existing transaction for FishDB which were added after a given savepoint.", nil
+}
@@ -161,32 +161,7 @@ func (eb *EventBridge) Handle(gm *graph.Manager, trans graph.Trans, event int, e
 
 		// Build up state
 
-		state := map[interface{}]interface{}{
-			"part":  fmt.Sprint(ed[0]),
-			"trans": trans,
-		}
-
-		// Include the right arguments into the state
-
-		switch event {
-		case graph.EventNodeCreated, graph.EventNodeUpdate, graph.EventNodeDeleted, graph.EventNodeStore:
-			state["node"] = scope.ConvertJSONToECALObject(ed[1].(data.Node).Data())
-
-		case graph.EventNodeUpdated:
-			state["node"] = scope.ConvertJSONToECALObject(ed[1].(data.Node).Data())
-			state["old_node"] = scope.ConvertJSONToECALObject(ed[2].(data.Node).Data())
-
-		case graph.EventEdgeCreated, graph.EventEdgeDeleted, graph.EventEdgeStore:
-			state["edge"] = scope.ConvertJSONToECALObject(ed[1].(data.Edge).Data())
-
-		case graph.EventEdgeUpdated:
-			state["edge"] = scope.ConvertJSONToECALObject(ed[1].(data.Edge).Data())
-			state["old_edge"] = scope.ConvertJSONToECALObject(ed[2].(data.Edge).Data())
-
-		case graph.EventNodeDelete, graph.EventEdgeDelete:
-			state["key"] = fmt.Sprint(ed[1])
-			state["kind"] = fmt.Sprint(ed[2])
-		}
+		state := eventState(trans, event, ed...)
 
 		// Try to inject the event
 
@@ -236,3 +211,38 @@ func (eb *EventBridge) Handle(gm *graph.Manager, trans graph.Trans, event int, e
 
 	return err
 }
+
+/*
+eventState builds the ECAL event state for a graph event.
+*/
+func eventState(trans graph.Trans, event int, ed ...interface{}) map[interface{}]interface{} {
+
+	state := map[interface{}]interface{}{
+		"part":  fmt.Sprint(ed[0]),
+		"trans": trans,
+	}
+
+	// Include the right arguments into the state
+
+	switch event {
+	case graph.EventNodeCreated, graph.EventNodeUpdate, graph.EventNodeDeleted, graph.EventNodeStore:
+		state["node"] = scope.ConvertJSONToECALObject(ed[1].(data.Node).Data())
+
+	case graph.EventNodeUpdated:
+		state["node"] = scope.ConvertJSONToECALObject(ed[1].(data.Node).Data())
+		state["old_node"] = scope.ConvertJSONToECALObject(ed[2].(data.Node).Data())
+
+	case graph.EventEdgeCreated, graph.EventEdgeDeleted, graph.EventEdgeStore:
+		state["edge"] = scope.ConvertJSONToECALObject(ed[1].(data.Edge).Data())
+
+	case graph.EventEdgeUpdated:
+		state["edge"] = scope.ConvertJSONToECALObject(ed[1].(data.Edge).Data())
+		state["old_edge"] = scope.ConvertJSONToECALObject(ed[2].(data.Edge).Data())
+
+	case graph.EventNodeDelete, graph.EventEdgeDelete:
+		state["key"] = fmt.Sprint(ed[1])
+		state["kind"] = fmt.Sprint(ed[2])
+	}
+
+	return state
+}
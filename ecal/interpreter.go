@@ -12,6 +12,7 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/Fisch-Labs/FishDB/config"
 	"github.com/Fisch-Labs/FishDB/ecal/dbfunc"
@@ -44,12 +45,20 @@ type ScriptingInterpreter struct {
 	DebugServerPort string // Debug server port
 
 	WebsocketConnections *datautil.MapCache
+
+	triggers     map[string]*loadedTrigger // Triggers which are loaded from the database
+	triggerMutex *sync.RWMutex             // Mutex to protect the loaded triggers
+	reloadMutex  *sync.Mutex               // Mutex to serialize trigger reloads
 }
 
 /*
-NewScriptingInterpreter returns a new ECAL scripting interpreter.
+NewScriptingInterpreter returns a new ECAL scripting interpreter. Trigger nodes
+in the system partition of the given graph manager are protected so they can
+only be written by the interpreter.
 */
 func NewScriptingInterpreter(scriptFolder string, gm *graph.Manager) *ScriptingInterpreter {
+	gm.ProtectSystemKind(KindTrigger)
+
 	return &ScriptingInterpreter{
 		GM:                   gm,
 		Dir:                  scriptFolder,
@@ -60,6 +69,9 @@ func NewScriptingInterpreter(scriptFolder string, gm *graph.Manager) *ScriptingI
 		DebugServerHost:      config.Str(config.ECALDebugServerHost),
 		DebugServerPort:      config.Str(config.ECALDebugServerPort),
 		WebsocketConnections: datautil.NewMapCache(5000, 0),
+		triggers:             make(map[string]*loadedTrigger),
+		triggerMutex:         &sync.RWMutex{},
+		reloadMutex:          &sync.Mutex{},
 	}
 }
 
@@ -79,6 +91,7 @@ After this function completes:
 - A debug server might be running which can reload the entry script
 - ECAL's event processor has been started
 - GraphManager events are being forwarded to ECAL
+- Triggers which are stored in the database have been loaded
 */
 func (si *ScriptingInterpreter) Run() error {
 	var err error
@@ -152,6 +165,14 @@ func (si *ScriptingInterpreter) Run() error {
 				Processor: i.RuntimeProvider.Processor,
 				Logger:    i.RuntimeProvider.Logger,
 			})
+
+			// Load the triggers which are stored in the database
+
+			if err == nil {
+				if err = si.ReloadTriggers(); err == nil {
+					si.GM.SetGraphRule(&TriggerBridge{si})
+				}
+			}
 		}
 	}
 
@@ -227,8 +248,19 @@ func AddFishDBStdlibFunctions(gm *graph.Manager) {
 	stdlib.AddStdlibFunc("db", "newTrans", &dbfunc.NewTransFunc{GM: gm})
 	stdlib.AddStdlibFunc("db", "newRollingTrans", &dbfunc.NewRollingTransFunc{GM: gm})
 	stdlib.AddStdlibFunc("db", "commit", &dbfunc.CommitTransFunc{GM: gm})
+	stdlib.AddStdlibFunc("db", "rollback", &dbfunc.RollbackTransFunc{GM: gm})
+	stdlib.AddStdlibFunc("db", "savepoint", &dbfunc.SavepointTransFunc{GM: gm})
+	stdlib.AddStdlibFunc("db", "rollbackTo", &dbfunc.RollbackToTransFunc{GM: gm})
 	stdlib.AddStdlibFunc("db", "query", &dbfunc.QueryFunc{GM: gm})
 	stdlib.AddStdlibFunc("db", "graphQL", &dbfunc.GraphQLFunc{GM: gm})
+	stdlib.AddStdlibFunc("db", "bfs", &dbfunc.AlgorithmFunc{GM: gm, Algorithm: "bfs"})
+	stdlib.AddStdlibFunc("db", "dfs", &dbfunc.AlgorithmFunc{GM: gm, Algorithm: "dfs"})
+	stdlib.AddStdlibFunc("db", "shortestPath", &dbfunc.AlgorithmFunc{GM: gm, Algorithm: "shortestpath"})
+	stdlib.AddStdlibFunc("db", "connectedComponents", &dbfunc.AlgorithmFunc{GM: gm, Algorithm: "components"})
+	stdlib.AddStdlibFunc("db", "pageRank", &dbfunc.AlgorithmFunc{GM: gm, Algorithm: "pagerank"})
+	stdlib.AddStdlibFunc("db", "personalizedPageRank", &dbfunc.AlgorithmFunc{GM: gm, Algorithm: "personalizedpagerank"})
+	stdlib.AddStdlibFunc("db", "degreeCentrality", &dbfunc.AlgorithmFunc{GM: gm, Algorithm: "degree"})
+	stdlib.AddStdlibFunc("db", "communities", &dbfunc.AlgorithmFunc{GM: gm, Algorithm: "communities"})
 	stdlib.AddStdlibFunc("db", "raiseGraphEventHandled", &dbfunc.RaiseGraphEventHandledFunc{})
 	stdlib.AddStdlibFunc("db", "raiseWebEventHandled", &dbfunc.RaiseWebEventHandledFunc{})
 
@@ -0,0 +1,496 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package ecal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Fisch-Labs/FishDB/graph"
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/Tide/parser"
+	"github.com/Fisch-Labs/Tide/scope"
+	"github.com/Fisch-Labs/Tide/util"
+	"github.com/Fisch-Labs/Toolkit/errorutil"
+	"github.com/Fisch-Labs/Toolkit/stringutil"
+)
+
+/*
+KindTrigger is the node kind of triggers in the system partition
+*/
+const KindTrigger = "ECALTrigger"
+
+/*
+Trigger models an ECAL trigger which is stored in the database. The ECAL body
+of an enabled trigger is run for every graph event of the given event kinds
+(e.g. db.node.created). The body can access the event in the same way as the
+body of a sink.
+*/
+type Trigger struct {
+	Name    string   `json:"name"`    // Unique name of the trigger
+	Kinds   []string `json:"kinds"`   // Event kinds which run the trigger
+	Body    string   `json:"body"`    // ECAL code of the trigger
+	Enabled bool     `json:"enabled"` // Flag if the trigger is enabled
+	Version int      `json:"version"` // Version of the trigger definition
+}
+
+/*
+loadedTrigger is a trigger which was loaded into the interpreter.
+*/
+type loadedTrigger struct {
+	*Trigger
+	ast *parser.ASTNode // Parsed body (nil if the trigger is disabled)
+}
+
+/*
+Triggers returns all loaded triggers sorted by name.
+*/
+func (si *ScriptingInterpreter) Triggers() []*Trigger {
+	si.triggerMutex.RLock()
+	defer si.triggerMutex.RUnlock()
+
+	res := make([]*Trigger, 0, len(si.triggers))
+
+	for _, t := range si.triggers {
+		res = append(res, t.Trigger)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+
+	return res
+}
+
+/*
+Trigger returns a loaded trigger. Returns nil if the trigger does not exist.
+*/
+func (si *ScriptingInterpreter) Trigger(name string) *Trigger {
+	si.triggerMutex.RLock()
+	defer si.triggerMutex.RUnlock()
+
+	if t, ok := si.triggers[name]; ok {
+		return t.Trigger
+	}
+
+	return nil
+}
+
+/*
+DefineTrigger stores a new version of a trigger and reloads all triggers.
+The version of the given trigger is set to the stored version.
+*/
+func (si *ScriptingInterpreter) DefineTrigger(trigger *Trigger) error {
+
+	if err := si.checkTrigger(trigger); err != nil {
+		return err
+	}
+
+	old, err := si.GM.FetchNode(graph.SystemPartition, trigger.Name, KindTrigger)
+	if err != nil {
+		return err
+	}
+
+	var rev interface{} = 0
+
+	trigger.Version = 1
+	if old != nil {
+		rev = old.Attr(data.NodeRevision)
+		trigger.Version = nodeToTrigger(old).Version + 1
+	}
+
+	// The expected revision makes sure that concurrent definitions do not
+	// produce the same version
+
+	node := triggerToNode(trigger)
+	node.SetAttr(data.NodeRevision, rev)
+
+	trans := graph.NewSystemGraphTrans(si.GM)
+
+	if err := trans.StoreNode(graph.SystemPartition, node); err != nil {
+		return err
+	}
+
+	if err := trans.Commit(); err != nil {
+		return err
+	}
+
+	return si.ReloadTriggers()
+}
+
+/*
+EnableTrigger enables or disables a trigger and reloads all triggers.
+*/
+func (si *ScriptingInterpreter) EnableTrigger(name string, enabled bool) error {
+
+	if si.Trigger(name) == nil {
+		return fmt.Errorf("Unknown trigger %v", name)
+	}
+
+	node := data.NewGraphNode()
+	node.SetAttr(data.NodeKey, name)
+	node.SetAttr(data.NodeKind, KindTrigger)
+	node.SetAttr("enabled", enabled)
+
+	trans := graph.NewSystemGraphTrans(si.GM)
+
+	if err := trans.UpdateNode(graph.SystemPartition, node); err != nil {
+		return err
+	}
+
+	if err := trans.Commit(); err != nil {
+		return err
+	}
+
+	return si.ReloadTriggers()
+}
+
+/*
+RevertTrigger defines a new version of a trigger from the kinds and body of a
+previous version.
+*/
+func (si *ScriptingInterpreter) RevertTrigger(name string, version int) error {
+
+	versions, err := si.TriggerVersions(name)
+	if err != nil {
+		return err
+	}
+
+	// Keep the current enabled state of the trigger
+
+	enabled := true
+	if current := si.Trigger(name); current != nil {
+		enabled = current.Enabled
+	}
+
+	for _, t := range versions {
+		if t.Version == version {
+			return si.DefineTrigger(&Trigger{name, t.Kinds, t.Body, enabled, 0})
+		}
+	}
+
+	return fmt.Errorf("Unknown version %v of trigger %v", version, name)
+}
+
+/*
+RemoveTrigger removes a trigger and reloads all triggers.
+*/
+func (si *ScriptingInterpreter) RemoveTrigger(name string) error {
+
+	if si.Trigger(name) == nil {
+		return fmt.Errorf("Unknown trigger %v", name)
+	}
+
+	trans := graph.NewSystemGraphTrans(si.GM)
+
+	if err := trans.RemoveNode(graph.SystemPartition, name, KindTrigger); err != nil {
+		return err
+	}
+
+	if err := trans.Commit(); err != nil {
+		return err
+	}
+
+	return si.ReloadTriggers()
+}
+
+/*
+TriggerVersions returns all versions of a trigger ordered from the oldest to
+the newest version. The versions are read from the node history of the trigger.
+*/
+func (si *ScriptingInterpreter) TriggerVersions(name string) ([]*Trigger, error) {
+	res := make([]*Trigger, 0)
+
+	history, err := si.GM.NodeHistory(graph.SystemPartition, name, KindTrigger)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range history {
+		if entry.Deleted {
+			continue
+		}
+
+		t := nodeToTrigger(entry.Node)
+
+		// Only the last state of every version is returned
+
+		if len(res) > 0 && res[len(res)-1].Version == t.Version {
+			res[len(res)-1] = t
+		} else {
+			res = append(res, t)
+		}
+	}
+
+	return res, nil
+}
+
+/*
+ReloadTriggers loads all triggers from the database into the interpreter.
+Triggers with an invalid body are loaded but not run.
+*/
+func (si *ScriptingInterpreter) ReloadTriggers() error {
+
+	// Only one reload should happen at a time so the last reload always
+	// reads the latest state
+
+	si.reloadMutex.Lock()
+	defer si.reloadMutex.Unlock()
+
+	triggers := make(map[string]*loadedTrigger)
+
+	it, err := si.GM.NodeKeyIterator(graph.SystemPartition, KindTrigger)
+
+	for err == nil && it != nil && it.HasNext() {
+		var node data.Node
+
+		key := it.Next()
+
+		if err = it.Error(); err == nil {
+			if node, err = si.GM.FetchNode(graph.SystemPartition, key, KindTrigger); err == nil && node != nil {
+				t := &loadedTrigger{nodeToTrigger(node), nil}
+
+				if t.Enabled {
+					var perr error
+
+					// A trigger with an invalid body is not run but can still be
+					// changed or removed
+
+					if t.ast, perr = si.parseTrigger(t.Trigger); perr != nil {
+						t.ast = nil
+						si.Interpreter.RuntimeProvider.Logger.LogError(
+							fmt.Sprintf("Could not load trigger %v: %v", t.Name, perr))
+					}
+				}
+
+				triggers[t.Name] = t
+			}
+		}
+	}
+
+	if err == nil {
+		si.triggerMutex.Lock()
+		si.triggers = triggers
+		si.triggerMutex.Unlock()
+	}
+
+	return err
+}
+
+/*
+checkTrigger checks if a given trigger can be defined.
+*/
+func (si *ScriptingInterpreter) checkTrigger(trigger *Trigger) error {
+
+	if trigger.Name == "" {
+		return fmt.Errorf("Trigger needs a name")
+	}
+
+	if len(trigger.Kinds) == 0 {
+		return fmt.Errorf("Trigger %v needs at least one event kind", trigger.Name)
+	}
+
+	for _, kind := range trigger.Kinds {
+		known := false
+
+		for _, name := range EventMapping {
+			if kind == name {
+				known = true
+				break
+			}
+		}
+
+		if !known {
+			return fmt.Errorf("Unknown event kind for trigger %v: %v", trigger.Name, kind)
+		}
+	}
+
+	_, err := si.parseTrigger(trigger)
+
+	return err
+}
+
+/*
+parseTrigger parses and validates the body of a trigger.
+*/
+func (si *ScriptingInterpreter) parseTrigger(trigger *Trigger) (*parser.ASTNode, error) {
+
+	if si.Interpreter == nil {
+		return nil, fmt.Errorf("ECAL interpreter is not running")
+	}
+
+	ast, err := parser.ParseWithRuntime(fmt.Sprintf("trigger %v", trigger.Name),
+		trigger.Body, si.Interpreter.RuntimeProvider)
+
+	if err == nil {
+		err = ast.Runtime.Validate()
+	}
+
+	return ast, err
+}
+
+/*
+matchingTriggers returns all enabled triggers which handle a given event kind.
+*/
+func (si *ScriptingInterpreter) matchingTriggers(kind string) []*loadedTrigger {
+	var res []*loadedTrigger
+
+	si.triggerMutex.RLock()
+	defer si.triggerMutex.RUnlock()
+
+	for _, t := range si.triggers {
+		if t.ast != nil {
+			for _, k := range t.Kinds {
+				if k == kind {
+					res = append(res, t)
+					break
+				}
+			}
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+
+	return res
+}
+
+/*
+triggerToNode converts a trigger into a node which can be stored.
+*/
+func triggerToNode(trigger *Trigger) data.Node {
+	node := data.NewGraphNode()
+
+	node.SetAttr(data.NodeKey, trigger.Name)
+	node.SetAttr(data.NodeKind, KindTrigger)
+	node.SetAttr("kinds", strings.Join(trigger.Kinds, ","))
+	node.SetAttr("body", trigger.Body)
+	node.SetAttr("enabled", trigger.Enabled)
+	node.SetAttr("version", trigger.Version)
+
+	return node
+}
+
+/*
+nodeToTrigger converts a stored node into a trigger.
+*/
+func nodeToTrigger(node data.Node) *Trigger {
+	var version int
+	var kinds []string
+
+	fmt.Sscan(fmt.Sprint(node.Attr("version")), &version)
+
+	if k := node.Attr("kinds"); k != nil && k != "" {
+		kinds = strings.Split(fmt.Sprint(k), ",")
+	}
+
+	return &Trigger{node.Key(), kinds, fmt.Sprint(node.Attr("body")),
+		stringutil.IsTrueValue(fmt.Sprint(node.Attr("enabled"))), version}
+}
+
+/*
+TriggerBridge is a rule for a graph manager which runs the triggers of a
+scripting interpreter. Events of the system partition do not run triggers.
+*/
+type TriggerBridge struct {
+	SI *ScriptingInterpreter
+}
+
+/*
+Name returns the name of the rule.
+*/
+func (tb *TriggerBridge) Name() string {
+	return "ecal.triggers"
+}
+
+/*
+Handles returns a list of events which are handled by this rule.
+*/
+func (tb *TriggerBridge) Handles() []int {
+	return []int{
+		graph.EventNodeCreated,
+		graph.EventNodeUpdated,
+		graph.EventNodeDeleted,
+		graph.EventEdgeCreated,
+		graph.EventEdgeUpdated,
+		graph.EventEdgeDeleted,
+		graph.EventNodeStore,
+		graph.EventNodeUpdate,
+		graph.EventNodeDelete,
+		graph.EventEdgeStore,
+		graph.EventEdgeDelete,
+	}
+}
+
+/*
+Handle handles an event.
+*/
+func (tb *TriggerBridge) Handle(gm *graph.Manager, trans graph.Trans, event int, ed ...interface{}) error {
+
+	name, ok := EventMapping[event]
+	if !ok {
+		return nil
+	}
+
+	// Trigger nodes can only be changed by the interpreter which reloads the
+	// triggers itself
+
+	if fmt.Sprint(ed[0]) == graph.SystemPartition {
+		return nil
+	}
+
+	triggers := tb.SI.matchingTriggers(name)
+	if len(triggers) == 0 {
+		return nil
+	}
+
+	erp := tb.SI.Interpreter.RuntimeProvider
+
+	// Triggers see the event in the same way as sinks
+
+	ecalEvent := map[interface{}]interface{}{
+		"name":  fmt.Sprintf("FishDB: %v", name),
+		"kind":  name,
+		"state": eventState(trans, event, ed...),
+	}
+
+	var errList []error
+	var handled bool
+
+	for _, t := range triggers {
+		vs := scope.NewScopeWithParent(fmt.Sprintf("trigger %v", t.Name), tb.SI.Interpreter.GlobalVS)
+		vs.SetValue("event", ecalEvent)
+
+		if _, err := t.ast.Runtime.Eval(vs, make(map[string]interface{}), erp.NewThreadID()); err != nil {
+
+			// Check if the trigger returned a special graph.ErrEventHandled error
+
+			if re, ok := err.(*util.RuntimeErrorWithDetail); ok && re.Detail == graph.ErrEventHandled.Error() {
+				handled = true
+			} else {
+				errList = append(errList, fmt.Errorf("Trigger %v: %v", t.Name, err))
+			}
+		}
+	}
+
+	var err error
+
+	if len(errList) > 0 {
+		err = &errorutil.CompositeError{Errors: errList}
+	} else if handled {
+		err = graph.ErrEventHandled
+	}
+
+	if err != nil {
+		erp.Logger.LogDebug(fmt.Sprintf("FishDB event %v was handled by ECAL triggers and returned: %v", name, err))
+	}
+
+	return err
+}
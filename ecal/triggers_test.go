@@ -0,0 +1,218 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package ecal
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/graph"
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+)
+
+func TestTriggers(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := graph.NewGraphManager(mgs)
+
+	ds := NewScriptingInterpreter(testScriptDir, gm)
+
+	if err := ds.DefineTrigger(&Trigger{Name: "foo", Kinds: []string{"db.node.created"}, Body: "log(1)"}); err == nil ||
+		err.Error() != "ECAL interpreter is not running" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	writeScript(`
+x := "global"
+`)
+
+	if err := ds.Run(); err != nil {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if err := ds.DefineTrigger(&Trigger{Name: "foo", Body: "log(1)"}); err == nil ||
+		err.Error() != "Trigger foo needs at least one event kind" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if err := ds.DefineTrigger(&Trigger{Name: "foo", Kinds: []string{"db.node.moved"}, Body: "log(1)"}); err == nil ||
+		err.Error() != "Unknown event kind for trigger foo: db.node.moved" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if err := ds.DefineTrigger(&Trigger{Name: "foo", Kinds: []string{"db.node.created"}, Body: "log(("}); err == nil {
+		t.Error("Invalid body should not be accepted")
+		return
+	}
+
+	// Define a trigger which can access the event and the global scope
+
+	if err := ds.DefineTrigger(&Trigger{Name: "foo", Kinds: []string{"db.node.created", "db.node.updated"},
+		Body: `log("Trigger: ", event.kind, " ", event.state.node.key, " ", x)`, Enabled: true}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := ds.Triggers(); len(res) != 1 || res[0].Name != "foo" || res[0].Version != 1 || !res[0].Enabled {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	node := data.NewGraphNode()
+	node.SetAttr("key", "123")
+	node.SetAttr("kind", "bar")
+
+	if err := gm.StoreNode("main", node); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Disabled triggers are not run
+
+	if err := ds.EnableTrigger("foo", false); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.UpdateNode("main", node); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := ds.EnableTrigger("foo", true); err != nil {
+		t.Error(err)
+		return
+	}
+
+	node.SetAttr("name", "test")
+
+	if err := gm.UpdateNode("main", node); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := checkLog(`Trigger: db.node.created 123 global
+Trigger: db.node.updated 123 global
+`); err != nil {
+		t.Error(err)
+	}
+
+	// A trigger can stop an operation
+
+	if err := ds.DefineTrigger(&Trigger{Name: "foo", Kinds: []string{"db.node.store"}, Body: `
+if event.state.node.key == "456" {
+  db.raiseGraphEventHandled()
+}
+if event.state.node.key == "789" {
+  raise("Oh no")
+}
+`, Enabled: true}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	node = data.NewGraphNode()
+	node.SetAttr("key", "456")
+	node.SetAttr("kind", "bar")
+
+	if err := gm.StoreNode("main", node); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res, err := gm.FetchNode("main", "456", "bar"); res != nil || err != nil {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	node.SetAttr("key", "789")
+
+	if err := gm.StoreNode("main", node); err == nil {
+		t.Error("Trigger error should be returned")
+		return
+	}
+
+	// Check the versions of the trigger
+
+	versions, err := ds.TriggerVersions("foo")
+	if err != nil || len(versions) != 2 || versions[0].Version != 1 ||
+		versions[1].Version != 2 || versions[1].Kinds[0] != "db.node.store" {
+		t.Error("Unexpected result:", versions, err)
+		return
+	}
+
+	if err := ds.RevertTrigger("foo", 5); err == nil || err.Error() != "Unknown version 5 of trigger foo" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if err := ds.RevertTrigger("foo", 1); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := ds.Trigger("foo"); res.Version != 3 || len(res.Kinds) != 2 || !res.Enabled {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// Triggers cannot be written directly into the database
+
+	tnode := triggerToNode(&Trigger{"direct", []string{"db.node.deleted"},
+		`log("Direct: ", event.state.node.key)`, true, 1})
+
+	expected := "GraphError: Graph rule error (GraphError: Protected system kind " +
+		"(ECALTrigger can only be written in a system transaction))"
+
+	if err := gm.StoreNode(graph.SystemPartition, tnode); err == nil || err.Error() != expected {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if _, err := gm.RemoveNode(graph.SystemPartition, "foo", KindTrigger); err == nil || err.Error() != expected {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if _, err := gm.RemoveNode("main", "123", "bar"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := ds.Trigger("direct"); res != nil {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if err := checkLog(`Trigger: db.node.created 123 global
+Trigger: db.node.updated 123 global
+`); err != nil {
+		t.Error(err)
+	}
+
+	// Remove all triggers
+
+	if err := ds.RemoveTrigger("foo"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := ds.RemoveTrigger("foo"); err == nil || err.Error() != "Unknown trigger foo" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if res := fmt.Sprint(ds.Triggers()); res != "[]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+}
@@ -0,0 +1,254 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package interpreter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Fisch-Labs/FishDB/eql/parser"
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/hash"
+)
+
+/*
+queryProfile collects statistics while a query is evaluated. All statistics
+are kept per traversal level (0 are the start nodes).
+*/
+type queryProfile struct {
+	levels []*levelProfile // Statistics of all traversal levels
+}
+
+/*
+levelProfile holds the statistics of a single traversal level.
+*/
+type levelProfile struct {
+	fetched    int           // Number of fetched nodes (each traversed node comes with an edge)
+	fetchTime  time.Duration // Time spent fetching nodes and edges
+	whereCount int           // Number of where clause evaluations
+	whereMatch int           // Number of where clause matches
+	whereTime  time.Duration // Time spent in where clause evaluation
+}
+
+/*
+level returns the statistics of a traversal level.
+*/
+func (qp *queryProfile) level(level int) *levelProfile {
+	for len(qp.levels) <= level {
+		qp.levels = append(qp.levels, &levelProfile{})
+	}
+	return qp.levels[level]
+}
+
+/*
+fetchNodes fetches nodes and edges of a traversal level. The time and the
+number of fetched nodes is recorded if the query is profiled.
+*/
+func (p *eqlRuntimeProvider) fetchNodes(level int, fetch func() (int, error)) error {
+
+	if p.profile == nil {
+		_, err := fetch()
+		return err
+	}
+
+	start := time.Now()
+	count, err := fetch()
+
+	lp := p.profile.level(level)
+	lp.fetched += count
+	lp.fetchTime += time.Since(start)
+
+	return err
+}
+
+/*
+condEval evaluates a where clause of a traversal level. The time and the
+result is recorded if the query is profiled.
+*/
+func (p *eqlRuntimeProvider) condEval(level int, where *parser.ASTNode,
+	node data.Node, edge data.Edge) (interface{}, error) {
+
+	if p.profile == nil {
+		return where.Runtime.(CondRuntime).CondEval(node, edge)
+	}
+
+	start := time.Now()
+	res, err := where.Runtime.(CondRuntime).CondEval(node, edge)
+
+	lp := p.profile.level(level)
+	lp.whereCount++
+	lp.whereTime += time.Since(start)
+
+	if res == true {
+		lp.whereMatch++
+	}
+
+	return res, err
+}
+
+/*
+explainRuntime is the runtime for explain and profile expressions. An explain
+expression returns the plan of a query without running it. A profile
+expression runs the query and returns the plan with the collected statistics.
+*/
+type explainRuntime struct {
+	rtp  *eqlRuntimeProvider
+	node *parser.ASTNode
+}
+
+/*
+explainRuntimeInst returns a new runtime component instance.
+*/
+func explainRuntimeInst(rtp *eqlRuntimeProvider, node *parser.ASTNode) parser.Runtime {
+	return &explainRuntime{rtp, node}
+}
+
+/*
+Validate this node and all its child nodes.
+*/
+func (rt *explainRuntime) Validate() error {
+	return rt.node.Children[0].Runtime.Validate()
+}
+
+/*
+Eval evaluate this runtime component.
+*/
+func (rt *explainRuntime) Eval() (interface{}, error) {
+	var rows [][]interface{}
+	var res interface{}
+	var err error
+
+	profile := rt.node.Name == parser.NodePROFILE
+
+	if !profile {
+
+		if err = rt.Validate(); err != nil {
+			return nil, err
+		}
+
+		rows = rt.planRows(nil)
+
+		return rt.newResult([]string{"Level", "Operation", "Detail"}, rows), nil
+	}
+
+	// Run the query and collect statistics
+
+	hits, misses := hash.CacheStats()
+	start := time.Now()
+
+	rt.rtp.profile = &queryProfile{}
+	defer func() {
+		rt.rtp.profile = nil
+	}()
+
+	if res, err = rt.node.Children[0].Runtime.Eval(); err != nil {
+		return nil, err
+	}
+
+	total := time.Since(start)
+	newHits, newMisses := hash.CacheStats()
+
+	rows = rt.planRows(rt.rtp.profile)
+
+	rows = append(rows, []interface{}{"", "result", fmt.Sprintf("%v rows", res.(*SearchResult).RowCount()),
+		res.(*SearchResult).RowCount(), total.String()})
+
+	rows = append(rows, []interface{}{"", "cache", fmt.Sprintf("%v hits, %v misses",
+		newHits-hits, newMisses-misses), int(newHits - hits), ""})
+
+	return rt.newResult([]string{"Level", "Operation", "Detail", "Count", "Time"}, rows), nil
+}
+
+/*
+planRows returns the rows which describe the plan of the query. The rows
+contain the statistics of a given query profile if it is not nil.
+*/
+func (rt *explainRuntime) planRows(qp *queryProfile) [][]interface{} {
+	var rows [][]interface{}
+
+	addRow := func(level int, op string, detail string, count int, t time.Duration) {
+		row := []interface{}{level + 1, op, detail}
+		if qp != nil {
+			row = append(row, count, t.String())
+		}
+		rows = append(rows, row)
+	}
+
+	stats := func(level int) *levelProfile {
+		if qp == nil {
+			return &levelProfile{}
+		}
+		return qp.level(level)
+	}
+
+	addWhere := func(level int, where *parser.ASTNode) {
+		if where != nil {
+			cond, _ := parser.PrettyPrint(where.Children[0])
+			lp := stats(level)
+
+			if qp != nil {
+				cond = fmt.Sprintf("%v (%v matches)", cond, lp.whereMatch)
+			}
+
+			addRow(level, "where", cond, lp.whereCount, lp.whereTime)
+		}
+	}
+
+	lp := stats(0)
+	addRow(0, "start", rt.rtp.plan, lp.fetched, lp.fetchTime)
+	addWhere(0, rt.rtp.where)
+
+	var addTraversals func(traversals []*parser.ASTNode)
+
+	addTraversals = func(traversals []*parser.ASTNode) {
+		for _, child := range traversals {
+			trt := child.Runtime.(*traversalRuntime)
+			lp := stats(trt.specIndex)
+
+			addRow(trt.specIndex, "traverse", trt.description(), lp.fetched, lp.fetchTime)
+			addWhere(trt.specIndex, trt.where)
+
+			var children []*parser.ASTNode
+			for _, c := range child.Children[1:] {
+				if c.Name == parser.NodeTRAVERSE {
+					children = append(children, c)
+				}
+			}
+
+			addTraversals(children)
+		}
+	}
+
+	addTraversals(rt.rtp.traversals)
+
+	return rows
+}
+
+/*
+newResult creates a search result which contains a given table.
+*/
+func (rt *explainRuntime) newResult(labels []string, rows [][]interface{}) *SearchResult {
+	var format, colData []string
+
+	query, _ := parser.PrettyPrint(rt.node)
+
+	for _, label := range labels {
+		format = append(format, "auto")
+		colData = append(colData, rt.node.Name+":"+label)
+	}
+
+	res := &SearchResult{rt.rtp.name, query, rt.rtp.withFlags, SearchHeader{"", rt.rtp.part,
+		labels, format, colData}, nil, make([][]string, 0), make([][]interface{}, 0)}
+
+	for _, row := range rows {
+		res.Source = append(res.Source, make([]string, len(row)))
+		res.Data = append(res.Data, row)
+	}
+
+	return res
+}
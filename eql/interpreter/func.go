@@ -38,6 +38,7 @@ Runtime map for where related functions
 var whereFunc = map[string]FuncWhere{
 	"count":     whereCount,
 	"parseDate": whereParseDate,
+	"score":     whereScore,
 }
 
 /*
@@ -146,6 +147,44 @@ func whereParseDate(astNode *parser.ASTNode, rtp *eqlRuntimeProvider,
 	return ret, err
 }
 
+/*
+whereScore calculates the relevance score of a node attribute for a given
+full text query.
+*/
+func whereScore(astNode *parser.ASTNode, rtp *eqlRuntimeProvider,
+	node data.Node, edge data.Edge) (interface{}, error) {
+
+	// Check parameters
+
+	if len(astNode.Children) != 3 {
+		return nil, rtp.newRuntimeError(ErrInvalidConstruct,
+			"Score function requires 2 parameters: attribute name, query string", astNode)
+	}
+
+	attr := astNode.Children[1].Token.Val
+
+	query, err := astNode.Children[2].Runtime.(CondRuntime).CondEval(node, edge)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodeScore(rtp, node, attr, fmt.Sprint(query))
+}
+
+/*
+nodeScore calculates the relevance score of a node attribute for a given full
+text query. The score is 0 if the node kind has no index.
+*/
+func nodeScore(rtp *eqlRuntimeProvider, node data.Node, attr string, query string) (float64, error) {
+
+	iq, err := rtp.gm.NodeIndexQuery(rtp.part, node.Kind())
+	if err != nil || iq == nil {
+		return 0, err
+	}
+
+	return iq.Score(attr, query, node.Key())
+}
+
 // Show related functions
 // ======================
 
@@ -153,8 +192,20 @@ func whereParseDate(astNode *parser.ASTNode, rtp *eqlRuntimeProvider,
 Runtime map for show related functions
 */
 var showFunc = map[string]FuncShowInst{
-	"count":  showCountInst,
-	"objget": showObjgetInst,
+	"count":    showCountInst,
+	"objget":   showObjgetInst,
+	"distance": showDistanceInst,
+	"score":    showScoreInst,
+	"path":     showPathInst,
+
+	// Aggregate functions
+
+	"sum":      showAggregateInst("sum", aggregateSum),
+	"avg":      showAggregateInst("avg", aggregateAvg),
+	"min":      showAggregateInst("min", aggregateMin),
+	"max":      showAggregateInst("max", aggregateMax),
+	"collect":  showAggregateInst("collect", aggregateCollect),
+	"distinct": showAggregateInst("distinct", aggregateDistinct),
 }
 
 /*
@@ -178,6 +229,20 @@ type FuncShow interface {
 	eval(node data.Node, edge data.Edge) (interface{}, string, error)
 }
 
+/*
+FuncAggregate is the interface definition for show related functions which
+aggregate the values of all rows of a group. The rows of a result are grouped
+if the result contains an aggregate function column.
+*/
+type FuncAggregate interface {
+	FuncShow
+
+	/*
+		aggregate combines the values of all rows of a group into a single value.
+	*/
+	aggregate(values []interface{}) interface{}
+}
+
 /*
 FuncShowInst creates a function object. Returns which column data should be queried and
 how the colummn should be named.
@@ -338,3 +403,383 @@ func (so *showObjget) eval(node data.Node, edge data.Edge) (interface{}, string,
 
 	return val, "n:" + node.Kind() + ":" + node.Key(), nil
 }
+
+// Show Distance
+// -------------
+
+/*
+showDistanceInst creates a new showDistance object.
+*/
+func showDistanceInst(astNode *parser.ASTNode, rtp *eqlRuntimeProvider) (FuncShow, string, string, error) {
+
+	// Check parameters
+
+	if len(astNode.Children) != 1 {
+		return nil, "", "", fmt.Errorf("Distance function does not take any parameters")
+	}
+
+	return &showDistance{rtp}, "1:n:key", "Distance", nil
+}
+
+/*
+showDistance is the vector distance of a start node which was found by a
+nearest clause.
+*/
+type showDistance struct {
+	rtp *eqlRuntimeProvider
+}
+
+/*
+name returns the name of the function.
+*/
+func (sd *showDistance) name() string {
+	return "distance"
+}
+
+/*
+eval returns the vector distance of a start node.
+*/
+func (sd *showDistance) eval(node data.Node, edge data.Edge) (interface{}, string, error) {
+	var ret interface{}
+
+	if dist, ok := sd.rtp.distances[node.Key()]; ok {
+		ret = dist
+	}
+
+	return ret, "n:" + node.Kind() + ":" + node.Key(), nil
+}
+
+// Show Score
+// ----------
+
+/*
+showScoreInst creates a new showScore object.
+*/
+func showScoreInst(astNode *parser.ASTNode, rtp *eqlRuntimeProvider) (FuncShow, string, string, error) {
+
+	// Check parameters
+
+	if len(astNode.Children) != 4 {
+		return nil, "", "",
+			fmt.Errorf("Score function requires 3 parameters: traversal step, attribute name, query string")
+	}
+
+	pos := astNode.Children[1].Token.Val
+	attr := astNode.Children[2].Token.Val
+	query := astNode.Children[3].Token.Val
+
+	return &showScore{rtp, attr, query}, pos + ":n:key", "Score", nil
+}
+
+/*
+showScore is the relevance score of a node attribute for a full text query.
+*/
+type showScore struct {
+	rtp   *eqlRuntimeProvider
+	attr  string
+	query string
+}
+
+/*
+name returns the name of the function.
+*/
+func (ss *showScore) name() string {
+	return "score"
+}
+
+/*
+eval calculates the relevance score of a node.
+*/
+func (ss *showScore) eval(node data.Node, edge data.Edge) (interface{}, string, error) {
+
+	score, err := nodeScore(ss.rtp, node, ss.attr, ss.query)
+
+	return score, "n:" + node.Kind() + ":" + node.Key(), err
+}
+
+// Show Path
+// ---------
+
+/*
+showPathInst creates a new showPath object.
+*/
+func showPathInst(astNode *parser.ASTNode, rtp *eqlRuntimeProvider) (FuncShow, string, string, error) {
+
+	// Check parameters
+
+	np := len(astNode.Children)
+
+	if np != 2 && np != 3 {
+		return nil, "", "",
+			fmt.Errorf("Path function requires 1 or 2 parameters: traversal step, nodes or edges")
+	}
+
+	pos := astNode.Children[1].Token.Val
+	showEdges := false
+
+	if np == 3 {
+		if what := strings.ToLower(astNode.Children[2].Token.Val); what == "edges" {
+			showEdges = true
+		} else if what != "nodes" {
+			return nil, "", "", fmt.Errorf("Path function can only show nodes or edges not: %v", what)
+		}
+	}
+
+	// An invalid position is reported by the caller
+
+	step, _ := strconv.Atoi(pos)
+
+	return &showPath{rtp.traversalAt(step - 1), showEdges}, pos + ":n:key", "Path", nil
+}
+
+/*
+showPath is the path from the start node to the node of a traversal step.
+*/
+type showPath struct {
+	traversal *traversalRuntime // Traversal of the step (nil for the start node)
+	showEdges bool              // Flag if the edge keys should be shown
+}
+
+/*
+name returns the name of the function.
+*/
+func (sp *showPath) name() string {
+	return "path"
+}
+
+/*
+eval returns the keys of all nodes or edges on the path to a node.
+*/
+func (sp *showPath) eval(node data.Node, edge data.Edge) (interface{}, string, error) {
+	var path *traversalPath
+
+	if node == nil {
+		return nil, "", nil
+	}
+
+	if sp.traversal != nil {
+		path = sp.traversal.path()
+	} else {
+		path = &traversalPath{[]string{node.Key()}, []string{}}
+	}
+
+	if path == nil {
+		return nil, "", nil
+	} else if sp.showEdges {
+		return path.edges, "n:" + node.Kind() + ":" + node.Key(), nil
+	}
+
+	return path.nodes, "n:" + node.Kind() + ":" + node.Key(), nil
+}
+
+// Show Aggregate
+// --------------
+
+/*
+showAggregateInst returns a function which creates a new showAggregate object.
+The aggregate function gets all not null values of a group.
+*/
+func showAggregateInst(name string, aggregate func(values []interface{}) interface{}) FuncShowInst {
+	return func(astNode *parser.ASTNode, rtp *eqlRuntimeProvider) (FuncShow, string, string, error) {
+
+		// Check parameters
+
+		if len(astNode.Children) != 2 {
+			return nil, "", "", fmt.Errorf("%v%v function requires 1 parameter: column data",
+				strings.ToUpper(name[:1]), name[1:])
+		}
+
+		colData := astNode.Children[1].Token.Val
+		colDataSplit := strings.SplitN(colData, ":", 3)
+
+		switch len(colDataSplit) {
+		case 1:
+
+			// Attribute from the root node kind
+
+			colData = "1:n:" + colData
+
+		case 2:
+
+			// First matching kind in a row provides the attribute (nodes
+			// are preferred over edges)
+
+			kind := colDataSplit[0]
+			colData = ""
+
+			for i, spec := range rtp.specs {
+				if sspec := strings.Split(spec, ":"); i == 0 && spec == kind || i > 0 && sspec[3] == kind {
+					colData = fmt.Sprint(i+1, ":n:", colDataSplit[1])
+					break
+				}
+			}
+
+			for i, spec := range rtp.specs {
+				if sspec := strings.Split(spec, ":"); colData == "" && i > 0 && sspec[1] == kind {
+					colData = fmt.Sprint(i+1, ":e:", colDataSplit[1])
+					break
+				}
+			}
+
+			if colData == "" {
+				return nil, "", "", fmt.Errorf("Cannot determine data position for kind: %v", kind)
+			}
+		}
+
+		colDataSplit = strings.SplitN(colData, ":", 3)
+		attr := colDataSplit[len(colDataSplit)-1]
+
+		return &showAggregate{name, attr, colDataSplit[1] == "e", aggregate}, colData,
+			fmt.Sprintf("%v%v(%v)", strings.ToUpper(name[:1]), name[1:],
+				rtp.ni.AttributeDisplayString("", attr)), nil
+	}
+}
+
+/*
+showAggregate is an aggregate function over an attribute of a node or an edge.
+*/
+type showAggregate struct {
+	fname   string                                 // Name of the function
+	attr    string                                 // Attribute which should be aggregated
+	isEdge  bool                                   // Flag if the attribute is an edge attribute
+	aggFunc func(values []interface{}) interface{} // Function which aggregates the values
+}
+
+/*
+name returns the name of the function.
+*/
+func (sa *showAggregate) name() string {
+	return sa.fname
+}
+
+/*
+eval returns the attribute value of a single row.
+*/
+func (sa *showAggregate) eval(node data.Node, edge data.Edge) (interface{}, string, error) {
+
+	if sa.isEdge {
+		if edge == nil {
+			return nil, "", nil
+		}
+		return edge.Attr(sa.attr), "e:" + edge.Kind() + ":" + edge.Key(), nil
+	}
+
+	if node == nil {
+		return nil, "", nil
+	}
+
+	return node.Attr(sa.attr), "n:" + node.Kind() + ":" + node.Key(), nil
+}
+
+/*
+aggregate combines the values of all rows of a group into a single value.
+*/
+func (sa *showAggregate) aggregate(values []interface{}) interface{} {
+	var notNull []interface{}
+
+	for _, v := range values {
+		if v != nil {
+			notNull = append(notNull, v)
+		}
+	}
+
+	return sa.aggFunc(notNull)
+}
+
+/*
+aggregateNumbers returns all values which are numbers.
+*/
+func aggregateNumbers(values []interface{}) []float64 {
+	var ret []float64
+
+	for _, v := range values {
+		if num, err := strconv.ParseFloat(fmt.Sprint(v), 64); err == nil {
+			ret = append(ret, num)
+		}
+	}
+
+	return ret
+}
+
+/*
+aggregateSum returns the sum of all numbers.
+*/
+func aggregateSum(values []interface{}) interface{} {
+	var sum float64
+
+	for _, num := range aggregateNumbers(values) {
+		sum += num
+	}
+
+	return sum
+}
+
+/*
+aggregateAvg returns the average of all numbers. The result is null if there
+are no numbers.
+*/
+func aggregateAvg(values []interface{}) interface{} {
+	nums := aggregateNumbers(values)
+
+	if len(nums) == 0 {
+		return nil
+	}
+
+	return aggregateSum(values).(float64) / float64(len(nums))
+}
+
+/*
+aggregateMin returns the smallest value. Values are compared as numbers if
+possible.
+*/
+func aggregateMin(values []interface{}) interface{} {
+	var ret interface{}
+
+	for _, v := range values {
+		if ret == nil || lessValue(v, ret) {
+			ret = v
+		}
+	}
+
+	return ret
+}
+
+/*
+aggregateMax returns the largest value. Values are compared as numbers if
+possible.
+*/
+func aggregateMax(values []interface{}) interface{} {
+	var ret interface{}
+
+	for _, v := range values {
+		if ret == nil || lessValue(ret, v) {
+			ret = v
+		}
+	}
+
+	return ret
+}
+
+/*
+aggregateCollect returns a list of all values.
+*/
+func aggregateCollect(values []interface{}) interface{} {
+	return append([]interface{}{}, values...)
+}
+
+/*
+aggregateDistinct returns a list of all distinct values.
+*/
+func aggregateDistinct(values []interface{}) interface{} {
+	ret := []interface{}{}
+	seen := make(map[string]bool)
+
+	for _, v := range values {
+		if key := fmt.Sprint(v); !seen[key] {
+			seen[key] = true
+			ret = append(ret, v)
+		}
+	}
+
+	return ret
+}
@@ -266,3 +266,84 @@ Mike, 4
 		return
 	}
 }
+
+func TestAggregateFunctions(t *testing.T) {
+	gm, _ := songGraph()
+	rt := NewGetRuntimeProvider("test", "main", gm, NewDefaultNodeInfo(gm))
+
+	if _, err := getResult("get Author traverse :::Song end show name, @sum(Song:ranking), "+
+		"@avg(2:n:ranking), @min(Song:name), @max(Song:ranking) group by name", `
+Labels: Author Name, Sum(Ranking), Avg(Ranking), Min(Name), Max(Ranking)
+Format: auto, auto, auto, auto, auto
+Data: 1:n:name, 2:func:sum(), 2:func:avg(), 2:func:min(), 2:func:max()
+Hans, 19, 19, MyOnlySong3, 19
+John, 32, 8, Aria1, 18
+Mike, 15, 3.75, DeadSong2, 6
+`[1:], rt, true); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Aggregate functions can be used on edges and can be ordered
+
+	if _, err := getResult("get Song traverse :Wrote::Author end show Author:name, @distinct(Wrote:number) as numbers, "+
+		"@collect(ranking), @sum(2:e:number) group by 2:n:name with ordering(descending 2:func:sum)", `
+Labels: Author Name, numbers, Collect(Ranking), Sum(Number)
+Format: auto, auto, auto, auto
+Data: 2:n:name, 2:func:distinct(), 1:func:collect(), 2:func:sum()
+Mike, [1 4 2 3], [5 3 6 1], 10
+John, [1 2 3 4], [8 2 4 18], 10
+Hans, [3], [19], 3
+`[1:], rt, false); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// All rows form a single group if there is no group by clause - there
+	// is no group if there are no rows
+
+	if _, err := getResult("get Song traverse :::Author end show @sum(ranking), @max(name), @avg(foo), @distinct(Author:name)", `
+Labels: Sum(Ranking), Max(Name), Avg(Foo), Distinct(Name)
+Format: auto, auto, auto, auto
+Data: 1:func:sum(), 1:func:max(), 1:func:avg(), 2:func:distinct()
+66, StrangeSong1, <not set>, [Mike Hans John]
+`[1:], rt, false); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err := getResult("get Song where ranking > 100 show @sum(ranking)", `
+Labels: Sum(Ranking)
+Format: auto
+Data: 1:func:sum()
+`[1:], rt, false); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Test error cases
+
+	if _, err := getResult("get Author show @sum()", "", rt, true); err == nil || err.Error() !=
+		"EQL error in test: Invalid construct (Sum function requires 1 parameter: column data) (Line:1 Pos:17)" {
+		t.Error(err)
+		return
+	}
+
+	if _, err := getResult("get Author show @sum(Song:ranking)", "", rt, true); err == nil || err.Error() !=
+		"EQL error in test: Invalid construct (Cannot determine data position for kind: Song) (Line:1 Pos:17)" {
+		t.Error(err)
+		return
+	}
+
+	if _, err := getResult("get Author show name, @max(name) group by 1:func:max", "", rt, true); err == nil || err.Error() !=
+		"EQL error in test: Invalid construct (Cannot group by aggregate function: 1:func:max) (Line:1 Pos:43)" {
+		t.Error(err)
+		return
+	}
+
+	if _, err := getResult("get Author show name group by Song:name", "", rt, true); err == nil || err.Error() !=
+		"EQL error in test: Invalid construct (Cannot determine column for group by term: Song:name) (Line:1 Pos:31)" {
+		t.Error(err)
+		return
+	}
+}
@@ -8,6 +8,8 @@
 package interpreter
 
 import (
+	"fmt"
+
 	"github.com/Fisch-Labs/FishDB/eql/parser"
 	"github.com/Fisch-Labs/FishDB/graph"
 )
@@ -39,8 +41,8 @@ NewGetRuntimeProvider creates a new GetRuntimeProvider object. This provider
 can interpret GET queries.
 */
 func NewGetRuntimeProvider(name string, part string, gm *graph.Manager, ni NodeInfo) *GetRuntimeProvider {
-	return &GetRuntimeProvider{&eqlRuntimeProvider{name, part, gm, ni, "", false, nil, "",
-		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+	return &GetRuntimeProvider{&eqlRuntimeProvider{name, part, gm, ni, "", nil, false, nil, "",
+		nil, "", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
 }
 
 /*
@@ -91,6 +93,8 @@ func (rt *getRuntime) Validate() error {
 			return rt.rtp.newRuntimeError(ErrUnknownNodeKind, startKind, rt.node.Children[0])
 		}
 
+		rt.rtp.plan = fmt.Sprintf("scan of all %v nodes", startKind)
+
 		rt.rtp.nextStartKey = func() (string, error) {
 			nextKey := startKeyIterator.Next()
 			if startKeyIterator.LastError != nil {
@@ -112,6 +116,8 @@ func (rt *getRuntime) Validate() error {
 
 		nodePtr := len(nodes)
 
+		rt.rtp.plan = fmt.Sprintf("scan of %v nodes in group %v", startKind, rt.rtp.groupScope)
+
 		// Iterate over all traversed nodes
 
 		rt.rtp.nextStartKey = func() (string, error) {
@@ -126,6 +132,38 @@ func (rt *getRuntime) Validate() error {
 		}
 	}
 
+	// Use the index to narrow down the start keys if possible
+
+	if initErr == nil && rt.rtp.where != nil && rt.rtp.nearest == nil && rt.rtp.groupScope == "" {
+
+		keys, desc, err := rt.rtp.where.Runtime.(*whereRuntime).indexPlan(startKind)
+
+		if err != nil {
+			return err
+		} else if keys != nil {
+
+			rt.rtp.plan = fmt.Sprintf("index lookup of %v nodes: %v (%v candidates)",
+				startKind, desc, len(keys))
+
+			rt.rtp.nextStartKey = func() (string, error) {
+				if len(keys) == 0 {
+					return "", nil
+				}
+
+				key := keys[0]
+				keys = keys[1:]
+
+				return key, nil
+			}
+		}
+	}
+
+	// Replace the start keys with the nearest nodes if requested
+
+	if initErr == nil && rt.rtp.nearest != nil {
+		return rt.rtp.applyNearest(startKind, rt.rtp.groupScope == "")
+	}
+
 	return initErr
 }
 
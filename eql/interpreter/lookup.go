@@ -8,6 +8,8 @@
 package interpreter
 
 import (
+	"fmt"
+
 	"github.com/Fisch-Labs/FishDB/eql/parser"
 	"github.com/Fisch-Labs/FishDB/graph"
 )
@@ -39,8 +41,8 @@ NewLookupRuntimeProvider creates a new LookupRuntimeProvider object. This provid
 can interpret LOOKUP queries.
 */
 func NewLookupRuntimeProvider(name string, part string, gm *graph.Manager, ni NodeInfo) *LookupRuntimeProvider {
-	return &LookupRuntimeProvider{&eqlRuntimeProvider{name, part, gm, ni, "", false, nil, "",
-		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+	return &LookupRuntimeProvider{&eqlRuntimeProvider{name, part, gm, ni, "", nil, false, nil, "",
+		nil, "", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
 }
 
 /*
@@ -110,6 +112,8 @@ func (rt *lookupRuntime) Validate() error {
 
 		nodePtr := len(keys)
 
+		rt.rtp.plan = fmt.Sprintf("lookup of %v %v nodes", nodePtr, startKind)
+
 		if nodePtr > 0 {
 
 			// Iterate over all traversed nodes
@@ -145,6 +149,8 @@ func (rt *lookupRuntime) Validate() error {
 
 		nodePtr := len(nodes)
 
+		rt.rtp.plan = fmt.Sprintf("lookup of %v %v nodes in group %v", len(keys), startKind, rt.rtp.groupScope)
+
 		// Iterate over all traversed nodes
 
 		rt.rtp.nextStartKey = func() (string, error) {
@@ -164,6 +170,12 @@ func (rt *lookupRuntime) Validate() error {
 		}
 	}
 
+	// Order the given keys by vector distance if requested
+
+	if initErr == nil && rt.rtp.nearest != nil {
+		return rt.rtp.applyNearest(startKind, false)
+	}
+
 	return initErr
 }
 
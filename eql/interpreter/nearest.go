@@ -0,0 +1,259 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package interpreter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Fisch-Labs/FishDB/eql/parser"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+)
+
+/*
+nearestRuntime is the runtime for nearest clauses. A nearest clause replaces
+the start nodes of a query with the k nodes which have the closest vectors to
+a given vector.
+*/
+type nearestRuntime struct {
+	rtp  *eqlRuntimeProvider
+	node *parser.ASTNode
+
+	k      int       // Number of nodes to return
+	vector []float32 // Vector to compare with
+	attr   string    // Vector attribute of the start node kind
+}
+
+/*
+nearestRuntimeInst returns a new runtime component instance.
+*/
+func nearestRuntimeInst(rtp *eqlRuntimeProvider, node *parser.ASTNode) parser.Runtime {
+	return &nearestRuntime{rtp, node, 0, nil, ""}
+}
+
+/*
+Validate this node and all its child nodes.
+*/
+func (rt *nearestRuntime) Validate() error {
+	var err error
+
+	// Check number of results
+
+	kNode := rt.node.Children[0]
+
+	if rt.k, err = strconv.Atoi(kNode.Token.Val); err != nil || rt.k < 1 {
+		return rt.rtp.newRuntimeError(ErrInvalidConstruct,
+			"Number of nearest nodes must be a positive integer: "+kNode.Token.Val, kNode)
+	}
+
+	// Determine the vector
+
+	vecNode := rt.node.Children[1]
+
+	if vecNode.Name == parser.NodeLIST {
+
+		rt.vector = make([]float32, len(vecNode.Children))
+
+		for i, child := range vecNode.Children {
+			num, err := rt.numberValue(child)
+			if err != nil {
+				return err
+			}
+			rt.vector[i] = float32(num)
+		}
+
+	} else if strings.HasPrefix(vecNode.Token.Val, "$") {
+
+		param, ok := rt.rtp.params[vecNode.Token.Val[1:]]
+		if !ok {
+			return rt.rtp.newRuntimeError(ErrInvalidConstruct,
+				"Unknown query parameter: "+vecNode.Token.Val, vecNode)
+		}
+
+		if rt.vector, ok = util.ToVector(param); !ok {
+			return rt.rtp.newRuntimeError(ErrNotAVector,
+				fmt.Sprintf("%v=%v", vecNode.Token.Val, param), vecNode)
+		}
+
+	} else {
+
+		return rt.rtp.newRuntimeError(ErrNotAVector, vecNode.Token.Val, vecNode)
+	}
+
+	if len(rt.vector) == 0 {
+		return rt.rtp.newRuntimeError(ErrNotAVector, "Vector must not be empty", vecNode)
+	}
+
+	rt.attr = rt.node.Children[2].Token.Val
+
+	return nil
+}
+
+/*
+numberValue returns the number value of a list element.
+*/
+func (rt *nearestRuntime) numberValue(node *parser.ASTNode) (float64, error) {
+
+	if node.Name == parser.NodeVALUE {
+
+		if num, err := strconv.ParseFloat(node.Token.Val, 64); err == nil {
+			return num, nil
+		}
+
+	} else if (node.Name == parser.NodeMINUS || node.Name == parser.NodePLUS) && len(node.Children) == 1 {
+
+		num, err := rt.numberValue(node.Children[0])
+		if err == nil && node.Name == parser.NodeMINUS {
+			num = -num
+		}
+
+		return num, err
+	}
+
+	res, _ := parser.PrettyPrint(node)
+
+	return 0, rt.rtp.newRuntimeError(ErrNotANumber, res, node)
+}
+
+/*
+Eval evaluate this runtime component.
+*/
+func (rt *nearestRuntime) Eval() (interface{}, error) {
+	return nil, rt.rtp.newRuntimeError(ErrInvalidConstruct, rt.node.Name, rt.node)
+}
+
+/*
+nearestKeys returns the keys of the nearest nodes of a given kind sorted by
+distance (nearest first). If a list of candidate keys is given then only these
+nodes are considered and the distances are calculated exactly. Otherwise the
+vector index of the partition is queried. The distances of all returned nodes
+are stored in the runtime provider.
+*/
+func (rt *nearestRuntime) nearestKeys(kind string, candidates []string) ([]string, error) {
+	var keys []string
+	var dists []float32
+
+	if candidates == nil {
+
+		vq, err := rt.rtp.gm.VectorQuery(rt.rtp.part, kind, rt.attr)
+		if err != nil {
+			return nil, err
+		}
+
+		if vq != nil {
+			if keys, dists, err = vq.Nearest(rt.vector, rt.k); err != nil {
+				return nil, rt.rtp.newRuntimeError(ErrInvalidConstruct, err.Error(), rt.node)
+			}
+		}
+
+	} else {
+
+		distMap := make(map[string]float32)
+
+		for _, key := range candidates {
+
+			node, err := rt.rtp.gm.FetchNodePart(rt.rtp.part, key, kind, []string{rt.attr})
+			if err != nil {
+				return nil, err
+			} else if node == nil {
+				continue
+			}
+
+			vec, ok := node.Attr(rt.attr).([]float32)
+			if !ok {
+				continue
+			} else if len(vec) != len(rt.vector) {
+				return nil, rt.rtp.newRuntimeError(ErrInvalidConstruct,
+					fmt.Sprintf("Vector has %v dimensions - attribute %v of node %v has %v dimensions",
+						len(rt.vector), rt.attr, key, len(vec)), rt.node)
+			}
+
+			if _, ok := distMap[key]; !ok {
+				distMap[key] = util.VectorDistance(rt.vector, vec)
+				keys = append(keys, key)
+			}
+		}
+
+		sort.SliceStable(keys, func(i, j int) bool {
+			return distMap[keys[i]] < distMap[keys[j]]
+		})
+
+		if len(keys) > rt.k {
+			keys = keys[:rt.k]
+		}
+
+		dists = make([]float32, len(keys))
+		for i, key := range keys {
+			dists[i] = distMap[key]
+		}
+	}
+
+	rt.rtp.distances = make(map[string]float32)
+
+	for i, key := range keys {
+		rt.rtp.distances[key] = dists[i]
+	}
+
+	return keys, nil
+}
+
+/*
+applyNearest replaces the start keys of the runtime provider with the keys of
+the nearest nodes. If allNodes is set then all nodes of the start kind are
+considered otherwise only the current start keys.
+*/
+func (p *eqlRuntimeProvider) applyNearest(kind string, allNodes bool) error {
+	var candidates []string
+
+	if !allNodes {
+
+		candidates = []string{}
+
+		for p.nextStartKey != nil {
+
+			key, err := p.nextStartKey()
+			if err != nil {
+				return err
+			} else if key == "" {
+				break
+			}
+
+			candidates = append(candidates, key)
+		}
+	}
+
+	nrt := p.nearest.Runtime.(*nearestRuntime)
+
+	keys, err := nrt.nearestKeys(kind, candidates)
+	if err != nil {
+		return err
+	}
+
+	if allNodes {
+		p.plan = fmt.Sprintf("vector index lookup of the nearest %v %v nodes by %v", nrt.k, kind, nrt.attr)
+	} else {
+		p.plan = fmt.Sprintf("%v - nearest %v by %v", p.plan, nrt.k, nrt.attr)
+	}
+
+	// Iterate over the nearest nodes starting with the nearest node
+
+	keyPtr := 0
+
+	p.nextStartKey = func() (string, error) {
+		if keyPtr < len(keys) {
+			keyPtr++
+			return keys[keyPtr-1], nil
+		}
+
+		return "", nil
+	}
+
+	return nil
+}
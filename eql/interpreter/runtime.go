@@ -23,7 +23,7 @@ resetting state (used for testing)
 */
 var allowMultiEval = false
 
-// Special flags which can be set by with statements
+// Special flags which can be set by with statements and group by clauses
 
 type withFlags struct {
 	ordering     []byte // Result ordering
@@ -31,6 +31,7 @@ type withFlags struct {
 	notnullCol   []int  // Columns which must not be null
 	uniqueCol    []int  // Columns which will only contain unique values
 	uniqueColCnt []bool // Flag if unique values should be counted
+	groupCol     []int  // Columns which are used to group rows
 }
 
 const (
@@ -57,16 +58,23 @@ type eqlRuntimeProvider struct {
 	ni         NodeInfo       // NodeInfo to use for formatting
 	groupScope string         // Group scope for query
 
+	params map[string]interface{} // Query parameters which can be referenced with $<name>
+
 	allowNilTraversal bool       // Flag if empty traversals should be included in the result
 	withFlags         *withFlags // Special flags which can be set by with statements
 
 	primaryKind  string                 // Primary node kind
 	nextStartKey func() (string, error) // Function to get the next start key
+	plan         string                 // Description of how the start keys are determined
 
 	traversals []*parser.ASTNode // Array of all top level query traversals
 	where      *parser.ASTNode   // First where clause
+	nearest    *parser.ASTNode   // Nearest clause node
 	show       *parser.ASTNode   // Show clause node
 
+	distances map[string]float32 // Vector distances of the start nodes (nearest clause)
+	profile   *queryProfile      // Statistics of the query (only if the query is profiled)
+
 	specs      []string            // Flat list of traversals of this query
 	attrsNodes []map[string]string // Attributes for nodes to query on each traversal
 	attrsEdges []map[string]string // Attributes for nodes to query on each traversal
@@ -82,6 +90,21 @@ type eqlRuntimeProvider struct {
 	_attrsEdgesFetch [][]string // Internal copy of attrsEdges better suited for fetchPart calls
 }
 
+/*
+SetParams sets the query parameters which can be referenced with $<name>.
+*/
+func (p *eqlRuntimeProvider) SetParams(params map[string]interface{}) {
+	p.params = params
+}
+
+/*
+Plan returns a description of how the start nodes of the last validated query
+are determined (e.g. a scan of all nodes or an index lookup).
+*/
+func (p *eqlRuntimeProvider) Plan() string {
+	return p.plan
+}
+
 /*
 Initialise and validate data structures.
 */
@@ -95,14 +118,17 @@ func (p *eqlRuntimeProvider) init(startKind string,
 	// Clear any with flags
 
 	p.withFlags = &withFlags{make([]byte, 0), make([]int, 0), make([]int, 0),
-		make([]int, 0), make([]bool, 0)}
+		make([]int, 0), make([]bool, 0), make([]int, 0)}
 
 	// Reinitialise datastructures
 
 	p.groupScope = ""
 	p.traversals = make([]*parser.ASTNode, 0)
 	p.where = nil
+	p.nearest = nil
 	p.show = nil
+	p.plan = ""
+	p.distances = nil
 
 	p.specs = make([]string, 0)
 	p.attrsNodes = make([]map[string]string, 0)
@@ -125,7 +151,7 @@ func (p *eqlRuntimeProvider) init(startKind string,
 
 	// With clause is interpreted straight after finishing the columns
 
-	var withChild *parser.ASTNode
+	var withChild, groupChild *parser.ASTNode
 
 	// Go through the children, check if they are valid and initialise them
 
@@ -148,6 +174,21 @@ func (p *eqlRuntimeProvider) init(startKind string,
 
 			p.where = child
 
+		} else if child.Name == parser.NodeNEAREST {
+
+			// Check if the show clause or some traversals are already populated
+
+			if p.show != nil || len(p.traversals) > 0 {
+				return p.newRuntimeError(ErrInvalidConstruct,
+					"nearest clause must be before show clause and traversals", child)
+			}
+
+			if err := child.Runtime.Validate(); err != nil {
+				return err
+			}
+
+			p.nearest = child
+
 		} else if child.Name == parser.NodeTRAVERSE {
 
 			// Check if show clause or where clause is already populated
@@ -189,6 +230,10 @@ func (p *eqlRuntimeProvider) init(startKind string,
 				return p.newRuntimeError(ErrUnknownNodeKind, pk, child.Children[0])
 			}
 
+		} else if child.Name == parser.NodeGROUPBY {
+
+			groupChild = child
+
 		} else if child.Name == parser.NodeWITH {
 
 			withChild = child
@@ -206,7 +251,13 @@ func (p *eqlRuntimeProvider) init(startKind string,
 		return err
 	}
 
-	// Interpret with clause straight after populating the columns
+	// Interpret group by and with clause straight after populating the columns
+
+	if groupChild != nil {
+		if err := p.initGroupBy(groupChild, nodeKindPos, edgeKindPos); err != nil {
+			return err
+		}
+	}
 
 	if withChild != nil {
 		if err := p.initWithFlags(withChild, nodeKindPos, edgeKindPos); err != nil {
@@ -222,78 +273,104 @@ func (p *eqlRuntimeProvider) init(startKind string,
 }
 
 /*
-initWithFlags populates the withFlags datastructure. It is assumed that the
-columns have been populated before calling this function.
+findColumn finds the column for a given column data specification of a with
+term or group by clause. Columns of aggregate functions can only be found with
+a function specification (e.g. 1:func:sum). It is assumed that the columns
+have been populated before calling this function.
 */
-func (p *eqlRuntimeProvider) initWithFlags(withNode *parser.ASTNode,
-	nodeKindPos map[string][]int, edgeKindPos map[string][]int) error {
-
-	// Helper function to find a specified column
+func (p *eqlRuntimeProvider) findColumn(colData string, clause string, node *parser.ASTNode,
+	nodeKindPos map[string][]int, edgeKindPos map[string][]int) (int, error) {
 
-	findColumn := func(colData string, node *parser.ASTNode) (int, error) {
+	col := -1
+	colDataSplit := strings.SplitN(colData, ":", 3)
 
-		col := -1
-		colDataSplit := strings.SplitN(colData, ":", 3)
+	isAggregate := func(i int) bool {
+		_, ok := p.colFunc[i].(FuncAggregate)
+		return ok
+	}
 
-		switch len(colDataSplit) {
-		case 1:
+	switch len(colDataSplit) {
+	case 1:
 
-			// Find the first column which displays the given attribute
+		// Find the first column which displays the given attribute
 
-			for i, cd := range p.colData {
-				cds := strings.SplitN(cd, ":", 3)
-				if cds[2] == colDataSplit[0] {
-					col = i
-				}
+		for i, cd := range p.colData {
+			cds := strings.SplitN(cd, ":", 3)
+			if cds[2] == colDataSplit[0] && !isAggregate(i) {
+				col = i
 			}
+		}
 
-		case 2:
+	case 2:
 
-			// Search for first kind / attribute occurrence
+		// Search for first kind / attribute occurrence
 
-			kind := colDataSplit[0]
-			attr := colDataSplit[1]
+		kind := colDataSplit[0]
+		attr := colDataSplit[1]
 
-			searchColData := func(pos int, t string) {
-				cstr := fmt.Sprint(pos+1, ":", t, ":", attr)
+		searchColData := func(pos int, t string) {
+			cstr := fmt.Sprint(pos+1, ":", t, ":", attr)
 
-				for i, c := range p.colData {
-					if c == cstr {
-						col = i
-					}
+			for i, c := range p.colData {
+				if c == cstr && !isAggregate(i) {
+					col = i
 				}
 			}
+		}
 
-			if poslist, ok := nodeKindPos[kind]; ok {
-				searchColData(poslist[0], "n")
+		if poslist, ok := nodeKindPos[kind]; ok {
+			searchColData(poslist[0], "n")
 
-			} else if poslist, ok := edgeKindPos[kind]; ok {
-				searchColData(poslist[0], "e")
+		} else if poslist, ok := edgeKindPos[kind]; ok {
+			searchColData(poslist[0], "e")
 
-			} else {
-				return -1, p.newRuntimeError(ErrInvalidConstruct,
-					"Cannot determine column for with term: "+colData, node)
-			}
+		} else {
+			return -1, p.newRuntimeError(ErrInvalidConstruct,
+				"Cannot determine column for "+clause+" term: "+colData, node)
+		}
+
+	case 3:
 
-		case 3:
+		if colDataSplit[1] == "func" {
 
-			// Search for exact specification
+			// Search for a function column
+
+			fname := strings.TrimSuffix(colDataSplit[2], "()")
 
 			for i, c := range p.colData {
-				if c == colData {
+				if p.colFunc[i] != nil && p.colFunc[i].name() == fname &&
+					strings.SplitN(c, ":", 2)[0] == colDataSplit[0] {
 					col = i
 				}
 			}
+
+			break
 		}
 
-		if col == -1 {
-			return -1, p.newRuntimeError(ErrInvalidConstruct,
-				"Cannot determine column for with term: "+colData, node)
+		// Search for exact specification
+
+		for i, c := range p.colData {
+			if c == colData && !isAggregate(i) {
+				col = i
+			}
 		}
+	}
 
-		return col, nil
+	if col == -1 {
+		return -1, p.newRuntimeError(ErrInvalidConstruct,
+			"Cannot determine column for "+clause+" term: "+colData, node)
 	}
 
+	return col, nil
+}
+
+/*
+initWithFlags populates the withFlags datastructure. It is assumed that the
+columns have been populated before calling this function.
+*/
+func (p *eqlRuntimeProvider) initWithFlags(withNode *parser.ASTNode,
+	nodeKindPos map[string][]int, edgeKindPos map[string][]int) error {
+
 	// Go through all children and initialise the withFlags data structure
 
 	for _, child := range withNode.Children {
@@ -308,7 +385,7 @@ func (p *eqlRuntimeProvider) initWithFlags(withNode *parser.ASTNode,
 
 				if child.Name == parser.NodeISNOTNULL || child.Name == parser.NodeUNIQUE || child.Name == parser.NodeUNIQUECOUNT {
 
-					c, err := findColumn(child.Children[0].Token.Val, child)
+					c, err := p.findColumn(child.Children[0].Token.Val, "with", child, nodeKindPos, edgeKindPos)
 					if err != nil {
 						return err
 					}
@@ -333,7 +410,7 @@ func (p *eqlRuntimeProvider) initWithFlags(withNode *parser.ASTNode,
 
 				if child.Name == parser.NodeASCENDING || child.Name == parser.NodeDESCENDING {
 
-					c, err := findColumn(child.Children[0].Token.Val, child)
+					c, err := p.findColumn(child.Children[0].Token.Val, "with", child, nodeKindPos, edgeKindPos)
 					if err != nil {
 						return err
 					}
@@ -359,6 +436,31 @@ func (p *eqlRuntimeProvider) initWithFlags(withNode *parser.ASTNode,
 	return nil
 }
 
+/*
+initGroupBy populates the group columns of the withFlags datastructure. It is
+assumed that the columns have been populated before calling this function.
+*/
+func (p *eqlRuntimeProvider) initGroupBy(groupNode *parser.ASTNode,
+	nodeKindPos map[string][]int, edgeKindPos map[string][]int) error {
+
+	for _, child := range groupNode.Children {
+
+		c, err := p.findColumn(child.Token.Val, "group by", child, nodeKindPos, edgeKindPos)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := p.colFunc[c].(FuncAggregate); ok {
+			return p.newRuntimeError(ErrInvalidConstruct,
+				"Cannot group by aggregate function: "+child.Token.Val, child)
+		}
+
+		p.withFlags.groupCol = append(p.withFlags.groupCol, c)
+	}
+
+	return nil
+}
+
 /*
 initCols populates the column related attributes. This function assumes that
 specs is filled with all necessary traversals.
@@ -640,6 +742,14 @@ func (p *eqlRuntimeProvider) next() (bool, error) {
 		childRuntime := child.Runtime.(*traversalRuntime)
 		if childRuntime.hasMoreNodes() {
 			_, err := childRuntime.Eval()
+
+			if err == ErrEmptyTraversal {
+
+				// Advance if the remaining nodes only lead to empty traversals
+
+				return p.next()
+			}
+
 			return err == nil, err
 		}
 	}
@@ -654,8 +764,18 @@ func (p *eqlRuntimeProvider) next() (bool, error) {
 	// Fetch node - always require the key attribute
 	// to make sure we get a node back if it exists
 
-	node, err := p.gm.FetchNodePart(p.part, startKey, p.specs[0],
-		append(p._attrsNodesFetch[0], "key"))
+	var node data.Node
+
+	err = p.fetchNodes(0, func() (int, error) {
+		node, err = p.gm.FetchNodePart(p.part, startKey, p.specs[0],
+			append(p._attrsNodesFetch[0], "key"))
+
+		if node == nil {
+			return 0, err
+		}
+
+		return 1, err
+	})
 
 	if err != nil || node == nil {
 		return false, err
@@ -666,7 +786,7 @@ func (p *eqlRuntimeProvider) next() (bool, error) {
 	addNode := true
 
 	if p.where != nil {
-		res, err := p.where.Runtime.(CondRuntime).CondEval(node, nil)
+		res, err := p.condEval(0, p.where, node, nil)
 		if err != nil {
 			return false, err
 		}
@@ -745,6 +865,9 @@ var generalProviderMap = map[string]generalInst{
 	parser.NodeFUNC:     valueRuntimeInst,
 	parser.NodeTRAVERSE: traversalRuntimeInst,
 	parser.NodeWHERE:    whereRuntimeInst,
+	parser.NodeNEAREST:  nearestRuntimeInst,
+	parser.NodeEXPLAIN:  explainRuntimeInst,
+	parser.NodePROFILE:  explainRuntimeInst,
 
 	// Condition components
 	// ====================
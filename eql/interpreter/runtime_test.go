@@ -255,25 +255,25 @@ func TestErrors(t *testing.T) {
 
 	msm = mgs.StorageManager("main"+"mynewnode"+graph.StorageSuffixNodes, false).(*storage.MemoryStorageManager)
 
-	msm.AccessMap[5] = storage.AccessCacheAndFetchError // Node 3 attribute lookup
+	msm.AccessMap[6] = storage.AccessCacheAndFetchError // Node 3 attribute lookup
 
 	if err := runSearch("get mynode traverse :::mynewnode traverse :::mynewnode end end", "", rt); err.Error() !=
-		"GraphError: Could not read graph information (Slot not found (mystorage/mainmynewnode.nodes - Location:5))" {
+		"GraphError: Could not read graph information (Slot not found (mystorage/mainmynewnode.nodes - Location:6))" {
 		t.Error(err)
 		return
 	}
 
-	delete(msm.AccessMap, 5)
+	delete(msm.AccessMap, 6)
 
-	msm.AccessMap[11] = storage.AccessCacheAndFetchError // Traversal spec error
+	msm.AccessMap[12] = storage.AccessCacheAndFetchError // Traversal spec error
 
 	if err := runSearch("get mynode traverse :::mynewnode traverse :::mynewnode end end", "", rt); err.Error() !=
-		"GraphError: Could not read graph information (Slot not found (mystorage/mainmynewnode.nodes - Location:11))" {
+		"GraphError: Could not read graph information (Slot not found (mystorage/mainmynewnode.nodes - Location:12))" {
 		t.Error(err)
 		return
 	}
 
-	delete(msm.AccessMap, 11)
+	delete(msm.AccessMap, 12)
 
 	msm = mgs.StorageManager("main"+"myedge"+graph.StorageSuffixEdges, false).(*storage.MemoryStorageManager)
 
@@ -575,6 +575,109 @@ Data: 3:n:key, 2:n:key, 1:n:key
 	}
 }
 
+func TestVariableDepthTraversal(t *testing.T) {
+	gm := dependencyGraph()
+	rt := NewLookupRuntimeProvider("test", "main", gm, NewDefaultNodeInfo(gm))
+
+	spec := "Dependent:DependsOn:Dependency:Pkg"
+
+	// Every node is only reported once with its shortest path - the cycle
+	// back to the start node is not followed
+
+	if err := runSearch("lookup Pkg \"a\" traverse "+spec+" depth 1..100 end show 2:n:key, @path(2), @path(2, edges)", `
+Labels: Key, Path, Path
+Format: auto, auto, auto
+Data: 2:n:key, 2:func:path(), 2:func:path()
+b, [a b], [a-b]
+c, [a c], [a-c]
+d, [a c d], [a-c c-d]
+e, [a c d e], [a-c c-d d-e]
+f, [a c f], [a-c c-f]
+`[1:], rt); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := runSearch("lookup Pkg \"a\" traverse "+spec+" depth 2..3 where key != d end show 2:n:key, @path(2)", `
+Labels: Key, Path
+Format: auto, auto
+Data: 2:n:key, 2:func:path()
+e, [a c d e]
+f, [a c f]
+`[1:], rt); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Shortest path between two nodes
+
+	if err := runSearch("lookup Pkg \"b\" traverse "+spec+" depth 1..10 to a end show 2:n:key, @path(2), @path(1)", `
+Labels: Key, Path, Path
+Format: auto, auto, auto
+Data: 2:n:key, 2:func:path(), 1:func:path()
+a, [b c d e a], [b]
+`[1:], rt); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := runSearch("lookup Pkg \"b\" traverse "+spec+" depth 1..2 to a end show 2:n:key", `
+Labels: Key
+Format: auto
+Data: 2:n:key
+`[1:], rt); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Paths of nested traversals start at the start node - f has no
+	// dependencies and is skipped
+
+	if err := runSearch("lookup Pkg \"a\" traverse "+spec+" depth 2 traverse "+spec+" end end show 3:n:key, @path(3)", `
+Labels: Key, Path
+Format: auto, auto
+Data: 3:n:key, 3:func:path()
+e, [a c d e]
+`[1:], rt); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Rows of nested traversals which lead to an empty traversal are skipped
+
+	if err := runSearch("lookup Pkg \"a\" traverse "+spec+" traverse "+spec+" traverse "+spec+" end end end show 4:n:key", `
+Labels: Key
+Format: auto
+Data: 4:n:key
+d
+e
+f
+`[1:], rt); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Test error cases
+
+	for query, expected := range map[string]string{
+		"lookup Pkg \"a\" traverse ::: depth 0..2": "EQL error in test: Invalid construct (Invalid traversal depth " +
+			"(must be <min>..<max> with 0 < min <= max): 0..2) (Line:1 Pos:29)",
+		"lookup Pkg \"a\" traverse ::: depth 3..1": "EQL error in test: Invalid construct (Invalid traversal depth " +
+			"(must be <min>..<max> with 0 < min <= max): 3..1) (Line:1 Pos:29)",
+		"lookup Pkg \"a\" traverse ::: depth x": "EQL error in test: Invalid construct (Invalid traversal depth " +
+			"(must be <min>..<max> with 0 < min <= max): x) (Line:1 Pos:29)",
+		"lookup Pkg \"a\" traverse ::: end show @path(2, x)": "EQL error in test: Invalid construct " +
+			"(Path function can only show nodes or edges not: x) (Line:1 Pos:38)",
+		"lookup Pkg \"a\" show @path()": "EQL error in test: Invalid construct " +
+			"(Path function requires 1 or 2 parameters: traversal step, nodes or edges) (Line:1 Pos:21)",
+	} {
+		if err := runSearch(query, "", rt); err == nil || err.Error() != expected {
+			t.Error("Unexpected result for", query, ":", err)
+			return
+		}
+	}
+}
+
 func simpleGraph() (*graph.Manager, *graphstorage.MemoryGraphStorage) {
 
 	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
@@ -738,3 +841,37 @@ func dataNodes() *graph.Manager {
 
 	return gm
 }
+
+func dependencyGraph() *graph.Manager {
+
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := graph.NewGraphManager(mgs)
+
+	for _, key := range []string{"a", "b", "c", "d", "e", "f"} {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "Pkg")
+		gm.StoreNode("main", node)
+	}
+
+	for _, dep := range []string{"a-b", "b-c", "c-d", "d-e", "e-a", "a-c", "c-f"} {
+		edge := data.NewGraphEdge()
+
+		edge.SetAttr("key", dep)
+		edge.SetAttr("kind", "DependsOn")
+
+		edge.SetAttr(data.EdgeEnd1Key, dep[:1])
+		edge.SetAttr(data.EdgeEnd1Kind, "Pkg")
+		edge.SetAttr(data.EdgeEnd1Role, "Dependent")
+		edge.SetAttr(data.EdgeEnd1Cascading, false)
+
+		edge.SetAttr(data.EdgeEnd2Key, dep[2:])
+		edge.SetAttr(data.EdgeEnd2Kind, "Pkg")
+		edge.SetAttr(data.EdgeEnd2Role, "Dependency")
+		edge.SetAttr(data.EdgeEnd2Cascading, false)
+
+		gm.StoreEdge("main", edge)
+	}
+
+	return gm
+}
@@ -53,6 +53,7 @@ var (
 	ErrNotARegex        = errors.New("Value of operand is not a valid regex")
 	ErrNotANumber       = errors.New("Value of operand is not a number")
 	ErrNotAList         = errors.New("Value of operand is not a list")
+	ErrNotAVector       = errors.New("Value of operand is not a vector")
 	ErrInvalidConstruct = errors.New("Invalid construct")
 	ErrUnknownNodeKind  = errors.New("Unknown node kind")
 	ErrInvalidSpec      = errors.New("Invalid traversal spec")
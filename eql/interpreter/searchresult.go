@@ -262,6 +262,12 @@ func (sr *SearchResult) finish() {
 		}
 	}
 
+	// Apply grouping
+
+	if len(sr.withFlags.groupCol) > 0 || sr.hasAggregates() {
+		sr.group()
+	}
+
 	// Apply ordering
 
 	for i, ordering := range sr.withFlags.ordering {
@@ -271,6 +277,75 @@ func (sr *SearchResult) finish() {
 
 }
 
+/*
+hasAggregates checks if the result contains an aggregate function column.
+*/
+func (sr *SearchResult) hasAggregates() bool {
+	for _, cf := range sr.colFunc {
+		if _, ok := cf.(FuncAggregate); ok {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+group collapses all rows which have the same values in the group columns.
+Aggregate function columns combine the values of all rows of a group. All
+other columns show the value of the first row of a group. All rows form a
+single group if there are no group columns.
+*/
+func (sr *SearchResult) group() {
+	var keys []string
+
+	groups := make(map[string][]int)
+
+	for i, row := range sr.Data {
+		groupVals := make([]string, 0, len(sr.withFlags.groupCol))
+
+		for _, g := range sr.withFlags.groupCol {
+			groupVals = append(groupVals, fmt.Sprint(row[g]))
+		}
+
+		key := fmt.Sprintf("%q", groupVals)
+
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		groups[key] = append(groups[key], i)
+	}
+
+	data := make([][]interface{}, 0, len(keys))
+	source := make([][]string, 0, len(keys))
+
+	for _, key := range keys {
+		rows := groups[key]
+
+		row := append([]interface{}{}, sr.Data[rows[0]]...)
+		src := append([]string{}, sr.Source[rows[0]]...)
+
+		for i, cf := range sr.colFunc {
+			if af, ok := cf.(FuncAggregate); ok {
+				values := make([]interface{}, 0, len(rows))
+
+				for _, r := range rows {
+					values = append(values, sr.Data[r][i])
+				}
+
+				row[i] = af.aggregate(values)
+				src[i] = ""
+			}
+		}
+
+		data = append(data, row)
+		source = append(source, src)
+	}
+
+	sr.Data = data
+	sr.Source = source
+}
+
 /*
 Header returns all column headers.
 */
@@ -411,22 +486,11 @@ func (c SearchResultRowComparator) Less(i, j int) bool {
 	c1 := c.Data[i][c.Column]
 	c2 := c.Data[j][c.Column]
 
-	num1, err := strconv.ParseFloat(fmt.Sprint(c1), 64)
-	if err == nil {
-		num2, err := strconv.ParseFloat(fmt.Sprint(c2), 64)
-		if err == nil {
-			if c.Ascening {
-				return num1 < num2
-			}
-			return num1 > num2
-		}
-	}
-
 	if c.Ascening {
-		return fmt.Sprintf("%v", c1) < fmt.Sprintf("%v", c2)
+		return lessValue(c1, c2)
 	}
 
-	return fmt.Sprintf("%v", c1) > fmt.Sprintf("%v", c2)
+	return lessValue(c2, c1)
 }
 
 func (c SearchResultRowComparator) Swap(i, j int) {
@@ -434,6 +498,23 @@ func (c SearchResultRowComparator) Swap(i, j int) {
 	c.Source[i], c.Source[j] = c.Source[j], c.Source[i]
 }
 
+/*
+lessValue compares two result values. Values are compared as numbers if
+possible otherwise as strings.
+*/
+func lessValue(c1 interface{}, c2 interface{}) bool {
+
+	num1, err := strconv.ParseFloat(fmt.Sprint(c1), 64)
+	if err == nil {
+		num2, err := strconv.ParseFloat(fmt.Sprint(c2), 64)
+		if err == nil {
+			return num1 < num2
+		}
+	}
+
+	return fmt.Sprintf("%v", c1) < fmt.Sprintf("%v", c2)
+}
+
 // Testing functions
 // =================
 
@@ -8,6 +8,8 @@
 package interpreter
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/Fisch-Labs/FishDB/eql/parser"
@@ -21,21 +23,35 @@ type traversalRuntime struct {
 	rtp  *eqlRuntimeProvider
 	node *parser.ASTNode
 
-	where *parser.ASTNode // Traversal where clause
+	where  *parser.ASTNode   // Traversal where clause
+	parent *traversalRuntime // Parent traversal (nil if the source is a start node)
 
-	sourceNode data.Node   // Source node for traversal - should be injected by the parent
-	spec       string      // Spec for this traversal
-	specIndex  int         // Index of this traversal in the traversals array
-	nodes      []data.Node // Nodes of the last traversal result
-	edges      []data.Edge // Edges of the last traversal result
-	curptr     int         // Pointer to the next node in the last traversal result
+	minDepth int    // Minimal number of traversal steps (only for variable depth traversals)
+	maxDepth int    // Maximal number of traversal steps (0 for a single step)
+	target   string // Key of the target node (only for shortest path traversals)
+
+	sourceNode data.Node        // Source node for traversal - should be injected by the parent
+	spec       string           // Spec for this traversal
+	specIndex  int              // Index of this traversal in the traversals array
+	nodes      []data.Node      // Nodes of the last traversal result
+	edges      []data.Edge      // Edges of the last traversal result
+	paths      []*traversalPath // Paths from the source node of the last traversal result
+	curptr     int              // Pointer to the next node in the last traversal result
+}
+
+/*
+traversalPath is a path of traversal steps.
+*/
+type traversalPath struct {
+	nodes []string // Keys of the nodes on the path
+	edges []string // Keys of the edges on the path
 }
 
 /*
 traversalRuntimeInst returns a new runtime component instance.
 */
 func traversalRuntimeInst(rtp *eqlRuntimeProvider, node *parser.ASTNode) parser.Runtime {
-	return &traversalRuntime{rtp, node, nil, nil, "", -1, nil, nil, 0}
+	return &traversalRuntime{rtp, node, nil, nil, 0, 0, "", nil, "", -1, nil, nil, nil, 0}
 }
 
 /*
@@ -56,6 +72,9 @@ func (rt *traversalRuntime) Validate() error {
 	rt.spec = spec
 	rt.specIndex = len(rt.rtp.specs)
 	rt.where = nil
+	rt.minDepth = 0
+	rt.maxDepth = 0
+	rt.target = ""
 	rt.rtp.specs = append(rt.rtp.specs, spec)
 	rt.rtp.attrsNodes = append(rt.rtp.attrsNodes, make(map[string]string))
 	rt.rtp.attrsEdges = append(rt.rtp.attrsEdges, make(map[string]string))
@@ -66,10 +85,18 @@ func (rt *traversalRuntime) Validate() error {
 
 		if child.Name == parser.NodeTRAVERSE {
 
+			child.Runtime.(*traversalRuntime).parent = rt
+
 			if err := child.Runtime.Validate(); err != nil {
 				return err
 			}
 
+		} else if child.Name == parser.NodeDEPTH {
+
+			if err := rt.initDepth(child); err != nil {
+				return err
+			}
+
 		} else if child.Name == parser.NodeWHERE {
 
 			whereRuntime := child.Runtime.(*whereRuntime)
@@ -92,6 +119,51 @@ func (rt *traversalRuntime) Validate() error {
 	return nil
 }
 
+/*
+initDepth initialises a variable depth traversal. The depth is either given as
+a range <min>..<max> or as a single number of traversal steps.
+*/
+func (rt *traversalRuntime) initDepth(depthNode *parser.ASTNode) error {
+	var err error
+
+	depth := depthNode.Children[0].Token.Val
+	sdepth := strings.SplitN(depth, "..", 2)
+
+	if rt.minDepth, err = strconv.Atoi(sdepth[0]); err == nil {
+		rt.maxDepth, err = strconv.Atoi(sdepth[len(sdepth)-1])
+	}
+
+	if err != nil || rt.minDepth < 1 || rt.maxDepth < rt.minDepth {
+		rt.minDepth, rt.maxDepth = 0, 0
+
+		return rt.rtp.newRuntimeError(ErrInvalidConstruct,
+			"Invalid traversal depth (must be <min>..<max> with 0 < min <= max): "+depth, depthNode)
+	}
+
+	if len(depthNode.Children) > 1 {
+		rt.target = depthNode.Children[1].Token.Val
+	}
+
+	return nil
+}
+
+/*
+description returns a description of this traversal.
+*/
+func (rt *traversalRuntime) description() string {
+	desc := rt.spec
+
+	if rt.maxDepth > 0 {
+		desc = fmt.Sprintf("%v depth %v..%v", desc, rt.minDepth, rt.maxDepth)
+
+		if rt.target != "" {
+			desc = fmt.Sprintf("%v to %v", desc, rt.target)
+		}
+	}
+
+	return desc
+}
+
 /*
 hasMoreNodes returns true if this traversal runtime component can produce more
 nodes. If the result is negative then a new source node is required.
@@ -115,54 +187,72 @@ traverses it.
 func (rt *traversalRuntime) newSource(node data.Node) error {
 	var nodes []data.Node
 	var edges []data.Edge
+	var paths []*traversalPath
 
 	rt.sourceNode = node
 
 	// Do the actual traversal if we got a node
 
 	if node != nil {
-		var err error
 
-		// Do a simple traversal without getting any node data first
+		err := rt.rtp.fetchNodes(rt.specIndex, func() (int, error) {
+			var err error
 
-		nodes, edges, err = rt.rtp.gm.TraverseMulti(rt.rtp.part, rt.sourceNode.Key(),
-			rt.sourceNode.Kind(), rt.spec, false)
+			// Do a simple traversal without getting any node data first
 
-		if err != nil {
-			return err
-		}
+			if rt.maxDepth > 0 {
+				nodes, edges, paths, err = rt.traverseDepth()
+			} else {
+				nodes, edges, err = rt.rtp.gm.TraverseMulti(rt.rtp.part, rt.sourceNode.Key(),
+					rt.sourceNode.Kind(), rt.spec, false)
 
-		// Now get the attributes which are required
+				for i, node := range nodes {
+					paths = append(paths, &traversalPath{[]string{node.Key()}, []string{edges[i].Key()}})
+				}
+			}
+
+			if err != nil {
+				return 0, err
+			}
 
-		for _, node := range nodes {
-			attrs := rt.rtp._attrsNodesFetch[rt.specIndex]
+			// Now get the attributes which are required
 
-			if len(attrs) > 0 {
-				n, err := rt.rtp.gm.FetchNodePart(rt.rtp.part, node.Key(), node.Kind(), attrs)
+			for _, node := range nodes {
+				attrs := rt.rtp._attrsNodesFetch[rt.specIndex]
 
-				if err != nil {
-					return err
-				} else if n != nil {
-					for _, attr := range attrs {
-						node.SetAttr(attr, n.Attr(attr))
+				if len(attrs) > 0 {
+					n, err := rt.rtp.gm.FetchNodePart(rt.rtp.part, node.Key(), node.Kind(), attrs)
+
+					if err != nil {
+						return 0, err
+					} else if n != nil {
+						for _, attr := range attrs {
+							node.SetAttr(attr, n.Attr(attr))
+						}
 					}
 				}
 			}
-		}
-		for _, edge := range edges {
-			attrs := rt.rtp._attrsEdgesFetch[rt.specIndex]
-
-			if len(attrs) > 0 {
-				e, err := rt.rtp.gm.FetchEdgePart(rt.rtp.part, edge.Key(), edge.Kind(), attrs)
-
-				if err != nil {
-					return err
-				} else if e != nil {
-					for _, attr := range attrs {
-						edge.SetAttr(attr, e.Attr(attr))
+			for _, edge := range edges {
+				attrs := rt.rtp._attrsEdgesFetch[rt.specIndex]
+
+				if len(attrs) > 0 {
+					e, err := rt.rtp.gm.FetchEdgePart(rt.rtp.part, edge.Key(), edge.Kind(), attrs)
+
+					if err != nil {
+						return 0, err
+					} else if e != nil {
+						for _, attr := range attrs {
+							edge.SetAttr(attr, e.Attr(attr))
+						}
 					}
 				}
 			}
+
+			return len(nodes), nil
+		})
+
+		if err != nil {
+			return err
 		}
 	}
 
@@ -172,11 +262,12 @@ func (rt *traversalRuntime) newSource(node data.Node) error {
 
 		fNodes := make([]data.Node, 0, len(nodes))
 		fEdges := make([]data.Edge, 0, len(edges))
+		fPaths := make([]*traversalPath, 0, len(paths))
 
 		for i, node := range nodes {
 			edge := edges[i]
 
-			res, err := rt.where.Runtime.(CondRuntime).CondEval(node, edge)
+			res, err := rt.rtp.condEval(rt.specIndex, rt.where, node, edge)
 			if err != nil {
 				return err
 			}
@@ -184,15 +275,18 @@ func (rt *traversalRuntime) newSource(node data.Node) error {
 			if res.(bool) {
 				fNodes = append(fNodes, node)
 				fEdges = append(fEdges, edge)
+				fPaths = append(fPaths, paths[i])
 			}
 		}
 
 		nodes = fNodes
 		edges = fEdges
+		paths = fPaths
 	}
 
 	rt.nodes = nodes
 	rt.edges = edges
+	rt.paths = paths
 	rt.curptr = 0
 
 	// Check if there are no nodes to display and return an error if
@@ -209,6 +303,99 @@ func (rt *traversalRuntime) newSource(node data.Node) error {
 	return err
 }
 
+/*
+traverseDepth traverses from the source node in a breadth-first manner up to
+the maximal depth. Every node is only visited once - cycles are not followed.
+Returns all nodes which have a shortest path from the source node within the
+depth range with the last edge and the path which leads to them. Only the
+target node is returned if a target was given (the traversal stops once it
+has been found).
+*/
+func (rt *traversalRuntime) traverseDepth() ([]data.Node, []data.Edge, []*traversalPath, error) {
+	var nodes []data.Node
+	var edges []data.Edge
+	var paths []*traversalPath
+
+	type step struct {
+		node data.Node
+		path *traversalPath
+	}
+
+	visited := map[string]bool{rt.sourceNode.Kind() + ":" + rt.sourceNode.Key(): true}
+	frontier := []step{{rt.sourceNode, &traversalPath{}}}
+
+	for depth := 1; depth <= rt.maxDepth && len(frontier) > 0; depth++ {
+		var next []step
+
+		for _, s := range frontier {
+
+			tnodes, tedges, err := rt.rtp.gm.TraverseMulti(rt.rtp.part, s.node.Key(),
+				s.node.Kind(), rt.spec, false)
+
+			if err != nil {
+				return nil, nil, nil, err
+			}
+
+			for i, tnode := range tnodes {
+				id := tnode.Kind() + ":" + tnode.Key()
+
+				if visited[id] {
+					continue
+				}
+
+				visited[id] = true
+
+				path := &traversalPath{
+					append(append([]string{}, s.path.nodes...), tnode.Key()),
+					append(append([]string{}, s.path.edges...), tedges[i].Key()),
+				}
+
+				if depth >= rt.minDepth && (rt.target == "" || rt.target == tnode.Key()) {
+					nodes = append(nodes, tnode)
+					edges = append(edges, tedges[i])
+					paths = append(paths, path)
+
+					if rt.target != "" {
+						return nodes, edges, paths, nil
+					}
+				}
+
+				next = append(next, step{tnode, path})
+			}
+		}
+
+		frontier = next
+	}
+
+	return nodes, edges, paths, nil
+}
+
+/*
+path returns the path from the start node of the query to the node of this
+traversal in the current row. Returns nil if there is no node in the current
+row.
+*/
+func (rt *traversalRuntime) path() *traversalPath {
+
+	if rt.curptr < 1 || rt.curptr > len(rt.paths) || len(rt.rtp.rowNode) <= rt.specIndex ||
+		rt.rtp.rowNode[rt.specIndex] == nil {
+		return nil
+	}
+
+	var ret *traversalPath
+
+	if rt.parent == nil {
+		ret = &traversalPath{[]string{rt.sourceNode.Key()}, nil}
+	} else if ret = rt.parent.path(); ret == nil {
+		return nil
+	}
+
+	own := rt.paths[rt.curptr-1]
+
+	return &traversalPath{append(append([]string{}, ret.nodes...), own.nodes...),
+		append(append([]string{}, ret.edges...), own.edges...)}
+}
+
 /*
 Eval evaluate this runtime component.
 */
@@ -254,7 +441,14 @@ func (rt *traversalRuntime) Eval() (interface{}, error) {
 		if child.Name == parser.NodeTRAVERSE {
 			childRuntime := child.Runtime.(*traversalRuntime)
 
-			if err := childRuntime.newSource(rt.rtp.rowNode[rt.specIndex]); err != nil {
+			if err := childRuntime.newSource(rt.rtp.rowNode[rt.specIndex]); err == ErrEmptyTraversal &&
+				rt.curptr < len(rt.nodes) {
+
+				// Continue with the next node if a deeper traversal is empty
+
+				return rt.Eval()
+
+			} else if err != nil {
 				return nil, err
 			}
 		}
@@ -262,3 +456,32 @@ func (rt *traversalRuntime) Eval() (interface{}, error) {
 
 	return nil, nil
 }
+
+/*
+traversalAt returns the traversal runtime of a given traversal index. Returns
+nil if there is no such traversal.
+*/
+func (p *eqlRuntimeProvider) traversalAt(index int) *traversalRuntime {
+	var find func(traversals []*parser.ASTNode) *traversalRuntime
+
+	find = func(traversals []*parser.ASTNode) *traversalRuntime {
+		for _, child := range traversals {
+			if child.Name != parser.NodeTRAVERSE {
+				continue
+			}
+
+			trt := child.Runtime.(*traversalRuntime)
+
+			if trt.specIndex == index {
+				return trt
+			}
+
+			if ret := find(child.Children[1:]); ret != nil {
+				return ret
+			}
+		}
+		return nil
+	}
+
+	return find(p.traversals)
+}
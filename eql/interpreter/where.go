@@ -10,11 +10,14 @@ package interpreter
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/Fisch-Labs/FishDB/eql/parser"
+	"github.com/Fisch-Labs/FishDB/graph"
 	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/util"
 )
 
 /*
@@ -378,6 +381,260 @@ func (rt *whereRuntime) CondEval(node data.Node, edge data.Edge) (interface{}, e
 	return toBool(res), err
 }
 
+/*
+indexPlan returns the keys of all nodes of a given kind which may match the
+condition of this where clause according to the index and a description of
+the index lookups. Returns nil keys if the index cannot be used for the
+condition. The following conditions are looked up:
+
+  - attr = constant (value index - numbers only if the attribute is range indexed)
+  - attr <, <=, >, >= number or date constant (attribute must be range indexed)
+  - and / or combinations of the above (and also works if only one side can be
+    looked up)
+
+The returned nodes must still be checked against the whole condition. The
+index is not used while it is rebuilt and the index of an attribute is not
+used while it is stale since it may not match the index schema.
+Note: Contains conditions are never looked up - the word index finds only
+values which contain the constant as whole words while the condition matches
+any substring.
+*/
+func (rt *whereRuntime) indexPlan(kind string) ([]string, string, error) {
+
+	if rt.rtp.gm.IndexRebuilding(rt.rtp.part, kind) {
+		return nil, "", nil
+	}
+
+	iq, err := rt.rtp.gm.NodeIndexQuery(rt.rtp.part, kind)
+	if err != nil || iq == nil {
+		return nil, "", err
+	}
+
+	return rt.condIndexPlan(rt.astNode.Children[0], iq, kind, rt.rtp.gm.IndexSchema(kind))
+}
+
+/*
+condIndexPlan returns the keys of all nodes which may match a given condition
+according to the index and a description of the lookup. Returns nil keys if
+the index cannot be used.
+*/
+func (rt *whereRuntime) condIndexPlan(astNode *parser.ASTNode, iq graph.IndexQuery,
+	kind string, schema map[string]string) ([]string, string, error) {
+
+	switch astNode.Name {
+
+	case parser.NodeAND, parser.NodeOR:
+
+		keys1, desc1, err := rt.condIndexPlan(astNode.Children[0], iq, kind, schema)
+		if err != nil {
+			return nil, "", err
+		}
+
+		keys2, desc2, err := rt.condIndexPlan(astNode.Children[1], iq, kind, schema)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if astNode.Name == parser.NodeAND {
+
+			// A conjunction can use the lookup of one side if the other
+			// side cannot be looked up
+
+			if keys1 == nil {
+				return keys2, desc2, nil
+			} else if keys2 == nil {
+				return keys1, desc1, nil
+			}
+
+			return intersectKeys(keys1, keys2), fmt.Sprintf("(%v and %v)", desc1, desc2), nil
+		}
+
+		// A disjunction requires lookups of both sides
+
+		if keys1 == nil || keys2 == nil {
+			return nil, "", nil
+		}
+
+		return unionKeys(keys1, keys2), fmt.Sprintf("(%v or %v)", desc1, desc2), nil
+
+	case parser.NodeEQ, parser.NodeLT, parser.NodeLEQ, parser.NodeGT, parser.NodeGEQ:
+
+		op := astNode.Name
+		attr, ok1 := indexAttr(astNode.Children[0])
+		val, ok2 := indexConstant(astNode.Children[1])
+
+		if !ok1 || !ok2 {
+
+			// Try the comparison the other way around (e.g. 30 < age)
+
+			attr, ok1 = indexAttr(astNode.Children[1])
+			val, ok2 = indexConstant(astNode.Children[0])
+
+			op = map[string]string{
+				parser.NodeEQ:  parser.NodeEQ,
+				parser.NodeLT:  parser.NodeGT,
+				parser.NodeLEQ: parser.NodeGEQ,
+				parser.NodeGT:  parser.NodeLT,
+				parser.NodeGEQ: parser.NodeLEQ,
+			}[op]
+		}
+
+		if !ok1 || !ok2 {
+			return nil, "", nil
+		}
+
+		// The index of the attribute may not match its index mode until
+		// the index was rebuilt
+
+		if rt.rtp.gm.IndexStale(rt.rtp.part, kind, attr) {
+			return nil, "", nil
+		}
+
+		mode, ok := schema[attr]
+		if !ok {
+			mode = schema[""]
+		}
+		if mode == "" {
+			mode = util.IndexModeFullText
+		}
+
+		return rt.lookupIndex(iq, mode, attr, op, val)
+	}
+
+	return nil, "", nil
+}
+
+/*
+lookupIndex looks up a single comparison of an attribute with a constant in
+the index. Returns nil keys if the index mode of the attribute does not support
+the comparison.
+*/
+func (rt *whereRuntime) lookupIndex(iq graph.IndexQuery, mode string, attr string,
+	op string, val string) ([]string, string, error) {
+
+	var keys []string
+	var err error
+
+	desc := fmt.Sprintf("%v %v %v", attr, op, val)
+
+	_, isNum := util.RangeValue(val)
+
+	switch {
+
+	case op == parser.NodeEQ && mode == util.IndexModeRange && isNum:
+
+		keys, err = iq.LookupRange(attr, val, val)
+		desc += " [range index]"
+
+	case op == parser.NodeEQ && mode != util.IndexModeNone:
+
+		// Numbers can be written in different ways and are compared by
+		// value - a lookup is only possible with the range index
+
+		if _, perr := strconv.ParseFloat(val, 64); perr == nil {
+			return nil, "", nil
+		}
+
+		if keys, err = iq.LookupValue(attr, val); err == nil {
+			if keys == nil {
+				keys = []string{}
+			}
+			sort.Strings(keys)
+		}
+
+		desc += " [value index]"
+
+	case op != parser.NodeEQ && mode == util.IndexModeRange && isNum:
+
+		// Bounds of the range lookup are inclusive - the condition itself
+		// decides later about values which are equal to the bound
+
+		if op == parser.NodeLT || op == parser.NodeLEQ {
+			keys, err = iq.LookupRange(attr, "", val)
+		} else {
+			keys, err = iq.LookupRange(attr, val, "")
+		}
+
+		desc += " [range index]"
+	}
+
+	if err != nil || keys == nil {
+		return nil, "", err
+	}
+
+	return keys, desc, nil
+}
+
+/*
+intersectKeys returns all keys of the first list which are also in the second
+list.
+*/
+func intersectKeys(keys1 []string, keys2 []string) []string {
+	keySet := make(map[string]bool, len(keys2))
+	for _, key := range keys2 {
+		keySet[key] = true
+	}
+
+	ret := make([]string, 0)
+	for _, key := range keys1 {
+		if keySet[key] {
+			ret = append(ret, key)
+		}
+	}
+
+	return ret
+}
+
+/*
+unionKeys returns all keys of the first list followed by all keys of the
+second list which are not in the first list.
+*/
+func unionKeys(keys1 []string, keys2 []string) []string {
+	keySet := make(map[string]bool, len(keys1))
+	for _, key := range keys1 {
+		keySet[key] = true
+	}
+
+	ret := append(make([]string, 0, len(keys1)+len(keys2)), keys1...)
+	for _, key := range keys2 {
+		if !keySet[key] {
+			keySet[key] = true
+			ret = append(ret, key)
+		}
+	}
+
+	return ret
+}
+
+/*
+indexAttr returns the node attribute of a condition value if the value can be
+looked up in the index.
+*/
+func indexAttr(astNode *parser.ASTNode) (string, bool) {
+	valRuntime, ok := astNode.Runtime.(*valueRuntime)
+
+	if !ok || !valRuntime.isNodeAttrValue || valRuntime.nestedValuePath != nil ||
+		valRuntime.condVal == data.NodeKey || valRuntime.condVal == data.NodeKind {
+		return "", false
+	}
+
+	return valRuntime.condVal, true
+}
+
+/*
+indexConstant returns the value of a constant of a condition.
+*/
+func indexConstant(astNode *parser.ASTNode) (string, bool) {
+	valRuntime, ok := astNode.Runtime.(*valueRuntime)
+
+	if !ok || valRuntime.isNodeAttrValue || valRuntime.isEdgeAttrValue ||
+		astNode.Name != parser.NodeVALUE || astNode.Token.ID != parser.TokenVALUE {
+		return "", false
+	}
+
+	return valRuntime.condVal, true
+}
+
 // Where related runtimes
 // ======================
 
@@ -78,15 +78,20 @@ const (
 
 	TokenGET
 	TokenLOOKUP
+	TokenEXPLAIN
+	TokenPROFILE
 	TokenFROM
 	TokenGROUP
+	TokenGROUPBY
 	TokenWITH
 	TokenLIST
 	TokenNULLTRAVERSAL
 	TokenFILTERING
 	TokenORDERING
 	TokenWHERE
+	TokenNEAREST
 	TokenTRAVERSE
+	TokenDEPTH
 	TokenEND
 	TokenPRIMARY
 	TokenSHOW
@@ -138,10 +143,14 @@ const (
 
 	// Keywords
 
-	NodeGET    = "get"
-	NodeLOOKUP = "lookup"
-	NodeFROM   = "from"
-	NodeWHERE  = "where"
+	NodeGET     = "get"
+	NodeLOOKUP  = "lookup"
+	NodeEXPLAIN = "explain"
+	NodePROFILE = "profile"
+	NodeFROM    = "from"
+	NodeWHERE   = "where"
+
+	NodeNEAREST = "nearest"
 
 	NodeUNIQUE      = "unique"
 	NodeUNIQUECOUNT = "uniquecount"
@@ -150,9 +159,11 @@ const (
 	NodeDESCENDING  = "desc"
 
 	NodeTRAVERSE = "traverse"
+	NodeDEPTH    = "depth"
 	NodePRIMARY  = "primary"
 	NodeSHOW     = "show"
 	NodeSHOWTERM = "showterm"
+	NodeGROUPBY  = "groupby"
 	NodeWITH     = "with"
 	NodeLIST     = "list"
 
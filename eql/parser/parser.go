@@ -10,6 +10,7 @@ package parser
 import (
 	"bytes"
 	"fmt"
+	"strings"
 
 	"github.com/Fisch-Labs/Toolkit/stringutil"
 )
@@ -185,7 +186,7 @@ func init() {
 		// Special tokens - always handled in a denotation function
 
 		TokenCOMMA:  {NodeCOMMA, nil, nil, nil, 0, nil, nil},
-		TokenGROUP:  {NodeGROUP, nil, nil, nil, 0, nil, nil},
+		TokenGROUP:  {NodeGROUP, nil, nil, nil, 0, ndGroupBy, nil},
 		TokenEND:    {NodeEND, nil, nil, nil, 0, nil, nil},
 		TokenAS:     {NodeAS, nil, nil, nil, 0, nil, nil},
 		TokenFORMAT: {NodeFORMAT, nil, nil, nil, 0, nil, nil},
@@ -194,8 +195,14 @@ func init() {
 
 		TokenGET:    {NodeGET, nil, nil, nil, 0, ndGet, nil},
 		TokenLOOKUP: {NodeLOOKUP, nil, nil, nil, 0, ndLookup, nil},
-		TokenFROM:   {NodeFROM, nil, nil, nil, 0, ndFrom, nil},
-		TokenWHERE:  {NodeWHERE, nil, nil, nil, 0, ndPrefix, nil},
+
+		TokenEXPLAIN: {NodeEXPLAIN, nil, nil, nil, 0, ndExplain, nil},
+		TokenPROFILE: {NodePROFILE, nil, nil, nil, 0, ndExplain, nil},
+
+		TokenFROM:  {NodeFROM, nil, nil, nil, 0, ndFrom, nil},
+		TokenWHERE: {NodeWHERE, nil, nil, nil, 0, ndPrefix, nil},
+
+		TokenNEAREST: {NodeNEAREST, nil, nil, nil, 0, ndNearest, nil},
 
 		TokenUNIQUE:      {NodeUNIQUE, nil, nil, nil, 0, ndPrefix, nil},
 		TokenUNIQUECOUNT: {NodeUNIQUECOUNT, nil, nil, nil, 0, ndPrefix, nil},
@@ -204,9 +211,11 @@ func init() {
 		TokenDESCENDING:  {NodeDESCENDING, nil, nil, nil, 0, ndPrefix, nil},
 
 		TokenTRAVERSE: {NodeTRAVERSE, nil, nil, nil, 0, ndTraverse, nil},
+		TokenDEPTH:    {NodeDEPTH, nil, nil, nil, 0, nil, nil},
 		TokenPRIMARY:  {NodePRIMARY, nil, nil, nil, 0, ndPrefix, nil},
 		TokenSHOW:     {NodeSHOW, nil, nil, nil, 0, ndShow, nil},
 		TokenSHOWTERM: {NodeSHOWTERM, nil, nil, nil, 0, ndShow, nil},
+		TokenGROUPBY:  {NodeGROUPBY, nil, nil, nil, 0, nil, nil},
 		TokenWITH:     {NodeWITH, nil, nil, nil, 0, ndWith, nil},
 		TokenLIST:     {NodeLIST, nil, nil, nil, 0, nil, nil},
 
@@ -249,6 +258,23 @@ func init() {
 	}
 }
 
+/*
+Map of words which are only keywords at the start of a statement - these words
+are not reserved and can be used as plain values everywhere else
+*/
+var statementKeywordMap = map[string]LexTokenID{
+	"explain": TokenEXPLAIN,
+	"profile": TokenPROFILE,
+}
+
+/*
+Map of words which are only keywords at the start of a clause - these words
+are not reserved and can be used as plain values everywhere else
+*/
+var clauseKeywordMap = map[string]LexTokenID{
+	"nearest": TokenNEAREST,
+}
+
 // Parser
 // ======
 
@@ -284,6 +310,8 @@ func ParseWithRuntime(name string, input string, rp RuntimeProvider) (*ASTNode,
 
 	p.node = node
 
+	positionalKeyword(p, statementKeywordMap)
+
 	return p.run(0)
 }
 
@@ -434,6 +462,8 @@ func ndGet(p *parser, self *ASTNode) (*ASTNode, error) {
 	// Parse the rest and add it as children
 
 	for p.node.Token.ID != TokenEOF {
+		positionalKeyword(p, clauseKeywordMap)
+
 		exp, err := p.run(0)
 		if err != nil {
 			return nil, err
@@ -473,6 +503,8 @@ func ndLookup(p *parser, self *ASTNode) (*ASTNode, error) {
 	// Parse the rest and add it as children
 
 	for p.node.Token.ID != TokenEOF {
+		positionalKeyword(p, clauseKeywordMap)
+
 		exp, err := p.run(0)
 		if err != nil {
 			return nil, err
@@ -500,6 +532,71 @@ func ndFrom(p *parser, self *ASTNode) (*ASTNode, error) {
 	return self, acceptChild(p, self.Children[0], TokenVALUE)
 }
 
+/*
+ndExplain is used to parse explain and profile expressions.
+*/
+func ndExplain(p *parser, self *ASTNode) (*ASTNode, error) {
+
+	// Must be followed by a get or lookup expression
+
+	if p.node.Token.ID != TokenGET && p.node.Token.ID != TokenLOOKUP {
+		if p.node.Token.ID == TokenEOF {
+			return nil, p.newParserError(ErrUnexpectedEnd, "", *p.node.Token)
+		}
+		return nil, p.newParserError(ErrUnexpectedToken, p.node.Token.Val, *p.node.Token)
+	}
+
+	exp, err := p.run(0)
+	if err != nil {
+		return nil, err
+	}
+
+	self.Children = append(self.Children, exp)
+
+	return self, nil
+}
+
+/*
+ndNearest is used to parse nearest <k> to <vector> on <attr> expressions.
+*/
+func ndNearest(p *parser, self *ASTNode) (*ASTNode, error) {
+
+	// Must specify the number of results
+
+	if err := acceptChild(p, self, TokenVALUE); err != nil {
+		return nil, err
+	}
+
+	// The words "to" and "on" are not reserved - they are plain values
+
+	if err := skipValue(p, "to"); err != nil {
+		return nil, err
+	}
+
+	// Must specify a vector - either as list or as query parameter
+
+	if p.node.Token.ID == TokenLBRACK {
+
+		exp, err := p.run(0)
+		if err != nil {
+			return nil, err
+		}
+
+		self.Children = append(self.Children, exp)
+
+	} else if err := acceptChild(p, self, TokenVALUE); err != nil {
+		return nil, err
+	}
+
+	if err := skipValue(p, "on"); err != nil {
+		return nil, err
+	}
+
+	// Must specify the vector attribute
+
+	return self, acceptChild(p, self, TokenVALUE)
+}
+
 /*
 ndTraverse is used to parse traverse expressions.
 */
@@ -511,6 +608,33 @@ func ndTraverse(p *parser, self *ASTNode) (*ASTNode, error) {
 		return nil, err
 	}
 
+	// Parse an optional depth range and target node key - the words "depth"
+	// and "to" are not reserved
+
+	if p.node.Token.ID == TokenVALUE && strings.ToLower(p.node.Token.Val) == "depth" {
+
+		// Create a depth token
+
+		st := astNodeMap[TokenDEPTH].instance(p, p.node.Token)
+
+		skipToken(p, TokenVALUE)
+
+		if err := acceptChild(p, st, TokenVALUE); err != nil {
+			return nil, err
+		}
+
+		if p.node.Token.ID == TokenVALUE && strings.ToLower(p.node.Token.Val) == "to" {
+
+			skipToken(p, TokenVALUE)
+
+			if err := acceptChild(p, st, TokenVALUE); err != nil {
+				return nil, err
+			}
+		}
+
+		self.Children = append(self.Children, st)
+	}
+
 	// Parse the rest and add it as children - must end with "end" if
 	// further clauses are given
 
@@ -643,6 +767,39 @@ func ndShow(p *parser, self *ASTNode) (*ASTNode, error) {
 	return self, nil
 }
 
+/*
+ndGroupBy is used to parse group by clauses. The from group ... expression
+is handled in ndFrom.
+*/
+func ndGroupBy(p *parser, self *ASTNode) (*ASTNode, error) {
+
+	// Must be followed by the word "by" which is not reserved
+
+	if err := skipValue(p, "by"); err != nil {
+		return nil, err
+	}
+
+	// Create a group by token
+
+	st := astNodeMap[TokenGROUPBY].instance(p, self.Token)
+
+	// Must have at least one column
+
+	if err := acceptChild(p, st, TokenVALUE); err != nil {
+		return nil, err
+	}
+
+	// Read all commas and accept further values as additional columns
+
+	for skipToken(p, TokenCOMMA) == nil {
+		if err := acceptChild(p, st, TokenVALUE); err != nil {
+			return nil, err
+		}
+	}
+
+	return st, nil
+}
+
 /*
 ndWith is used to parse a with clauses.
 */
@@ -782,6 +939,37 @@ func skipToken(p *parser, ids ...LexTokenID) error {
 	return err
 }
 
+/*
+skipValue skips over a given unquoted value.
+*/
+func skipValue(p *parser, val string) error {
+
+	if p.node.Token.ID != TokenVALUE || strings.ToLower(p.node.Token.Val) != val {
+		if p.node.Token.ID == TokenEOF {
+			return p.newParserError(ErrUnexpectedEnd, "", *p.node.Token)
+		}
+		return p.newParserError(ErrUnexpectedToken, p.node.Token.Val, *p.node.Token)
+	}
+
+	return skipToken(p, TokenVALUE)
+}
+
+/*
+positionalKeyword turns the current value token into a keyword token if its
+value is one of the given words. This is used for words which are only
+keywords at a certain position.
+*/
+func positionalKeyword(p *parser, words map[string]LexTokenID) {
+
+	if p.node.Token.ID == TokenVALUE {
+		if id, ok := words[strings.ToLower(p.node.Token.Val)]; ok {
+			token := *p.node.Token
+			token.ID = id
+			p.node = astNodeMap[id].instance(p, &token)
+		}
+	}
+}
+
 /*
 acceptChild accepts the current token as a child.
 */
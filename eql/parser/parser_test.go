@@ -932,3 +932,238 @@ func testParserRun(tokens []LexToken) (*ASTNode, error) {
 
 	return p.run(0)
 }
+
+func TestNearestParsing(t *testing.T) {
+
+	input := `
+get Chunk nearest 20 to [0.5, -1, 2] on embedding traverse :::Doc end`
+	expectedOutput := `
+get
+  value: "Chunk"
+  nearest
+    value: "20"
+    list
+      value: "0.5"
+      minus
+        value: "1"
+      value: "2"
+    value: "embedding"
+  traverse
+    value: ":::Doc"
+`[1:]
+
+	if res, err := Parse("mytest", input); err != nil || fmt.Sprint(res) != expectedOutput {
+		t.Error("Unexpected parser output:\n", res, "expected was:\n", expectedOutput, "Error:", err)
+		return
+	}
+
+	input = `
+lookup Chunk "a", "b" NEAREST 1 TO $q ON embedding`
+	expectedOutput = `
+lookup
+  value: "Chunk"
+  value: "a"
+  value: "b"
+  nearest
+    value: "1"
+    value: "$q"
+    value: "embedding"
+`[1:]
+
+	if res, err := Parse("mytest", input); err != nil || fmt.Sprint(res) != expectedOutput {
+		t.Error("Unexpected parser output:\n", res, "expected was:\n", expectedOutput, "Error:", err)
+		return
+	}
+
+	// The word nearest is only a keyword at the start of a clause
+
+	input = `
+get Chunk where nearest = 1 show nearest`
+	expectedOutput = `
+get
+  value: "Chunk"
+  where
+    =
+      value: "nearest"
+      value: "1"
+  show
+    showterm: "nearest"
+`[1:]
+
+	if res, err := Parse("mytest", input); err != nil || fmt.Sprint(res) != expectedOutput {
+		t.Error("Unexpected parser output:\n", res, "expected was:\n", expectedOutput, "Error:", err)
+		return
+	}
+
+	// Test error cases
+
+	if res, err := Parse("mytest", "get Chunk nearest 20 from $q on embedding"); err == nil || err.Error() !=
+		"Parse error in mytest: Unexpected term (from) (Line:1 Pos:22)" {
+		t.Error("Unexpected result", res, err)
+		return
+	}
+
+	if res, err := Parse("mytest", "get Chunk nearest 20 to $q with embedding"); err == nil || err.Error() !=
+		"Parse error in mytest: Unexpected term (with) (Line:1 Pos:28)" {
+		t.Error("Unexpected result", res, err)
+		return
+	}
+
+	if res, err := Parse("mytest", "get Chunk nearest 20 to $q"); err == nil || err.Error() !=
+		"Parse error in mytest: Unexpected end (Line:1 Pos:25)" {
+		t.Error("Unexpected result", res, err)
+		return
+	}
+}
+
+func TestExplainParsing(t *testing.T) {
+
+	input := `
+explain get Song where ranking > 5 traverse :::Author end`
+	expectedOutput := `
+explain
+  get
+    value: "Song"
+    where
+      >
+        value: "ranking"
+        value: "5"
+    traverse
+      value: ":::Author"
+`[1:]
+
+	if res, err := Parse("mytest", input); err != nil || fmt.Sprint(res) != expectedOutput {
+		t.Error("Unexpected parser output:\n", res, "expected was:\n", expectedOutput, "Error:", err)
+		return
+	}
+
+	input = `
+PROFILE lookup Song "a"`
+	expectedOutput = `
+profile
+  lookup
+    value: "Song"
+    value: "a"
+`[1:]
+
+	if res, err := Parse("mytest", input); err != nil || fmt.Sprint(res) != expectedOutput {
+		t.Error("Unexpected parser output:\n", res, "expected was:\n", expectedOutput, "Error:", err)
+		return
+	}
+
+	// The words explain and profile are only keywords at the start of a statement
+
+	input = `
+explain get Song where profile = 1 show explain, profile`
+	expectedOutput = `
+explain
+  get
+    value: "Song"
+    where
+      =
+        value: "profile"
+        value: "1"
+    show
+      showterm: "explain"
+      showterm: "profile"
+`[1:]
+
+	if res, err := Parse("mytest", input); err != nil || fmt.Sprint(res) != expectedOutput {
+		t.Error("Unexpected parser output:\n", res, "expected was:\n", expectedOutput, "Error:", err)
+		return
+	}
+
+	// Test error cases
+
+	if res, err := Parse("mytest", "explain where a = 1"); err == nil || err.Error() !=
+		"Parse error in mytest: Unexpected term (where) (Line:1 Pos:9)" {
+		t.Error("Unexpected result", res, err)
+		return
+	}
+
+	if res, err := Parse("mytest", "profile"); err == nil || err.Error() !=
+		"Parse error in mytest: Unexpected end (Line:1 Pos:1)" {
+		t.Error("Unexpected result", res, err)
+		return
+	}
+}
+
+func TestGroupByParsing(t *testing.T) {
+
+	input := `
+get Song show name, @sum(ranking) group by name, 1:n:kind`
+	expectedOutput := `
+get
+  value: "Song"
+  show
+    showterm: "name"
+    showterm
+      func
+        value: "sum"
+        value: "ranking"
+  groupby
+    value: "name"
+    value: "1:n:kind"
+`[1:]
+
+	if res, err := Parse("mytest", input); err != nil || fmt.Sprint(res) != expectedOutput {
+		t.Error("Unexpected parser output:\n", res, "expected was:\n", expectedOutput, "Error:", err)
+		return
+	}
+
+	// Test error cases
+
+	if res, err := Parse("mytest", "get Song group name"); err == nil || err.Error() !=
+		"Parse error in mytest: Unexpected term (name) (Line:1 Pos:16)" {
+		t.Error("Unexpected result", res, err)
+		return
+	}
+
+	if res, err := Parse("mytest", "get Song group by"); err == nil || err.Error() !=
+		"Parse error in mytest: Unexpected end" {
+		t.Error("Unexpected result", res, err)
+		return
+	}
+}
+
+func TestTraversalDepthParsing(t *testing.T) {
+
+	input := `
+get Song traverse :::Author depth 1..3 where name = John traverse ::: DEPTH 2 to "a b" end end`
+	expectedOutput := `
+get
+  value: "Song"
+  traverse
+    value: ":::Author"
+    depth
+      value: "1..3"
+    where
+      =
+        value: "name"
+        value: "John"
+    traverse
+      value: ":::"
+      depth
+        value: "2"
+        value: "a b"
+`[1:]
+
+	if res, err := Parse("mytest", input); err != nil || fmt.Sprint(res) != expectedOutput {
+		t.Error("Unexpected parser output:\n", res, "expected was:\n", expectedOutput, "Error:", err)
+		return
+	}
+
+	// Test error cases
+
+	if res, err := Parse("mytest", "get Song traverse ::: depth"); err == nil || err.Error() !=
+		"Parse error in mytest: Unexpected end" {
+		t.Error("Unexpected result", res, err)
+		return
+	}
+
+	if res, err := Parse("mytest", "get Song traverse ::: depth 1 to"); err == nil || err.Error() !=
+		"Parse error in mytest: Unexpected end" {
+		t.Error("Unexpected result", res, err)
+		return
+	}
+}
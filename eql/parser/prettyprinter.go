@@ -21,9 +21,10 @@ import (
 /*
 Map of pretty printer templates for AST nodes
 
-There is special treatment for NodeVALUE, NodeGET, NodeLOOKUP, NodeTRAVERSE,
-NodeFUNC, NodeSHOW, NodeSHOWTERM, NodeORDERING, NodeFILTERING, NodeWITH,
-NodeLPAREN, NodeRPAREN, NodeLBRACK and NodeRBRACK.
+There is special treatment for NodeVALUE, NodeGET, NodeLOOKUP, NodeEXPLAIN,
+NodePROFILE, NodeTRAVERSE, NodeFUNC, NodeSHOW, NodeSHOWTERM, NodeGROUPBY,
+NodeORDERING, NodeFILTERING, NodeWITH, NodeLPAREN, NodeRPAREN, NodeLBRACK and
+NodeRBRACK.
 */
 var prettyPrinterMap = map[string]*template.Template{
 	NodeTRUE:                 template.Must(template.New(NodeTRUE).Parse("true")),
@@ -43,12 +44,16 @@ var prettyPrinterMap = map[string]*template.Template{
 	NodeFROM + "_1":  template.Must(template.New(NodeFROM).Parse("from {{.c1}}")),
 	NodeWHERE + "_1": template.Must(template.New(NodeWHERE).Parse("where {{.c1}}")),
 
+	NodeNEAREST + "_3": template.Must(template.New(NodeNEAREST).Parse("nearest {{.c1}} to {{.c2}} on {{.c3}}")),
+
 	NodeUNIQUE + "_1":      template.Must(template.New(NodeUNIQUE).Parse("unique {{.c1}}")),
 	NodeUNIQUECOUNT + "_1": template.Must(template.New(NodeUNIQUECOUNT).Parse("uniquecount {{.c1}}")),
 	NodeISNOTNULL + "_1":   template.Must(template.New(NodeISNOTNULL).Parse("isnotnull {{.c1}}")),
 	NodeASCENDING + "_1":   template.Must(template.New(NodeASCENDING).Parse("ascending {{.c1}}")),
 	NodeDESCENDING + "_1":  template.Must(template.New(NodeDESCENDING).Parse("descending {{.c1}}")),
 
+	NodeDEPTH + "_1":   template.Must(template.New(NodeDEPTH).Parse("depth {{.c1}}")),
+	NodeDEPTH + "_2":   template.Must(template.New(NodeDEPTH).Parse("depth {{.c1}} to {{.c2}}")),
 	NodePRIMARY + "_1": template.Must(template.New(NodePRIMARY).Parse("primary {{.c1}}")),
 	NodeLIST:           template.Must(template.New(NodeLIST).Parse("list")),
 
@@ -110,7 +115,7 @@ func PrettyPrint(ast *ASTNode) (string, error) {
 		}
 
 		isNumber, _ := regexp.MatchString("^[0-9][0-9\\.e-+]*$", val)
-		isInlineString, _ := regexp.MatchString("^[a-zA-Z0-9_:.]*$", val)
+		isInlineString, _ := regexp.MatchString("^\\$?[a-zA-Z0-9_:.]*$", val)
 
 		if allowNonQuotation && (isNumber || isInlineString) {
 			return val
@@ -132,6 +137,13 @@ func PrettyPrint(ast *ASTNode) (string, error) {
 			return quoteValue(ast.Token.Val, true), nil
 		}
 
+		// Explained queries are printed like normal queries
+
+		if (ast.Name == NodeEXPLAIN || ast.Name == NodePROFILE) && len(ast.Children) == 1 {
+			res, err := visit(ast.Children[0], level)
+			return ast.Name + " " + res, err
+		}
+
 		var children map[string]string
 		var tempKey = ast.Name
 		var buf bytes.Buffer
@@ -195,7 +207,8 @@ func PrettyPrint(ast *ASTNode) (string, error) {
 
 			for ; i < len(children); i++ {
 				buf.WriteString(children[fmt.Sprint("c", i+1)])
-				if i < len(children)-1 && ast.Children[i+1].Name != NodeSHOW {
+				if i < len(children)-1 && ast.Children[i+1].Name != NodeSHOW &&
+					ast.Children[i+1].Name != NodeGROUPBY {
 					buf.WriteString(" ")
 				}
 			}
@@ -212,7 +225,8 @@ func PrettyPrint(ast *ASTNode) (string, error) {
 
 			for i := 1; i < len(children); i++ {
 				buf.WriteString(children[fmt.Sprint("c", i+1)])
-				if i < len(children)-1 && ast.Children[i+1].Name != NodeSHOW {
+				if i < len(children)-1 && ast.Children[i+1].Name != NodeSHOW &&
+					ast.Children[i+1].Name != NodeGROUPBY {
 					buf.WriteString(" ")
 				}
 			}
@@ -284,6 +298,19 @@ func PrettyPrint(ast *ASTNode) (string, error) {
 
 			return buf.String(), nil
 
+		} else if ast.Name == NodeGROUPBY {
+
+			buf.WriteString("\ngroup by ")
+
+			for i := 0; i < len(children); i++ {
+				buf.WriteString(children[fmt.Sprint("c", i+1)])
+				if i < len(children)-1 {
+					buf.WriteString(", ")
+				}
+			}
+
+			return buf.String(), nil
+
 		} else if ast.Name == NodeORDERING {
 
 			buf.WriteString("ordering")
@@ -573,3 +573,157 @@ func testPrettyPrinting(input, astOutput, ppOutput string) error {
 
 	return nil
 }
+
+func TestNearestPrinting(t *testing.T) {
+
+	input := `
+GET Chunk NEAREST 20 TO [0.5,-1 , 2] ON embedding`
+	expectedOutput := `
+get
+  value: "Chunk"
+  nearest
+    value: "20"
+    list
+      value: "0.5"
+      minus
+        value: "1"
+      value: "2"
+    value: "embedding"
+`[1:]
+
+	if err := testPrettyPrinting(input, expectedOutput,
+		"get Chunk nearest 20 to [0.5, -1, 2] on embedding"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	input = `
+lookup Chunk "a" nearest 3 to $query on embedding traverse :::Doc end`
+	expectedOutput = `
+lookup
+  value: "Chunk"
+  value: "a"
+  nearest
+    value: "3"
+    value: "$query"
+    value: "embedding"
+  traverse
+    value: ":::Doc"
+`[1:]
+
+	if err := testPrettyPrinting(input, expectedOutput,
+		`lookup Chunk "a" nearest 3 to $query on embedding 
+  traverse :::Doc
+  end`); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestExplainPrinting(t *testing.T) {
+
+	input := `
+EXPLAIN get Song where ranking > 5 traverse :::Author end`
+	expectedOutput := `
+explain
+  get
+    value: "Song"
+    where
+      >
+        value: "ranking"
+        value: "5"
+    traverse
+      value: ":::Author"
+`[1:]
+
+	if err := testPrettyPrinting(input, expectedOutput,
+		`explain get Song where ranking > 5 
+  traverse :::Author
+  end`); err != nil {
+		t.Error(err)
+		return
+	}
+
+	input = `
+profile lookup Song "a"`
+	expectedOutput = `
+profile
+  lookup
+    value: "Song"
+    value: "a"
+`[1:]
+
+	if err := testPrettyPrinting(input, expectedOutput,
+		`profile lookup Song "a"`); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestGroupByPrinting(t *testing.T) {
+
+	input := `
+get Song show name, @sum(ranking) group by name, 1:n:kind with ordering(ascending name)`
+	expectedOutput := `
+get
+  value: "Song"
+  show
+    showterm: "name"
+    showterm
+      func
+        value: "sum"
+        value: "ranking"
+  groupby
+    value: "name"
+    value: "1:n:kind"
+  with
+    ordering
+      asc
+        value: "name"
+`[1:]
+
+	if err := testPrettyPrinting(input, expectedOutput,
+		`get Song 
+show
+  name,
+  @sum(ranking)
+group by name, 1:n:kind 
+with
+  ordering(ascending name)`); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestTraversalDepthPrinting(t *testing.T) {
+
+	input := `
+get Song traverse :::Author depth 1..3 where name = John traverse ::: depth 2 to "a b" end end`
+	expectedOutput := `
+get
+  value: "Song"
+  traverse
+    value: ":::Author"
+    depth
+      value: "1..3"
+    where
+      =
+        value: "name"
+        value: "John"
+    traverse
+      value: ":::"
+      depth
+        value: "2"
+        value: "a b"
+`[1:]
+
+	if err := testPrettyPrinting(input, expectedOutput,
+		`get Song 
+  traverse :::Author depth 1..3 where name = John 
+    traverse ::: depth 2 to "a b"
+    end
+  end`); err != nil {
+		t.Error(err)
+		return
+	}
+}
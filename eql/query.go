@@ -11,6 +11,23 @@ Package eql contains the main API for EQL.
 Example EQL query:
 
 GET Person where name = "Marvin"
+
+A query which is prefixed with EXPLAIN returns the plan of the query without
+running it. A query which is prefixed with PROFILE is run and returns the plan
+with statistics (fetched nodes, where clause evaluations, time and cache hits).
+
+The show clause can contain aggregate functions (@sum, @avg, @min, @max,
+@collect and @distinct) which combine the values of all rows with the same
+values in the columns of a GROUP BY clause:
+
+GET Author TRAVERSE :::Song END SHOW name, @avg(Song:ranking) GROUP BY name
+
+A traversal with a DEPTH range follows its spec repeatedly and returns every
+reachable node once with its shortest path (shown with the @path function). A
+traversal with a target (DEPTH <min>..<max> TO <key>) returns the shortest
+path to the target node:
+
+LOOKUP Pkg "a" TRAVERSE :DependsOn::Pkg DEPTH 1..4 END SHOW 2:n:key, @path(2)
 */
 package eql
 
@@ -34,28 +51,56 @@ func RunQuery(name string, part string, query string, gm *graph.Manager) (Search
 	return RunQueryWithNodeInfo(name, part, query, gm, interpreter.NewDefaultNodeInfo(gm))
 }
 
+/*
+RunQueryWithParams runs a search query against a given graph database. The
+query can reference the given parameters with $<name>.
+*/
+func RunQueryWithParams(name string, part string, query string, gm *graph.Manager,
+	params map[string]interface{}) (SearchResult, error) {
+	return runQuery(name, part, query, gm, interpreter.NewDefaultNodeInfo(gm), params)
+}
+
 /*
 RunQueryWithNodeInfo runs a search query against a given graph database. Using
 a given NodeInfo object to retrieve rendering information.
 */
 func RunQueryWithNodeInfo(name string, part string, query string, gm *graph.Manager, ni interpreter.NodeInfo) (SearchResult, error) {
-	var rtp parser.RuntimeProvider
+	return runQuery(name, part, query, gm, ni, nil)
+}
 
-	word := strings.ToLower(parser.FirstWord(query))
+/*
+ExplainQuery returns a description of how a search query determines its start
+nodes (e.g. a scan of all nodes or an index lookup). The query is validated
+and the index is consulted but no nodes are fetched.
+*/
+func ExplainQuery(name string, part string, query string, gm *graph.Manager) (string, error) {
 
-	if word == "get" {
-		rtp = interpreter.NewGetRuntimeProvider(name, part, gm, ni)
-	} else if word == "lookup" {
-		rtp = interpreter.NewLookupRuntimeProvider(name, part, gm, ni)
-	} else {
-		return nil, &interpreter.RuntimeError{
-			Source: name,
-			Type:   interpreter.ErrInvalidConstruct,
-			Detail: "Unknown query type: " + word,
-			Node:   nil,
-			Line:   1,
-			Pos:    1,
-		}
+	rtp, err := newRuntimeProvider(name, part, query, gm, interpreter.NewDefaultNodeInfo(gm), nil)
+	if err != nil {
+		return "", err
+	}
+
+	ast, err := parser.ParseWithRuntime(name, query, rtp)
+	if err != nil {
+		return "", err
+	}
+
+	if err := ast.Runtime.Validate(); err != nil {
+		return "", err
+	}
+
+	return rtp.(interface{ Plan() string }).Plan(), nil
+}
+
+/*
+runQuery runs a search query against a given graph database.
+*/
+func runQuery(name string, part string, query string, gm *graph.Manager, ni interpreter.NodeInfo,
+	params map[string]interface{}) (SearchResult, error) {
+
+	rtp, err := newRuntimeProvider(name, part, query, gm, ni, params)
+	if err != nil {
+		return nil, err
 	}
 
 	ast, err := parser.ParseWithRuntime(name, query, rtp)
@@ -71,6 +116,42 @@ func RunQueryWithNodeInfo(name string, part string, query string, gm *graph.Mana
 	return &queryResult{res.(*interpreter.SearchResult)}, nil
 }
 
+/*
+newRuntimeProvider creates a runtime provider for a given search query.
+*/
+func newRuntimeProvider(name string, part string, query string, gm *graph.Manager, ni interpreter.NodeInfo,
+	params map[string]interface{}) (parser.RuntimeProvider, error) {
+
+	word := strings.ToLower(parser.FirstWord(query))
+
+	if word == parser.NodeEXPLAIN || word == parser.NodePROFILE {
+
+		// Explained and profiled queries are run by the provider of the query
+
+		query = strings.TrimSpace(query)
+		word = strings.ToLower(parser.FirstWord(query[len(word):]))
+	}
+
+	if word == "get" {
+		grtp := interpreter.NewGetRuntimeProvider(name, part, gm, ni)
+		grtp.SetParams(params)
+		return grtp, nil
+	} else if word == "lookup" {
+		lrtp := interpreter.NewLookupRuntimeProvider(name, part, gm, ni)
+		lrtp.SetParams(params)
+		return lrtp, nil
+	}
+
+	return nil, &interpreter.RuntimeError{
+		Source: name,
+		Type:   interpreter.ErrInvalidConstruct,
+		Detail: "Unknown query type: " + word,
+		Node:   nil,
+		Line:   1,
+		Pos:    1,
+	}
+}
+
 /*
 ParseQuery parses a search query and return its Abstract Syntax Tree.
 */
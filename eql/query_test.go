@@ -8,6 +8,7 @@
 package eql
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/Fisch-Labs/FishDB/eql/interpreter"
@@ -37,6 +38,457 @@ Aria4
 	}
 }
 
+func TestSnapshotQuery(t *testing.T) {
+	gm, _ := songGraph()
+
+	if err := gm.CreateSnapshot("main", "frozen"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	node := data.NewGraphNode()
+	node.SetAttr("key", "000")
+	node.SetAttr("kind", "Author")
+	node.SetAttr("name", "Johnny")
+	gm.StoreNode("main", node)
+
+	// Queries can name the snapshot instead of the partition
+
+	res, err := RunQuery("test", "frozen", "lookup Author '000' traverse :::Song end show Author:name, Song:key with ordering(ascending key)", gm)
+
+	if err != nil || res.String() != `
+Labels: Author Name, Song Key
+Format: auto, auto
+Data: 1:n:name, 2:n:key
+John, Aria1
+John, Aria2
+John, Aria3
+John, Aria4
+`[1:] {
+		t.Error("Unexpected result: ", err, res)
+		return
+	}
+
+	res, err = RunQuery("test", "main", "lookup Author '000'", gm)
+
+	if err != nil || res.String() != `
+Labels: Author Key, Author Name
+Format: auto, auto
+Data: 1:n:key, 1:n:name
+000, Johnny
+`[1:] {
+		t.Error("Unexpected result: ", err, res)
+		return
+	}
+}
+
+func TestNearestQuery(t *testing.T) {
+	gm, _ := songGraph()
+
+	for key, vec := range map[string][]float32{
+		"Aria1":       {1, 0},
+		"LoveSong3":   {0, 1},
+		"MyOnlySong3": {-1, 0},
+	} {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "Song")
+		node.SetAttr("embedding", vec)
+		gm.UpdateNode("main", node)
+	}
+
+	// Nearest nodes can be used as start nodes for traversals
+
+	res, err := RunQuery("test", "main", "get Song nearest 2 to [1, 0] on embedding traverse :::Author end show Song:key, @distance(), Author:name", gm)
+
+	if err != nil || res.String() != `
+Labels: Song Key, Distance, Author Name
+Format: auto, auto, auto
+Data: 1:n:key, 1:func:distance(), 2:n:name
+Aria1, 0, John
+LoveSong3, 1, Mike
+`[1:] {
+		t.Error("Unexpected result: ", err, res)
+		return
+	}
+
+	// Vectors can be given as query parameters
+
+	res, err = RunQueryWithParams("test", "main", "get Song nearest 1 to $q on embedding show key", gm,
+		map[string]interface{}{"q": []interface{}{-1.0, 0.1}})
+
+	if err != nil || res.String() != `
+Labels: Song Key
+Format: auto
+Data: 1:n:key
+MyOnlySong3
+`[1:] {
+		t.Error("Unexpected result: ", err, res)
+		return
+	}
+
+	// Lookup orders the given keys by distance
+
+	res, err = RunQuery("test", "main", "lookup Song 'Aria1', 'Aria2', 'MyOnlySong3' nearest 3 to [-1, 0.1] on embedding show key", gm)
+
+	if err != nil || res.String() != `
+Labels: Song Key
+Format: auto
+Data: 1:n:key
+MyOnlySong3
+Aria1
+`[1:] {
+		t.Error("Unexpected result: ", err, res)
+		return
+	}
+
+	// Test error cases
+
+	_, err = RunQuery("test", "main", "get Song nearest 1 to $q on embedding", gm)
+	if err == nil || err.Error() != "EQL error in test: Invalid construct (Unknown query parameter: $q) (Line:1 Pos:23)" {
+		t.Error(err)
+		return
+	}
+
+	_, err = RunQueryWithParams("test", "main", "get Song nearest 1 to $q on embedding", gm,
+		map[string]interface{}{"q": "foo"})
+	if err == nil || err.Error() != "EQL error in test: Value of operand is not a vector ($q=foo) (Line:1 Pos:23)" {
+		t.Error(err)
+		return
+	}
+
+	_, err = RunQuery("test", "main", "get Song nearest x to [1] on embedding", gm)
+	if err == nil || err.Error() != "EQL error in test: Invalid construct (Number of nearest nodes must be a positive integer: x) (Line:1 Pos:18)" {
+		t.Error(err)
+		return
+	}
+
+	_, err = RunQuery("test", "main", "get Song nearest 1 to [a] on embedding", gm)
+	if err == nil || err.Error() != "EQL error in test: Value of operand is not a number (a) (Line:1 Pos:24)" {
+		t.Error(err)
+		return
+	}
+
+	_, err = RunQuery("test", "main", "get Song nearest 1 to [1, 0, 0] on embedding", gm)
+	if err == nil || err.Error() != "EQL error in test: Invalid construct (GraphError: Index error (Vector has 3 dimensions - index of attribute embedding has 2 dimensions)) (Line:1 Pos:10)" {
+		t.Error(err)
+		return
+	}
+}
+
+func TestScoreQuery(t *testing.T) {
+	gm, _ := songGraph()
+
+	for key, desc := range map[string]string{
+		"Aria1":       "A slow song about a fish",
+		"LoveSong3":   "Fish fish fish",
+		"MyOnlySong3": "A song about birds",
+	} {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "Song")
+		node.SetAttr("desc", desc)
+		gm.UpdateNode("main", node)
+	}
+
+	// Scores can be used to filter and to order results
+
+	res, err := RunQuery("test", "main", "get Song where @score(desc, 'fish') > 0 show key, @score(1, desc, fish) with ordering(descending 1:func:score)", gm)
+
+	if err != nil || res.String() != `
+Labels: Song Key, Score
+Format: auto, auto
+Data: 1:n:key, 1:func:score()
+LoveSong3, 0.7907119880251786
+Aria1, 0.40610585487698
+`[1:] {
+		t.Error("Unexpected result: ", err, res)
+		return
+	}
+
+	// Test error cases
+
+	_, err = RunQuery("test", "main", "get Song where @score(desc) > 0", gm)
+	if err == nil || err.Error() != "EQL error in test: Invalid construct (Score function requires 2 parameters: attribute name, query string) (Line:1 Pos:16)" {
+		t.Error(err)
+		return
+	}
+
+	_, err = RunQuery("test", "main", "get Song show key, @score(1, desc)", gm)
+	if err == nil || err.Error() != "EQL error in test: Invalid construct (Score function requires 3 parameters: traversal step, attribute name, query string) (Line:1 Pos:20)" {
+		t.Error(err)
+		return
+	}
+
+	_, err = RunQuery("test", "main", "get Song show key, @score(1, desc, fish) with ordering(descending 1:func:foo)", gm)
+	if err == nil || err.Error() != "EQL error in test: Invalid construct (Cannot determine column for with term: 1:func:foo) (Line:1 Pos:56)" {
+		t.Error(err)
+		return
+	}
+}
+
+func TestRangeQuery(t *testing.T) {
+	gm, _ := songGraph()
+
+	gm.SetIndexMode("Song", "ranking", "range")
+
+	// The index is stale until it is rebuilt - all nodes are checked
+
+	plan, err := ExplainQuery("test", "main", "get Song where ranking > 5 show key", gm)
+
+	if err != nil || plan != "scan of all Song nodes" {
+		t.Error("Unexpected result: ", plan, err)
+		return
+	}
+
+	res, err := RunQuery("test", "main", "get Song where ranking > 5 show key", gm)
+
+	if err != nil || res.String() != `
+Labels: Song Key
+Format: auto
+Data: 1:n:key
+DeadSong2
+MyOnlySong3
+Aria1
+Aria4
+`[1:] {
+		t.Error("Unexpected result: ", err, res)
+		return
+	}
+
+	if _, err := gm.RebuildIndex("main", "Song"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	node := data.NewGraphNode()
+	node.SetAttr("key", "UnrankedSong")
+	node.SetAttr("kind", "Song")
+	node.SetAttr("ranking", "unknown")
+	gm.StoreNode("main", node)
+
+	// Comparisons of range indexed attributes are looked up in the index
+	// and results are in the order of the index
+
+	res, err = RunQuery("test", "main", "get Song where ranking > 5 show key, ranking", gm)
+
+	if err != nil || res.String() != `
+Labels: Song Key, Ranking
+Format: auto, auto
+Data: 1:n:key, 1:n:ranking
+DeadSong2, 6
+Aria1, 8
+Aria4, 18
+MyOnlySong3, 19
+`[1:] {
+		t.Error("Unexpected result: ", err, res)
+		return
+	}
+
+	res, err = RunQuery("test", "main", "get Song where 3 <= ranking and ranking < 18 and name != 'Aria3' show key", gm)
+
+	if err != nil || res.String() != `
+Labels: Song Key
+Format: auto
+Data: 1:n:key
+FightSong4
+StrangeSong1
+DeadSong2
+Aria1
+`[1:] {
+		t.Error("Unexpected result: ", err, res)
+		return
+	}
+
+	res, err = RunQuery("test", "main", "get Song where ranking >= 100 show key", gm)
+
+	if err != nil || res.String() != `
+Labels: Song Key
+Format: auto
+Data: 1:n:key
+`[1:] {
+		t.Error("Unexpected result: ", err, res)
+		return
+	}
+
+	// Conditions which cannot be looked up check all nodes
+
+	res, err = RunQuery("test", "main", "get Song where ranking > 5 or name beginswith 'Aria' show key", gm)
+
+	if err != nil || res.String() != `
+Labels: Song Key
+Format: auto
+Data: 1:n:key
+DeadSong2
+UnrankedSong
+MyOnlySong3
+Aria1
+Aria2
+Aria3
+Aria4
+`[1:] {
+		t.Error("Unexpected result: ", err, res)
+		return
+	}
+}
+
+func TestQueryPlan(t *testing.T) {
+	gm, _ := songGraph()
+
+	gm.SetIndexMode("Song", "ranking", "range")
+
+	if _, err := gm.RebuildIndex("main", "Song"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	for key, desc := range map[string]string{
+		"Aria1":     "A slow song about a fish",
+		"Aria2":     "A song about a goldfish",
+		"LoveSong3": "Fish fish fish",
+	} {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "Song")
+		node.SetAttr("desc", desc)
+		gm.UpdateNode("main", node)
+	}
+
+	for _, test := range []struct {
+		query string
+		plan  string
+		keys  string
+	}{
+		{"get Song", "scan of all Song nodes",
+			"[StrangeSong1 FightSong4 DeadSong2 LoveSong3 MyOnlySong3 Aria1 Aria2 Aria3 Aria4]"},
+		{"get Song where name = 'Aria2'",
+			"index lookup of Song nodes: name = Aria2 [value index] (1 candidates)", "[Aria2]"},
+		{"get Song where 'aria2' = name",
+			"index lookup of Song nodes: name = aria2 [value index] (1 candidates)", "[]"},
+		{"get Song where desc contains 'fish' and ranking < 5",
+			"index lookup of Song nodes: ranking < 5 [range index] (5 candidates)",
+			"[LoveSong3 Aria2]"},
+		{"get Song where desc contains 'fish'", "scan of all Song nodes", "[LoveSong3 Aria1 Aria2]"},
+		{"get Song where name = 'Aria2' or name = 'Aria3' or ranking = 8",
+			"index lookup of Song nodes: ((name = Aria2 [value index] or name = Aria3 [value index]) or " +
+				"ranking = 8 [range index]) (3 candidates)", "[Aria2 Aria3 Aria1]"},
+		{"get Song where name = 'Aria2' and name beginswith 'Aria'",
+			"index lookup of Song nodes: name = Aria2 [value index] (1 candidates)", "[Aria2]"},
+		{"get Song where name = 'Aria2' or name beginswith 'Aria'", "scan of all Song nodes",
+			"[Aria1 Aria2 Aria3 Aria4]"},
+		{"get Song where not name = 'Aria2' and name = 'Aria3'",
+			"index lookup of Song nodes: name = Aria3 [value index] (1 candidates)", "[Aria3]"},
+		{"get Song where name = 'Foo'",
+			"index lookup of Song nodes: name = Foo [value index] (0 candidates)", "[]"},
+		{"get Song where name = 8", "scan of all Song nodes", "[]"},
+		{"get Song where desc contains 'bird'", "scan of all Song nodes", "[]"},
+		{"get Song where name contains 'ria'", "scan of all Song nodes", "[Aria1 Aria2 Aria3 Aria4]"},
+		{"get Song where key = 'Aria2'", "scan of all Song nodes", "[Aria2]"},
+		{"lookup Song 'Aria1', 'Aria2' where name = 'Aria2'", "lookup of 2 Song nodes", "[Aria2]"},
+		{"get Song nearest 1 to [1, 0] on embedding where name = 'Aria2'",
+			"vector index lookup of the nearest 1 Song nodes by embedding", "[]"},
+	} {
+		plan, err := ExplainQuery("test", "main", test.query, gm)
+		if err != nil || plan != test.plan {
+			t.Error("Unexpected plan for", test.query, ":", plan, err)
+			return
+		}
+
+		res, err := RunQuery("test", "main", test.query, gm)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		var keys []string
+		for _, row := range res.Rows() {
+			keys = append(keys, fmt.Sprint(row[0]))
+		}
+
+		if fmt.Sprint(keys) != test.keys {
+			t.Error("Unexpected result for", test.query, ":", keys)
+			return
+		}
+	}
+
+	// Test error cases
+
+	if _, err := ExplainQuery("test", "main", "get Song where foo(", gm); err == nil {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if _, err := ExplainQuery("test", "main", "foo Song", gm); err == nil ||
+		err.Error() != "EQL error in test: Invalid construct (Unknown query type: foo) (Line:1 Pos:1)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+}
+
+func TestExplainProfileQuery(t *testing.T) {
+	gm, _ := songGraph()
+
+	res, err := RunQuery("test", "main", "explain get Song where name = 'Aria1' or ranking > 10 "+
+		"traverse :::Author where name != 'Hans' traverse :::Song end end show key", gm)
+
+	if err != nil || res.String() != `
+Labels: Level, Operation, Detail
+Format: auto, auto, auto
+Data: explain:Level, explain:Operation, explain:Detail
+1, start, scan of all Song nodes
+1, where, name = Aria1 or ranking > 10
+2, traverse, :::Author
+2, where, name != Hans
+3, traverse, :::Song
+`[1:] {
+		t.Error("Unexpected result: ", err, res)
+		return
+	}
+
+	res, err = RunQuery("test", "main", "PROFILE get Song where name = 'Aria1' or ranking > 10 "+
+		"traverse :::Author where name != 'Hans' traverse :::Song end end show key", gm)
+
+	if err != nil || res.Header().Labels() == nil || len(res.Rows()) != 7 {
+		t.Error("Unexpected result: ", err, res)
+		return
+	}
+
+	// Remove timings from the result
+
+	var rows []string
+	for _, row := range res.Rows() {
+		rows = append(rows, fmt.Sprint(row[:4]))
+	}
+
+	if fmt.Sprint(rows) != "[[1 start scan of all Song nodes 9] [1 where name = Aria1 or ranking > 10 (3 matches) 9] "+
+		"[2 traverse :::Author 3] [2 where name != Hans (2 matches) 3] [3 traverse :::Song 8] "+
+		"[ result 8 rows 8] [ cache "+fmt.Sprint(res.Row(6)[2])+" "+fmt.Sprint(res.Row(6)[3])+"]]" {
+		t.Error("Unexpected result: ", rows)
+		return
+	}
+
+	res, err = RunQuery("test", "main", "profile lookup Song 'Aria1', 'Aria2'", gm)
+
+	if err != nil || fmt.Sprint(res.Row(0)[:4]) != "[1 start lookup of 2 Song nodes 2]" {
+		t.Error("Unexpected result: ", err, res)
+		return
+	}
+
+	// Test error cases
+
+	_, err = RunQuery("test", "main", "explain get Song traverse foo end", gm)
+	if err == nil || err.Error() != "EQL error in test: Invalid traversal spec (foo) (Line:1 Pos:18)" {
+		t.Error(err)
+		return
+	}
+
+	_, err = RunQuery("test", "main", "profile get Song where @foo(1)", gm)
+	if err == nil || err.Error() != "EQL error in test: Invalid construct (Unknown function: foo) (Line:1 Pos:24)" {
+		t.Error(err)
+		return
+	}
+}
+
 func TestQuery(t *testing.T) {
 	gm, _ := songGraph()
 
@@ -0,0 +1,332 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package algo
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/graph"
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+)
+
+const linkSpec = "From:Link:To:Page"
+
+/*
+linkGraph creates a graph of pages which are connected by links with a cost:
+
+	a -> b (1), b -> c (1), a -> c (5), c -> d (1), d -> a (1), e -> f (2)
+
+The page g has no links.
+*/
+func linkGraph() *graph.Manager {
+	gm := graph.NewGraphManager(graphstorage.NewMemoryGraphStorage("mystorage"))
+
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		node := data.NewGraphNode()
+		node.SetAttr(data.NodeKey, key)
+		node.SetAttr(data.NodeKind, "Page")
+		node.SetAttr(data.NodeName, "Page "+key)
+		gm.StoreNode("main", node)
+	}
+
+	for i, link := range [][]interface{}{{"a", "b", 1}, {"b", "c", 1}, {"a", "c", 5},
+		{"c", "d", 1}, {"d", "a", 1}, {"e", "f", 2}} {
+
+		edge := data.NewGraphEdge()
+		edge.SetAttr(data.NodeKey, fmt.Sprint(i))
+		edge.SetAttr(data.NodeKind, "Link")
+		edge.SetAttr(data.EdgeEnd1Key, link[0])
+		edge.SetAttr(data.EdgeEnd1Kind, "Page")
+		edge.SetAttr(data.EdgeEnd1Role, "From")
+		edge.SetAttr(data.EdgeEnd1Cascading, false)
+		edge.SetAttr(data.EdgeEnd2Key, link[1])
+		edge.SetAttr(data.EdgeEnd2Kind, "Page")
+		edge.SetAttr(data.EdgeEnd2Role, "To")
+		edge.SetAttr(data.EdgeEnd2Cascading, false)
+		edge.SetAttr("cost", link[2])
+		gm.StoreEdge("main", edge)
+	}
+
+	return gm
+}
+
+func TestTraversals(t *testing.T) {
+	gm := linkGraph()
+
+	g, _ := NewGraph(gm, "main", "", nil)
+
+	iterate := func(it *Iterator) string {
+		var res []string
+
+		for it.HasNext() {
+			n, depth := it.Next()
+			res = append(res, fmt.Sprintf("%v:%v", n.Key, depth))
+		}
+
+		if it.Error() != nil {
+			return it.Error().Error()
+		}
+
+		return fmt.Sprint(res)
+	}
+
+	if res := iterate(g.BFS(NodeID{"a", "Page"})); res != "[a:0 b:1 c:1 d:1]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := iterate(g.DFS(NodeID{"a", "Page"})); res != "[a:0 b:1 c:2 d:3]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := iterate(g.BFS(NodeID{"e", "Page"})); res != "[e:0 f:1]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := iterate(g.BFS(NodeID{"x", "Page"})); res != "[]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if n, depth := g.BFS(NodeID{"x", "Page"}).Next(); n.Key != "" || depth != -1 {
+		t.Error("Unexpected result:", n, depth)
+		return
+	}
+
+	// Follow links only in one direction
+
+	g, _ = NewGraph(gm, "main", linkSpec, nil)
+
+	if res := iterate(g.BFS(NodeID{"b", "Page"})); res != "[b:0 c:1 d:2 a:3]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := iterate(g.DFS(NodeID{"f", "Page"})); res != "[f:0]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// Only nodes of the given kinds are part of the graph
+
+	g, _ = NewGraph(gm, "main", "", []string{"Other"})
+
+	if res := iterate(g.BFS(NodeID{"a", "Page"})); res != "[]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if _, err := NewGraph(gm, "main", "a:b", nil); err == nil || err.Error() != "GraphError: Invalid data (Invalid spec: a:b)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+}
+
+func TestShortestPath(t *testing.T) {
+	gm := linkGraph()
+
+	g, _ := NewGraph(gm, "main", linkSpec, nil)
+
+	path, dists, dist, err := g.ShortestPath(NodeID{"a", "Page"}, NodeID{"d", "Page"}, "cost")
+	if err != nil || fmt.Sprint(path) != "[Page:a Page:b Page:c Page:d]" ||
+		fmt.Sprint(dists) != "[0 1 2 3]" || dist != 3 {
+		t.Error("Unexpected result:", path, dists, dist, err)
+		return
+	}
+
+	// Without weights the direct link is shorter
+
+	path, dists, dist, err = g.ShortestPath(NodeID{"a", "Page"}, NodeID{"d", "Page"}, "")
+	if err != nil || fmt.Sprint(path) != "[Page:a Page:c Page:d]" ||
+		fmt.Sprint(dists) != "[0 1 2]" || dist != 2 {
+		t.Error("Unexpected result:", path, dists, dist, err)
+		return
+	}
+
+	path, _, dist, err = g.ShortestPath(NodeID{"a", "Page"}, NodeID{"a", "Page"}, "cost")
+	if err != nil || fmt.Sprint(path) != "[Page:a]" || dist != 0 {
+		t.Error("Unexpected result:", path, dist, err)
+		return
+	}
+
+	// Unreachable nodes have no path
+
+	path, _, _, err = g.ShortestPath(NodeID{"a", "Page"}, NodeID{"e", "Page"}, "cost")
+	if err != nil || path != nil {
+		t.Error("Unexpected result:", path, err)
+		return
+	}
+
+	path, _, _, err = g.ShortestPath(NodeID{"x", "Page"}, NodeID{"a", "Page"}, "cost")
+	if err != nil || path != nil {
+		t.Error("Unexpected result:", path, err)
+		return
+	}
+
+	// Test error cases
+
+	if _, _, _, err = g.ShortestPath(NodeID{"a", "Page"}, NodeID{"d", "Page"}, "foo"); err == nil ||
+		err.Error() != "GraphError: Invalid data (Edge 0 of kind Link has no non-negative numeric weight in attribute foo: <nil>)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+}
+
+func TestComponentsAndCommunities(t *testing.T) {
+	gm := linkGraph()
+
+	g, _ := NewGraph(gm, "main", linkSpec, nil)
+
+	labels, err := g.ConnectedComponents()
+	if err != nil || fmt.Sprint(labels.Groups()) != "[[Page:a Page:b Page:c Page:d] [Page:e Page:f] [Page:g]]" {
+		t.Error("Unexpected result:", labels, err)
+		return
+	}
+
+	labels, err = g.LabelPropagation(100)
+	if err != nil || fmt.Sprint(labels.Groups()) != "[[Page:a Page:b Page:c Page:d] [Page:e Page:f] [Page:g]]" {
+		t.Error("Unexpected result:", labels, err)
+		return
+	}
+
+	// Without iterations every node stays in its own community
+
+	labels, err = g.LabelPropagation(0)
+	if err != nil || len(labels.Groups()) != 7 {
+		t.Error("Unexpected result:", labels, err)
+		return
+	}
+}
+
+func TestCentrality(t *testing.T) {
+	gm := linkGraph()
+
+	g, _ := NewGraph(gm, "main", linkSpec, nil)
+
+	scores, err := g.DegreeCentrality()
+	if err != nil || fmt.Sprint(scores.Top(3)) != "[Page:a Page:c Page:b]" ||
+		scores[NodeID{"a", "Page"}] != 0.5 || scores[NodeID{"g", "Page"}] != 0 {
+		t.Error("Unexpected result:", scores, err)
+		return
+	}
+
+	scores, err = g.PageRank(0.85, 100)
+	if err != nil || fmt.Sprint(scores.Top(-1)) != "[Page:c Page:d Page:a Page:b Page:f Page:e Page:g]" {
+		t.Error("Unexpected result:", scores.Top(-1), scores, err)
+		return
+	}
+
+	sum := 0.0
+	for _, score := range scores {
+		sum += score
+	}
+
+	if math.Abs(sum-1) > 1e-9 {
+		t.Error("Unexpected sum of scores:", sum)
+		return
+	}
+
+	// Without damping all nodes have the same score
+
+	scores, _ = g.PageRank(0, 100)
+	if score := scores[NodeID{"c", "Page"}]; math.Abs(score-1.0/7) > 1e-9 {
+		t.Error("Unexpected result:", scores)
+		return
+	}
+
+	if _, err := g.PageRank(2, 100); err == nil || err.Error() != "GraphError: Invalid data (Damping factor must be between 0 and 1: 2)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	// An empty graph
+
+	g, _ = NewGraph(graph.NewGraphManager(graphstorage.NewMemoryGraphStorage("mystorage")), "main", "", nil)
+
+	if scores, err = g.DegreeCentrality(); err != nil || len(scores) != 0 {
+		t.Error("Unexpected result:", scores, err)
+		return
+	}
+}
+
+func TestPersonalizedPageRank(t *testing.T) {
+	gm := linkGraph()
+
+	g, _ := NewGraph(gm, "main", linkSpec, nil)
+
+	scores, paths, err := g.PersonalizedPageRank([]NodeID{{"a", "Page"}}, 0.85, 100, 0)
+	if err != nil || fmt.Sprint(scores.Top(-1)) != "[Page:a Page:c Page:d Page:b]" {
+		t.Error("Unexpected result:", scores, err)
+		return
+	}
+
+	if res := fmt.Sprint(*paths[NodeID{"d", "Page"}]); res != "{[Page:a Page:c Page:d] [Link:2 Link:3]}" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := fmt.Sprint(*paths[NodeID{"a", "Page"}]); res != "{[Page:a] []}" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	sum := 0.0
+	for _, score := range scores {
+		sum += score
+	}
+
+	if math.Abs(sum-1) > 1e-9 {
+		t.Error("Unexpected sum of scores:", sum)
+		return
+	}
+
+	// Limit the distance from the seed nodes
+
+	scores, paths, err = g.PersonalizedPageRank([]NodeID{{"a", "Page"}, {"x", "Page"}, {"a", "Page"}}, 0.85, 100, 1)
+	if err != nil || fmt.Sprint(scores.Top(-1)) != "[Page:a Page:b Page:c]" || len(paths) != 3 {
+		t.Error("Unexpected result:", scores, paths, err)
+		return
+	}
+
+	// Only follow edges of certain kinds
+
+	g.SetEdgeKinds([]string{"Other"})
+
+	scores, _, err = g.PersonalizedPageRank([]NodeID{{"e", "Page"}}, 0.85, 100, 0)
+	if err != nil || fmt.Sprint(scores) != "map[Page:e:1]" {
+		t.Error("Unexpected result:", scores, err)
+		return
+	}
+
+	g.SetEdgeKinds(nil)
+
+	scores, _, err = g.PersonalizedPageRank([]NodeID{{"e", "Page"}}, 0.85, 100, 0)
+	if err != nil || fmt.Sprint(scores.Top(-1)) != "[Page:e Page:f]" {
+		t.Error("Unexpected result:", scores, err)
+		return
+	}
+
+	// Unknown seed nodes reach nothing
+
+	scores, paths, err = g.PersonalizedPageRank([]NodeID{{"x", "Page"}}, 0.85, 100, 0)
+	if err != nil || len(scores) != 0 || len(paths) != 0 {
+		t.Error("Unexpected result:", scores, paths, err)
+		return
+	}
+
+	if _, _, err := g.PersonalizedPageRank(nil, -1, 100, 0); err == nil ||
+		err.Error() != "GraphError: Invalid data (Damping factor must be between 0 and 1: -1)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+}
@@ -0,0 +1,109 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package algo
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Fisch-Labs/FishDB/graph/util"
+)
+
+/*
+PageRankTolerance is the maximal total change of all scores between two
+iterations at which the PageRank computation stops.
+*/
+var PageRankTolerance = 1e-9
+
+/*
+PageRank computes the PageRank of all nodes. Edges are followed from a node
+using the traversal spec of the graph. The damping factor is the probability
+of following an edge (usually 0.85) - the random surfer jumps to a random node
+otherwise. The computation stops after a maximal number of iterations or when
+the scores do not change anymore. The scores of all nodes add up to 1.
+*/
+func (g *Graph) PageRank(damping float64, iterations int) (Scores, error) {
+
+	if damping < 0 || damping > 1 {
+		return nil, &util.GraphError{Type: util.ErrInvalidData,
+			Detail: fmt.Sprintf("Damping factor must be between 0 and 1: %v", damping)}
+	}
+
+	adj, err := g.adjacency()
+	if err != nil {
+		return nil, err
+	}
+
+	n := float64(len(adj.nodes))
+	scores := make(Scores)
+
+	for _, node := range adj.nodes {
+		scores[node] = 1 / n
+	}
+
+	for i := 0; i < iterations; i++ {
+		next := make(Scores)
+
+		// Scores of nodes without outgoing edges are distributed to all nodes
+
+		dangling := 0.0
+
+		for _, node := range adj.nodes {
+			if len(adj.directed[node]) == 0 {
+				dangling += scores[node]
+			}
+		}
+
+		for _, node := range adj.nodes {
+			next[node] += (1-damping)/n + damping*dangling/n
+
+			for _, target := range adj.directed[node] {
+				next[target] += damping * scores[node] / float64(len(adj.directed[node]))
+			}
+		}
+
+		change := 0.0
+
+		for _, node := range adj.nodes {
+			change += math.Abs(next[node] - scores[node])
+		}
+
+		scores = next
+
+		if change < PageRankTolerance {
+			break
+		}
+	}
+
+	return scores, nil
+}
+
+/*
+DegreeCentrality computes the degree centrality of all nodes. The degree
+centrality is the number of edges of a node (in any direction) divided by the
+number of other nodes in the graph.
+*/
+func (g *Graph) DegreeCentrality() (Scores, error) {
+
+	adj, err := g.adjacency()
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(Scores)
+
+	for _, node := range adj.nodes {
+		scores[node] = 0
+
+		if len(adj.nodes) > 1 {
+			scores[node] = float64(adj.edges[node]) / float64(len(adj.nodes)-1)
+		}
+	}
+
+	return scores, nil
+}
@@ -0,0 +1,111 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package algo
+
+/*
+ConnectedComponents computes the weakly connected components of the graph
+(edges are considered in any direction). Components are numbered from 0 in
+the order of their smallest node.
+*/
+func (g *Graph) ConnectedComponents() (Labels, error) {
+
+	adj, err := g.adjacency()
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make(map[NodeID]int)
+
+	for i, node := range adj.nodes {
+		if _, ok := labels[node]; ok {
+			continue
+		}
+
+		labels[node] = i
+		queue := []NodeID{node}
+
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+
+			for _, nb := range adj.undirected[cur] {
+				if _, ok := labels[nb]; !ok {
+					labels[nb] = i
+					queue = append(queue, nb)
+				}
+			}
+		}
+	}
+
+	return numberLabels(adj.nodes, labels), nil
+}
+
+/*
+LabelPropagation detects communities using label propagation. Every node
+starts in its own community and then repeatedly joins the community which is
+most common among its neighbours (edges are considered in any direction). A
+node keeps its community on a tie if it is one of the most common ones -
+otherwise the tie is broken by the order of the nodes. The computation stops
+after a maximal number of iterations or when no node changes its community
+anymore. Communities are numbered from 0 in the order of their smallest node.
+*/
+func (g *Graph) LabelPropagation(iterations int) (Labels, error) {
+
+	adj, err := g.adjacency()
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make(map[NodeID]int)
+
+	for i, node := range adj.nodes {
+		labels[node] = i
+	}
+
+	for i := 0; i < iterations; i++ {
+		changed := false
+
+		for _, node := range adj.nodes {
+			counts := make(map[int]int)
+			maxCount := 0
+
+			for _, nb := range adj.undirected[node] {
+				if nb != node {
+					counts[labels[nb]]++
+
+					if counts[labels[nb]] > maxCount {
+						maxCount = counts[labels[nb]]
+					}
+				}
+			}
+
+			best := labels[node]
+
+			if counts[best] < maxCount {
+				best = len(adj.nodes)
+
+				for label, count := range counts {
+					if count == maxCount && label < best {
+						best = label
+					}
+				}
+			}
+
+			if best != labels[node] {
+				labels[node] = best
+				changed = true
+			}
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	return numberLabels(adj.nodes, labels), nil
+}
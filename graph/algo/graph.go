@@ -0,0 +1,279 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+/*
+Package algo contains graph algorithms which operate on a partition of a
+graph.Manager.
+
+The algorithms see the graph through a Graph object which defines the
+partition, the node kinds and a traversal spec. Edges are followed from a
+node using the traversal spec (e.g. ":::" follows all edges while
+"Dependent:DependsOn:Dependency:Pkg" only follows edges in one direction).
+Nodes of kinds which are not part of the graph are ignored. The followed edges
+can be further restricted to a set of edge kinds.
+
+Available algorithms are:
+
+BFS / DFS - Breadth-first and depth-first iterators from a start node.
+
+ShortestPath - Weighted shortest path between two nodes (Dijkstra) using
+a numeric edge attribute.
+
+ConnectedComponents - Weakly connected components of the graph.
+
+PageRank - Importance ranking of all nodes.
+
+PersonalizedPageRank - Importance ranking of the nodes around a set of seed
+nodes (random walk with restart) together with the paths which led to them.
+
+DegreeCentrality - Number of edges of a node normalized by the maximal
+possible number of edges.
+
+LabelPropagation - Community detection by label propagation.
+
+Subgraph - Induced subgraph around a set of seed nodes which can be written
+as JSON or in a compact text format (triples or markdown) within a token
+budget.
+
+Scores and labels can be written back as node attributes using a graph
+transaction.
+*/
+package algo
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Fisch-Labs/FishDB/graph"
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+)
+
+/*
+NodeID identifies a node in a partition.
+*/
+type NodeID struct {
+	Key  string // Node key
+	Kind string // Node kind
+}
+
+/*
+String returns a string representation of a node id.
+*/
+func (n NodeID) String() string {
+	return n.Kind + ":" + n.Key
+}
+
+/*
+less compares two node ids by kind and key.
+*/
+func (n NodeID) less(o NodeID) bool {
+	if n.Kind != o.Kind {
+		return n.Kind < o.Kind
+	}
+	return n.Key < o.Key
+}
+
+/*
+sortNodeIDs sorts a list of node ids by kind and key.
+*/
+func sortNodeIDs(nodes []NodeID) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].less(nodes[j])
+	})
+}
+
+/*
+Graph is the view of the algorithms on a partition of a graph.Manager.
+*/
+type Graph struct {
+	gm    *graph.Manager  // GraphManager which holds the data
+	part  string          // Partition of the graph
+	spec  string          // Traversal spec which is used to find neighbours
+	kinds map[string]bool // Node kinds which are part of the graph
+	edges map[string]bool // Edge kinds which are followed (all if empty)
+}
+
+/*
+NewGraph creates a new graph view on a partition. The traversal spec defaults
+to ":::" (all edges). The graph contains all node kinds if the given kinds are
+nil.
+*/
+func NewGraph(gm *graph.Manager, part string, spec string, kinds []string) (*Graph, error) {
+
+	if spec == "" {
+		spec = ":::"
+	}
+
+	if len(strings.Split(spec, ":")) != 4 {
+		return nil, &util.GraphError{Type: util.ErrInvalidData, Detail: "Invalid spec: " + spec}
+	}
+
+	if kinds == nil {
+		kinds = gm.NodeKinds()
+	}
+
+	kindMap := make(map[string]bool)
+	for _, kind := range kinds {
+		kindMap[kind] = true
+	}
+
+	return &Graph{gm, part, spec, kindMap, nil}, nil
+}
+
+/*
+SetEdgeKinds restricts the edges which are followed to the given edge kinds.
+All edges matching the traversal spec are followed if the given kinds are nil.
+*/
+func (g *Graph) SetEdgeKinds(kinds []string) {
+	g.edges = nil
+
+	if kinds != nil {
+		g.edges = make(map[string]bool)
+		for _, kind := range kinds {
+			g.edges[kind] = true
+		}
+	}
+}
+
+/*
+Nodes returns all nodes of the graph sorted by kind and key.
+*/
+func (g *Graph) Nodes() ([]NodeID, error) {
+	var kinds []string
+	var nodes []NodeID
+
+	for kind := range g.kinds {
+		kinds = append(kinds, kind)
+	}
+
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		it, err := g.gm.NodeKeyIterator(g.part, kind)
+		if err != nil {
+			return nil, err
+		} else if it == nil {
+			continue
+		}
+
+		for it.HasNext() {
+			key := it.Next()
+
+			if err := it.Error(); err != nil {
+				return nil, err
+			} else if key != "" {
+				nodes = append(nodes, NodeID{key, kind})
+			}
+		}
+	}
+
+	sortNodeIDs(nodes)
+
+	return nodes, nil
+}
+
+/*
+Exists checks if a node is part of the graph.
+*/
+func (g *Graph) Exists(n NodeID) (bool, error) {
+	if !g.kinds[n.Kind] {
+		return false, nil
+	}
+
+	node, err := g.gm.FetchNodePart(g.part, n.Key, n.Kind, []string{data.NodeKey})
+
+	return node != nil, err
+}
+
+/*
+neighbour is a node which can be reached from another node with an edge.
+*/
+type neighbour struct {
+	node NodeID
+	edge data.Edge
+}
+
+/*
+neighbours returns all neighbours of a node sorted by kind and key. The
+returned edges contain all edge attributes if allData is set.
+*/
+func (g *Graph) neighbours(n NodeID, allData bool) ([]neighbour, error) {
+	var res []neighbour
+
+	nodes, edges, err := g.gm.TraverseMulti(g.part, n.Key, n.Kind, g.spec, allData)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, node := range nodes {
+		if g.kinds[node.Kind()] && (g.edges == nil || g.edges[edges[i].Kind()]) {
+			res = append(res, neighbour{NodeID{node.Key(), node.Kind()}, edges[i]})
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].node.less(res[j].node)
+	})
+
+	return res, nil
+}
+
+/*
+adjacency holds the edges between all nodes of a graph.
+*/
+type adjacency struct {
+	nodes      []NodeID            // All nodes sorted by kind and key
+	directed   map[NodeID][]NodeID // Neighbours which can be reached via the traversal spec
+	undirected map[NodeID][]NodeID // Neighbours which are connected in any direction
+	edges      map[NodeID]int      // Number of distinct edges of each node
+}
+
+/*
+adjacency loads the edges between all nodes of the graph.
+*/
+func (g *Graph) adjacency() (*adjacency, error) {
+
+	nodes, err := g.Nodes()
+	if err != nil {
+		return nil, err
+	}
+
+	adj := &adjacency{nodes, make(map[NodeID][]NodeID),
+		make(map[NodeID][]NodeID), make(map[NodeID]int)}
+
+	seen := make(map[string]bool)
+
+	for _, n := range nodes {
+		nbs, err := g.neighbours(n, false)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, nb := range nbs {
+			adj.directed[n] = append(adj.directed[n], nb.node)
+
+			// Every edge is only counted once for the undirected view
+
+			ekey := nb.edge.Kind() + ":" + nb.edge.Key()
+
+			if !seen[ekey] {
+				seen[ekey] = true
+
+				adj.undirected[n] = append(adj.undirected[n], nb.node)
+				adj.edges[n]++
+
+				if nb.node != n {
+					adj.undirected[nb.node] = append(adj.undirected[nb.node], n)
+					adj.edges[nb.node]++
+				}
+			}
+		}
+	}
+
+	return adj, nil
+}
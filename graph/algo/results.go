@@ -0,0 +1,129 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package algo
+
+import (
+	"sort"
+
+	"github.com/Fisch-Labs/FishDB/graph"
+	"github.com/Fisch-Labs/FishDB/graph/data"
+)
+
+/*
+Scores assigns every node of a graph a numeric score.
+*/
+type Scores map[NodeID]float64
+
+/*
+Top returns the k nodes with the highest scores (all nodes if k is negative).
+Nodes with equal scores are sorted by kind and key.
+*/
+func (s Scores) Top(k int) []NodeID {
+	res := make([]NodeID, 0, len(s))
+
+	for n := range s {
+		res = append(res, n)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if s[res[i]] != s[res[j]] {
+			return s[res[i]] > s[res[j]]
+		}
+		return res[i].less(res[j])
+	})
+
+	if k >= 0 && k < len(res) {
+		res = res[:k]
+	}
+
+	return res
+}
+
+/*
+Write stores all scores as a node attribute in a given transaction.
+*/
+func (s Scores) Write(trans graph.Trans, part string, attr string) error {
+	for _, n := range s.Top(-1) {
+		if err := writeAttr(trans, part, n, attr, s[n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+Labels assigns every node of a graph a group number (e.g. a component or a
+community). Groups are numbered from 0 in the order of their smallest node.
+*/
+type Labels map[NodeID]int
+
+/*
+Groups returns the nodes of all groups. Nodes are sorted by kind and key.
+*/
+func (l Labels) Groups() [][]NodeID {
+	var res [][]NodeID
+
+	for n, label := range l {
+		for len(res) <= label {
+			res = append(res, nil)
+		}
+		res[label] = append(res[label], n)
+	}
+
+	for _, group := range res {
+		sortNodeIDs(group)
+	}
+
+	return res
+}
+
+/*
+Write stores all labels as a node attribute in a given transaction.
+*/
+func (l Labels) Write(trans graph.Trans, part string, attr string) error {
+	for _, group := range l.Groups() {
+		for _, n := range group {
+			if err := writeAttr(trans, part, n, attr, l[n]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+/*
+writeAttr updates a single attribute of a node in a given transaction.
+*/
+func writeAttr(trans graph.Trans, part string, n NodeID, attr string, val interface{}) error {
+	node := data.NewGraphNode()
+
+	node.SetAttr(data.NodeKey, n.Key)
+	node.SetAttr(data.NodeKind, n.Kind)
+	node.SetAttr(attr, val)
+
+	return trans.UpdateNode(part, node)
+}
+
+/*
+numberLabels renumbers labels from 0 in the order of the given nodes.
+*/
+func numberLabels(nodes []NodeID, labels map[NodeID]int) Labels {
+	res := make(Labels)
+	mapping := make(map[int]int)
+
+	for _, n := range nodes {
+		label, ok := mapping[labels[n]]
+		if !ok {
+			label = len(mapping)
+			mapping[labels[n]] = label
+		}
+		res[n] = label
+	}
+
+	return res
+}
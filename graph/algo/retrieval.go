@@ -0,0 +1,166 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package algo
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Fisch-Labs/FishDB/graph/util"
+)
+
+/*
+Path is a path from a seed node to another node.
+*/
+type Path struct {
+	Nodes []NodeID // Nodes of the path starting with the seed node
+	Edges []NodeID // Keys and kinds of the edges between the nodes
+}
+
+/*
+PersonalizedPageRank computes the personalized PageRank (random walk with
+restart) of all nodes which can be reached from a set of seed nodes. Edges are
+followed from a node using the traversal spec of the graph. The damping factor
+is the probability of following an edge - the random walk restarts at one of
+the seed nodes otherwise. Only nodes up to a maximal distance from the seed
+nodes are considered (no limit if maxDepth is 0). The computation stops after
+a maximal number of iterations or when the scores do not change anymore.
+Returns the scores of all reached nodes (including the seed nodes) and the
+shortest path from a seed node to every reached node. Seed nodes which do not
+exist are ignored.
+*/
+func (g *Graph) PersonalizedPageRank(seeds []NodeID, damping float64, iterations int,
+	maxDepth int) (Scores, map[NodeID]*Path, error) {
+
+	if damping < 0 || damping > 1 {
+		return nil, nil, &util.GraphError{Type: util.ErrInvalidData,
+			Detail: fmt.Sprintf("Damping factor must be between 0 and 1: %v", damping)}
+	}
+
+	// Collect all nodes which can be reached from the seed nodes
+
+	var nodes []NodeID
+
+	paths := make(map[NodeID]*Path)
+	depth := make(map[NodeID]int)
+	out := make(map[NodeID][]NodeID)
+
+	for _, seed := range seeds {
+		if _, ok := paths[seed]; ok {
+			continue
+		}
+
+		ok, err := g.Exists(seed)
+		if err != nil {
+			return nil, nil, err
+		} else if ok {
+			nodes = append(nodes, seed)
+			paths[seed] = &Path{[]NodeID{seed}, nil}
+		}
+	}
+
+	restart := 1 / float64(len(nodes))
+	seedCount := len(nodes)
+
+	for i := 0; i < len(nodes); i++ {
+		n := nodes[i]
+
+		if maxDepth > 0 && depth[n] >= maxDepth {
+			continue
+		}
+
+		nbs, err := g.neighbours(n, false)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, nb := range nbs {
+			out[n] = append(out[n], nb.node)
+
+			if _, ok := paths[nb.node]; !ok {
+				path := paths[n]
+
+				paths[nb.node] = &Path{
+					append(append([]NodeID{}, path.Nodes...), nb.node),
+					append(append([]NodeID{}, path.Edges...), NodeID{nb.edge.Key(), nb.edge.Kind()}),
+				}
+
+				depth[nb.node] = depth[n] + 1
+				nodes = append(nodes, nb.node)
+			}
+		}
+	}
+
+	// Edges to nodes beyond the maximal depth are not followed
+
+	for n, targets := range out {
+		var reached []NodeID
+
+		for _, target := range targets {
+			if maxDepth == 0 || depth[target] <= maxDepth {
+				reached = append(reached, target)
+			}
+		}
+
+		out[n] = reached
+	}
+
+	// Run the random walk - it starts at the seed nodes
+
+	scores := make(Scores)
+
+	for _, n := range nodes[:seedCount] {
+		scores[n] = restart
+	}
+
+	for i := 0; i < iterations; i++ {
+		next := make(Scores)
+
+		// Scores of nodes without outgoing edges go back to the seed nodes
+
+		dangling := 0.0
+
+		for _, n := range nodes {
+			if len(out[n]) == 0 {
+				dangling += scores[n]
+			}
+		}
+
+		for _, n := range nodes[:seedCount] {
+			next[n] += ((1 - damping) + damping*dangling) * restart
+		}
+
+		for _, n := range nodes {
+			for _, target := range out[n] {
+				next[target] += damping * scores[n] / float64(len(out[n]))
+			}
+		}
+
+		change := 0.0
+
+		for _, n := range nodes {
+			change += math.Abs(next[n] - scores[n])
+		}
+
+		scores = next
+
+		if change < PageRankTolerance {
+			break
+		}
+	}
+
+	// Every reached node has a score
+
+	for _, n := range nodes {
+		if _, ok := scores[n]; !ok {
+			scores[n] = 0
+		}
+	}
+
+	return scores, paths, nil
+}
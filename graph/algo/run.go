@@ -0,0 +1,318 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package algo
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Fisch-Labs/FishDB/graph"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+)
+
+/*
+Algorithms is a map of all algorithms which can be run by name to a short
+description of their parameters.
+*/
+var Algorithms = map[string]string{
+	"bfs":                  "Breadth-first traversal (key, kind)",
+	"dfs":                  "Depth-first traversal (key, kind)",
+	"shortestpath":         "Weighted shortest path (key, kind, tokey, tokind, weight)",
+	"components":           "Connected components (attr)",
+	"pagerank":             "PageRank (damping, iterations, top, attr)",
+	"personalizedpagerank": "Personalized PageRank from seed nodes (seeds, damping, iterations, maxdepth, top, attr)",
+	"degree":               "Degree centrality (top, attr)",
+	"communities":          "Label propagation communities (iterations, attr)",
+}
+
+/*
+Run runs an algorithm by name on a partition. All algorithms accept the
+parameters spec (traversal spec), kinds (comma separated list of node kinds)
+and edgekinds (comma separated list of edge kinds) which define the graph.
+Returns a list of rows - every row contains the key and kind of a node and
+the result of the algorithm for the node (depth, distance, score or label).
+The seed nodes of the personalized PageRank are given as a comma separated
+list of kind:key pairs. Its rows contain only the reached nodes (not the seed
+nodes) together with the path which led to them. The results of algorithms
+which produce scores or labels are written to a node attribute if the attr
+parameter is given. The changes are added to the given transaction which must
+be committed by the caller.
+*/
+func Run(gm *graph.Manager, part string, name string, params map[string]string,
+	trans graph.Trans) ([]map[string]interface{}, error) {
+
+	var res []map[string]interface{}
+	var scores Scores
+	var labels Labels
+	var paths map[NodeID]*Path
+	var kinds []string
+
+	if _, ok := Algorithms[name]; !ok {
+		return nil, &util.GraphError{Type: util.ErrInvalidData, Detail: "Unknown algorithm: " + name}
+	}
+
+	if params["kinds"] != "" {
+		kinds = strings.Split(params["kinds"], ",")
+	}
+
+	g, err := NewGraph(gm, part, params["spec"], kinds)
+
+	if err == nil {
+		if params["edgekinds"] != "" {
+			g.SetEdgeKinds(strings.Split(params["edgekinds"], ","))
+		}
+
+		switch name {
+
+		case "bfs", "dfs":
+			var it *Iterator
+
+			start := NodeID{params["key"], params["kind"]}
+
+			if err = requireParams(params, "key", "kind"); err == nil {
+				if name == "bfs" {
+					it = g.BFS(start)
+				} else {
+					it = g.DFS(start)
+				}
+
+				for it.HasNext() {
+					n, depth := it.Next()
+
+					if err = it.Error(); err == nil {
+						res = append(res, newRow(n, "depth", float64(depth)))
+					}
+				}
+			}
+
+		case "shortestpath":
+			var path []NodeID
+			var dists []float64
+
+			if err = requireParams(params, "key", "kind", "tokey", "tokind"); err == nil {
+				path, dists, _, err = g.ShortestPath(NodeID{params["key"], params["kind"]},
+					NodeID{params["tokey"], params["tokind"]}, params["weight"])
+
+				for i, n := range path {
+					res = append(res, newRow(n, "distance", dists[i]))
+				}
+			}
+
+		case "components":
+			labels, err = g.ConnectedComponents()
+
+		case "pagerank":
+			var damping float64
+			var iterations int
+
+			if damping, err = floatParam(params, "damping", 0.85); err == nil {
+				if iterations, err = intParam(params, "iterations", 100); err == nil {
+					scores, err = g.PageRank(damping, iterations)
+				}
+			}
+
+		case "personalizedpagerank":
+			var seeds []NodeID
+			var damping float64
+			var iterations, maxDepth int
+
+			if err = requireParams(params, "seeds"); err == nil {
+				seeds, err = ParseNodeIDs(params["seeds"])
+			}
+
+			if err == nil {
+				if damping, err = floatParam(params, "damping", 0.85); err == nil {
+					if iterations, err = intParam(params, "iterations", 100); err == nil {
+						if maxDepth, err = intParam(params, "maxdepth", 0); err == nil {
+							scores, paths, err = g.PersonalizedPageRank(seeds, damping, iterations, maxDepth)
+						}
+					}
+				}
+			}
+
+			for _, seed := range seeds {
+				delete(scores, seed)
+			}
+
+		case "degree":
+			scores, err = g.DegreeCentrality()
+
+		case "communities":
+			var iterations int
+
+			if iterations, err = intParam(params, "iterations", 100); err == nil {
+				labels, err = g.LabelPropagation(iterations)
+			}
+		}
+	}
+
+	if err == nil && scores != nil {
+		var top int
+
+		if top, err = intParam(params, "top", -1); err == nil {
+			for _, n := range scores.Top(top) {
+				row := newRow(n, "score", scores[n])
+
+				if path, ok := paths[n]; ok {
+					row["path"] = nodeList(path.Nodes)
+					row["edges"] = nodeList(path.Edges)
+				}
+
+				res = append(res, row)
+			}
+		}
+	}
+
+	if err == nil && labels != nil {
+		var nodes []NodeID
+
+		for n := range labels {
+			nodes = append(nodes, n)
+		}
+
+		sortNodeIDs(nodes)
+
+		for _, n := range nodes {
+			res = append(res, newRow(n, "label", float64(labels[n])))
+		}
+	}
+
+	// Write the results if requested
+
+	if attr := params["attr"]; err == nil && attr != "" {
+
+		if scores == nil && labels == nil {
+			err = &util.GraphError{Type: util.ErrInvalidData,
+				Detail: "Results of algorithm " + name + " cannot be written to nodes"}
+		} else if trans == nil {
+			err = &util.GraphError{Type: util.ErrInvalidData,
+				Detail: "Writing results requires a transaction"}
+		} else if scores != nil {
+			err = scores.Write(trans, part, attr)
+		} else {
+			err = labels.Write(trans, part, attr)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if res == nil {
+		res = make([]map[string]interface{}, 0)
+	}
+
+	return res, nil
+}
+
+/*
+AlgorithmNames returns the names of all algorithms in alphabetical order.
+*/
+func AlgorithmNames() []string {
+	var names []string
+
+	for name := range Algorithms {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+/*
+ParseNodeIDs parses a comma separated list of kind:key pairs.
+*/
+func ParseNodeIDs(s string) ([]NodeID, error) {
+	var res []NodeID
+
+	for _, n := range strings.Split(s, ",") {
+		sn := strings.SplitN(n, ":", 2)
+
+		if len(sn) != 2 {
+			return nil, &util.GraphError{Type: util.ErrInvalidData,
+				Detail: "Node must be given as kind:key: " + n}
+		}
+
+		res = append(res, NodeID{sn[1], sn[0]})
+	}
+
+	return res, nil
+}
+
+/*
+newRow creates a new result row for a node.
+*/
+func newRow(n NodeID, name string, val interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"key":  n.Key,
+		"kind": n.Kind,
+		name:   val,
+	}
+}
+
+/*
+nodeList converts a list of node ids into a list of maps with key and kind.
+*/
+func nodeList(nodes []NodeID) []interface{} {
+	res := make([]interface{}, len(nodes))
+
+	for i, n := range nodes {
+		res[i] = map[string]interface{}{
+			"key":  n.Key,
+			"kind": n.Kind,
+		}
+	}
+
+	return res
+}
+
+/*
+requireParams checks that all given parameters have a value.
+*/
+func requireParams(params map[string]string, names ...string) error {
+	for _, name := range names {
+		if params[name] == "" {
+			return &util.GraphError{Type: util.ErrInvalidData,
+				Detail: fmt.Sprintf("Parameter %v is required (%v)", name, strings.Join(names, ", "))}
+		}
+	}
+	return nil
+}
+
+/*
+floatParam returns a numeric parameter or a default value.
+*/
+func floatParam(params map[string]string, name string, def float64) (float64, error) {
+	if val, ok := params[name]; ok && val != "" {
+		res, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return 0, &util.GraphError{Type: util.ErrInvalidData,
+				Detail: fmt.Sprintf("Parameter %v must be a number: %v", name, val)}
+		}
+		return res, nil
+	}
+	return def, nil
+}
+
+/*
+intParam returns an integer parameter or a default value.
+*/
+func intParam(params map[string]string, name string, def int) (int, error) {
+	if val, ok := params[name]; ok && val != "" {
+		res, err := strconv.Atoi(val)
+		if err != nil {
+			return 0, &util.GraphError{Type: util.ErrInvalidData,
+				Detail: fmt.Sprintf("Parameter %v must be an integer number: %v", name, val)}
+		}
+		return res, nil
+	}
+	return def, nil
+}
@@ -0,0 +1,123 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package algo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/graph"
+)
+
+func TestRun(t *testing.T) {
+	gm := linkGraph()
+
+	if res := AlgorithmNames(); fmt.Sprint(res) != "[bfs communities components degree dfs pagerank personalizedpagerank shortestpath]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res, err := Run(gm, "main", "bfs", map[string]string{"key": "e", "kind": "Page"}, nil); err != nil ||
+		fmt.Sprint(res) != "[map[depth:0 key:e kind:Page] map[depth:1 key:f kind:Page]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := Run(gm, "main", "dfs", map[string]string{"key": "x", "kind": "Page"}, nil); err != nil ||
+		fmt.Sprint(res) != "[]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := Run(gm, "main", "shortestpath", map[string]string{"key": "a", "kind": "Page",
+		"tokey": "c", "tokind": "Page", "weight": "cost", "spec": linkSpec}, nil); err != nil ||
+		fmt.Sprint(res) != "[map[distance:0 key:a kind:Page] map[distance:1 key:b kind:Page] map[distance:2 key:c kind:Page]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := Run(gm, "main", "pagerank", map[string]string{"spec": linkSpec, "top": "2"}, nil); err != nil ||
+		len(res) != 2 || res[0]["key"] != "c" || res[1]["key"] != "d" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := Run(gm, "main", "personalizedpagerank", map[string]string{"seeds": "Page:a,Page:b",
+		"spec": linkSpec, "edgekinds": "Link", "maxdepth": "2", "top": "1"}, nil); err != nil || len(res) != 1 ||
+		res[0]["key"] != "c" || fmt.Sprint(res[0]["path"]) != "[map[key:a kind:Page] map[key:c kind:Page]]" ||
+		fmt.Sprint(res[0]["edges"]) != "[map[key:2 kind:Link]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := Run(gm, "main", "degree", map[string]string{"kinds": "Page", "top": "1"}, nil); err != nil ||
+		fmt.Sprint(res) != "[map[key:a kind:Page score:0.5]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := Run(gm, "main", "communities", map[string]string{"iterations": "10"}, nil); err != nil ||
+		len(res) != 7 || fmt.Sprint(res[4]) != "map[key:e kind:Page label:1]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Write the results to the nodes
+
+	trans := graph.NewGraphTrans(gm)
+
+	if _, err := Run(gm, "main", "components", map[string]string{"attr": "component"}, trans); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err := Run(gm, "main", "pagerank", map[string]string{"attr": "rank", "top": "1"}, trans); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if node, err := gm.FetchNode("main", "f", "Page"); err != nil ||
+		node.Attr("component") != 1 || node.Attr("rank").(float64) <= 0 {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	// Test error cases
+
+	for _, test := range []struct {
+		name   string
+		params map[string]string
+		trans  graph.Trans
+		err    string
+	}{
+		{"foo", nil, nil, "GraphError: Invalid data (Unknown algorithm: foo)"},
+		{"bfs", map[string]string{"key": "a"}, nil, "GraphError: Invalid data (Parameter kind is required (key, kind))"},
+		{"bfs", map[string]string{"spec": "a:b"}, nil, "GraphError: Invalid data (Invalid spec: a:b)"},
+		{"personalizedpagerank", nil, nil, "GraphError: Invalid data (Parameter seeds is required (seeds))"},
+		{"personalizedpagerank", map[string]string{"seeds": "a"}, nil, "GraphError: Invalid data (Node must be given as kind:key: a)"},
+		{"personalizedpagerank", map[string]string{"seeds": "Page:a", "maxdepth": "x"}, nil,
+			"GraphError: Invalid data (Parameter maxdepth must be an integer number: x)"},
+		{"pagerank", map[string]string{"damping": "x"}, nil, "GraphError: Invalid data (Parameter damping must be a number: x)"},
+		{"pagerank", map[string]string{"iterations": "x"}, nil, "GraphError: Invalid data (Parameter iterations must be an integer number: x)"},
+		{"communities", map[string]string{"iterations": "x"}, nil, "GraphError: Invalid data (Parameter iterations must be an integer number: x)"},
+		{"degree", map[string]string{"top": "x"}, nil, "GraphError: Invalid data (Parameter top must be an integer number: x)"},
+		{"degree", map[string]string{"attr": "x"}, nil, "GraphError: Invalid data (Writing results requires a transaction)"},
+		{"bfs", map[string]string{"key": "a", "kind": "Page", "attr": "x"}, trans,
+			"GraphError: Invalid data (Results of algorithm bfs cannot be written to nodes)"},
+	} {
+		if _, err := Run(gm, "main", test.name, test.params, test.trans); err == nil || err.Error() != test.err {
+			t.Error("Unexpected result:", test.name, err)
+			return
+		}
+	}
+}
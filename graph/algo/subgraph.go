@@ -0,0 +1,370 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package algo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+)
+
+/*
+Known subgraph formats
+*/
+const (
+	FormatJSON     = "json"     // Nodes and edges as JSON objects
+	FormatTriples  = "triples"  // One subject predicate object triple per line
+	FormatMarkdown = "markdown" // One markdown section per node
+)
+
+/*
+Subgraph is a part of a graph which was extracted around a set of seed nodes.
+*/
+type Subgraph struct {
+	Nodes     []data.Node // Nodes ordered by their distance from the seed nodes
+	Edges     []data.Edge // All edges between the nodes
+	Truncated bool        // Flag if the subgraph was truncated
+}
+
+/*
+Subgraph extracts the induced subgraph of all nodes which can be reached from
+a set of seed nodes within a maximal number of hops. The subgraph contains
+all edges between its nodes which match the traversal spec and the edge kinds
+of the graph. Seed nodes which do not exist are ignored.
+*/
+func (g *Graph) Subgraph(seeds []NodeID, hops int) (*Subgraph, error) {
+	var ids []NodeID
+	var edgeIDs []NodeID
+
+	depth := make(map[NodeID]int)
+	seenEdges := make(map[NodeID]bool)
+
+	for _, seed := range seeds {
+		if _, ok := depth[seed]; ok {
+			continue
+		}
+
+		ok, err := g.Exists(seed)
+		if err != nil {
+			return nil, err
+		} else if ok {
+			ids = append(ids, seed)
+			depth[seed] = 0
+		}
+	}
+
+	// Collect the nodes and all edges which were seen on the way
+
+	for i := 0; i < len(ids); i++ {
+		n := ids[i]
+
+		nbs, err := g.neighbours(n, false)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, nb := range nbs {
+			eid := NodeID{nb.edge.Key(), nb.edge.Kind()}
+
+			if _, ok := depth[nb.node]; !ok && depth[n] < hops {
+				ids = append(ids, nb.node)
+				depth[nb.node] = depth[n] + 1
+			}
+
+			if !seenEdges[eid] {
+				seenEdges[eid] = true
+				edgeIDs = append(edgeIDs, eid)
+			}
+		}
+	}
+
+	sg := &Subgraph{}
+
+	// Revisions are not part of the subgraph
+
+	for _, n := range ids {
+		node, err := g.gm.FetchNode(g.part, n.Key, n.Kind)
+		if err != nil {
+			return nil, err
+		} else if node != nil {
+			node.SetAttr(data.NodeRevision, nil)
+			sg.Nodes = append(sg.Nodes, node)
+		}
+	}
+
+	// Only keep edges where both ends are part of the subgraph
+
+	for _, eid := range edgeIDs {
+		edge, err := g.gm.FetchEdge(g.part, eid.Key, eid.Kind)
+		if err != nil {
+			return nil, err
+		} else if edge == nil {
+			continue
+		}
+
+		edge.SetAttr(data.NodeRevision, nil)
+
+		_, ok1 := depth[NodeID{edge.End1Key(), edge.End1Kind()}]
+		_, ok2 := depth[NodeID{edge.End2Key(), edge.End2Kind()}]
+
+		if ok1 && ok2 {
+			sg.Edges = append(sg.Edges, edge)
+		}
+	}
+
+	return sg, nil
+}
+
+/*
+Limit returns a subgraph which fits into a given budget of tokens when it is
+written in a given format. Nodes are added in the order of the subgraph -
+edges are added with their last node. Returns the subgraph itself if the
+budget is not positive.
+*/
+func (sg *Subgraph) Limit(format string, maxTokens int) (*Subgraph, error) {
+
+	if err := checkFormat(format); err != nil || maxTokens <= 0 {
+		return sg, err
+	}
+
+	res := &Subgraph{}
+	tokens := 0
+
+	nodeEdges := sg.nodeEdges()
+
+	for i, node := range sg.Nodes {
+		edges := nodeEdges[i]
+		nodeTokens := EstimateTokens(formatNode(format, node, edges))
+
+		if tokens+nodeTokens > maxTokens {
+			res.Truncated = true
+			break
+		}
+
+		tokens += nodeTokens
+		res.Nodes = append(res.Nodes, node)
+		res.Edges = append(res.Edges, edges...)
+	}
+
+	return res, nil
+}
+
+/*
+Format writes the subgraph in a given format.
+*/
+func (sg *Subgraph) Format(format string) (string, error) {
+	var buf bytes.Buffer
+
+	if err := checkFormat(format); err != nil {
+		return "", err
+	}
+
+	if format == FormatJSON {
+		nodes := make([]map[string]interface{}, 0, len(sg.Nodes))
+		edges := make([]map[string]interface{}, 0, len(sg.Edges))
+
+		for _, node := range sg.Nodes {
+			nodes = append(nodes, node.Data())
+		}
+
+		for _, edge := range sg.Edges {
+			edges = append(edges, edge.Data())
+		}
+
+		res, err := json.MarshalIndent(map[string]interface{}{
+			"nodes":     nodes,
+			"edges":     edges,
+			"truncated": sg.Truncated,
+		}, "", "  ")
+
+		return string(res), err
+	}
+
+	nodeEdges := sg.nodeEdges()
+
+	for i, node := range sg.Nodes {
+		buf.WriteString(formatNode(format, node, nodeEdges[i]))
+	}
+
+	return buf.String(), nil
+}
+
+/*
+nodeEdges returns for each node of the subgraph all edges which connect the
+node with itself or the nodes before it.
+*/
+func (sg *Subgraph) nodeEdges() [][]data.Edge {
+	res := make([][]data.Edge, len(sg.Nodes))
+
+	pos := make(map[NodeID]int)
+
+	for i, node := range sg.Nodes {
+		pos[NodeID{node.Key(), node.Kind()}] = i
+	}
+
+	// Each edge belongs to the later of its two nodes
+
+	for _, edge := range sg.Edges {
+		pos1, ok1 := pos[NodeID{edge.End1Key(), edge.End1Kind()}]
+		pos2, ok2 := pos[NodeID{edge.End2Key(), edge.End2Kind()}]
+
+		if !ok1 || !ok2 {
+			continue
+		}
+
+		if pos2 > pos1 {
+			pos1 = pos2
+		}
+
+		res[pos1] = append(res[pos1], edge)
+	}
+
+	return res
+}
+
+/*
+EstimateTokens estimates the number of LLM tokens of a text (about 4
+characters per token).
+*/
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+/*
+checkFormat checks if a given subgraph format is known.
+*/
+func checkFormat(format string) error {
+	if format != FormatJSON && format != FormatTriples && format != FormatMarkdown {
+		return &util.GraphError{Type: util.ErrInvalidData, Detail: "Unknown subgraph format: " + format}
+	}
+	return nil
+}
+
+/*
+formatNode writes a node and a given list of its edges in a given format.
+*/
+func formatNode(format string, node data.Node, edges []data.Edge) string {
+	var buf bytes.Buffer
+
+	name := func(key string, kind string) string {
+		return kind + ":" + key
+	}
+
+	switch format {
+
+	case FormatJSON:
+		res, _ := json.Marshal(node.Data())
+		buf.Write(res)
+
+		for _, edge := range edges {
+			res, _ := json.Marshal(edge.Data())
+			buf.Write(res)
+		}
+
+	case FormatTriples:
+		attrs := nodeAttrs(node)
+
+		if len(attrs) == 0 {
+			fmt.Fprintf(&buf, "%v kind %v\n", name(node.Key(), node.Kind()), node.Kind())
+		}
+
+		for _, attr := range attrs {
+			fmt.Fprintf(&buf, "%v %v %v\n", name(node.Key(), node.Kind()), attr, formatValue(node.Attr(attr)))
+		}
+
+		for _, edge := range edges {
+			fmt.Fprintf(&buf, "%v %v %v\n", name(edge.End1Key(), edge.End1Kind()), edge.Kind(),
+				name(edge.End2Key(), edge.End2Kind()))
+		}
+
+	case FormatMarkdown:
+		fmt.Fprintf(&buf, "## %v %v\n", node.Kind(), node.Key())
+
+		for _, attr := range nodeAttrs(node) {
+			fmt.Fprintf(&buf, "- %v: %v\n", attr, formatValue(node.Attr(attr)))
+		}
+
+		for _, edge := range edges {
+			fmt.Fprintf(&buf, "- %v %v -[%v]-> %v %v", edge.End1Kind(), edge.End1Key(), edge.Kind(),
+				edge.End2Kind(), edge.End2Key())
+
+			if attrs := edgeAttrs(edge); len(attrs) > 0 {
+				buf.WriteString(" (")
+
+				for i, attr := range attrs {
+					if i > 0 {
+						buf.WriteString(", ")
+					}
+					fmt.Fprintf(&buf, "%v: %v", attr, formatValue(edge.Attr(attr)))
+				}
+
+				buf.WriteString(")")
+			}
+
+			buf.WriteString("\n")
+		}
+
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
+
+/*
+formatValue writes an attribute value. Strings are quoted.
+*/
+func formatValue(val interface{}) string {
+	if s, ok := val.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(val)
+}
+
+/*
+nodeAttrs returns the sorted names of all attributes of a node except key and
+kind.
+*/
+func nodeAttrs(node data.Node) []string {
+	var res []string
+
+	for attr := range node.Data() {
+		if attr != data.NodeKey && attr != data.NodeKind {
+			res = append(res, attr)
+		}
+	}
+
+	sort.Strings(res)
+
+	return res
+}
+
+/*
+edgeAttrs returns the sorted names of all attributes of an edge except the
+attributes which identify the edge and its ends.
+*/
+func edgeAttrs(edge data.Edge) []string {
+	var res []string
+
+	for attr := range edge.Data() {
+		switch attr {
+		case data.NodeKey, data.NodeKind, data.EdgeEnd1Key, data.EdgeEnd1Kind, data.EdgeEnd1Role,
+			data.EdgeEnd1Cascading, data.EdgeEnd1CascadingLast, data.EdgeEnd2Key, data.EdgeEnd2Kind,
+			data.EdgeEnd2Role, data.EdgeEnd2Cascading, data.EdgeEnd2CascadingLast:
+			continue
+		}
+		res = append(res, attr)
+	}
+
+	sort.Strings(res)
+
+	return res
+}
@@ -0,0 +1,127 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package algo
+
+import (
+	"testing"
+)
+
+func TestSubgraph(t *testing.T) {
+	gm := linkGraph()
+
+	g, _ := NewGraph(gm, "main", "", nil)
+
+	sg, err := g.Subgraph([]NodeID{{"b", "Page"}, {"x", "Page"}}, 1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res, err := sg.Format(FormatTriples); err != nil || res != `
+Page:b name "Page b"
+Page:a name "Page a"
+Page:a Link Page:b
+Page:c name "Page c"
+Page:b Link Page:c
+Page:a Link Page:c
+`[1:] {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := sg.Format(FormatMarkdown); err != nil || res != `
+## Page b
+- name: "Page b"
+
+## Page a
+- name: "Page a"
+- Page a -[Link]-> Page b (cost: 1)
+
+## Page c
+- name: "Page c"
+- Page b -[Link]-> Page c (cost: 1)
+- Page a -[Link]-> Page c (cost: 5)
+
+`[1:] {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Limit the subgraph to a token budget
+
+	sg, _ = sg.Limit(FormatTriples, 20)
+
+	if res, err := sg.Format(FormatJSON); err != nil || res != `
+{
+  "edges": [
+    {
+      "cost": 1,
+      "end1cascading": false,
+      "end1key": "a",
+      "end1kind": "Page",
+      "end1role": "From",
+      "end2cascading": false,
+      "end2key": "b",
+      "end2kind": "Page",
+      "end2role": "To",
+      "key": "0",
+      "kind": "Link"
+    }
+  ],
+  "nodes": [
+    {
+      "key": "b",
+      "kind": "Page",
+      "name": "Page b"
+    },
+    {
+      "key": "a",
+      "kind": "Page",
+      "name": "Page a"
+    }
+  ],
+  "truncated": true
+}`[1:] {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, _ := sg.Limit(FormatJSON, 0); res != sg {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// Only follow certain edges and nodes
+
+	g, _ = NewGraph(gm, "main", "", []string{"Page"})
+	g.SetEdgeKinds([]string{})
+
+	if sg, err = g.Subgraph([]NodeID{{"b", "Page"}}, 2); err != nil || len(sg.Nodes) != 1 || len(sg.Edges) != 0 {
+		t.Error("Unexpected result:", sg, err)
+		return
+	}
+
+	g, _ = NewGraph(gm, "main", "", []string{})
+
+	if sg, err = g.Subgraph([]NodeID{{"b", "Page"}}, 2); err != nil || len(sg.Nodes) != 0 {
+		t.Error("Unexpected result:", sg, err)
+		return
+	}
+
+	// Test error cases
+
+	if _, err := sg.Format("foo"); err == nil || err.Error() != "GraphError: Invalid data (Unknown subgraph format: foo)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if _, err := sg.Limit("foo", 10); err == nil || err.Error() != "GraphError: Invalid data (Unknown subgraph format: foo)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+}
@@ -0,0 +1,273 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package algo
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/Fisch-Labs/FishDB/graph/util"
+)
+
+/*
+Iterator iterates over all nodes which can be reached from a start node.
+*/
+type Iterator struct {
+	g       *Graph          // Graph which is traversed
+	dfs     bool            // Flag if the traversal is depth-first
+	pending []visit         // Queue (BFS) or stack (DFS) of pending nodes
+	visited map[NodeID]bool // Nodes which have been visited (or queued for BFS)
+	err     error           // Last encountered error
+}
+
+/*
+visit is a pending node of a traversal.
+*/
+type visit struct {
+	node  NodeID
+	depth int
+}
+
+/*
+BFS returns a breadth-first iterator starting from a given node. The start
+node is the first node of the iteration. The iterator is empty if the start
+node does not exist.
+*/
+func (g *Graph) BFS(start NodeID) *Iterator {
+	return g.newIterator(start, false)
+}
+
+/*
+DFS returns a depth-first (pre-order) iterator starting from a given node. The
+start node is the first node of the iteration. The iterator is empty if the
+start node does not exist.
+*/
+func (g *Graph) DFS(start NodeID) *Iterator {
+	return g.newIterator(start, true)
+}
+
+/*
+newIterator creates a new traversal iterator.
+*/
+func (g *Graph) newIterator(start NodeID, dfs bool) *Iterator {
+	it := &Iterator{g, dfs, nil, make(map[NodeID]bool), nil}
+
+	ok, err := g.Exists(start)
+
+	if it.err = err; ok {
+		it.pending = append(it.pending, visit{start, 0})
+		if !dfs {
+			it.visited[start] = true
+		}
+	}
+
+	return it
+}
+
+/*
+HasNext returns if there is a next node.
+*/
+func (it *Iterator) HasNext() bool {
+	return it.err == nil && len(it.pending) > 0
+}
+
+/*
+Next returns the next node and its depth (distance in edges from the start
+node for BFS; depth in the traversal tree for DFS). Sets the error of the
+iterator if an error occurs.
+*/
+func (it *Iterator) Next() (NodeID, int) {
+	var v visit
+
+	if !it.HasNext() {
+		return NodeID{}, -1
+	}
+
+	if it.dfs {
+		v = it.pending[len(it.pending)-1]
+		it.pending = it.pending[:len(it.pending)-1]
+		it.visited[v.node] = true
+	} else {
+		v = it.pending[0]
+		it.pending = it.pending[1:]
+	}
+
+	nbs, err := it.g.neighbours(v.node, false)
+	if err != nil {
+		it.err = err
+		return NodeID{}, -1
+	}
+
+	if it.dfs {
+
+		// Push neighbours in reverse order so the smallest neighbour is visited first
+
+		for i := len(nbs) - 1; i >= 0; i-- {
+			if !it.visited[nbs[i].node] {
+				it.pending = append(it.pending, visit{nbs[i].node, v.depth + 1})
+			}
+		}
+
+		// Drop nodes from the stack which have been visited in the meantime
+
+		for len(it.pending) > 0 && it.visited[it.pending[len(it.pending)-1].node] {
+			it.pending = it.pending[:len(it.pending)-1]
+		}
+
+	} else {
+
+		for _, nb := range nbs {
+			if !it.visited[nb.node] {
+				it.visited[nb.node] = true
+				it.pending = append(it.pending, visit{nb.node, v.depth + 1})
+			}
+		}
+	}
+
+	return v.node, v.depth
+}
+
+/*
+Error returns the last encountered error.
+*/
+func (it *Iterator) Error() error {
+	return it.err
+}
+
+/*
+ShortestPath returns the shortest path between two nodes using Dijkstra's
+algorithm. The weight of an edge is read from a numeric edge attribute. All
+edges have a weight of 1 if no weight attribute is given. Returns the nodes of
+the path (including start and end node), the distance of every node on the
+path from the start node and the total distance. The returned path is nil if
+the end node cannot be reached.
+*/
+func (g *Graph) ShortestPath(from NodeID, to NodeID, weightAttr string) ([]NodeID, []float64, float64, error) {
+
+	if ok, err := g.Exists(from); !ok || err != nil {
+		return nil, nil, 0, err
+	}
+
+	dist := map[NodeID]float64{from: 0}
+	parent := make(map[NodeID]NodeID)
+	done := make(map[NodeID]bool)
+
+	pq := &nodeQueue{{from, 0}}
+
+	for pq.Len() > 0 {
+		cur := heap.Pop(pq).(nodeDist)
+
+		if done[cur.node] {
+			continue
+		}
+
+		done[cur.node] = true
+
+		if cur.node == to {
+
+			// Build the path by following the parents back to the start node
+
+			path := []NodeID{to}
+			dists := []float64{cur.dist}
+
+			for n := to; n != from; {
+				n = parent[n]
+				path = append([]NodeID{n}, path...)
+				dists = append([]float64{dist[n]}, dists...)
+			}
+
+			return path, dists, cur.dist, nil
+		}
+
+		nbs, err := g.neighbours(cur.node, weightAttr != "")
+		if err != nil {
+			return nil, nil, 0, err
+		}
+
+		for _, nb := range nbs {
+			weight := 1.0
+
+			if weightAttr != "" {
+				val := nb.edge.Attr(weightAttr)
+
+				if weight, err = strconv.ParseFloat(fmt.Sprint(val), 64); err != nil ||
+					weight < 0 || math.IsNaN(weight) {
+
+					return nil, nil, 0, &util.GraphError{Type: util.ErrInvalidData,
+						Detail: fmt.Sprintf("Edge %v of kind %v has no non-negative numeric weight in attribute %v: %v",
+							nb.edge.Key(), nb.edge.Kind(), weightAttr, val)}
+				}
+			}
+
+			if d, ok := dist[nb.node]; !done[nb.node] && (!ok || cur.dist+weight < d) {
+				dist[nb.node] = cur.dist + weight
+				parent[nb.node] = cur.node
+				heap.Push(pq, nodeDist{nb.node, cur.dist + weight})
+			}
+		}
+	}
+
+	return nil, nil, 0, nil
+}
+
+/*
+nodeDist is a node with its distance from a start node.
+*/
+type nodeDist struct {
+	node NodeID
+	dist float64
+}
+
+/*
+nodeQueue is a priority queue of nodes ordered by distance.
+*/
+type nodeQueue []nodeDist
+
+/*
+Len returns the number of nodes in the queue.
+*/
+func (q nodeQueue) Len() int {
+	return len(q)
+}
+
+/*
+Less compares two nodes of the queue by distance (and by kind and key if the
+distance is equal).
+*/
+func (q nodeQueue) Less(i, j int) bool {
+	if q[i].dist != q[j].dist {
+		return q[i].dist < q[j].dist
+	}
+	return q[i].node.less(q[j].node)
+}
+
+/*
+Swap swaps two nodes of the queue.
+*/
+func (q nodeQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+/*
+Push adds a node to the queue.
+*/
+func (q *nodeQueue) Push(x interface{}) {
+	*q = append(*q, x.(nodeDist))
+}
+
+/*
+Pop removes the last node from the queue.
+*/
+func (q *nodeQueue) Pop() interface{} {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
@@ -312,7 +312,7 @@ can be used to provide a full-text search.
 */
 func (ge *graphEdge) IndexMap() map[string]string {
 	return createIndexMap(ge.graphNode, func(attr string) bool {
-		return attr == NodeKey || attr == NodeKind || attr == EdgeEnd1Key ||
+		return attr == NodeKey || attr == NodeKind || attr == NodeRevision || attr == EdgeEnd1Key ||
 			attr == EdgeEnd1Kind || attr == EdgeEnd1Role ||
 			attr == EdgeEnd1Cascading || attr == EdgeEnd1CascadingLast ||
 			attr == EdgeEnd2Key || attr == EdgeEnd2Kind || attr == EdgeEnd2Role ||
@@ -78,6 +78,14 @@ NodeKind is the kind attribute for a node
 */
 const NodeKind = "kind"
 
+/*
+NodeRevision is the revision attribute for a node. The revision of a node is
+maintained by the graph and is increased every time the node is written. A
+revision which is set on a node which should be written is the expected
+revision of the stored node.
+*/
+const NodeRevision = "_rev"
+
 /*
 CopyNode returns a shallow copy of a given node.
 */
@@ -182,10 +190,22 @@ can be used to provide a full-text search.
 */
 func (gn *graphNode) IndexMap() map[string]string {
 	return createIndexMap(gn, func(attr string) bool {
-		return attr == NodeKey || attr == NodeKind
+		return attr == NodeKey || attr == NodeKind || attr == NodeRevision
 	})
 }
 
+/*
+isIgnoredIndexValue checks if a value should not be part of an index map.
+Byte slices and vectors are not useful for a full-text search.
+*/
+func isIgnoredIndexValue(val interface{}) bool {
+	switch val.(type) {
+	case []byte, []float32:
+		return true
+	}
+	return false
+}
+
 /*
 createIndexMap creates a representation of a node as a string map. A filter
 function can be specified to filters out specific attributes.
@@ -213,6 +233,7 @@ func createIndexMap(gn *graphNode, attFilter func(attr string) bool) map[string]
 			}
 
 			// See the type of val and print it accordingly - ignore byte slices
+			// and vectors
 
 			if st, ok := val.(string); ok {
 
@@ -226,10 +247,10 @@ func createIndexMap(gn *graphNode, attFilter func(attr string) bool) map[string]
 
 				ret[attr] = st.String()
 
-			} else if _, ok := val.([]byte); !ok {
+			} else if !isIgnoredIndexValue(val) {
 
-				// For all other cases (except ignored byte slices) try first a
-				// JSON representation
+				// For all other cases (except ignored byte slices and vectors)
+				// try first a JSON representation
 
 				jsonBytes, err := json.Marshal(val)
 				jsonString := string(jsonBytes)
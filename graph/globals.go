@@ -26,7 +26,27 @@ function.
 
 All nodes and edges in the datastore are indexed. The index can be queried
 using a IndexQuery object. The manager can produce these with the NodeIndexQuery()
-or EdgeIndexQuery function.
+or EdgeIndexQuery function. The index keeps term statistics for every attribute
+so that matches can be ranked by their BM25 relevance score. The way values are
+split into words can be configured for every node kind and attribute with the
+SetAnalyzer() function. Which attributes are indexed at all can be configured
+with the SetIndexMode() function. The index of a kind can be rebuilt with the
+RebuildIndex() function after the configuration was changed. A rebuilt index
+replaces the existing index only once it is complete.
+
+# Vector search
+
+Node attributes which hold a float32 slice are vectors (e.g. embeddings). All
+vectors are stored in an approximate nearest neighbour index. The index can be
+queried using a VectorQuery object. The manager can produce these with the
+VectorQuery() function.
+
+# Snapshots
+
+A snapshot is a named copy of a partition which is registered as a partition
+of its own. It can be queried like any other partition and can later be merged
+back into its source partition or discarded. Snapshots are created with the
+CreateSnapshot() function.
 
 # Transactions
 
@@ -37,14 +57,70 @@ A transaction commit does an automatic rollback if an error occurs
 
 A trans object can be created with the NewGraphTrans() function.
 
+Nodes and edges can be merged into the graph with the MergeNode() and
+MergeEdge() functions of a transaction. Existing nodes are matched by a set of
+attributes and existing edges by their ends. The attributes of matched nodes
+and edges are merged according to merge policies (overwrite, keepfirst, append
+or max). Nodes and edges which do not match are created.
+
+Pending operations can be discarded with Rollback(). Savepoint() records the
+current state of a transaction under a name and RollbackTo() discards all
+operations which were added after it. The FetchNode(), FetchEdge() and
+Traverse() functions of a transaction read the graph as it will be after the
+transaction was committed.
+
+# Locking
+
+Every partition has its own reader / writer lock. A commit takes the writer
+locks of all partitions it writes to in alphabetical order of their names so
+transactions which write to different partitions can be committed at the same
+time. Partitions which are written by rules during a commit are locked once
+they are used. If such a partition cannot be locked in order the commit waits
+for a limited time and fails with an ErrLocked error if the partition stays
+locked. Operations which change the whole graph (e.g. snapshots or schemas)
+exclude all other operations.
+
+# Revisions
+
+Every node and edge has a revision which is increased every time it is written.
+The revision can be fetched with the reserved attribute data.NodeRevision. If a
+node or edge which should be written has a data.NodeRevision attribute then it
+is the expected revision of the stored node or edge. The write fails with an
+ErrConflict error if the stored revision is different. A node or edge which
+does not exist has the revision 0.
+
 # Rules
 
 (Use with caution)
 
 Graph rules provide automatic operations which help to keep the graph consistent.
-Rules trigger on global graph events. The rules SystemRuleDeleteNodeEdges and
-SystemRuleUpdateNodeStats are automatically loaded when a new Manager is created.
-See the code for further details.
+Rules trigger on global graph events. The rules SystemRuleDeleteNodeEdges,
+SystemRuleUpdateNodeStats and SystemRuleValidateSchema are automatically loaded
+when a new Manager is created. See the code for further details.
+
+# Schemas
+
+Node and edge kinds are schemaless by default. An optional schema can be set
+for every kind with the SetSchema() function. It defines required attributes,
+attribute types, the node kinds which an edge kind may connect and the maximal
+number of edges of a kind per node and role. Schemas are enforced by the
+SystemRuleValidateSchema rule.
+
+# Unique constraints
+
+Only the key of a node is unique by default. A unique constraint can be added
+for a node kind in a partition with the AddUniqueConstraint() function. It
+ensures that no two nodes have the same values for a set of attributes. The
+values of all nodes are recorded in a unique constraint storage which is
+checked when nodes are written.
+
+# System partition
+
+The partition SystemPartition holds system nodes which configure the database
+itself (e.g. webhooks or ECAL triggers). System nodes are regular nodes and can
+be read and written with the normal API. A kind can be protected with the
+ProtectSystemKind() function - nodes and edges of a protected kind can only be
+written in transactions which were created with NewSystemGraphTrans().
 
 # Graph databases
 
@@ -80,6 +156,9 @@ Each node kind database stores:
 	PrefixNSEdge + node key + spec -> map[edge key]edgeinfo{other node key, other node kind}]
 	(connection from one node to another via a spec)
 
+	PrefixNSRevision + node key -> revision
+	(revision of a certain node)
+
 # Edges database
 
 Each edge kind database stores:
@@ -90,10 +169,69 @@ Each edge kind database stores:
 	PrefixNSAttr + edge key + attr num -> value
 	(attribute value of a certain edge)
 
+	PrefixNSRevision + edge key -> revision
+	(revision of a certain edge)
+
 # Index database
 
 The text index managed by util/indexmanager.go. IndexQuery provides access to
 the full text search index.
+
+# Unique constraint database
+
+Each node kind unique constraint database stores:
+
+	PrefixNSUnique + constraint attributes + "#" + values -> node key
+	(the node which holds certain values of a unique constraint)
+
+# Vector index database
+
+The vector index managed by util/vectorindexmanager.go. VectorQuery provides
+access to the approximate nearest neighbour index of node vectors.
+
+# History database
+
+Every committed change produces a new graph version. Each node and edge kind
+history database stores:
+
+	PrefixNSVersions + item key -> [ VERSIONS ]
+	(a list of all versions of a certain node or edge)
+
+	PrefixNSVersion + item key + version -> versionEntry
+	(data of a certain node or edge in a certain version)
+
+	PrefixNSHistEdges + node key -> map[edge kind + "#" + edge key]<empty string>
+	(a lookup for all edges which were ever connected to a certain node)
+
+# Change log database
+
+Every committed change is also appended as a change set to the change log.
+A change set holds all node and edge changes of a commit with their state
+before and after the commit. Change sets have a sequence number which is
+assigned in the order they are appended so consumers can resume reading
+after the last change set they have seen. Old change sets are removed
+according to the retention of the change log. The change log database stores:
+
+	PrefixCLSeq -> sequence number
+	(sequence number of the last change set)
+
+	PrefixCLFirstSeq -> sequence number
+	(sequence number of the first change set which was not removed)
+
+	PrefixCLChangeSet + sequence number -> changeSetEntry
+	(all changes of a certain commit)
+
+	PrefixCLPending -> [ VERSIONS ]
+	(graph versions of all commits which are flushed at the moment)
+
+	PrefixCLPendingSet + version -> changeSetEntry
+	(all changes of a commit which is flushed at the moment)
+
+The changes of a commit are stored as a pending change set before the commit
+is flushed and are appended to the change log afterwards. Pending change sets
+which are left if the database stops in between are appended or discarded
+when the graph manager is created - depending on whether the changes were
+stored in the history.
 */
 package graph
 
@@ -104,6 +242,11 @@ VERSION of the GraphManager
 */
 const VERSION = 1
 
+/*
+SystemPartition is the partition which holds system nodes
+*/
+const SystemPartition = "_system"
+
 /*
 MainDBEntryPrefix is the prefix for entries stored in the main database
 */
@@ -157,6 +300,51 @@ MainDBEdgeCount is the MainDB entry key for an edge count
 */
 const MainDBEdgeCount = MainDBEntryPrefix + "ecnt"
 
+/*
+MainDBGraphVersion is the MainDB entry key for the last committed graph version
+*/
+const MainDBGraphVersion = MainDBEntryPrefix + "gver"
+
+/*
+MainDBSnapshots is the MainDB entry key for snapshot information
+*/
+const MainDBSnapshots = MainDBEntryPrefix + "snap"
+
+/*
+MainDBAnalyzers is the MainDB entry key for word index analyzer information
+*/
+const MainDBAnalyzers = MainDBEntryPrefix + "anlz"
+
+/*
+MainDBIndexSchema is the MainDB entry key for index schema information
+*/
+const MainDBIndexSchema = MainDBEntryPrefix + "isch"
+
+/*
+MainDBIndexStale is the MainDB entry key for indices which do not match the index schema
+*/
+const MainDBIndexStale = MainDBEntryPrefix + "istl"
+
+/*
+MainDBIndexStorages is the MainDB entry key for the storages of rebuilt indices
+*/
+const MainDBIndexStorages = MainDBEntryPrefix + "istg"
+
+/*
+MainDBChangeCursors is the MainDB entry key for the change log cursors of consumers
+*/
+const MainDBChangeCursors = MainDBEntryPrefix + "ccur"
+
+/*
+MainDBSchema is the MainDB entry key for node and edge kind schemas
+*/
+const MainDBSchema = MainDBEntryPrefix + "schm"
+
+/*
+MainDBUniqueConstraints is the MainDB entry key for unique constraint information
+*/
+const MainDBUniqueConstraints = MainDBEntryPrefix + "uniq"
+
 // Root IDs for StorageManagers
 // ============================
 
@@ -193,6 +381,37 @@ StorageSuffixEdgesIndex is the suffix for an edge index
 */
 const StorageSuffixEdgesIndex = ".edgeidx"
 
+/*
+StorageSuffixNodesHistory is the suffix for a node history
+*/
+const StorageSuffixNodesHistory = ".nodehist"
+
+/*
+StorageSuffixEdgesHistory is the suffix for an edge history
+*/
+const StorageSuffixEdgesHistory = ".edgehist"
+
+/*
+StorageSuffixNodesVector is the suffix for a node vector index
+*/
+const StorageSuffixNodesVector = ".nodevec"
+
+/*
+StorageSuffixNodesUnique is the suffix for a node unique constraint storage
+*/
+const StorageSuffixNodesUnique = ".nodeuniq"
+
+/*
+StorageSuffixRebuild is the suffix for the second storage of an index - a
+rebuilt index alternates between the two storages
+*/
+const StorageSuffixRebuild = ".rebuild"
+
+/*
+StorageChangeLog is the name of the change log storage
+*/
+const StorageChangeLog = "graph.changelog"
+
 // PREFIXES for Node storage
 // =========================
 
@@ -220,6 +439,67 @@ PrefixNSEdge is the prefix for storing a link from a node (and a spec) to an edg
 */
 const PrefixNSEdge = "\x04"
 
+/*
+PrefixNSRevision is the prefix for storing the revision of a node or edge
+*/
+const PrefixNSRevision = "\x08"
+
+// PREFIXES for Unique constraint storage
+// ======================================
+
+/*
+PrefixNSUnique is the prefix for storing the values of a unique constraint
+*/
+const PrefixNSUnique = "\x09"
+
+// PREFIXES for History storage
+// ============================
+
+/*
+PrefixNSVersions is the prefix for storing the list of versions of a node or edge
+*/
+const PrefixNSVersions = "\x05"
+
+/*
+PrefixNSVersion is the prefix for storing a single version of a node or edge
+*/
+const PrefixNSVersion = "\x06"
+
+/*
+PrefixNSHistEdges is the prefix for storing all edges which were ever connected to a node
+*/
+const PrefixNSHistEdges = "\x07"
+
+// PREFIXES for Change log storage
+// ===============================
+
+/*
+PrefixCLSeq is the prefix for storing the sequence number of the last change set
+*/
+const PrefixCLSeq = "\x01"
+
+/*
+PrefixCLChangeSet is the prefix for storing a change set
+*/
+const PrefixCLChangeSet = "\x02"
+
+/*
+PrefixCLFirstSeq is the prefix for storing the sequence number of the first
+change set which was not removed by the retention of the change log
+*/
+const PrefixCLFirstSeq = "\x03"
+
+/*
+PrefixCLPending is the prefix for storing the graph versions of all pending
+change sets
+*/
+const PrefixCLPending = "\x04"
+
+/*
+PrefixCLPendingSet is the prefix for storing a pending change set
+*/
+const PrefixCLPendingSet = "\x05"
+
 // Graph events
 //=============
 
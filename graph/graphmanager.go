@@ -27,6 +27,13 @@ type Manager struct {
 	mapCache     map[string]map[string]string // Cache which caches maps stored in the main database
 	mutex        *sync.RWMutex                // Mutex to protect atomic graph operations
 	storageMutex *sync.Mutex                  // Special mutex for storage object access
+	mainMutex    *sync.Mutex                  // Mutex to protect the main database
+	partLocks    *partitionLocks              // Reader / writer locks of all partitions
+	writeOps     *writeOperations             // Write operations which are running
+	indexLocks   *partitionLocks              // Reader / writer locks of all index storages
+	rebuilds     map[string]bool              // Partitions and kinds whose index is being rebuilt
+	rebuildMutex *sync.Mutex                  // Mutex to protect the list of index rebuilds
+	changeLog    *changeLog                   // State of the change log
 }
 
 /*
@@ -37,6 +44,8 @@ func NewGraphManager(gs graphstorage.Storage) *Manager {
 
 	gm.SetGraphRule(&SystemRuleDeleteNodeEdges{})
 	gm.SetGraphRule(&SystemRuleUpdateNodeStats{})
+	gm.SetGraphRule(&SystemRuleValidateSchema{})
+	gm.SetGraphRule(&SystemRuleProtectSystemKinds{})
 
 	return gm
 }
@@ -71,12 +80,23 @@ func createGraphManager(gs graphstorage.Storage) *Manager {
 		}
 	}
 
+	mainMutex := &sync.Mutex{}
+
 	gm := &Manager{gs, &graphRulesManager{nil, make(map[string]Rule),
-		make(map[int]map[string]Rule)}, util.NewNamesManager(mdb),
-		make(map[string]map[string]string), &sync.RWMutex{}, &sync.Mutex{}}
+		make(map[int]map[string]Rule), make(map[string]bool)}, util.NewSharedNamesManager(mdb, mainMutex),
+		make(map[string]map[string]string), &sync.RWMutex{}, &sync.Mutex{},
+		mainMutex, newPartitionLocks(), newWriteOperations(), newPartitionLocks(), make(map[string]bool),
+		&sync.Mutex{}, newChangeLog()}
 
 	gm.gr.gm = gm
 
+	// Append the change sets of commits which were flushed before the
+	// database stopped but which were not appended to the change log
+
+	if err := gm.recoverChangeSets(); err != nil {
+		panic(fmt.Sprint("Cannot recover change log: ", err))
+	}
+
 	return gm
 }
 
@@ -101,6 +121,15 @@ func (gm *Manager) GraphRules() []string {
 	return gm.gr.GraphRules()
 }
 
+/*
+ProtectSystemKind protects all nodes and edges of a given kind in the system
+partition. They can only be written in transactions which were created with
+NewSystemGraphTrans.
+*/
+func (gm *Manager) ProtectSystemKind(kind string) {
+	gm.gr.protectedKinds[kind] = true
+}
+
 /*
 NodeIndexQuery returns an object to query the full text search index for nodes.
 */
@@ -110,7 +139,7 @@ func (gm *Manager) NodeIndexQuery(part string, kind string) (IndexQuery, error)
 		return nil, err
 	}
 
-	return util.NewIndexManager(iht), nil
+	return newLockedIndexQuery(gm, part, kind, StorageSuffixNodesIndex), nil
 }
 
 /*
@@ -122,7 +151,7 @@ func (gm *Manager) EdgeIndexQuery(part string, kind string) (IndexQuery, error)
 		return nil, err
 	}
 
-	return util.NewIndexManager(iht), nil
+	return newLockedIndexQuery(gm, part, kind, StorageSuffixEdgesIndex), nil
 }
 
 /*
@@ -0,0 +1,117 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Fisch-Labs/FishDB/graph/util"
+	"github.com/Fisch-Labs/FishDB/hash"
+)
+
+/*
+Analyzers returns all analyzers of a node kind as a map of attribute to
+analyzer specification. The analyzer for all attributes of the kind is
+stored under the empty attribute name.
+*/
+func (gm *Manager) Analyzers(kind string) map[string]string {
+
+	// Take reader lock
+
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+
+	return gm.analyzers(kind)
+}
+
+/*
+SetAnalyzer sets the analyzer which builds the word index of a node attribute.
+The analyzer is given as specification (see util.NewAnalyzer). If the attribute
+is empty then the analyzer is used for all attributes of the node kind which
+have no analyzer of their own. An empty specification removes an analyzer.
+The configuration is stored in the main database. Values which were indexed
+before the analyzer was changed must be reindexed to be found with the new
+analyzer.
+*/
+func (gm *Manager) SetAnalyzer(kind string, attr string, spec string) error {
+
+	if kind == "" || strings.Contains(kind, "#") {
+		return &util.GraphError{Type: util.ErrInvalidData, Detail: fmt.Sprint("Invalid node kind: ", kind)}
+	}
+
+	if spec != "" {
+
+		a, err := util.NewAnalyzer(spec)
+		if err != nil {
+			return err
+		}
+
+		spec = a.String()
+	}
+
+	// Take writer lock
+
+	gm.mutex.Lock()
+	defer gm.mutex.Unlock()
+
+	analyzers := gm.getMainDBMap(MainDBAnalyzers)
+	if analyzers == nil {
+		analyzers = make(map[string]string)
+	}
+
+	if spec == "" {
+		delete(analyzers, kind+"#"+attr)
+	} else {
+		analyzers[kind+"#"+attr] = spec
+	}
+
+	gm.storeMainDBMap(MainDBAnalyzers, analyzers)
+
+	return gm.flushMain()
+}
+
+/*
+analyzers returns all analyzers of a node kind. It is assumed that the caller
+holds a lock.
+*/
+func (gm *Manager) analyzers(kind string) map[string]string {
+	ret := make(map[string]string)
+
+	for k, spec := range gm.getMainDBMap(MainDBAnalyzers) {
+		if strings.HasPrefix(k, kind+"#") {
+			ret[k[len(kind)+1:]] = spec
+		}
+	}
+
+	return ret
+}
+
+/*
+nodeIndexManager returns an index manager for the index of a node kind which
+uses the configured analyzers and index schema. It is assumed that the caller
+holds a lock.
+*/
+func (gm *Manager) nodeIndexManager(iht *hash.HTree, kind string) *util.IndexManager {
+	im := util.NewIndexManager(iht)
+
+	for attr, spec := range gm.analyzers(kind) {
+
+		// Specifications are validated before they are stored
+
+		if a, err := util.NewAnalyzer(spec); err == nil {
+			im.SetAnalyzer(attr, a)
+		}
+	}
+
+	for attr, mode := range gm.indexSchema(kind) {
+		im.SetIndexMode(attr, mode)
+	}
+
+	return im
+}
@@ -0,0 +1,118 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+)
+
+func TestAnalyzers(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	if err := gm.SetAnalyzer("article", "", "lowercase,fold,stop,stem"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.SetAnalyzer("article", "title", "lowercase, ngram:3"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := gm.Analyzers("article"); fmt.Sprint(res) != "map[:lowercase,fold,stop,stem title:lowercase,ngram:3]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	constructNode := func(key, title, text string) data.Node {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "article")
+		node.SetAttr("title", title)
+		node.SetAttr("text", text)
+		return node
+	}
+
+	gm.StoreNode("main", constructNode("1", "FishDB", "Running the cafés of the city"))
+
+	trans := NewGraphTrans(gm)
+	trans.StoreNode("main", constructNode("2", "GraphDB", "A café runs"))
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	iq, _ := gm.NodeIndexQuery("main", "article")
+
+	if res, err := iq.LookupPhrase("text", "runs the cafe"); err != nil || fmt.Sprint(res) != "[1]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := iq.LookupPhrase("title", "phdb"); err != nil || fmt.Sprint(res) != "[2]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Reopening the database uses the same analyzers
+
+	gm = NewGraphManager(mgs)
+
+	gm.UpdateNode("main", constructNode("2", "GraphDB", "Some cafés run in the city"))
+
+	iq, _ = gm.NodeIndexQuery("main", "article")
+
+	if res, err := iq.LookupPhrase("text", "cafe run"); err != nil || fmt.Sprint(res) != "[2]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := iq.LookupWord("text", "city"); err != nil || len(res) != 2 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	gm.RemoveNode("main", "1", "article")
+
+	if res, err := iq.LookupWord("text", "running"); err != nil || fmt.Sprint(res) != "map[2:[3]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Remove an analyzer
+
+	if err := gm.SetAnalyzer("article", "title", ""); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := gm.Analyzers("article"); fmt.Sprint(res) != "map[:lowercase,fold,stop,stem]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// Test error cases
+
+	if err := gm.SetAnalyzer("article", "title", "foo"); err == nil ||
+		err.Error() != "GraphError: Invalid data (Unknown analyzer filter: foo)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if err := gm.SetAnalyzer("", "title", "lowercase"); err == nil ||
+		err.Error() != "GraphError: Invalid data (Invalid node kind: )" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+}
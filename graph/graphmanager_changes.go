@@ -0,0 +1,766 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"encoding/gob"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+	"github.com/Fisch-Labs/FishDB/hash"
+)
+
+/*
+Change operations
+*/
+const (
+	ChangeUpsert = "upsert" // A node or edge was created or updated
+	ChangeDelete = "delete" // A node or edge was removed
+)
+
+/*
+DefaultChangeLimit is the number of change sets which is returned by
+FetchChanges if no limit is given.
+*/
+var DefaultChangeLimit = 100
+
+/*
+MaxChangeLimit is the maximal number of change sets which is returned by a
+single FetchChanges call.
+*/
+var MaxChangeLimit = 1000
+
+/*
+ChangeSet models all changes of a single committed write operation in the
+change log.
+*/
+type ChangeSet struct {
+	Seq     uint64    // Sequence number of the change set in the change log
+	Version uint64    // Graph version which was produced by the commit
+	Time    time.Time // Commit time
+	TransID string    // ID of the committing transaction (empty if no transaction was used)
+	Changes []*Change // Changes in the order they were made
+}
+
+/*
+Change models the change of a single node or edge in a change set.
+*/
+type Change struct {
+	Op     string    // Change operation (ChangeUpsert or ChangeDelete)
+	Part   string    // Partition of the node or edge
+	Kind   string    // Kind of the node or edge
+	Key    string    // Key of the node or edge
+	IsEdge bool      // Flag if the change is an edge change
+	Before data.Node // Node or edge before the commit (nil if it did not exist)
+	After  data.Node // Node or edge after the commit (nil if it was removed)
+}
+
+/*
+changeSetEntry is an internal structure which stores a change set
+*/
+type changeSetEntry struct {
+	Seq     uint64         // Sequence number of the change set
+	Version uint64         // Graph version which was produced by the commit
+	Time    int64          // Commit time (Unix time in nanoseconds)
+	TransID string         // ID of the committing transaction
+	Changes []*changeEntry // Changes of the change set
+}
+
+/*
+changeEntry is an internal structure which stores the change of a single
+node or edge
+*/
+type changeEntry struct {
+	Part   string                 // Partition of the node or edge
+	Key    string                 // Key of the node or edge
+	Kind   string                 // Kind of the node or edge
+	IsEdge bool                   // Flag if the change is an edge change
+	Before map[string]interface{} // Data before the commit (nil if it did not exist)
+	After  map[string]interface{} // Data after the commit (nil if it was removed)
+}
+
+/*
+changeLog holds the state which is needed to append to the change log.
+*/
+type changeLog struct {
+	mutex   *sync.Mutex   // Mutex to serialize change log operations
+	notify  chan struct{} // Channel which is closed once the next change set was appended
+	maxAge  time.Duration // Maximal age of kept change sets (0 keeps change sets of any age)
+	maxSize uint64        // Maximal number of kept change sets (0 keeps any number)
+}
+
+/*
+newChangeLog creates a new change log state object.
+*/
+func newChangeLog() *changeLog {
+	return &changeLog{&sync.Mutex{}, make(chan struct{}), 0, 0}
+}
+
+func init() {
+
+	// Make sure we can use the relevant types in a gob operation
+
+	gob.Register(&changeSetEntry{})
+	gob.Register(&changeEntry{})
+}
+
+/*
+changeKey returns the lookup key for the change of a node or edge in a commit.
+*/
+func changeKey(part string, key string, kind string, isEdge bool) string {
+	prefix := "n#"
+	if isEdge {
+		prefix = "e#"
+	}
+	return prefix + part + "#" + kind + "#" + key
+}
+
+/*
+recordChange records a new change of a node or edge in a commit.
+*/
+func (vc *versionCommit) recordChange(part string, key string, kind string, isEdge bool,
+	before map[string]interface{}) *changeEntry {
+
+	change := &changeEntry{part, key, kind, isEdge, before, nil}
+
+	vc.changes = append(vc.changes, change)
+	vc.lookup[changeKey(part, key, kind, isEdge)] = change
+
+	return change
+}
+
+/*
+changeEntries returns all changes of a commit which need to be recorded in the
+change log. Nodes and edges which were created and removed in the same commit
+are ignored.
+*/
+func (vc *versionCommit) changeEntries() []*changeEntry {
+	changes := make([]*changeEntry, 0, len(vc.changes))
+
+	for _, change := range vc.changes {
+		if change.Before != nil || change.After != nil {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes
+}
+
+/*
+SetChangeLogRetention sets the retention of the change log. Change sets which
+are older than maxAge or which exceed the maximal number of change sets maxSize
+are removed when new change sets are appended. A value of 0 disables the
+respective limit. The last change set is always kept.
+*/
+func (gm *Manager) SetChangeLogRetention(maxAge time.Duration, maxSize uint64) {
+	gm.changeLog.mutex.Lock()
+	defer gm.changeLog.mutex.Unlock()
+
+	gm.changeLog.maxAge = maxAge
+	gm.changeLog.maxSize = maxSize
+}
+
+/*
+FirstChangeSeq returns the sequence number of the first change set which is
+kept in the change log. All change sets before it were removed - a consumer
+whose cursor is before it has missed change sets.
+*/
+func (gm *Manager) FirstChangeSeq() (uint64, error) {
+	gm.changeLog.mutex.Lock()
+	defer gm.changeLog.mutex.Unlock()
+
+	cht, err := gm.getChangeLogHTree(false)
+	if err != nil || cht == nil {
+		return 1, err
+	}
+
+	return gm.firstChangeSeq(cht)
+}
+
+/*
+LastChangeSeq returns the sequence number of the last change set in the
+change log. Returns 0 if the change log is empty.
+*/
+func (gm *Manager) LastChangeSeq() (uint64, error) {
+	gm.changeLog.mutex.Lock()
+	defer gm.changeLog.mutex.Unlock()
+
+	cht, err := gm.getChangeLogHTree(false)
+	if err != nil || cht == nil {
+		return 0, err
+	}
+
+	return gm.lastChangeSeq(cht)
+}
+
+/*
+FetchChanges returns the change sets which follow a given sequence number in
+the order they were appended. At most limit change sets are returned (a limit
+of 0 or less returns DefaultChangeLimit change sets). The limit cannot exceed
+MaxChangeLimit. Change sets which were removed by the retention of the change
+log are skipped.
+*/
+func (gm *Manager) FetchChanges(seq uint64, limit int) ([]*ChangeSet, error) {
+	gm.changeLog.mutex.Lock()
+	defer gm.changeLog.mutex.Unlock()
+
+	res := make([]*ChangeSet, 0)
+
+	cht, err := gm.getChangeLogHTree(false)
+	if err != nil || cht == nil {
+		return res, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultChangeLimit
+	} else if limit > MaxChangeLimit {
+		limit = MaxChangeLimit
+	}
+
+	first, err := gm.firstChangeSeq(cht)
+	if err != nil {
+		return nil, err
+	}
+
+	last, err := gm.lastChangeSeq(cht)
+	if err != nil {
+		return nil, err
+	}
+
+	if seq < first {
+		seq = first - 1
+	}
+
+	for s := seq + 1; s <= last && len(res) < limit; s++ {
+
+		obj, err := cht.Get([]byte(PrefixCLChangeSet + versionToString(s)))
+		if err != nil {
+			return nil, &util.GraphError{Type: util.ErrReading, Detail: err.Error()}
+		} else if obj == nil {
+			continue
+		}
+
+		res = append(res, obj.(*changeSetEntry).changeSet())
+	}
+
+	return res, nil
+}
+
+/*
+ChangeNotify returns a channel which is closed once the next change set was
+appended to the change log.
+*/
+func (gm *Manager) ChangeNotify() <-chan struct{} {
+	gm.changeLog.mutex.Lock()
+	defer gm.changeLog.mutex.Unlock()
+
+	return gm.changeLog.notify
+}
+
+/*
+ChangeCursor returns the stored change log cursor of a consumer. Returns false
+if no cursor was stored for the consumer.
+*/
+func (gm *Manager) ChangeCursor(name string) (uint64, bool) {
+	var cursor uint64
+
+	val, ok := gm.getMainDBMap(MainDBChangeCursors)[name]
+
+	if ok {
+		_, err := fmt.Sscan(val, &cursor)
+		ok = err == nil
+	}
+
+	return cursor, ok
+}
+
+/*
+SetChangeCursor stores the change log cursor of a consumer. Cursors are stored
+in the main database outside of the graph - storing a cursor produces neither
+a graph version nor a change set.
+*/
+func (gm *Manager) SetChangeCursor(name string, cursor uint64) error {
+	gm.changeLog.mutex.Lock()
+	defer gm.changeLog.mutex.Unlock()
+
+	if !gm.updateMainDBMap(MainDBChangeCursors, func(cursors map[string]string) bool {
+		cursors[name] = fmt.Sprint(cursor)
+		return true
+	}) {
+		gm.storeMainDBMap(MainDBChangeCursors, map[string]string{name: fmt.Sprint(cursor)})
+	}
+
+	return gm.flushMain()
+}
+
+/*
+RemoveChangeCursor removes the stored change log cursor of a consumer.
+*/
+func (gm *Manager) RemoveChangeCursor(name string) error {
+	gm.changeLog.mutex.Lock()
+	defer gm.changeLog.mutex.Unlock()
+
+	gm.updateMainDBMap(MainDBChangeCursors, func(cursors map[string]string) bool {
+		_, ok := cursors[name]
+		delete(cursors, name)
+		return ok
+	})
+
+	return gm.flushMain()
+}
+
+/*
+TruncateChanges removes all change sets up to and including a given sequence
+number from the change log. The last change set is always kept.
+*/
+func (gm *Manager) TruncateChanges(seq uint64) error {
+	gm.changeLog.mutex.Lock()
+	defer gm.changeLog.mutex.Unlock()
+
+	cht, err := gm.getChangeLogHTree(false)
+	if err != nil || cht == nil {
+		return err
+	}
+
+	if err = gm.truncateChangeLog(cht, seq); err != nil {
+		gm.rollbackChangeLog()
+		return err
+	}
+
+	return gm.flushChangeLog()
+}
+
+/*
+writePendingChangeSet stores the changes of a commit as a pending change set
+before any other change of the commit is flushed. The pending change set is
+appended to the change log by writeChangeSet once the commit was flushed -
+pending change sets which are left if the database stops in between are
+recovered by recoverChangeSets.
+*/
+func (gm *Manager) writePendingChangeSet(commit *versionCommit) error {
+
+	changes := commit.changeEntries()
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	gm.changeLog.mutex.Lock()
+	defer gm.changeLog.mutex.Unlock()
+
+	cht, err := gm.getChangeLogHTree(true)
+	if err != nil {
+		return err
+	}
+
+	pending, err := gm.pendingChangeSets(cht)
+	if err != nil {
+		return err
+	}
+
+	pending = append(pending, commit.version)
+
+	entry := &changeSetEntry{0, commit.version, commit.time, commit.transID, changes}
+
+	if _, err = cht.Put([]byte(PrefixCLPendingSet+versionToString(commit.version)), entry); err == nil {
+		_, err = cht.Put([]byte(PrefixCLPending), pending)
+	}
+
+	if err != nil {
+		gm.rollbackChangeLog()
+		return &util.GraphError{Type: util.ErrWriting, Detail: err.Error()}
+	}
+
+	if err := gm.flushChangeLog(); err != nil {
+		gm.rollbackChangeLog()
+		return err
+	}
+
+	return nil
+}
+
+/*
+writeChangeSet appends the pending change set of a commit as a new change set
+to the change log and removes old change sets according to the retention of
+the change log. The change log is flushed before the function returns. It is
+assumed that all other changes of the commit were flushed before the function
+is called so consumers never see a change set of a commit which is not stored.
+*/
+func (gm *Manager) writeChangeSet(commit *versionCommit) error {
+
+	changes := commit.changeEntries()
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	gm.changeLog.mutex.Lock()
+	defer gm.changeLog.mutex.Unlock()
+
+	cht, err := gm.getChangeLogHTree(true)
+	if err != nil {
+		return err
+	}
+
+	entry := &changeSetEntry{0, commit.version, commit.time, commit.transID, changes}
+
+	if err := gm.appendChangeSet(cht, entry); err != nil {
+		gm.rollbackChangeLog()
+		return err
+	}
+
+	if err := gm.flushChangeLog(); err != nil {
+		gm.rollbackChangeLog()
+		return err
+	}
+
+	// Notify all waiting consumers
+
+	close(gm.changeLog.notify)
+	gm.changeLog.notify = make(chan struct{})
+
+	return nil
+}
+
+/*
+recoverChangeSets appends all pending change sets of commits which were
+flushed but whose change set was not appended to the change log because the
+database stopped in between. Only changes which were stored in the history of
+their node or edge are appended - pending change sets of commits which were
+not flushed at all are discarded.
+*/
+func (gm *Manager) recoverChangeSets() error {
+
+	gm.changeLog.mutex.Lock()
+	defer gm.changeLog.mutex.Unlock()
+
+	cht, err := gm.getChangeLogHTree(false)
+	if err != nil || cht == nil {
+		return err
+	}
+
+	pending, err := gm.pendingChangeSets(cht)
+	if err != nil || len(pending) == 0 {
+		return err
+	}
+
+	// Append the change sets in the order of their graph versions
+
+	versions := append([]uint64(nil), pending...)
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	for _, version := range versions {
+
+		obj, err := cht.Get([]byte(PrefixCLPendingSet + versionToString(version)))
+		if err != nil {
+			return &util.GraphError{Type: util.ErrReading, Detail: err.Error()}
+		}
+
+		var changes []*changeEntry
+
+		if obj != nil {
+			for _, change := range obj.(*changeSetEntry).Changes {
+				if ok, err := gm.changeStored(change, version); err != nil {
+					return err
+				} else if ok {
+					changes = append(changes, change)
+				}
+			}
+		}
+
+		if len(changes) > 0 {
+			entry := obj.(*changeSetEntry)
+			entry.Changes = changes
+			err = gm.appendChangeSet(cht, entry)
+		} else {
+			err = gm.removePendingChangeSet(cht, version)
+		}
+
+		if err != nil {
+			gm.rollbackChangeLog()
+			return err
+		}
+	}
+
+	return gm.flushChangeLog()
+}
+
+/*
+changeStored checks if the state of a node or edge which was produced by a
+given graph version was stored in its history.
+*/
+func (gm *Manager) changeStored(change *changeEntry, version uint64) (bool, error) {
+
+	getHTree := gm.getNodeHistoryHTree
+	if change.IsEdge {
+		getHTree = gm.getEdgeHistoryHTree
+	}
+
+	hht, err := getHTree(change.Part, change.Kind, false)
+	if err != nil || hht == nil {
+		return false, err
+	}
+
+	ok, err := hht.Exists([]byte(PrefixNSVersion + change.Key + versionToString(version)))
+	if err != nil {
+		return false, &util.GraphError{Type: util.ErrReading, Detail: err.Error()}
+	}
+
+	return ok, nil
+}
+
+/*
+appendChangeSet appends a given change set with the next sequence number to a
+given change log, removes its pending change set and removes old change sets
+according to the retention of the change log.
+*/
+func (gm *Manager) appendChangeSet(cht *hash.HTree, entry *changeSetEntry) error {
+
+	last, err := gm.lastChangeSeq(cht)
+	if err != nil {
+		return err
+	}
+
+	// Sequence numbers are assigned in the order change sets are appended -
+	// a consumer which has seen a sequence number has seen all change sets
+	// before it. Concurrent commits to different partitions may be appended
+	// in a different order than their graph versions. Commits which write the
+	// same partition are appended in commit order since they hold the
+	// partition lock until their change set was appended.
+
+	entry.Seq = last + 1
+
+	if _, err = cht.Put([]byte(PrefixCLChangeSet+versionToString(entry.Seq)), entry); err == nil {
+		_, err = cht.Put([]byte(PrefixCLSeq), entry.Seq)
+	}
+
+	if err != nil {
+		return &util.GraphError{Type: util.ErrWriting, Detail: err.Error()}
+	}
+
+	if err := gm.removePendingChangeSet(cht, entry.Version); err != nil {
+		return err
+	}
+
+	return gm.applyChangeLogRetention(cht, entry.Seq)
+}
+
+/*
+removePendingChangeSet removes the pending change set of a given graph version
+from a given change log.
+*/
+func (gm *Manager) removePendingChangeSet(cht *hash.HTree, version uint64) error {
+
+	pending, err := gm.pendingChangeSets(cht)
+	if err != nil {
+		return err
+	}
+
+	var res []uint64
+
+	for _, v := range pending {
+		if v != version {
+			res = append(res, v)
+		}
+	}
+
+	if len(res) == len(pending) {
+		return nil
+	}
+
+	if _, err := cht.Remove([]byte(PrefixCLPendingSet + versionToString(version))); err != nil {
+		return &util.GraphError{Type: util.ErrWriting, Detail: err.Error()}
+	}
+
+	if len(res) == 0 {
+		_, err = cht.Remove([]byte(PrefixCLPending))
+	} else {
+		_, err = cht.Put([]byte(PrefixCLPending), res)
+	}
+
+	if err != nil {
+		return &util.GraphError{Type: util.ErrWriting, Detail: err.Error()}
+	}
+
+	return nil
+}
+
+/*
+pendingChangeSets returns the graph versions of all pending change sets in a
+given change log.
+*/
+func (gm *Manager) pendingChangeSets(cht *hash.HTree) ([]uint64, error) {
+
+	obj, err := cht.Get([]byte(PrefixCLPending))
+	if err != nil {
+		return nil, &util.GraphError{Type: util.ErrReading, Detail: err.Error()}
+	} else if obj == nil {
+		return nil, nil
+	}
+
+	// Copy the list so the stored value is never changed
+
+	return append([]uint64(nil), obj.([]uint64)...), nil
+}
+
+/*
+applyChangeLogRetention removes all change sets which are older than the
+maximal age or which exceed the maximal number of change sets of the change
+log. The given last change set is always kept.
+*/
+func (gm *Manager) applyChangeLogRetention(cht *hash.HTree, last uint64) error {
+	var upto uint64
+
+	if maxSize := gm.changeLog.maxSize; maxSize > 0 && last > maxSize {
+		upto = last - maxSize
+	}
+
+	if maxAge := gm.changeLog.maxAge; maxAge > 0 {
+
+		first, err := gm.firstChangeSeq(cht)
+		if err != nil {
+			return err
+		}
+
+		if first <= upto {
+			first = upto + 1
+		}
+
+		// Change sets are appended roughly in the order of their commit
+		// time - stop at the first change set which is young enough
+
+		cutoff := time.Now().Add(-maxAge).UnixNano()
+
+		for s := first; s < last; s++ {
+
+			obj, err := cht.Get([]byte(PrefixCLChangeSet + versionToString(s)))
+			if err != nil {
+				return &util.GraphError{Type: util.ErrReading, Detail: err.Error()}
+			} else if obj != nil && obj.(*changeSetEntry).Time >= cutoff {
+				break
+			}
+
+			upto = s
+		}
+	}
+
+	return gm.truncateChangeLog(cht, upto)
+}
+
+/*
+truncateChangeLog removes all change sets up to and including a given sequence
+number from a given change log. The last change set is always kept.
+*/
+func (gm *Manager) truncateChangeLog(cht *hash.HTree, seq uint64) error {
+
+	first, err := gm.firstChangeSeq(cht)
+	if err != nil {
+		return err
+	}
+
+	last, err := gm.lastChangeSeq(cht)
+	if err != nil {
+		return err
+	}
+
+	if last == 0 {
+		return nil
+	} else if seq >= last {
+		seq = last - 1
+	}
+
+	if seq < first {
+		return nil
+	}
+
+	for s := first; s <= seq; s++ {
+		if _, err := cht.Remove([]byte(PrefixCLChangeSet + versionToString(s))); err != nil {
+			return &util.GraphError{Type: util.ErrWriting, Detail: err.Error()}
+		}
+	}
+
+	if _, err := cht.Put([]byte(PrefixCLFirstSeq), seq+1); err != nil {
+		return &util.GraphError{Type: util.ErrWriting, Detail: err.Error()}
+	}
+
+	return nil
+}
+
+/*
+firstChangeSeq returns the sequence number of the first change set which is
+kept in a given change log.
+*/
+func (gm *Manager) firstChangeSeq(cht *hash.HTree) (uint64, error) {
+
+	obj, err := cht.Get([]byte(PrefixCLFirstSeq))
+	if err != nil {
+		return 0, &util.GraphError{Type: util.ErrReading, Detail: err.Error()}
+	} else if obj == nil {
+		return 1, nil
+	}
+
+	return obj.(uint64), nil
+}
+
+/*
+lastChangeSeq returns the sequence number of the last change set in a given
+change log.
+*/
+func (gm *Manager) lastChangeSeq(cht *hash.HTree) (uint64, error) {
+
+	obj, err := cht.Get([]byte(PrefixCLSeq))
+	if err != nil {
+		return 0, &util.GraphError{Type: util.ErrReading, Detail: err.Error()}
+	} else if obj == nil {
+		return 0, nil
+	}
+
+	return obj.(uint64), nil
+}
+
+/*
+changeSet converts a stored change set into a ChangeSet.
+*/
+func (cse *changeSetEntry) changeSet() *ChangeSet {
+
+	toNode := func(nodeData map[string]interface{}, isEdge bool) data.Node {
+		if nodeData == nil {
+			return nil
+		}
+
+		node := data.NewGraphNode()
+		for attr, val := range nodeData {
+			node.SetAttr(attr, val)
+		}
+
+		if isEdge {
+			return data.NewGraphEdgeFromNode(node)
+		}
+
+		return node
+	}
+
+	changes := make([]*Change, 0, len(cse.Changes))
+
+	for _, change := range cse.Changes {
+
+		op := ChangeUpsert
+		if change.After == nil {
+			op = ChangeDelete
+		}
+
+		changes = append(changes, &Change{op, change.Part, change.Kind, change.Key, change.IsEdge,
+			toNode(change.Before, change.IsEdge), toNode(change.After, change.IsEdge)})
+	}
+
+	return &ChangeSet{cse.Seq, cse.Version, time.Unix(0, cse.Time), cse.TransID, changes}
+}
@@ -0,0 +1,498 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+)
+
+func TestChangeLog(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	constructNode := func(key string, name string) data.Node {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "Person")
+		node.SetAttr("name", name)
+		return node
+	}
+
+	if res, err := gm.FetchChanges(0, 0); len(res) != 0 || err != nil {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	notify := gm.ChangeNotify()
+
+	// Commit a transaction with two nodes and an edge
+
+	trans := NewGraphTrans(gm)
+
+	trans.StoreNode("main", constructNode("1", "John"))
+	trans.StoreNode("main", constructNode("2", "Jane"))
+
+	edge := data.NewGraphEdge()
+	edge.SetAttr("key", "abc")
+	edge.SetAttr("kind", "Knows")
+	edge.SetAttr(data.EdgeEnd1Key, "1")
+	edge.SetAttr(data.EdgeEnd1Kind, "Person")
+	edge.SetAttr(data.EdgeEnd1Role, "friend")
+	edge.SetAttr(data.EdgeEnd1Cascading, false)
+	edge.SetAttr(data.EdgeEnd2Key, "2")
+	edge.SetAttr(data.EdgeEnd2Kind, "Person")
+	edge.SetAttr(data.EdgeEnd2Role, "friend")
+	edge.SetAttr(data.EdgeEnd2Cascading, false)
+
+	trans.StoreEdge("main", edge)
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	select {
+	case <-notify:
+	default:
+		t.Error("Consumers should have been notified")
+		return
+	}
+
+	res, err := gm.FetchChanges(0, 0)
+	if err != nil || len(res) != 1 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if cs := res[0]; cs.Seq != 1 || cs.Version != gm.CurrentVersion() || cs.TransID != trans.ID() ||
+		len(cs.Changes) != 3 {
+		t.Error("Unexpected change set:", cs)
+		return
+	}
+
+	for _, change := range res[0].Changes {
+		if change.Op != ChangeUpsert || change.Part != "main" || change.Before != nil || change.After == nil {
+			t.Error("Unexpected change:", change)
+			return
+		}
+
+		if change.IsEdge {
+			if change.Key != "abc" || change.Kind != "Knows" || change.After.(data.Edge).End2Key() != "2" {
+				t.Error("Unexpected change:", change)
+				return
+			}
+		} else if change.Kind != "Person" || change.After.Attr("name") != map[string]string{
+			"1": "John", "2": "Jane"}[change.Key] {
+
+			t.Error("Unexpected change:", change)
+			return
+		}
+	}
+
+	// Update a node without a transaction
+
+	if err := gm.UpdateNode("main", constructNode("1", "Johnny")); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Update a node twice in the same transaction - only the state before
+	// and after the commit is recorded
+
+	trans = NewGraphTrans(gm)
+
+	trans.UpdateNode("main", constructNode("2", "Janet"))
+	trans.UpdateNode("main", constructNode("2", "Jenny"))
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Remove a node - the edge is removed by a rule
+
+	if _, err := gm.RemoveNode("main", "2", "Person"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if seq, err := gm.LastChangeSeq(); seq != 5 || err != nil {
+		t.Error("Unexpected result:", seq, err)
+		return
+	}
+
+	res, err = gm.FetchChanges(1, 2)
+	if err != nil || len(res) != 2 || res[0].Seq != 2 || res[1].Seq != 3 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if c := res[0].Changes[0]; len(res[0].Changes) != 1 || res[0].TransID != "" || c.Key != "1" ||
+		c.Before.Attr("name") != "John" || c.After.Attr("name") != "Johnny" {
+		t.Error("Unexpected change:", c)
+		return
+	}
+
+	if c := res[1].Changes[0]; len(res[1].Changes) != 1 || c.Key != "2" ||
+		c.Before.Attr("name") != "Jane" || c.After.Attr("name") != "Jenny" {
+		t.Error("Unexpected change:", c)
+		return
+	}
+
+	res, err = gm.FetchChanges(3, 0)
+	if err != nil || len(res) != 2 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	var deleted []string
+
+	for _, cs := range res {
+		for _, c := range cs.Changes {
+			if c.Op != ChangeDelete || c.Before == nil || c.After != nil {
+				t.Error("Unexpected change:", c)
+				return
+			}
+
+			deleted = append(deleted, fmt.Sprint(c.Kind, ":", c.Key))
+		}
+	}
+
+	if fmt.Sprint(deleted) != "[Knows:abc Person:2]" && fmt.Sprint(deleted) != "[Person:2 Knows:abc]" {
+		t.Error("Unexpected result:", deleted)
+		return
+	}
+
+	// Nodes which are created and removed in the same commit are not recorded
+
+	trans = NewGraphTrans(gm)
+
+	trans.StoreNode("main", constructNode("3", "Jim"))
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	commit := gm.newVersionCommit("")
+
+	commit.recordChange("main", "4", "Person", false, nil)
+
+	if err := gm.writeChangeSet(commit); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if seq, err := gm.LastChangeSeq(); seq != 6 || err != nil {
+		t.Error("Unexpected result:", seq, err)
+		return
+	}
+
+	// Reading from the end returns nothing
+
+	if res, err := gm.FetchChanges(6, 0); len(res) != 0 || err != nil {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+}
+
+func TestChangeLogRetention(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	storeNodes := func(keys ...string) {
+		for _, key := range keys {
+			node := data.NewGraphNode()
+			node.SetAttr("key", key)
+			node.SetAttr("kind", "Person")
+
+			if err := gm.StoreNode("main", node); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+
+	seqs := func(after uint64, limit int) string {
+		var res []uint64
+
+		changeSets, err := gm.FetchChanges(after, limit)
+		if err != nil {
+			return err.Error()
+		}
+
+		for _, cs := range changeSets {
+			res = append(res, cs.Seq)
+		}
+
+		return fmt.Sprint(res)
+	}
+
+	storeNodes("1", "2", "3", "4", "5")
+
+	// Check the default and the maximal limit
+
+	oldDefault, oldMax := DefaultChangeLimit, MaxChangeLimit
+	DefaultChangeLimit, MaxChangeLimit = 3, 4
+
+	if res := seqs(0, 0) + seqs(0, 10) + seqs(1, 2); res != "[1 2 3][1 2 3 4][2 3]" {
+		t.Error("Unexpected result:", res)
+	}
+
+	DefaultChangeLimit, MaxChangeLimit = oldDefault, oldMax
+
+	// Truncate the change log - the last change set is always kept
+
+	if err := gm.TruncateChanges(2); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if first, err := gm.FirstChangeSeq(); first != 3 || err != nil {
+		t.Error("Unexpected result:", first, err)
+		return
+	}
+
+	if res := seqs(0, 0) + seqs(3, 0); res != "[3 4 5][4 5]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if err := gm.TruncateChanges(10); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := seqs(0, 0); res != "[5]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// Remove change sets which exceed the maximal number of change sets
+
+	gm.SetChangeLogRetention(0, 2)
+
+	storeNodes("6", "7", "8")
+
+	if res := seqs(0, 0); res != "[7 8]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// Remove change sets which are too old
+
+	gm.SetChangeLogRetention(time.Millisecond, 0)
+
+	time.Sleep(5 * time.Millisecond)
+
+	storeNodes("9")
+
+	if res := seqs(0, 0); res != "[9]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if first, err := gm.FirstChangeSeq(); first != 9 || err != nil {
+		t.Error("Unexpected result:", first, err)
+		return
+	}
+}
+
+func TestChangeCursors(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	if cursor, ok := gm.ChangeCursor("test"); cursor != 0 || ok {
+		t.Error("Unexpected result:", cursor, ok)
+		return
+	}
+
+	if err := gm.SetChangeCursor("test", 5); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.SetChangeCursor("test2", 7); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Cursors are neither versioned nor recorded in the change log
+
+	if seq, err := gm.LastChangeSeq(); seq != 0 || err != nil || gm.CurrentVersion() != 0 {
+		t.Error("Unexpected result:", seq, err, gm.CurrentVersion())
+		return
+	}
+
+	if cursor, ok := gm.ChangeCursor("test"); cursor != 5 || !ok {
+		t.Error("Unexpected result:", cursor, ok)
+		return
+	}
+
+	// A rollback of the main database does not change cursors
+
+	mainDB := gm.snapshotMain()
+
+	if err := gm.SetChangeCursor("test", 6); err != nil {
+		t.Error(err)
+		return
+	}
+
+	gm.restoreMain(mainDB)
+
+	if cursor, ok := gm.ChangeCursor("test"); cursor != 6 || !ok {
+		t.Error("Unexpected result:", cursor, ok)
+		return
+	}
+
+	if err := gm.RemoveChangeCursor("test"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if cursor, ok := gm.ChangeCursor("test"); cursor != 0 || ok {
+		t.Error("Unexpected result:", cursor, ok)
+		return
+	}
+
+	if cursor, ok := gm.ChangeCursor("test2"); cursor != 7 || !ok {
+		t.Error("Unexpected result:", cursor, ok)
+		return
+	}
+}
+
+func TestChangeLogRecovery(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	constructNode := func(key string, name string) data.Node {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "Person")
+		node.SetAttr("name", name)
+		return node
+	}
+
+	for _, key := range []string{"1", "2"} {
+		if err := gm.StoreNode("main", constructNode(key, "John")); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	cht, _ := gm.getChangeLogHTree(false)
+
+	if pending, err := gm.pendingChangeSets(cht); pending != nil || err != nil {
+		t.Error("Unexpected result:", pending, err)
+		return
+	}
+
+	// Simulate a stop after the second commit was flushed but before its
+	// change set was appended
+
+	obj, _ := cht.Get([]byte(PrefixCLChangeSet + versionToString(2)))
+	entry := obj.(*changeSetEntry)
+
+	cht.Remove([]byte(PrefixCLChangeSet + versionToString(2)))
+	cht.Put([]byte(PrefixCLSeq), uint64(1))
+
+	if err := gm.writePendingChangeSet(&versionCommit{entry.Version, entry.Time,
+		entry.TransID, entry.Changes, nil}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Simulate a stop of a commit which was not flushed
+
+	commit := gm.newVersionCommit("")
+	commit.recordChange("main", "3", "Person", false, nil).After = constructNode("3", "Jim").Data()
+
+	if err := gm.writePendingChangeSet(commit); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if pending, err := gm.pendingChangeSets(cht); fmt.Sprint(pending) != fmt.Sprintf("[%v %v]",
+		entry.Version, commit.version) || err != nil {
+		t.Error("Unexpected result:", pending, err)
+		return
+	}
+
+	// Only the flushed commit is recovered
+
+	gm = NewGraphManager(mgs)
+
+	res, err := gm.FetchChanges(0, 0)
+	if err != nil || len(res) != 2 || res[1].Seq != 2 || res[1].Version != entry.Version ||
+		res[1].Changes[0].Key != "2" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if pending, err := gm.pendingChangeSets(cht); pending != nil || err != nil {
+		t.Error("Unexpected result:", pending, err)
+		return
+	}
+
+	if obj, err := cht.Get([]byte(PrefixCLPendingSet + versionToString(commit.version))); obj != nil || err != nil {
+		t.Error("Unexpected result:", obj, err)
+		return
+	}
+}
+
+func TestChangeLogDiskStorage(t *testing.T) {
+
+	if !RunDiskStorageTests {
+		return
+	}
+
+	dgs, err := graphstorage.NewDiskGraphStorage(GraphManagerTestDBDir7, false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	gm := NewGraphManager(dgs)
+
+	node := data.NewGraphNode()
+	node.SetAttr("key", "1")
+	node.SetAttr("kind", "Person")
+
+	if err := gm.StoreNode("main", node); err != nil {
+		t.Error(err)
+		return
+	}
+
+	dgs.Close()
+
+	// The change log survives a restart
+
+	dgs, err = graphstorage.NewDiskGraphStorage(GraphManagerTestDBDir7, false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer dgs.Close()
+
+	gm = NewGraphManager(dgs)
+
+	if err := gm.StoreNode("main", node); err != nil {
+		t.Error(err)
+		return
+	}
+
+	res, err := gm.FetchChanges(0, 0)
+	if err != nil || len(res) != 2 || res[1].Seq != 2 || res[1].Changes[0].Before == nil {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+}
@@ -8,7 +8,6 @@
 package graph
 
 import (
-	"encoding/binary"
 	"encoding/gob"
 	"fmt"
 	"sort"
@@ -44,12 +43,7 @@ func init() {
 EdgeCount returns the edge count for a given edge kind.
 */
 func (gm *Manager) EdgeCount(kind string) uint64 {
-
-	if val, ok := gm.gs.MainDB()[MainDBEdgeCount+kind]; ok {
-		return binary.LittleEndian.Uint64([]byte(val))
-	}
-
-	return 0
+	return gm.readCount(MainDBEdgeCount + kind)
 }
 
 /*
@@ -64,8 +58,8 @@ func (gm *Manager) FetchNodeEdgeSpecs(part string, key string, kind string) ([]s
 
 	// Take reader lock
 
-	gm.mutex.RLock()
-	defer gm.mutex.RUnlock()
+	gm.rlockPartition(part)
+	defer gm.runlockPartition(part)
 
 	specsNodeKey := PrefixNSSpecs + key
 	obj, err := tree.Get([]byte(specsNodeKey))
@@ -173,8 +167,8 @@ func (gm *Manager) Traverse(part string, key string, kind string,
 
 	// Take reader lock
 
-	gm.mutex.RLock()
-	defer gm.mutex.RUnlock()
+	gm.rlockPartition(part)
+	defer gm.runlockPartition(part)
 
 	sspec := strings.Split(spec, ":")
 	if len(sspec) != 4 {
@@ -298,7 +292,9 @@ func (gm *Manager) FetchEdge(part string, key string, kind string) (data.Edge, e
 }
 
 /*
-FetchEdgePart fetches part of a single edge from a partition of the graph.
+FetchEdgePart fetches part of a single edge from a partition of the graph. The
+current revision of the edge is included as data.NodeRevision attribute if all
+attributes are fetched.
 */
 func (gm *Manager) FetchEdgePart(part string, key string, kind string,
 	attrs []string) (data.Edge, error) {
@@ -312,12 +308,15 @@ func (gm *Manager) FetchEdgePart(part string, key string, kind string,
 
 	// Take reader lock
 
-	gm.mutex.RLock()
-	defer gm.mutex.RUnlock()
+	gm.rlockPartition(part)
+	defer gm.runlockPartition(part)
 
-	// Read the edge from the datastore
+	// Read the edge and its revision from the datastore
 
 	node, err := gm.readNode(key, kind, attrs, edgeht, edgeht)
+	if err == nil && len(attrs) == 0 {
+		err = gm.addRevision(node, edgeht)
+	}
 
 	return data.NewGraphEdgeFromNode(node), err
 }
@@ -396,8 +395,18 @@ func (gm *Manager) StoreEdge(part string, edge data.Edge) error {
 
 		// Take writer lock
 
-		gm.mutex.Lock()
-		defer gm.mutex.Unlock()
+		locks, err := gm.lockPartition(part)
+		if err != nil {
+			return err
+		}
+		defer gm.unlockPartitions(locks)
+
+		// Get the edge index again - a rebuilt index may have replaced the
+		// index before the writer lock was taken
+
+		if iht, err = gm.getEdgeIndexHTree(part, edge.Kind(), true); err != nil {
+			return err
+		}
 
 		// Write edge to the datastore
 
@@ -406,15 +415,29 @@ func (gm *Manager) StoreEdge(part string, edge data.Edge) error {
 			return err
 		}
 
+		// Record the new state of the edge in the edge history
+
+		commit := gm.newVersionCommit("")
+
+		if err := gm.writeEdgeVersion(part, edge.Key(), edge.Kind(), commit, edgeht); err != nil {
+			return err
+		}
+
 		// Increase edge count if the edge was inserted and write the changes
 		// to the index.
 
 		if oldedge == nil {
 
+			// Record the new edge on both ends in the node history
+
+			if err := gm.writeHistoryEdge(part, edge); err != nil {
+				return err
+			}
+
 			// Increase edge count
 
-			currentCount := gm.EdgeCount(edge.Kind())
-			if err := gm.writeEdgeCount(edge.Kind(), currentCount+1, true); err != nil {
+			gm.updateCount(MainDBEdgeCount+edge.Kind(), 1)
+			if err := gm.flushMain(); err != nil {
 				return err
 			}
 
@@ -422,7 +445,7 @@ func (gm *Manager) StoreEdge(part string, edge data.Edge) error {
 
 			if iht != nil {
 
-				if err := util.NewIndexManager(iht).Index(edge.Key(), edge.IndexMap()); err != nil {
+				if err := gm.edgeIndexManager(iht, edge.Kind()).Index(edge.Key(), edge.IndexMap()); err != nil {
 
 					// The edge was written at this point and the model is
 					// consistent only the index is missing entries
@@ -433,7 +456,7 @@ func (gm *Manager) StoreEdge(part string, edge data.Edge) error {
 
 		} else if iht != nil {
 
-			err := util.NewIndexManager(iht).Reindex(edge.Key(), edge.IndexMap(),
+			err := gm.edgeIndexManager(iht, edge.Kind()).Reindex(edge.Key(), edge.IndexMap(),
 				oldedge.IndexMap())
 
 			if err != nil {
@@ -445,25 +468,33 @@ func (gm *Manager) StoreEdge(part string, edge data.Edge) error {
 			}
 		}
 
-		defer func() {
-
-			// Flush changes - errors only reported on the actual node storage flush
+		// Store the change as a pending change set before anything is flushed
 
-			gm.gs.FlushMain()
+		if err := gm.writePendingChangeSet(commit); err != nil {
+			return err
+		}
 
-			gm.flushEdgeIndex(part, edge.Kind())
+		// Flush changes - the change is only appended to the change log once
+		// it was flushed
 
-			gm.flushNodeStorage(part, edge.End1Kind())
+		if err := firstError(gm.flushMain(), gm.flushEdgeIndex(part, edge.Kind()),
+			gm.flushNodeStorage(part, edge.End1Kind()), gm.flushNodeStorage(part, edge.End2Kind()),
+			gm.flushEdgeStorage(part, edge.Kind()), gm.flushNodeHistory(part, edge.End1Kind()),
+			gm.flushNodeHistory(part, edge.End2Kind()), gm.flushEdgeHistory(part, edge.Kind())); err != nil {
+			return err
+		}
 
-			gm.flushNodeStorage(part, edge.End2Kind())
+		// Append the change to the change log
 
-			gm.flushEdgeStorage(part, edge.Kind())
-		}()
+		if err := gm.writeChangeSet(commit); err != nil {
+			return err
+		}
 
 		// Execute rules
 
 		trans := newInternalGraphTrans(gm)
 		trans.subtrans = true
+		trans.locks = locks
 
 		var event int
 		if oldedge == nil {
@@ -650,8 +681,18 @@ func (gm *Manager) RemoveEdge(part string, key string, kind string) (data.Edge,
 
 		// Take writer lock
 
-		gm.mutex.Lock()
-		defer gm.mutex.Unlock()
+		locks, err := gm.lockPartition(part)
+		if err != nil {
+			return nil, err
+		}
+		defer gm.unlockPartitions(locks)
+
+		// Get the edge index again - a rebuilt index may have replaced the
+		// index before the writer lock was taken
+
+		if iht, err = gm.getEdgeIndexHTree(part, kind, true); err != nil {
+			return nil, err
+		}
 
 		// Delete the node from the datastore
 
@@ -681,8 +722,16 @@ func (gm *Manager) RemoveEdge(part string, key string, kind string) (data.Edge,
 				return edge, err
 			}
 
+			// Record the removal of the edge in the edge history
+
+			commit := gm.newVersionCommit("")
+
+			if err := gm.writeEdgeVersion(part, key, kind, commit, edgeht); err != nil {
+				return edge, err
+			}
+
 			if iht != nil {
-				err := util.NewIndexManager(iht).Deindex(key, edge.IndexMap())
+				err := gm.edgeIndexManager(iht, kind).Deindex(key, edge.IndexMap())
 				if err != nil {
 					return edge, err
 				}
@@ -690,30 +739,34 @@ func (gm *Manager) RemoveEdge(part string, key string, kind string) (data.Edge,
 
 			// Decrease edge count
 
-			currentCount := gm.EdgeCount(edge.Kind())
-			if err := gm.writeEdgeCount(edge.Kind(), currentCount-1, true); err != nil {
-				return edge, err
-			}
-
-			defer func() {
+			gm.updateCount(MainDBEdgeCount+edge.Kind(), -1)
 
-				// Flush changes - errors only reported on the actual node storage flush
+			// Store the change as a pending change set before anything is flushed
 
-				gm.gs.FlushMain()
+			if err := gm.writePendingChangeSet(commit); err != nil {
+				return edge, err
+			}
 
-				gm.flushEdgeIndex(part, edge.Kind())
+			// Flush changes - the change is only appended to the change log
+			// once it was flushed
 
-				gm.flushNodeStorage(part, edge.End1Kind())
+			if err := firstError(gm.flushMain(), gm.flushEdgeIndex(part, edge.Kind()),
+				gm.flushNodeStorage(part, edge.End1Kind()), gm.flushNodeStorage(part, edge.End2Kind()),
+				gm.flushEdgeStorage(part, edge.Kind()), gm.flushEdgeHistory(part, edge.Kind())); err != nil {
+				return edge, err
+			}
 
-				gm.flushNodeStorage(part, edge.End2Kind())
+			// Append the change to the change log
 
-				gm.flushEdgeStorage(part, edge.Kind())
-			}()
+			if err := gm.writeChangeSet(commit); err != nil {
+				return edge, err
+			}
 
 			// Execute rules
 
 			trans := newInternalGraphTrans(gm)
 			trans.subtrans = true
+			trans.locks = locks
 
 			if err := gm.gr.graphEvent(trans, EventEdgeDeleted, part, edge); err != nil && err != ErrEventHandled {
 				return edge, err
@@ -849,5 +902,5 @@ func (gm *Manager) deleteEdge(edge data.Edge, end1Tree *hash.HTree, end2Tree *ha
 Default filter function to filter out system edge attributes.
 */
 func edgeAttributeFilter(attr string) bool {
-	return attr == data.NodeKey || attr == data.NodeKind
+	return attr == data.NodeKey || attr == data.NodeKind || attr == data.NodeRevision
 }
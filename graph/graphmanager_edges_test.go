@@ -126,6 +126,8 @@ func TestSimpleGraphStorage(t *testing.T) {
 		return
 	}
 
+	fetchedEdge.SetAttr(data.NodeRevision, nil)
+
 	if !data.NodeCompare(edge, fetchedEdge, nil) {
 		t.Error("Fetched edge should contain the same data as the stored edge")
 		return
@@ -647,7 +649,7 @@ func TestSimpleGraphStorageErrorCases(t *testing.T) {
 	delete(sm.(*storage.MemoryStorageManager).AccessMap, 1)
 
 	sm = gm.gs.StorageManager("main"+"myedge"+StorageSuffixEdgesIndex, false)
-	sm.(*storage.MemoryStorageManager).AccessMap[5] = storage.AccessInsertError
+	sm.(*storage.MemoryStorageManager).AccessMap[8] = storage.AccessInsertError
 
 	edge.SetAttr("name", "New edge name")
 
@@ -656,7 +658,7 @@ func TestSimpleGraphStorageErrorCases(t *testing.T) {
 		return
 	}
 
-	delete(sm.(*storage.MemoryStorageManager).AccessMap, 5)
+	delete(sm.(*storage.MemoryStorageManager).AccessMap, 8)
 
 	resetStorage := func() {
 		mgs = graphstorage.NewMemoryGraphStorage("mystorage")
@@ -0,0 +1,601 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+	"github.com/Fisch-Labs/FishDB/hash"
+)
+
+/*
+IndexSchema returns the index schema of a node or edge kind as a map of
+attribute to index mode. The mode for all attributes of the kind is stored
+under the empty attribute name. Attributes which are not part of the schema
+are indexed in full text mode.
+*/
+func (gm *Manager) IndexSchema(kind string) map[string]string {
+
+	// Take reader lock
+
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+
+	return gm.indexSchema(kind)
+}
+
+/*
+SetIndexMode sets the index mode of a node or edge attribute (see
+util.IndexModes). If the attribute is empty then the mode is used for all
+attributes of the kind which have no mode of their own. An empty mode removes
+the attribute from the schema. The schema is stored in the main database.
+Values which were indexed before the schema was changed keep their index
+entries until the index is rebuilt (see RebuildIndex) - the index of the
+attribute is stale until then (see IndexStale).
+*/
+func (gm *Manager) SetIndexMode(kind string, attr string, mode string) error {
+	return gm.SetIndexModes(kind, map[string]string{attr: mode})
+}
+
+/*
+SetIndexModes sets the index modes of several attributes of a node or edge
+kind (see SetIndexMode). All modes are checked before the schema is changed -
+either all modes are set or none.
+*/
+func (gm *Manager) SetIndexModes(kind string, modes map[string]string) error {
+
+	if kind == "" || strings.Contains(kind, "#") {
+		return &util.GraphError{Type: util.ErrInvalidData, Detail: fmt.Sprint("Invalid node kind: ", kind)}
+	}
+
+	for _, mode := range modes {
+		if mode != "" {
+			var known bool
+
+			for _, m := range util.IndexModes {
+				known = known || m == mode
+			}
+
+			if !known {
+				return &util.GraphError{Type: util.ErrInvalidData, Detail: fmt.Sprint("Unknown index mode: ", mode)}
+			}
+		}
+	}
+
+	// Take writer lock
+
+	gm.mutex.Lock()
+	defer gm.mutex.Unlock()
+
+	oldSchema := gm.getMainDBMap(MainDBIndexSchema)
+
+	schema := make(map[string]string)
+	for k, v := range oldSchema {
+		schema[k] = v
+	}
+
+	for attr, mode := range modes {
+		if mode == "" {
+			delete(schema, kind+"#"+attr)
+		} else {
+			schema[kind+"#"+attr] = mode
+		}
+	}
+
+	// Mark the index of attributes with a changed mode as stale in all
+	// partitions which have nodes or edges of the kind - the mark identifies
+	// the change
+
+	stale := make(map[string]string)
+	for k, v := range gm.getMainDBMap(MainDBIndexStale) {
+		stale[k] = v
+	}
+
+	mark := fmt.Sprint(time.Now().UnixNano())
+
+	for attr := range modes {
+
+		if indexMode(oldSchema, kind, attr) != indexMode(schema, kind, attr) {
+			for _, part := range gm.mainStringList(MainDBParts) {
+				if gm.storageManager(part+kind+StorageSuffixNodes) != nil ||
+					gm.storageManager(part+kind+StorageSuffixEdges) != nil {
+
+					stale[part+"#"+kind+"#"+attr] = mark
+				}
+			}
+		}
+	}
+
+	gm.storeMainDBMap(MainDBIndexSchema, schema)
+	gm.storeMainDBMap(MainDBIndexStale, stale)
+
+	return gm.flushMain()
+}
+
+/*
+IndexStale returns if the index of an attribute of a kind in a partition does
+not match the index schema. This is the case if the index mode of the attribute
+(or the mode of all attributes of the kind) was changed after nodes or edges
+were indexed and the index was not rebuilt since.
+*/
+func (gm *Manager) IndexStale(part string, kind string, attr string) bool {
+	stale := gm.getMainDBMap(MainDBIndexStale)
+
+	_, ok := stale[part+"#"+kind+"#"+attr]
+	_, all := stale[part+"#"+kind+"#"]
+
+	return ok || all
+}
+
+/*
+RebuildIndex rebuilds the index of all nodes and edges of a given kind in a
+partition. Every node and edge is indexed again using the current index schema
+and analyzers. The new index is built in a second storage while the partition
+can still be written to - index lookups use the existing index until the new
+index is complete. Changes which were made during the build are then applied
+from the change log and the new index replaces the existing index. The
+partition cannot be written to while the changes are applied. Returns the
+number of indexed nodes and edges.
+*/
+func (gm *Manager) RebuildIndex(part string, kind string) (uint64, error) {
+	rkey := part + "#" + kind
+
+	gm.rebuildMutex.Lock()
+	running := gm.rebuilds[rkey]
+	gm.rebuilds[rkey] = true
+	gm.rebuildMutex.Unlock()
+
+	if running {
+		return 0, &util.GraphError{Type: util.ErrInvalidData,
+			Detail: fmt.Sprintf("Index of kind %v in partition %v is already being rebuilt", kind, part)}
+	}
+
+	defer func() {
+		gm.rebuildMutex.Lock()
+		delete(gm.rebuilds, rkey)
+		gm.rebuildMutex.Unlock()
+	}()
+
+	// Remember which stale marks are removed by this rebuild - the schema
+	// may change again while the index is rebuilt
+
+	marks := make(map[string]string)
+
+	for k, v := range gm.getMainDBMap(MainDBIndexStale) {
+		if strings.HasPrefix(k, rkey+"#") {
+			marks[k] = v
+		}
+	}
+
+	nodeCount, err := gm.rebuildIndex(part, kind, false)
+	if err != nil {
+		return nodeCount, err
+	}
+
+	edgeCount, err := gm.rebuildIndex(part, kind, true)
+	if err != nil {
+		return nodeCount + edgeCount, err
+	}
+
+	// Remove the stale marks which were set before the rebuild started
+
+	gm.mutex.Lock()
+	defer gm.mutex.Unlock()
+
+	gm.updateMainDBMap(MainDBIndexStale, func(stale map[string]string) bool {
+		for k, v := range marks {
+			if stale[k] == v {
+				delete(stale, k)
+			}
+		}
+		return len(marks) > 0
+	})
+
+	return nodeCount + edgeCount, gm.flushMain()
+}
+
+/*
+IndexRebuilding returns if the index of a given kind in a partition is being
+rebuilt. The existing index may not match the current index schema while the
+index is rebuilt.
+*/
+func (gm *Manager) IndexRebuilding(part string, kind string) bool {
+	gm.rebuildMutex.Lock()
+	defer gm.rebuildMutex.Unlock()
+
+	return gm.rebuilds[part+"#"+kind]
+}
+
+/*
+indexBuild holds the state of an index build. Every item is indexed in the
+state it had at the change log sequence number at which it was read.
+*/
+type indexBuild struct {
+	im    *util.IndexManager // Index manager of the new index
+	count uint64             // Number of indexed items
+	seq   uint64             // Change log sequence number at which the item keys were read
+	seqs  map[string]uint64  // Change log sequence numbers at which the items were read
+}
+
+/*
+rebuildIndex rebuilds the node or edge index of a kind. The index alternates
+between two storages - the new index is built in the storage which is not used
+and replaces the existing index by switching the storage.
+*/
+func (gm *Manager) rebuildIndex(part string, kind string, isEdge bool) (uint64, error) {
+	var ib *indexBuild
+
+	storageSuffix, indexSuffix := StorageSuffixNodes, StorageSuffixNodesIndex
+	if isEdge {
+		storageSuffix, indexSuffix = StorageSuffixEdges, StorageSuffixEdgesIndex
+	}
+
+	if gm.storageManager(part+kind+storageSuffix) == nil {
+		return 0, nil
+	}
+
+	name := part + kind + indexSuffix
+	active := gm.indexStorageName(part, kind, indexSuffix)
+
+	target := name
+	if active == name {
+		target = name + StorageSuffixRebuild
+	}
+
+	gm.storageMutex.Lock()
+	sm := gm.gs.StorageManager(target, true)
+	gm.storageMutex.Unlock()
+
+	// The storage may still contain entries of an aborted rebuild
+
+	newBuild := func() (*indexBuild, error) {
+
+		if _, err := gm.clearStorageHTrees(sm); err != nil {
+			return nil, err
+		}
+
+		iht, err := gm.getHTree(sm, RootIDNodeHTree)
+		if err != nil {
+			return nil, err
+		}
+
+		ib := &indexBuild{seqs: make(map[string]uint64)}
+
+		if isEdge {
+			ib.im = gm.edgeIndexManager(iht, kind)
+		} else {
+			ib.im = gm.nodeIndexManager(iht, kind)
+		}
+
+		return ib, nil
+	}
+
+	ib, err := newBuild()
+
+	if err == nil {
+		err = gm.buildIndex(part, kind, isEdge, ib, false)
+	}
+
+	if err == nil {
+		var locks *partitionLockSet
+
+		// Apply the changes which were made during the build and replace
+		// the existing index - the partition cannot be written to until the
+		// new index is in place
+
+		if locks, err = gm.lockPartition(part); err == nil {
+			var complete bool
+
+			if complete, err = gm.catchUpIndex(part, kind, isEdge, ib); err == nil && !complete {
+
+				// Changes were removed from the change log before they could
+				// be applied - the index is built again
+
+				if ib, err = newBuild(); err == nil {
+					err = gm.buildIndex(part, kind, isEdge, ib, true)
+				}
+			}
+
+			if err == nil {
+				if ferr := sm.Flush(); ferr != nil {
+					err = &util.GraphError{Type: util.ErrFlushing, Detail: ferr.Error()}
+				} else {
+					err = gm.switchIndexStorage(name, target)
+				}
+			}
+
+			gm.unlockPartitions(locks)
+		}
+	}
+
+	if err != nil {
+		sm.Rollback()
+
+		if ib == nil {
+			return 0, err
+		}
+
+		return ib.count, err
+	}
+
+	// Remove the entries of the replaced index
+
+	if old := gm.storageManager(active); old != nil {
+
+		if _, err := gm.clearStorageHTrees(old); err != nil {
+			old.Rollback()
+			return ib.count, err
+		}
+
+		if err := old.Flush(); err != nil {
+			return ib.count, &util.GraphError{Type: util.ErrFlushing, Detail: err.Error()}
+		}
+	}
+
+	return ib.count, nil
+}
+
+/*
+buildIndex indexes all items of a kind. Each item is read while the reader lock
+of the partition is held unless the caller holds the writer lock.
+*/
+func (gm *Manager) buildIndex(part string, kind string, isEdge bool, ib *indexBuild, locked bool) error {
+
+	storageSuffix := StorageSuffixNodes
+	if isEdge {
+		storageSuffix = StorageSuffixEdges
+	}
+
+	read := func(f func() error) error {
+		if !locked {
+			gm.rlockPartition(part)
+			defer gm.runlockPartition(part)
+		}
+		return f()
+	}
+
+	var keys map[string]string
+
+	err := read(func() error {
+		var err error
+
+		if ib.seq, err = gm.LastChangeSeq(); err == nil {
+			keys, err = gm.readStorageKeys(part, kind, storageSuffix)
+		}
+
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for key := range keys {
+		var obj map[string]string
+
+		err := read(func() error {
+			var err error
+
+			if ib.seqs[key], err = gm.LastChangeSeq(); err == nil {
+				obj, err = gm.readIndexMap(part, kind, isEdge, key)
+			}
+
+			return err
+		})
+
+		if err != nil {
+			return err
+		} else if obj == nil {
+			continue
+		}
+
+		if err := ib.im.Index(key, obj); err != nil {
+			return err
+		}
+
+		ib.count++
+	}
+
+	return nil
+}
+
+/*
+catchUpIndex applies all changes of items of a kind to a new index which were
+made after the items were read. Returns false if changes were removed from the
+change log before they could be applied. It is assumed that the caller holds
+the writer lock of the partition.
+*/
+func (gm *Manager) catchUpIndex(part string, kind string, isEdge bool, ib *indexBuild) (bool, error) {
+	var changeSets []*ChangeSet
+
+	for seq := ib.seq; ; {
+
+		res, err := gm.FetchChanges(seq, MaxChangeLimit)
+		if err != nil {
+			return false, err
+		} else if len(res) == 0 {
+			break
+		}
+
+		changeSets = append(changeSets, res...)
+		seq = res[len(res)-1].Seq
+	}
+
+	if first, err := gm.FirstChangeSeq(); err != nil || first > ib.seq+1 {
+		return false, err
+	}
+
+	// Remove the state which was indexed - this is the state before the
+	// first change after the item was read
+
+	changed := make(map[string]bool)
+
+	for _, changeSet := range changeSets {
+		for _, change := range changeSet.Changes {
+
+			if change.Part != part || change.Kind != kind || change.IsEdge != isEdge || changed[change.Key] {
+				continue
+			}
+
+			seq, ok := ib.seqs[change.Key]
+			if !ok {
+				seq = ib.seq
+			}
+
+			if changeSet.Seq <= seq {
+				continue
+			}
+
+			changed[change.Key] = true
+
+			if change.Before != nil {
+				obj := change.Before.IndexMap()
+				if isEdge {
+					obj = data.NewGraphEdgeFromNode(change.Before).IndexMap()
+				}
+
+				if err := ib.im.Deindex(change.Key, obj); err != nil {
+					return false, err
+				}
+
+				ib.count--
+			}
+		}
+	}
+
+	// Index the current state of all changed items
+
+	for key := range changed {
+
+		obj, err := gm.readIndexMap(part, kind, isEdge, key)
+		if err != nil {
+			return false, err
+		} else if obj == nil {
+			continue
+		}
+
+		if err := ib.im.Index(key, obj); err != nil {
+			return false, err
+		}
+
+		ib.count++
+	}
+
+	return true, nil
+}
+
+/*
+readIndexMap reads the index map of a node or edge. Returns nil if the item
+does not exist. It is assumed that the caller holds a lock.
+*/
+func (gm *Manager) readIndexMap(part string, kind string, isEdge bool, key string) (map[string]string, error) {
+
+	if isEdge {
+
+		edgeht, err := gm.getEdgeStorageHTree(part, kind, false)
+		if err != nil || edgeht == nil {
+			return nil, err
+		}
+
+		node, err := gm.readNode(key, kind, nil, edgeht, edgeht)
+		if err != nil || node == nil {
+			return nil, err
+		}
+
+		return data.NewGraphEdgeFromNode(node).IndexMap(), nil
+	}
+
+	attht, valht, err := gm.getNodeStorageHTree(part, kind, false)
+	if err != nil || attht == nil || valht == nil {
+		return nil, err
+	}
+
+	node, err := gm.readNode(key, kind, nil, attht, valht)
+	if err != nil || node == nil {
+		return nil, err
+	}
+
+	return node.IndexMap(), nil
+}
+
+/*
+switchIndexStorage switches the storage of an index. Index lookups have to
+wait until the storage was switched.
+*/
+func (gm *Manager) switchIndexStorage(name string, storage string) error {
+	lock := gm.indexLocks.get(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Index storages of different kinds may be switched at the same time
+
+	gm.rebuildMutex.Lock()
+	defer gm.rebuildMutex.Unlock()
+
+	storages := make(map[string]string)
+	for k, v := range gm.getMainDBMap(MainDBIndexStorages) {
+		storages[k] = v
+	}
+
+	if storage == name {
+		delete(storages, name)
+	} else {
+		storages[name] = storage
+	}
+
+	gm.storeMainDBMap(MainDBIndexStorages, storages)
+
+	return gm.flushMain()
+}
+
+/*
+indexSchema returns the index schema of a kind. It is assumed that the caller
+holds a lock.
+*/
+func (gm *Manager) indexSchema(kind string) map[string]string {
+	ret := make(map[string]string)
+
+	for k, mode := range gm.getMainDBMap(MainDBIndexSchema) {
+		if strings.HasPrefix(k, kind+"#") {
+			ret[k[len(kind)+1:]] = mode
+		}
+	}
+
+	return ret
+}
+
+/*
+indexMode returns the index mode of an attribute of a kind in a given index
+schema.
+*/
+func indexMode(schema map[string]string, kind string, attr string) string {
+	if mode, ok := schema[kind+"#"+attr]; ok {
+		return mode
+	} else if mode, ok := schema[kind+"#"]; ok {
+		return mode
+	}
+	return util.IndexModeFullText
+}
+
+/*
+edgeIndexManager returns an index manager for the index of an edge kind which
+uses the configured index schema. It is assumed that the caller holds a lock.
+*/
+func (gm *Manager) edgeIndexManager(iht *hash.HTree, kind string) *util.IndexManager {
+	im := util.NewIndexManager(iht)
+
+	for attr, mode := range gm.indexSchema(kind) {
+		im.SetIndexMode(attr, mode)
+	}
+
+	return im
+}
@@ -0,0 +1,496 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+)
+
+func TestIndexSchema(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	if err := gm.SetIndexMode("doc", "", "value"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.SetIndexMode("doc", "title", "fulltext"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.SetIndexMode("doc", "blob", "none"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.SetIndexMode("link", "note", "none"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := gm.IndexSchema("doc"); fmt.Sprint(res) != "map[:value blob:none title:fulltext]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	constructNode := func(key, title, text string) data.Node {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "doc")
+		node.SetAttr("title", title)
+		node.SetAttr("text", text)
+		node.SetAttr("blob", text)
+		return node
+	}
+
+	gm.StoreNode("main", constructNode("1", "First doc", "Some text"))
+
+	trans := NewGraphTrans(gm)
+	trans.StoreNode("main", constructNode("2", "Second doc", "More text"))
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	edge := data.NewGraphEdge()
+	edge.SetAttr("key", "1")
+	edge.SetAttr("kind", "link")
+	edge.SetAttr(data.EdgeEnd1Key, "1")
+	edge.SetAttr(data.EdgeEnd1Kind, "doc")
+	edge.SetAttr(data.EdgeEnd1Role, "from")
+	edge.SetAttr(data.EdgeEnd1Cascading, false)
+	edge.SetAttr(data.EdgeEnd2Key, "2")
+	edge.SetAttr(data.EdgeEnd2Kind, "doc")
+	edge.SetAttr(data.EdgeEnd2Role, "to")
+	edge.SetAttr(data.EdgeEnd2Cascading, false)
+	edge.SetAttr("note", "Some note")
+	edge.SetAttr("name", "Some link")
+
+	if err := gm.StoreEdge("main", edge); err != nil {
+		t.Error(err)
+		return
+	}
+
+	iq, _ := gm.NodeIndexQuery("main", "doc")
+
+	if res, err := iq.LookupWord("title", "doc"); err != nil || len(res) != 2 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := iq.LookupWord("text", "text"); err != nil || res != nil {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := iq.LookupValue("text", "More text"); err != nil || fmt.Sprint(res) != "[2]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := iq.LookupValue("blob", "More text"); err != nil || len(res) != 0 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	eiq, _ := gm.EdgeIndexQuery("main", "link")
+
+	if res, err := eiq.LookupWord("note", "note"); err != nil || res != nil {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := eiq.LookupWord("name", "link"); err != nil || fmt.Sprint(res) != "map[1:[2]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Change the schema and rebuild the index
+
+	gm.SetIndexMode("doc", "", "")
+	gm.SetIndexMode("link", "note", "fulltext")
+
+	if res, err := iq.LookupWord("text", "text"); err != nil || res != nil {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := gm.RebuildIndex("main", "doc"); err != nil || res != 2 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := gm.RebuildIndex("main", "link"); err != nil || res != 1 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := iq.LookupWord("text", "text"); err != nil || len(res) != 2 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := iq.LookupValue("blob", "More text"); err != nil || len(res) != 0 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := eiq.LookupWord("note", "note"); err != nil || fmt.Sprint(res) != "map[1:[2]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Removing the last node of a full text attribute removes all its words
+
+	gm.SetIndexMode("doc", "blob", "")
+
+	if res, err := gm.RebuildIndex("main", "doc"); err != nil || res != 2 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	gm.RemoveNode("main", "1", "doc")
+
+	if res, err := iq.LookupWord("blob", "text"); err != nil || fmt.Sprint(res) != "map[2:[2]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := gm.RebuildIndex("main", "unknown"); err != nil || res != 0 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Lookups return complete results while the index is rebuilt
+
+	done := make(chan error)
+
+	go func() {
+		var err error
+		for i := 0; i < 20 && err == nil; i++ {
+			_, err = gm.RebuildIndex("main", "doc")
+		}
+		done <- err
+	}()
+
+	for running := true; running; {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			running = false
+		default:
+			if res, err := iq.LookupWord("blob", "text"); err != nil || fmt.Sprint(res) != "map[2:[2]]" {
+				t.Error("Unexpected result:", res, err)
+				return
+			}
+		}
+	}
+
+	if gm.IndexRebuilding("main", "doc") {
+		t.Error("Index should not be rebuilt anymore")
+		return
+	}
+
+	gm.rebuilds["main#doc"] = true
+
+	if !gm.IndexRebuilding("main", "doc") {
+		t.Error("Index should be rebuilt")
+		return
+	}
+
+	if _, err := gm.RebuildIndex("main", "doc"); err == nil || err.Error() !=
+		"GraphError: Invalid data (Index of kind doc in partition main is already being rebuilt)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	delete(gm.rebuilds, "main#doc")
+
+	// Test error cases
+
+	if err := gm.SetIndexMode("doc", "title", "foo"); err == nil ||
+		err.Error() != "GraphError: Invalid data (Unknown index mode: foo)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if err := gm.SetIndexModes("doc", map[string]string{"text": "none", "title": "foo"}); err == nil ||
+		err.Error() != "GraphError: Invalid data (Unknown index mode: foo)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if res := gm.IndexSchema("doc"); fmt.Sprint(res) != "map[title:fulltext]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if err := gm.SetIndexMode("", "title", "none"); err == nil ||
+		err.Error() != "GraphError: Invalid data (Invalid node kind: )" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+}
+
+func TestRangeIndexSchema(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	gm.SetIndexMode("person", "age", "range")
+
+	for i, age := range []interface{}{30, 25.5, "41", "unknown"} {
+		node := data.NewGraphNode()
+		node.SetAttr("key", fmt.Sprint(i))
+		node.SetAttr("kind", "person")
+		node.SetAttr("age", age)
+		gm.StoreNode("main", node)
+	}
+
+	iq, _ := gm.NodeIndexQuery("main", "person")
+
+	if res, err := iq.LookupRange("age", "26", ""); err != nil || fmt.Sprint(res) != "[0 2]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	node := data.NewGraphNode()
+	node.SetAttr("key", "0")
+	node.SetAttr("kind", "person")
+	node.SetAttr("age", 20)
+	gm.UpdateNode("main", node)
+
+	gm.RemoveNode("main", "2", "person")
+
+	if res, err := iq.LookupRange("age", "", ""); err != nil || fmt.Sprint(res) != "[0 1]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := iq.LookupValue("age", "unknown"); err != nil || fmt.Sprint(res) != "[3]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Changing the mode of an attribute with indexed values makes the index stale
+
+	gm.SetIndexMode("person", "height", "fulltext")
+
+	if gm.IndexStale("main", "person", "height") || gm.IndexStale("main", "person", "age") {
+		t.Error("Index should not be stale")
+		return
+	}
+
+	node = data.NewGraphNode()
+	node.SetAttr("key", "4")
+	node.SetAttr("kind", "person")
+	node.SetAttr("height", 180)
+	gm.StoreNode("main", node)
+
+	gm.SetIndexMode("person", "height", "range")
+
+	if !gm.IndexStale("main", "person", "height") || gm.IndexStale("main", "person", "age") ||
+		gm.IndexStale("other", "person", "height") {
+		t.Error("Only the index of height should be stale")
+		return
+	}
+
+	if res, err := iq.LookupRange("height", "", ""); err == nil || err.Error() !=
+		"GraphError: Index error (Index of attribute height is stale until the index of kind person in partition main is rebuilt)" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Snapshots have the same stale index
+
+	gm.CreateSnapshot("main", "snap")
+
+	if !gm.IndexStale("snap", "person", "height") {
+		t.Error("Index should be stale")
+		return
+	}
+
+	gm.DiscardSnapshot("snap")
+
+	if gm.IndexStale("snap", "person", "height") {
+		t.Error("Index should not be stale")
+		return
+	}
+
+	// A mode change of all attributes makes all attributes stale
+
+	gm.SetIndexMode("person", "", "value")
+
+	if !gm.IndexStale("main", "person", "age") {
+		t.Error("Index should be stale")
+		return
+	}
+
+	gm.SetIndexMode("person", "", "")
+
+	if res, err := gm.RebuildIndex("main", "person"); err != nil || res != 4 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if gm.IndexStale("main", "person", "height") || gm.IndexStale("main", "person", "age") {
+		t.Error("Index should not be stale")
+		return
+	}
+
+	if res, err := iq.LookupRange("height", "", ""); err != nil || fmt.Sprint(res) != "[4]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+}
+
+func TestRebuildIndexChanges(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	constructNode := func(key, text string) data.Node {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "doc")
+		node.SetAttr("text", text)
+		return node
+	}
+
+	for i := 0; i < 5; i++ {
+		gm.StoreNode("main", constructNode(fmt.Sprint(i), "Some text"))
+	}
+
+	// Build an index and apply the changes which were made afterwards
+
+	iht, _ := gm.getHTree(mgs.StorageManager("test", true), RootIDNodeHTree)
+	ib := &indexBuild{im: gm.nodeIndexManager(iht, "doc"), seqs: make(map[string]uint64)}
+
+	if err := gm.buildIndex("main", "doc", false, ib, false); err != nil || ib.count != 5 {
+		t.Error("Unexpected result:", ib.count, err)
+		return
+	}
+
+	gm.UpdateNode("main", constructNode("0", "Changed text"))
+	gm.RemoveNode("main", "1", "doc")
+	gm.StoreNode("main", constructNode("5", "New text"))
+	gm.UpdateNode("main", constructNode("5", "Newer text"))
+
+	if res, err := ib.im.LookupWord("text", "some"); err != nil || len(res) != 5 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if ok, err := gm.catchUpIndex("main", "doc", false, ib); !ok || err != nil || ib.count != 5 {
+		t.Error("Unexpected result:", ok, ib.count, err)
+		return
+	}
+
+	if res, err := ib.im.LookupWord("text", "some"); err != nil || fmt.Sprint(res) != "map[2:[1] 3:[1] 4:[1]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := ib.im.LookupWord("text", "changed"); err != nil || fmt.Sprint(res) != "map[0:[1]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := ib.im.LookupWord("text", "new"); err != nil || res != nil {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := ib.im.LookupWord("text", "newer"); err != nil || fmt.Sprint(res) != "map[5:[1]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Changes cannot be applied if they were removed from the change log
+
+	gm.StoreNode("main", constructNode("6", "Some text"))
+
+	last, _ := gm.LastChangeSeq()
+	gm.TruncateChanges(last)
+
+	if ok, err := gm.catchUpIndex("main", "doc", false, ib); ok || err != nil {
+		t.Error("Unexpected result:", ok, err)
+		return
+	}
+
+	// A rebuilt index alternates between two storages
+
+	iq, _ := gm.NodeIndexQuery("main", "doc")
+
+	for _, storage := range []string{"maindoc.nodeidx.rebuild", "maindoc.nodeidx"} {
+
+		if res, err := gm.RebuildIndex("main", "doc"); err != nil || res != 6 {
+			t.Error("Unexpected result:", res, err)
+			return
+		}
+
+		if res := gm.indexStorageName("main", "doc", StorageSuffixNodesIndex); res != storage {
+			t.Error("Unexpected result:", res)
+			return
+		}
+
+		if res, err := iq.LookupWord("text", "some"); err != nil || fmt.Sprint(res) != "map[2:[1] 3:[1] 4:[1] 6:[1]]" {
+			t.Error("Unexpected result:", res, err)
+			return
+		}
+	}
+
+	// The replaced index storage is cleared
+
+	if res, err := gm.clearStorageHTrees(mgs.StorageManager("maindoc.nodeidx.rebuild", false)); err != nil || res != 0 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Nodes can be written while the index is rebuilt
+
+	done := make(chan error)
+
+	go func() {
+		var err error
+		for i := 0; i < 10 && err == nil; i++ {
+			_, err = gm.RebuildIndex("main", "doc")
+		}
+		done <- err
+	}()
+
+	for i := 0; i < 50; i++ {
+		if err := gm.StoreNode("main", constructNode(fmt.Sprint("w", i), "Other text")); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	if err := <-done; err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res, err := iq.LookupWord("text", "other"); err != nil || len(res) != 50 {
+		t.Error("Unexpected result:", len(res), err)
+		return
+	}
+
+	if res, err := iq.LookupWord("text", "text"); err != nil || len(res) != 56 {
+		t.Error("Unexpected result:", len(res), err)
+		return
+	}
+}
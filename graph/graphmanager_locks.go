@@ -0,0 +1,269 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Fisch-Labs/FishDB/graph/util"
+)
+
+/*
+partitionLockTimeout is the time a commit waits for a partition lock which
+cannot be taken in order.
+*/
+var partitionLockTimeout = 5 * time.Second
+
+/*
+partitionLockRetry is the time between two attempts to take a partition lock
+which cannot be taken in order.
+*/
+var partitionLockRetry = time.Millisecond
+
+/*
+partitionLocks is a table of reader / writer locks - one lock for each partition
+(or for each index storage).
+*/
+type partitionLocks struct {
+	locks map[string]*sync.RWMutex // Locks of all partitions which have been used
+	mutex *sync.Mutex              // Mutex to protect the lock table
+}
+
+/*
+newPartitionLocks creates a new partition lock table.
+*/
+func newPartitionLocks() *partitionLocks {
+	return &partitionLocks{make(map[string]*sync.RWMutex), &sync.Mutex{}}
+}
+
+/*
+get returns the lock of a given partition.
+*/
+func (pl *partitionLocks) get(part string) *sync.RWMutex {
+	pl.mutex.Lock()
+	defer pl.mutex.Unlock()
+
+	lock, ok := pl.locks[part]
+	if !ok {
+		lock = &sync.RWMutex{}
+		pl.locks[part] = lock
+	}
+
+	return lock
+}
+
+/*
+rlockPartition takes the reader lock of a given partition. Operations which
+change the whole graph are excluded as well.
+*/
+func (gm *Manager) rlockPartition(part string) {
+	gm.mutex.RLock()
+	gm.partLocks.get(part).RLock()
+}
+
+/*
+runlockPartition releases the reader lock of a given partition.
+*/
+func (gm *Manager) runlockPartition(part string) {
+	gm.partLocks.get(part).RUnlock()
+	gm.mutex.RUnlock()
+}
+
+/*
+writeOperations keeps track of all running write operations. The changes of a
+failed write operation to the main database can only be undone completely if
+no other write operation ran at the same time.
+*/
+type writeOperations struct {
+	running map[*partitionLockSet]bool // Lock sets of all running write operations
+	mutex   *sync.Mutex                // Mutex to protect the running write operations
+}
+
+/*
+newWriteOperations creates a new empty table of running write operations.
+*/
+func newWriteOperations() *writeOperations {
+	return &writeOperations{make(map[*partitionLockSet]bool), &sync.Mutex{}}
+}
+
+/*
+partitionLockSet is the set of partition writer locks which are held by a
+single write operation. Locks are taken in alphabetical order of the partition
+names. A partition which sorts before a partition which is already held cannot
+be waited for without risking a deadlock - in this case the lock is polled until
+a timeout is reached.
+*/
+type partitionLockSet struct {
+	gm         *Manager        // Graph manager which owns the partition locks
+	held       map[string]bool // Partitions which are held
+	order      []string        // Held partitions in the order they were locked
+	concurrent bool            // Flag if another write operation ran at the same time
+}
+
+/*
+newPartitionLockSet creates a new empty partition lock set. The lock set is
+registered as running write operation until it is unlocked.
+*/
+func newPartitionLockSet(gm *Manager) *partitionLockSet {
+	ls := &partitionLockSet{gm, make(map[string]bool), nil, false}
+
+	gm.writeOps.mutex.Lock()
+	defer gm.writeOps.mutex.Unlock()
+
+	for other := range gm.writeOps.running {
+		other.concurrent = true
+		ls.concurrent = true
+	}
+
+	gm.writeOps.running[ls] = true
+
+	return ls
+}
+
+/*
+alone runs a given function if no other write operation ran at the same time
+as this one. No other write operation can start while the function runs.
+Returns if the function was run.
+*/
+func (ls *partitionLockSet) alone(f func()) bool {
+	ls.gm.writeOps.mutex.Lock()
+	defer ls.gm.writeOps.mutex.Unlock()
+
+	if ls.concurrent {
+		return false
+	}
+
+	f()
+
+	return true
+}
+
+/*
+holds returns if the writer lock of a given partition is held.
+*/
+func (ls *partitionLockSet) holds(part string) bool {
+	return ls.held[part]
+}
+
+/*
+lock takes the writer locks of all given partitions which are not held yet.
+*/
+func (ls *partitionLockSet) lock(parts []string) error {
+	var last string
+
+	missing := make([]string, 0, len(parts))
+
+	for _, part := range parts {
+		if !ls.held[part] {
+			missing = append(missing, part)
+		}
+	}
+
+	sort.Strings(missing)
+
+	// Determine the last held partition in lock order
+
+	for _, part := range ls.order {
+		if part > last {
+			last = part
+		}
+	}
+
+	for i, part := range missing {
+
+		if i > 0 && part == missing[i-1] {
+			continue
+		}
+
+		lock := ls.gm.partLocks.get(part)
+
+		if len(ls.order) == 0 || part > last {
+
+			// The lock can be taken in order - wait for it
+
+			lock.Lock()
+			last = part
+
+		} else if !ls.tryLock(lock) {
+
+			return &util.GraphError{
+				Type:   util.ErrLocked,
+				Detail: fmt.Sprintf("Timeout while waiting for partition %v", part),
+			}
+		}
+
+		ls.held[part] = true
+		ls.order = append(ls.order, part)
+	}
+
+	return nil
+}
+
+/*
+tryLock polls a partition writer lock until it can be taken or the timeout
+is reached.
+*/
+func (ls *partitionLockSet) tryLock(lock *sync.RWMutex) bool {
+	deadline := time.Now().Add(partitionLockTimeout)
+
+	for !lock.TryLock() {
+
+		if time.Now().After(deadline) {
+			return false
+		}
+
+		time.Sleep(partitionLockRetry)
+	}
+
+	return true
+}
+
+/*
+unlock releases all held partition writer locks and ends the write operation.
+*/
+func (ls *partitionLockSet) unlock() {
+	for i := len(ls.order) - 1; i >= 0; i-- {
+		ls.gm.partLocks.get(ls.order[i]).Unlock()
+	}
+
+	ls.held = make(map[string]bool)
+	ls.order = nil
+
+	ls.gm.writeOps.mutex.Lock()
+	defer ls.gm.writeOps.mutex.Unlock()
+
+	delete(ls.gm.writeOps.running, ls)
+}
+
+/*
+lockPartition takes the writer lock of a given partition and returns the lock
+set which holds it. Operations which change the whole graph are excluded as well.
+*/
+func (gm *Manager) lockPartition(part string) (*partitionLockSet, error) {
+	gm.mutex.RLock()
+
+	ls := newPartitionLockSet(gm)
+
+	if err := ls.lock([]string{part}); err != nil {
+		gm.unlockPartitions(ls)
+		return nil, err
+	}
+
+	return ls, nil
+}
+
+/*
+unlockPartitions releases all partition writer locks of a given lock set.
+*/
+func (gm *Manager) unlockPartitions(ls *partitionLockSet) {
+	ls.unlock()
+	gm.mutex.RUnlock()
+}
@@ -0,0 +1,340 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+)
+
+func TestPartitionLocks(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	constructNode := func(key string) data.Node {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "Person")
+		return node
+	}
+
+	// Hold the writer lock of partition a
+
+	locks, err := gm.lockPartition("a")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Writes to partition b are not blocked
+
+	if err := gm.StoreNode("b", constructNode("1")); err != nil {
+		t.Error(err)
+		return
+	}
+
+	trans := NewGraphTrans(gm)
+	trans.StoreNode("b", constructNode("2"))
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Writes to partition a wait until the lock is released
+
+	done := make(chan error)
+
+	go func() {
+		trans := NewGraphTrans(gm)
+		trans.StoreNode("a", constructNode("3"))
+		trans.StoreNode("b", constructNode("3"))
+		done <- trans.Commit()
+	}()
+
+	select {
+	case err := <-done:
+		t.Error("Commit should be blocked:", err)
+		return
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if res, err := gm.FetchNode("b", "3", "Person"); res != nil || err != nil {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	gm.unlockPartitions(locks)
+
+	if err := <-done; err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := gm.NodeCount("Person"); res != 4 {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// Partitions which cannot be locked in order are polled until a timeout
+	// is reached
+
+	oldTimeout := partitionLockTimeout
+	partitionLockTimeout = 10 * time.Millisecond
+	defer func() {
+		partitionLockTimeout = oldTimeout
+	}()
+
+	locks, err = gm.lockPartition("a")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ls := newPartitionLockSet(gm)
+
+	if err := ls.lock([]string{"b"}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = ls.lock([]string{"a", "b"})
+	if gerr, ok := err.(*util.GraphError); !ok || gerr.Type != util.ErrLocked ||
+		err.Error() != "GraphError: Could not lock partition (Timeout while waiting for partition a)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	gm.unlockPartitions(locks)
+
+	if err := ls.lock([]string{"a", "b"}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if !ls.holds("a") || !ls.holds("b") || ls.holds("c") {
+		t.Error("Unexpected lock set:", ls.held)
+		return
+	}
+
+	ls.unlock()
+
+	if ls.holds("a") || ls.holds("b") {
+		t.Error("Unexpected lock set:", ls.held)
+		return
+	}
+}
+
+func TestConcurrentPartitionCommits(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, 10)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func(part string) {
+			defer wg.Done()
+
+			for j := 0; j < 20; j++ {
+				node := data.NewGraphNode()
+				node.SetAttr("key", fmt.Sprint(j))
+				node.SetAttr("kind", "Person")
+				node.SetAttr("part", part)
+
+				trans := NewGraphTrans(gm)
+				trans.StoreNode(part, node)
+
+				if err := trans.Commit(); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(fmt.Sprint("part", i%5))
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+		return
+	}
+
+	// Each partition has 20 distinct nodes
+
+	if res := gm.NodeCount("Person"); res != 100 {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := gm.Partitions(); len(res) != 5 {
+		t.Error("Unexpected result:", res)
+		return
+	}
+}
+
+func TestCommitRollbackMainDB(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	constructNode := func(key string, kind string) data.Node {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", kind)
+		node.SetAttr("name", key)
+		return node
+	}
+
+	if err := gm.StoreNode("main", constructNode("1", "Person")); err != nil {
+		t.Error(err)
+		return
+	}
+
+	version := gm.CurrentVersion()
+
+	// Build a transaction which fails once its node has been written - the
+	// memory storage does not roll back so each attempt uses a new node
+
+	failingCommit := func(key string) error {
+		edge := data.NewGraphEdge()
+		edge.SetAttr("key", key)
+		edge.SetAttr("kind", "Link")
+		edge.SetAttr(data.EdgeEnd1Key, key)
+		edge.SetAttr(data.EdgeEnd1Kind, "Song")
+		edge.SetAttr(data.EdgeEnd1Role, "from")
+		edge.SetAttr(data.EdgeEnd1Cascading, false)
+		edge.SetAttr(data.EdgeEnd2Key, "b")
+		edge.SetAttr(data.EdgeEnd2Kind, "Song")
+		edge.SetAttr(data.EdgeEnd2Role, "to")
+		edge.SetAttr(data.EdgeEnd2Cascading, false)
+
+		trans := NewGraphTrans(gm)
+		trans.StoreNode("other", constructNode(key, "Song"))
+		trans.StoreEdge("other", edge)
+
+		return trans.Commit()
+	}
+
+	// Without other write operations the main database is rolled back completely
+
+	if err := failingCommit("a"); err == nil ||
+		err.Error() != "GraphError: Invalid data (Can't find edge endpoint: b (Song))" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if res := fmt.Sprint(gm.Partitions(), gm.NodeKinds(), gm.NodeCount("Song"),
+		gm.CurrentVersion()-version); res != "[main] [Person] 0 0" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// If another write operation runs at the same time only the counts and
+	// the graph version are reverted
+
+	locks, err := gm.lockPartition("main")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := failingCommit("c"); err == nil {
+		t.Error("Commit should fail")
+		return
+	}
+
+	gm.unlockPartitions(locks)
+
+	if res := fmt.Sprint(gm.Partitions(), gm.NodeKinds(), gm.NodeCount("Song"),
+		gm.CurrentVersion()-version); res != "[main other] [Person Song] 0 0" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+}
+
+/*
+benchmarkConcurrentCommits runs concurrent writers which commit single nodes.
+The writers either share a single partition or each writer uses its own partition.
+*/
+func benchmarkConcurrentCommits(b *testing.B, gs graphstorage.Storage, ownPartition bool) {
+	var writers, counter int64
+
+	gm := NewGraphManager(gs)
+
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		part := "main"
+
+		if ownPartition {
+			part = fmt.Sprint("part", atomic.AddInt64(&writers, 1))
+		}
+
+		for pb.Next() {
+			node := data.NewGraphNode()
+			node.SetAttr("key", fmt.Sprint(atomic.AddInt64(&counter, 1)))
+			node.SetAttr("kind", "Person")
+			node.SetAttr("name", "John")
+
+			trans := NewGraphTrans(gm)
+			trans.StoreNode(part, node)
+
+			if err := trans.Commit(); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+/*
+newBenchmarkDiskStorage creates a new empty disk graph storage for a benchmark.
+*/
+func newBenchmarkDiskStorage(b *testing.B) graphstorage.Storage {
+	os.RemoveAll(GraphManagerTestDBDir7)
+
+	dgs, err := graphstorage.NewDiskGraphStorage(GraphManagerTestDBDir7, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Cleanup(func() {
+		dgs.Close()
+		os.RemoveAll(GraphManagerTestDBDir7)
+	})
+
+	return dgs
+}
+
+func BenchmarkCommitSinglePartitionMemory(b *testing.B) {
+	benchmarkConcurrentCommits(b, graphstorage.NewMemoryGraphStorage("mystorage"), false)
+}
+
+func BenchmarkCommitPartitionPerWriterMemory(b *testing.B) {
+	benchmarkConcurrentCommits(b, graphstorage.NewMemoryGraphStorage("mystorage"), true)
+}
+
+func BenchmarkCommitSinglePartitionDisk(b *testing.B) {
+	benchmarkConcurrentCommits(b, newBenchmarkDiskStorage(b), false)
+}
+
+func BenchmarkCommitPartitionPerWriterDisk(b *testing.B) {
+	benchmarkConcurrentCommits(b, newBenchmarkDiskStorage(b), true)
+}
@@ -8,7 +8,6 @@
 package graph
 
 import (
-	"encoding/binary"
 	"encoding/gob"
 
 	"github.com/Fisch-Labs/FishDB/graph/data"
@@ -27,12 +26,7 @@ func init() {
 NodeCount returns the node count for a given node kind.
 */
 func (gm *Manager) NodeCount(kind string) uint64 {
-
-	if val, ok := gm.gs.MainDB()[MainDBNodeCount+kind]; ok {
-		return binary.LittleEndian.Uint64([]byte(val))
-	}
-
-	return 0
+	return gm.readCount(MainDBNodeCount + kind)
 }
 
 /*
@@ -54,7 +48,7 @@ func (gm *Manager) NodeKeyIterator(part string, kind string) (*NodeKeyIterator,
 		}
 	}
 
-	return &NodeKeyIterator{gm, it, nil}, nil
+	return &NodeKeyIterator{gm, part, it, nil}, nil
 }
 
 /*
@@ -65,7 +59,9 @@ func (gm *Manager) FetchNode(part string, key string, kind string) (data.Node, e
 }
 
 /*
-FetchNodePart fetches part of a single node from a partition of the graph.
+FetchNodePart fetches part of a single node from a partition of the graph. The
+current revision of the node is included as data.NodeRevision attribute if all
+attributes are fetched.
 */
 func (gm *Manager) FetchNodePart(part string, key string, kind string,
 	attrs []string) (data.Node, error) {
@@ -79,12 +75,17 @@ func (gm *Manager) FetchNodePart(part string, key string, kind string,
 
 	// Take reader lock
 
-	gm.mutex.RLock()
-	defer gm.mutex.RUnlock()
+	gm.rlockPartition(part)
+	defer gm.runlockPartition(part)
 
-	// Read the node from the datastore
+	// Read the node and its revision from the datastore
 
-	return gm.readNode(key, kind, attrs, attht, valht)
+	node, err := gm.readNode(key, kind, attrs, attht, valht)
+	if err == nil && len(attrs) == 0 {
+		err = gm.addRevision(node, valht)
+	}
+
+	return node, err
 }
 
 /*
@@ -148,6 +149,22 @@ func (gm *Manager) readNode(key string, kind string, attrs []string,
 
 		for _, attr := range attrs {
 
+			if attr == data.NodeRevision {
+
+				// The revision is not stored as a normal attribute
+
+				rev, err := gm.readRevision(key, valTree)
+				if err != nil {
+					return nil, err
+				}
+
+				if node == nil {
+					node = data.NewGraphNode()
+				}
+				node.SetAttr(data.NodeRevision, rev)
+				continue
+			}
+
 			if (attr == data.NodeKey || attr == data.NodeKind) && node == nil {
 
 				// Create node - we might only query for node key or node kind
@@ -249,8 +266,24 @@ func (gm *Manager) storeOrUpdateNode(part string, node data.Node, onlyUpdate boo
 
 	// Take writer lock
 
-	gm.mutex.Lock()
-	defer gm.mutex.Unlock()
+	locks, err := gm.lockPartition(part)
+	if err != nil {
+		return err
+	}
+	defer gm.unlockPartitions(locks)
+
+	// Get the node index again - a rebuilt index may have replaced the
+	// index before the writer lock was taken
+
+	if iht, err = gm.getNodeIndexHTree(part, node.Kind(), true); err != nil {
+		return err
+	}
+
+	// Check unique constraints
+
+	if err := gm.checkUniqueConstraints(part, node, onlyUpdate, nil, attht, valht); err != nil {
+		return err
+	}
 
 	// Write the node to the datastore
 
@@ -259,17 +292,25 @@ func (gm *Manager) storeOrUpdateNode(part string, node data.Node, onlyUpdate boo
 		return err
 	}
 
+	// Record the new state of the node in the node history
+
+	commit := gm.newVersionCommit("")
+
+	if err := gm.writeNodeVersion(part, node.Key(), node.Kind(), commit, attht, valht); err != nil {
+		return err
+	}
+
 	// Increase node count if the node was inserted and write the changes
 	// to the index.
 
 	if oldnode == nil {
-		currentCount := gm.NodeCount(node.Kind())
-		if err := gm.writeNodeCount(node.Kind(), currentCount+1, true); err != nil {
+		gm.updateCount(MainDBNodeCount+node.Kind(), 1)
+		if err := gm.flushMain(); err != nil {
 			return err
 		}
 
 		if iht != nil {
-			err := util.NewIndexManager(iht).Index(node.Key(), node.IndexMap())
+			err := gm.nodeIndexManager(iht, node.Kind()).Index(node.Key(), node.IndexMap())
 			if err != nil {
 
 				// The node was written at this point and the model is
@@ -281,7 +322,7 @@ func (gm *Manager) storeOrUpdateNode(part string, node data.Node, onlyUpdate boo
 
 	} else if iht != nil {
 
-		err := util.NewIndexManager(iht).Reindex(node.Key(), node.IndexMap(),
+		err := gm.nodeIndexManager(iht, node.Kind()).Reindex(node.Key(), node.IndexMap(),
 			oldnode.IndexMap())
 
 		if err != nil {
@@ -293,22 +334,42 @@ func (gm *Manager) storeOrUpdateNode(part string, node data.Node, onlyUpdate boo
 		}
 	}
 
-	defer func() {
+	// Update the vector index and the unique constraint storage
 
-		// Flush changes
+	if err := gm.updateNodeVectors(part, node.Key(), node.Kind(), node, oldnode); err != nil {
+		return err
+	}
+
+	if err := gm.updateNodeUniques(part, node.Key(), node.Kind(), oldnode, attht, valht); err != nil {
+		return err
+	}
+
+	// Store the change as a pending change set before anything is flushed
 
-		gm.gs.FlushMain()
+	if err := gm.writePendingChangeSet(commit); err != nil {
+		return err
+	}
 
-		gm.flushNodeIndex(part, node.Kind())
+	// Flush changes - the change is only appended to the change log once
+	// it was flushed
 
-		gm.flushNodeStorage(part, node.Kind())
+	if err := firstError(gm.flushMain(), gm.flushNodeIndex(part, node.Kind()),
+		gm.flushNodeStorage(part, node.Kind()), gm.flushNodeHistory(part, node.Kind()),
+		gm.flushNodeVector(part, node.Kind()), gm.flushNodeUnique(part, node.Kind())); err != nil {
+		return err
+	}
 
-	}()
+	// Append the change to the change log
+
+	if err := gm.writeChangeSet(commit); err != nil {
+		return err
+	}
 
 	// Execute rules
 
 	trans := newInternalGraphTrans(gm)
 	trans.subtrans = true
+	trans.locks = locks
 
 	var event int
 	if oldnode == nil {
@@ -343,6 +404,17 @@ func (gm *Manager) writeNode(node data.Node, onlyUpdate bool, attrTree *hash.HTr
 	var attrListOld interface{}
 	var err error
 
+	// Store lists of numbers of declared vector attributes as vectors
+
+	gm.convertVectors(node)
+
+	// Check the expected revision before anything is written
+
+	rev, revKnown, err := gm.checkRevision(node, valTree)
+	if err != nil {
+		return nil, err
+	}
+
 	// Store the node attributes
 
 	attrList := make([]string, 0, len(node.IndexMap()))
@@ -437,6 +509,12 @@ func (gm *Manager) writeNode(node data.Node, onlyUpdate bool, attrTree *hash.HTr
 		return nil, &util.GraphError{Type: util.ErrWriting, Detail: err.Error()}
 	}
 
+	// Increase the revision
+
+	if err := gm.writeNextRevision(node.Key(), rev, revKnown, valTree); err != nil {
+		return nil, err
+	}
+
 	// Remove deleted keys
 
 	if attrListOld != nil {
@@ -503,8 +581,18 @@ func (gm *Manager) RemoveNode(part string, key string, kind string) (data.Node,
 
 		// Take writer lock
 
-		gm.mutex.Lock()
-		defer gm.mutex.Unlock()
+		locks, err := gm.lockPartition(part)
+		if err != nil {
+			return nil, err
+		}
+		defer gm.unlockPartitions(locks)
+
+		// Get the node index again - a rebuilt index may have replaced the
+		// index before the writer lock was taken
+
+		if iht, err = gm.getNodeIndexHTree(part, kind, false); err != nil {
+			return nil, err
+		}
 
 		// Delete the node from the datastore
 
@@ -517,35 +605,59 @@ func (gm *Manager) RemoveNode(part string, key string, kind string) (data.Node,
 
 		if node != nil {
 
+			// Record the removal of the node in the node history
+
+			commit := gm.newVersionCommit("")
+
+			if err := gm.writeNodeVersion(part, key, kind, commit, attTree, valTree); err != nil {
+				return node, err
+			}
+
 			if iht != nil {
-				err := util.NewIndexManager(iht).Deindex(key, node.IndexMap())
+				err := gm.nodeIndexManager(iht, kind).Deindex(key, node.IndexMap())
 				if err != nil {
 					return node, err
 				}
 			}
 
-			// Decrease the node count
+			if err := gm.updateNodeVectors(part, key, kind, nil, node); err != nil {
+				return node, err
+			}
 
-			currentCount := gm.NodeCount(kind)
-			if err := gm.writeNodeCount(kind, currentCount-1, true); err != nil {
+			if err := gm.updateNodeUniques(part, key, kind, node, attTree, valTree); err != nil {
 				return node, err
 			}
 
-			defer func() {
+			// Decrease the node count
+
+			gm.updateCount(MainDBNodeCount+kind, -1)
+
+			// Store the change as a pending change set before anything is flushed
 
-				// Flush changes
+			if err := gm.writePendingChangeSet(commit); err != nil {
+				return node, err
+			}
+
+			// Flush changes - the change is only appended to the change log
+			// once it was flushed
 
-				gm.gs.FlushMain()
+			if err := firstError(gm.flushMain(), gm.flushNodeIndex(part, kind),
+				gm.flushNodeStorage(part, kind), gm.flushNodeHistory(part, kind),
+				gm.flushNodeVector(part, kind), gm.flushNodeUnique(part, kind)); err != nil {
+				return node, err
+			}
 
-				gm.flushNodeIndex(part, kind)
+			// Append the change to the change log
 
-				gm.flushNodeStorage(part, kind)
-			}()
+			if err := gm.writeChangeSet(commit); err != nil {
+				return node, err
+			}
 
 			// Execute rules
 
 			trans := newInternalGraphTrans(gm)
 			trans.subtrans = true
+			trans.locks = locks
 
 			if err := gm.gr.graphEvent(trans, EventNodeDeleted, part, node); err != nil && err != ErrEventHandled {
 				return node, err
@@ -602,6 +714,12 @@ func (gm *Manager) deleteNode(key string, kind string, attrTree *hash.HTree,
 		node.SetAttr(attr, val)
 	}
 
+	// Remove the revision
+
+	if _, err := valTree.Remove([]byte(PrefixNSRevision + key)); err != nil {
+		return node, &util.GraphError{Type: util.ErrWriting, Detail: err.Error()}
+	}
+
 	return node, nil
 }
 
@@ -609,5 +727,5 @@ func (gm *Manager) deleteNode(key string, kind string, attrTree *hash.HTree,
 Default filter function to filter out system node attributes.
 */
 func nodeAttributeFilter(attr string) bool {
-	return attr == data.NodeKey || attr == data.NodeKind
+	return attr == data.NodeKey || attr == data.NodeKind || attr == data.NodeRevision
 }
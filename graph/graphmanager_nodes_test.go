@@ -131,9 +131,9 @@ func TestSimpleNodeStorage(t *testing.T) {
 		return
 	}
 
-	// Check we got everything back
+	// Check we got everything back (including the revision)
 
-	if res := len(fnode2.Data()); res != 4 {
+	if res := len(fnode2.Data()); res != 5 {
 		t.Error("Unexpected number of attributes:", res)
 		return
 	}
@@ -190,9 +190,9 @@ func TestSimpleNodeStorage(t *testing.T) {
 		return
 	}
 
-	// Check we got everything back
+	// Check we got everything back (including the revision)
 
-	if res := len(fnode3.Data()); res != 4 {
+	if res := len(fnode3.Data()); res != 5 {
 		t.Error("Unexpected number of attributes:", res)
 		return
 	}
@@ -315,6 +315,8 @@ func TestSimpleNodeUpdate(t *testing.T) {
 	}
 
 	fetchedNode, _ := gm.FetchNode("main", "nodeToUpdate", "nodeupdatekind")
+	fetchedNode.SetAttr(data.NodeRevision, nil)
+
 	if !data.NodeCompare(node1, fetchedNode, nil) {
 		t.Error("Node should have been stored completely")
 		return
@@ -333,6 +335,7 @@ func TestSimpleNodeUpdate(t *testing.T) {
 	}
 
 	fetchedNode, _ = gm.FetchNode("main", "nodeToUpdate", "nodeupdatekind")
+	fetchedNode.SetAttr(data.NodeRevision, nil)
 
 	if len(fetchedNode.Data()) != len(node1.Data())+1 {
 		t.Error("Unexpected number of attributes")
@@ -536,7 +539,7 @@ func TestSimpleNodeStorageErrorCases(t *testing.T) {
 
 	delete(sm.AccessMap, 1)
 
-	msm.AccessMap[5] = storage.AccessInsertError
+	msm.AccessMap[6] = storage.AccessInsertError
 
 	if err := gm.StoreNode("testpart", node2); err.Error() !=
 		"GraphError: Could not write graph information (Record is already in-use (<memory> - ))" {
@@ -545,9 +548,9 @@ func TestSimpleNodeStorageErrorCases(t *testing.T) {
 		return
 	}
 
-	delete(msm.AccessMap, 5)
+	delete(msm.AccessMap, 6)
 
-	msm.AccessMap[5] = storage.AccessInsertError
+	msm.AccessMap[6] = storage.AccessInsertError
 
 	if err := gm.StoreNode("testpart", node2); err.Error() !=
 		"GraphError: Could not write graph information (Record is already in-use (<memory> - ))" {
@@ -556,7 +559,7 @@ func TestSimpleNodeStorageErrorCases(t *testing.T) {
 		return
 	}
 
-	delete(msm.AccessMap, 5)
+	delete(msm.AccessMap, 6)
 
 	node2.SetAttr("key", "123")
 	node2.SetAttr("Name", nil)
@@ -626,7 +629,7 @@ func TestSimpleNodeStorageErrorCases(t *testing.T) {
 		delete(is.AccessMap, uint64(i))
 	}
 
-	msm.AccessMap[9] = storage.AccessCacheAndFetchError
+	msm.AccessMap[12] = storage.AccessCacheAndFetchError
 
 	// This call does delete the node by blowing
 	// away the attribute list - the node is removed though its attribute
@@ -634,12 +637,12 @@ func TestSimpleNodeStorageErrorCases(t *testing.T) {
 
 	if res, err := gm.deleteNode("123", "testkind", attTree, valTree); err.Error() !=
 		"GraphError: Could not write graph information "+
-			"(Slot not found (mystorage/testparttestkind.nodes - Location:9))" {
+			"(Slot not found (mystorage/testparttestkind.nodes - Location:12))" {
 
 		t.Error("Unexpected result:", res, err)
 		return
 	}
-	delete(msm.AccessMap, 9)
+	delete(msm.AccessMap, 12)
 
 	if res, err := gm.FetchNodePart("testpart", "123", "testkind", nil); res != nil || err != nil {
 		t.Error("Unexpected result:", res, err)
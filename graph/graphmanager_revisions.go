@@ -0,0 +1,168 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+	"github.com/Fisch-Labs/FishDB/hash"
+)
+
+/*
+FetchNodeRevision returns the current revision of a node. Returns 0 if the
+node does not exist.
+*/
+func (gm *Manager) FetchNodeRevision(part string, key string, kind string) (uint64, error) {
+	return gm.fetchRevision(part, key, kind, false)
+}
+
+/*
+FetchEdgeRevision returns the current revision of an edge. Returns 0 if the
+edge does not exist.
+*/
+func (gm *Manager) FetchEdgeRevision(part string, key string, kind string) (uint64, error) {
+	return gm.fetchRevision(part, key, kind, true)
+}
+
+/*
+fetchRevision returns the current revision of a node or edge.
+*/
+func (gm *Manager) fetchRevision(part string, key string, kind string, isEdge bool) (uint64, error) {
+	var tree *hash.HTree
+	var err error
+
+	if isEdge {
+		tree, err = gm.getEdgeStorageHTree(part, kind, false)
+	} else {
+		_, tree, err = gm.getNodeStorageHTree(part, kind, false)
+	}
+
+	if err != nil || tree == nil {
+		return 0, err
+	}
+
+	// Take reader lock
+
+	gm.rlockPartition(part)
+	defer gm.runlockPartition(part)
+
+	return gm.readRevision(key, tree)
+}
+
+/*
+readRevision reads the stored revision of a node or edge. Returns 0 if the
+node or edge does not exist.
+*/
+func (gm *Manager) readRevision(key string, valTree *hash.HTree) (uint64, error) {
+
+	val, err := valTree.Get([]byte(PrefixNSRevision + key))
+	if err != nil {
+		return 0, &util.GraphError{Type: util.ErrReading, Detail: err.Error()}
+	}
+
+	rev, _ := val.(uint64)
+
+	return rev, nil
+}
+
+/*
+addRevision adds the stored revision of a node or edge as data.NodeRevision
+attribute. Nothing is done if the node or edge does not exist.
+*/
+func (gm *Manager) addRevision(node data.Node, valTree *hash.HTree) error {
+
+	if node == nil {
+		return nil
+	}
+
+	rev, err := gm.readRevision(node.Key(), valTree)
+	if err == nil {
+		node.SetAttr(data.NodeRevision, rev)
+	}
+
+	return err
+}
+
+/*
+checkRevision checks the expected revision of a node or edge which should be
+written against its stored revision. Returns the stored revision and if the
+revision was checked. Nothing is read from the datastore if the node or edge
+has no expected revision.
+*/
+func (gm *Manager) checkRevision(node data.Node, valTree *hash.HTree) (uint64, bool, error) {
+
+	expected, ok, err := expectedRevision(node)
+	if !ok || err != nil {
+		return 0, false, err
+	}
+
+	rev, err := gm.readRevision(node.Key(), valTree)
+
+	if err == nil && rev != expected {
+		err = &util.GraphError{
+			Type: util.ErrConflict,
+			Detail: fmt.Sprintf("%v of kind %v has revision %v but revision %v was expected",
+				node.Key(), node.Kind(), rev, expected),
+		}
+	}
+
+	return rev, true, err
+}
+
+/*
+writeNextRevision increases the stored revision of a node or edge. The stored
+revision is read from the datastore if it is not known.
+*/
+func (gm *Manager) writeNextRevision(key string, rev uint64, known bool, valTree *hash.HTree) error {
+	var err error
+
+	if !known {
+		if rev, err = gm.readRevision(key, valTree); err != nil {
+			return err
+		}
+	}
+
+	if _, err = valTree.Put([]byte(PrefixNSRevision+key), rev+1); err != nil {
+		return &util.GraphError{Type: util.ErrWriting, Detail: err.Error()}
+	}
+
+	return nil
+}
+
+/*
+expectedRevision returns the expected revision of a given node or edge. The
+second return value is false if the node or edge has no expected revision.
+*/
+func expectedRevision(node data.Node) (uint64, bool, error) {
+
+	val := node.Attr(data.NodeRevision)
+
+	switch v := val.(type) {
+	case nil:
+		return 0, false, nil
+	case uint64:
+		return v, true, nil
+	case float64:
+		if v >= 0 && v == math.Trunc(v) {
+			return uint64(v), true, nil
+		}
+	default:
+		if rev, err := strconv.ParseUint(fmt.Sprint(v), 10, 64); err == nil {
+			return rev, true, nil
+		}
+	}
+
+	return 0, false, &util.GraphError{
+		Type:   util.ErrInvalidData,
+		Detail: fmt.Sprintf("Invalid revision for %v of kind %v: %v", node.Key(), node.Kind(), val),
+	}
+}
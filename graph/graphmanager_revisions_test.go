@@ -0,0 +1,218 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+)
+
+func TestNodeRevisions(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	constructNode := func(key string, name string) data.Node {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "Person")
+		node.SetAttr("name", name)
+		return node
+	}
+
+	if rev, err := gm.FetchNodeRevision("main", "1", "Person"); rev != 0 || err != nil {
+		t.Error("Unexpected result:", rev, err)
+		return
+	}
+
+	if err := gm.StoreNode("main", constructNode("1", "John")); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if rev, err := gm.FetchNodeRevision("main", "1", "Person"); rev != 1 || err != nil {
+		t.Error("Unexpected result:", rev, err)
+		return
+	}
+
+	// The revision is part of the node data if the full node or the revision
+	// is requested
+
+	if res, err := gm.FetchNode("main", "1", "Person"); err != nil || res.Attr(data.NodeRevision) != uint64(1) {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := gm.FetchNodePart("main", "1", "Person", []string{"name"}); err != nil ||
+		res.Attr(data.NodeRevision) != nil {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := gm.FetchNodePart("main", "1", "Person", []string{data.NodeRevision, "name"}); err != nil ||
+		fmt.Sprint(res.Attr(data.NodeRevision)) != "1" || res.Attr("name") != "John" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Update the node with the expected revision
+
+	node := constructNode("1", "Johnny")
+	node.SetAttr(data.NodeRevision, 1)
+
+	trans := NewGraphTrans(gm)
+
+	if err := trans.UpdateNode("main", node); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if rev, err := gm.FetchNodeRevision("main", "1", "Person"); rev != 2 || err != nil {
+		t.Error("Unexpected result:", rev, err)
+		return
+	}
+
+	// Updating the node again with the old revision fails
+
+	node = constructNode("1", "Jim")
+	node.SetAttr(data.NodeRevision, "1")
+
+	trans = NewGraphTrans(gm)
+
+	if err := trans.UpdateNode("main", node); err != nil {
+		t.Error(err)
+		return
+	}
+
+	err := trans.Commit()
+	if gerr, ok := err.(*util.GraphError); !ok || gerr.Type != util.ErrConflict ||
+		err.Error() != "GraphError: Revision conflict (1 of kind Person has revision 2 but revision 1 was expected)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if res, err := gm.FetchNode("main", "1", "Person"); err != nil || res.Attr("name") != "Johnny" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Updates without an expected revision always succeed
+
+	if err := gm.UpdateNode("main", constructNode("1", "Jim")); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if rev, err := gm.FetchNodeRevision("main", "1", "Person"); rev != 3 || err != nil {
+		t.Error("Unexpected result:", rev, err)
+		return
+	}
+
+	// The expected revision 0 means that the node must not exist
+
+	node = constructNode("1", "Jack")
+	node.SetAttr(data.NodeRevision, 0)
+
+	if err := gm.StoreNode("main", node); err == nil || err.(*util.GraphError).Type != util.ErrConflict {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	node = constructNode("2", "Jack")
+	node.SetAttr(data.NodeRevision, 0)
+
+	if err := gm.StoreNode("main", node); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Invalid revisions are rejected
+
+	node.SetAttr(data.NodeRevision, "abc")
+
+	if err := NewGraphTrans(gm).StoreNode("main", node); err == nil ||
+		err.Error() != "GraphError: Invalid data (Invalid revision for 2 of kind Person: abc)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	// Removing a node resets its revision
+
+	if _, err := gm.RemoveNode("main", "2", "Person"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if rev, err := gm.FetchNodeRevision("main", "2", "Person"); rev != 0 || err != nil {
+		t.Error("Unexpected result:", rev, err)
+		return
+	}
+}
+
+func TestEdgeRevisions(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	for _, key := range []string{"1", "2"} {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "Person")
+
+		if err := gm.StoreNode("main", node); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	edge := data.NewGraphEdge()
+	edge.SetAttr("key", "abc")
+	edge.SetAttr("kind", "Knows")
+	edge.SetAttr(data.EdgeEnd1Key, "1")
+	edge.SetAttr(data.EdgeEnd1Kind, "Person")
+	edge.SetAttr(data.EdgeEnd1Role, "friend")
+	edge.SetAttr(data.EdgeEnd1Cascading, false)
+	edge.SetAttr(data.EdgeEnd2Key, "2")
+	edge.SetAttr(data.EdgeEnd2Kind, "Person")
+	edge.SetAttr(data.EdgeEnd2Role, "friend")
+	edge.SetAttr(data.EdgeEnd2Cascading, false)
+
+	if err := gm.StoreEdge("main", edge); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.StoreEdge("main", edge); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if rev, err := gm.FetchEdgeRevision("main", "abc", "Knows"); rev != 2 || err != nil {
+		t.Error("Unexpected result:", rev, err)
+		return
+	}
+
+	edge.SetAttr(data.NodeRevision, 1)
+
+	if err := gm.StoreEdge("main", edge); err == nil || err.(*util.GraphError).Type != util.ErrConflict {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if res, err := gm.FetchEdge("main", "abc", "Knows"); err != nil || res.Attr(data.NodeRevision) != uint64(2) {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+}
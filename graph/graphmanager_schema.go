@@ -0,0 +1,324 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+)
+
+/*
+SchemaTypes is a list of all known attribute types of a schema.
+*/
+var SchemaTypes = []string{"string", "number", "bool", "list", "map", "vector"}
+
+/*
+KindSchema is the schema of a node or edge kind. The ends and the cardinality
+are only used for edge kinds.
+*/
+type KindSchema struct {
+	Attrs       map[string]*AttrSchema `json:"attrs,omitempty"`       // Schemas of attributes
+	Ends        []*EdgeEndsSchema      `json:"ends,omitempty"`        // Allowed ends of an edge (all ends if empty)
+	Cardinality map[string]int         `json:"cardinality,omitempty"` // Maximal number of edges per node and role
+}
+
+/*
+AttrSchema is the schema of a node or edge attribute.
+*/
+type AttrSchema struct {
+	Type     string `json:"type,omitempty"`     // Type of the value (all types if empty)
+	Required bool   `json:"required,omitempty"` // Flag if the attribute must have a value
+}
+
+/*
+EdgeEndsSchema describes which nodes an edge may connect. Empty kinds or
+roles match all kinds or roles.
+*/
+type EdgeEndsSchema struct {
+	End1Kind string `json:"end1kind,omitempty"` // Kind of the first end
+	End1Role string `json:"end1role,omitempty"` // Role of the first end
+	End2Kind string `json:"end2kind,omitempty"` // Kind of the second end
+	End2Role string `json:"end2role,omitempty"` // Role of the second end
+}
+
+/*
+Schema returns the schema of a node or edge kind. Returns nil if the kind has
+no schema.
+*/
+func (gm *Manager) Schema(kind string) *KindSchema {
+
+	// Take reader lock
+
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+
+	return gm.kindSchema(kind)
+}
+
+/*
+SchemaKinds returns all node and edge kinds which have a schema.
+*/
+func (gm *Manager) SchemaKinds() []string {
+	var ret []string
+
+	// Take reader lock
+
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+
+	for kind := range gm.getMainDBMap(MainDBSchema) {
+		ret = append(ret, kind)
+	}
+
+	sort.Strings(ret)
+
+	return ret
+}
+
+/*
+SetSchema sets the schema of a node or edge kind. A nil schema removes the
+schema of the kind. The schema is stored in the main database and is checked
+whenever a node or edge of the kind is stored. Existing nodes and edges are
+not checked.
+*/
+func (gm *Manager) SetSchema(kind string, schema *KindSchema) error {
+	var val []byte
+
+	if kind == "" {
+		return &util.GraphError{Type: util.ErrInvalidData, Detail: fmt.Sprint("Invalid node kind: ", kind)}
+	}
+
+	if schema != nil {
+
+		for attr, as := range schema.Attrs {
+			var known bool
+
+			if as == nil {
+				return &util.GraphError{Type: util.ErrInvalidData,
+					Detail: fmt.Sprint("Missing schema for attribute: ", attr)}
+			}
+
+			for _, t := range SchemaTypes {
+				known = known || t == as.Type
+			}
+
+			if !known && as.Type != "" {
+				return &util.GraphError{Type: util.ErrInvalidData,
+					Detail: fmt.Sprintf("Unknown type of attribute %v: %v", attr, as.Type)}
+			}
+		}
+
+		for _, ends := range schema.Ends {
+			if ends == nil {
+				return &util.GraphError{Type: util.ErrInvalidData, Detail: "Missing edge ends"}
+			}
+		}
+
+		for role, max := range schema.Cardinality {
+			if max < 1 {
+				return &util.GraphError{Type: util.ErrInvalidData,
+					Detail: fmt.Sprintf("Cardinality of role %v must be a positive number: %v", role, max)}
+			}
+		}
+
+		val, _ = json.Marshal(schema)
+	}
+
+	// Take writer lock
+
+	gm.mutex.Lock()
+	defer gm.mutex.Unlock()
+
+	schemas := gm.getMainDBMap(MainDBSchema)
+	if schemas == nil {
+		schemas = make(map[string]string)
+	}
+
+	if schema == nil {
+		delete(schemas, kind)
+	} else {
+		schemas[kind] = string(val)
+	}
+
+	gm.storeMainDBMap(MainDBSchema, schemas)
+
+	return gm.flushMain()
+}
+
+/*
+kindSchema returns the schema of a kind or nil if the kind has no schema. It
+is assumed that the caller holds a lock.
+*/
+func (gm *Manager) kindSchema(kind string) *KindSchema {
+	var schema *KindSchema
+
+	if val, ok := gm.getMainDBMap(MainDBSchema)[kind]; ok {
+		if err := json.Unmarshal([]byte(val), &schema); err != nil {
+			return nil
+		}
+	}
+
+	return schema
+}
+
+/*
+validateNode checks a node against the schema of its kind.
+*/
+func (gm *Manager) validateNode(node data.Node) error {
+
+	if schema := gm.kindSchema(node.Kind()); schema != nil {
+		return validateAttrs("Node", node, schema)
+	}
+
+	return nil
+}
+
+/*
+validateEdge checks an edge against the schema of its kind. The cardinality
+is checked against the edges which are stored in a given partition.
+*/
+func (gm *Manager) validateEdge(part string, edge data.Edge) error {
+
+	schema := gm.kindSchema(edge.Kind())
+	if schema == nil {
+		return nil
+	}
+
+	if err := validateAttrs("Edge", edge, schema); err != nil {
+		return err
+	}
+
+	// Check that the edge connects allowed nodes
+
+	if len(schema.Ends) > 0 {
+		var allowed bool
+
+		matches := func(expected string, val string) bool {
+			return expected == "" || expected == val
+		}
+
+		for _, ends := range schema.Ends {
+			allowed = allowed || (matches(ends.End1Kind, edge.End1Kind()) &&
+				matches(ends.End1Role, edge.End1Role()) &&
+				matches(ends.End2Kind, edge.End2Kind()) &&
+				matches(ends.End2Role, edge.End2Role()))
+		}
+
+		if !allowed {
+			return fmt.Errorf("Edge %v of kind %v cannot connect %v (%v) with %v (%v)",
+				edge.Key(), edge.Kind(), edge.End1Kind(), edge.End1Role(),
+				edge.End2Kind(), edge.End2Role())
+		}
+	}
+
+	// Check the number of edges of both ends
+
+	checkCardinality := func(key string, kind string, role string) error {
+
+		max, ok := schema.Cardinality[role]
+		if !ok {
+			return nil
+		}
+
+		_, edges, err := gm.TraverseMulti(part, key, kind, role+":"+edge.Kind()+"::", false)
+		if err != nil {
+			return err
+		}
+
+		count := 1
+
+		for _, e := range edges {
+			if e.Key() != edge.Key() {
+				count++
+			}
+		}
+
+		if count > max {
+			return fmt.Errorf("Node %v of kind %v cannot have more than %v edges of kind %v in role %v",
+				key, kind, max, edge.Kind(), role)
+		}
+
+		return nil
+	}
+
+	if err := checkCardinality(edge.End1Key(), edge.End1Kind(), edge.End1Role()); err != nil {
+		return err
+	}
+
+	return checkCardinality(edge.End2Key(), edge.End2Kind(), edge.End2Role())
+}
+
+/*
+validateAttrs checks the attributes of a node or edge against a schema.
+*/
+func validateAttrs(item string, node data.Node, schema *KindSchema) error {
+	var attrs []string
+
+	for attr := range schema.Attrs {
+		attrs = append(attrs, attr)
+	}
+
+	sort.Strings(attrs)
+
+	for _, attr := range attrs {
+		as := schema.Attrs[attr]
+		val := node.Attr(attr)
+
+		if val == nil {
+			if as.Required {
+				return fmt.Errorf("%v %v of kind %v is missing required attribute %v",
+					item, node.Key(), node.Kind(), attr)
+			}
+			continue
+		}
+
+		if as.Type != "" && !isSchemaType(as.Type, val) {
+			return fmt.Errorf("Attribute %v of %v %v of kind %v must be of type %v: %v",
+				attr, strings.ToLower(item), node.Key(), node.Kind(), as.Type, val)
+		}
+	}
+
+	return nil
+}
+
+/*
+isSchemaType checks if a value is of a given schema type.
+*/
+func isSchemaType(t string, val interface{}) bool {
+
+	switch t {
+	case "string":
+		_, ok := val.(string)
+		return ok
+	case "bool":
+		_, ok := val.(bool)
+		return ok
+	case "vector":
+		_, ok := util.ToVector(val)
+		return ok
+	}
+
+	switch reflect.ValueOf(val).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return t == "number"
+	case reflect.Slice, reflect.Array:
+		return t == "list"
+	case reflect.Map:
+		return t == "map"
+	}
+
+	return false
+}
@@ -0,0 +1,205 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+)
+
+func TestSchema(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	if err := gm.SetSchema("Person", &KindSchema{Attrs: map[string]*AttrSchema{
+		"name": {Type: "string", Required: true},
+		"age":  {Type: "number"},
+		"tags": {Type: "list"},
+	}}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.SetSchema("WorksFor", &KindSchema{
+		Attrs: map[string]*AttrSchema{"since": {Type: "number", Required: true}},
+		Ends: []*EdgeEndsSchema{
+			{End1Kind: "Person", End1Role: "Employee", End2Kind: "Company", End2Role: "Employer"},
+		},
+		Cardinality: map[string]int{"Employee": 1},
+	}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := gm.SchemaKinds(); fmt.Sprint(res) != "[Person WorksFor]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := gm.Schema("Person"); res == nil || !res.Attrs["name"].Required || res.Attrs["age"].Type != "number" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := gm.Schema("Company"); res != nil {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	constructNode := func(key string, kind string, attrs map[string]interface{}) data.Node {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", kind)
+		for k, v := range attrs {
+			node.SetAttr(k, v)
+		}
+		return node
+	}
+
+	constructEdge := func(key string, end1 data.Node, end1role string, end2 data.Node, end2role string) data.Edge {
+		edge := data.NewGraphEdge()
+		edge.SetAttr("key", key)
+		edge.SetAttr("kind", "WorksFor")
+		edge.SetAttr("since", 2020)
+		edge.SetAttr(data.EdgeEnd1Key, end1.Key())
+		edge.SetAttr(data.EdgeEnd1Kind, end1.Kind())
+		edge.SetAttr(data.EdgeEnd1Role, end1role)
+		edge.SetAttr(data.EdgeEnd1Cascading, false)
+		edge.SetAttr(data.EdgeEnd2Key, end2.Key())
+		edge.SetAttr(data.EdgeEnd2Kind, end2.Kind())
+		edge.SetAttr(data.EdgeEnd2Role, end2role)
+		edge.SetAttr(data.EdgeEnd2Cascading, false)
+		return edge
+	}
+
+	// Nodes which match the schema can be stored
+
+	p1 := constructNode("1", "Person", map[string]interface{}{"name": "John", "age": 42, "tags": []string{"a"}})
+	p2 := constructNode("2", "Person", map[string]interface{}{"name": "Jane"})
+	c1 := constructNode("1", "Company", nil)
+	c2 := constructNode("2", "Company", nil)
+
+	for _, node := range []data.Node{p1, p2, c1, c2} {
+		if err := gm.StoreNode("main", node); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	if err := gm.StoreNode("main", constructNode("3", "Person", nil)); err == nil ||
+		err.Error() != "GraphError: Graph rule error (Node 3 of kind Person is missing required attribute name)" {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.StoreNode("main", constructNode("3", "Person", map[string]interface{}{"name": "Tim", "age": "old"})); err == nil ||
+		err.Error() != "GraphError: Graph rule error (Attribute age of node 3 of kind Person must be of type number: old)" {
+		t.Error(err)
+		return
+	}
+
+	if node, err := gm.FetchNode("main", "3", "Person"); err != nil || node != nil {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	// Updates are checked with the stored attributes
+
+	if err := gm.UpdateNode("main", constructNode("1", "Person", map[string]interface{}{"age": 43})); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.UpdateNode("main", constructNode("1", "Person", map[string]interface{}{"tags": "a"})); err == nil ||
+		err.Error() != "GraphError: Graph rule error (Attribute tags of node 1 of kind Person must be of type list: a)" {
+		t.Error(err)
+		return
+	}
+
+	// Edges must connect allowed nodes
+
+	if err := gm.StoreEdge("main", constructEdge("1", p1, "Employee", c1, "Employer")); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.StoreEdge("main", constructEdge("2", c2, "Employee", p2, "Employer")); err == nil ||
+		err.Error() != "GraphError: Graph rule error (Edge 2 of kind WorksFor cannot connect Company (Employee) with Person (Employer))" {
+		t.Error(err)
+		return
+	}
+
+	// A person can only work for one company - storing the same edge again is fine
+
+	if err := gm.StoreEdge("main", constructEdge("1", p1, "Employee", c1, "Employer")); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.StoreEdge("main", constructEdge("2", p1, "Employee", c2, "Employer")); err == nil ||
+		err.Error() != "GraphError: Graph rule error (Node 1 of kind Person cannot have more than 1 edges of kind WorksFor in role Employee)" {
+		t.Error(err)
+		return
+	}
+
+	// Transactions fail if they contain invalid data
+
+	trans := NewGraphTrans(gm)
+	trans.StoreEdge("main", constructEdge("2", p2, "Employee", c1, "Employer"))
+	trans.StoreEdge("main", constructEdge("3", p2, "Employee", c2, "Employer"))
+
+	if err := trans.Commit(); err == nil ||
+		err.Error() != "GraphError: Graph rule error (Node 2 of kind Person cannot have more than 1 edges of kind WorksFor in role Employee)" {
+		t.Error(err)
+		return
+	}
+
+	trans = NewGraphTrans(gm)
+	trans.StoreNode("main", constructNode("4", "Person", map[string]interface{}{"name": 4}))
+
+	if err := trans.Commit(); err == nil ||
+		err.Error() != "GraphError: Graph rule error (Attribute name of node 4 of kind Person must be of type string: 4)" {
+		t.Error(err)
+		return
+	}
+
+	// Removing the schema allows any data
+
+	if err := gm.SetSchema("Person", nil); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.StoreNode("main", constructNode("3", "Person", nil)); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Test error cases
+
+	if err := gm.SetSchema("", &KindSchema{}); err == nil ||
+		err.Error() != "GraphError: Invalid data (Invalid node kind: )" {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.SetSchema("Person", &KindSchema{Attrs: map[string]*AttrSchema{"name": {Type: "foo"}}}); err == nil ||
+		err.Error() != "GraphError: Invalid data (Unknown type of attribute name: foo)" {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.SetSchema("WorksFor", &KindSchema{Cardinality: map[string]int{"Employee": 0}}); err == nil ||
+		err.Error() != "GraphError: Invalid data (Cardinality of role Employee must be a positive number: 0)" {
+		t.Error(err)
+		return
+	}
+}
@@ -0,0 +1,515 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+	"github.com/Fisch-Labs/FishDB/hash"
+	"github.com/Fisch-Labs/FishDB/storage"
+)
+
+/*
+snapshotNodeSuffixes are the suffixes of all storages of a node kind which are
+part of a snapshot. The first suffix is the main node storage.
+*/
+var snapshotNodeSuffixes = []string{StorageSuffixNodes, StorageSuffixNodesIndex, StorageSuffixNodesHistory,
+	StorageSuffixNodesVector, StorageSuffixNodesUnique}
+
+/*
+snapshotEdgeSuffixes are the suffixes of all storages of an edge kind which are
+part of a snapshot. The first suffix is the main edge storage.
+*/
+var snapshotEdgeSuffixes = []string{StorageSuffixEdges, StorageSuffixEdgesIndex, StorageSuffixEdgesHistory}
+
+/*
+Snapshots returns all known snapshots as a map of snapshot name to source partition.
+*/
+func (gm *Manager) Snapshots() map[string]string {
+
+	// Take reader lock
+
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+
+	ret := make(map[string]string)
+
+	for name, part := range gm.getMainDBMap(MainDBSnapshots) {
+		ret[name] = part
+	}
+
+	return ret
+}
+
+/*
+CreateSnapshot creates a named snapshot of a partition. The snapshot is a copy
+of all stored data (including indices and history) of the partition and is
+registered as a new partition with the given name. The data is copied on the
+storage level which means no items need to be reindexed.
+*/
+func (gm *Manager) CreateSnapshot(part string, name string) error {
+
+	if err := gm.checkPartitionName(name); err != nil {
+		return err
+	}
+
+	// Take writer lock
+
+	gm.mutex.Lock()
+	defer gm.mutex.Unlock()
+
+	parts := gm.getMainDBMap(MainDBParts)
+
+	if _, ok := parts[part]; !ok {
+		return &util.GraphError{Type: util.ErrInvalidData, Detail: fmt.Sprint("Unknown partition: ", part)}
+	} else if _, ok := parts[name]; ok {
+		return &util.GraphError{Type: util.ErrInvalidData, Detail: fmt.Sprint("Partition already exists: ", name)}
+	}
+
+	var copied []storage.Manager
+
+	// Copy all storages of the partition
+
+	copyStorages := func(kinds []string, suffixes []string, countFunc func(string) uint64,
+		writeCountFunc func(string, uint64, bool) error) error {
+
+		counts := make(map[string]uint64)
+
+		for _, kind := range kinds {
+			for i, suffix := range suffixes {
+
+				src := gm.gs.StorageManager(gm.indexStorageName(part, kind, suffix), false)
+				if src == nil {
+					continue
+				}
+
+				dst := gm.gs.StorageManager(name+kind+suffix, true)
+				copied = append(copied, dst)
+
+				count, err := gm.copyStorageHTrees(src, dst)
+				if err != nil {
+					return err
+				}
+
+				if i == 0 {
+					counts[kind] = count
+				}
+			}
+		}
+
+		for kind, count := range counts {
+			writeCountFunc(kind, countFunc(kind)+count, false)
+		}
+
+		return nil
+	}
+
+	err := copyStorages(gm.mainStringList(MainDBNodeKinds), snapshotNodeSuffixes,
+		gm.NodeCount, gm.writeNodeCount)
+
+	if err == nil {
+		err = copyStorages(gm.mainStringList(MainDBEdgeKinds), snapshotEdgeSuffixes,
+			gm.EdgeCount, gm.writeEdgeCount)
+	}
+
+	if err != nil {
+		for _, sm := range copied {
+			sm.Rollback()
+		}
+
+		return err
+	}
+
+	// Register the snapshot
+
+	parts[name] = ""
+	gm.storeMainDBMap(MainDBParts, parts)
+
+	snapshots := gm.getMainDBMap(MainDBSnapshots)
+	if snapshots == nil {
+		snapshots = make(map[string]string)
+	}
+
+	snapshots[name] = part
+	gm.storeMainDBMap(MainDBSnapshots, snapshots)
+
+	// The copied index is stale wherever the index of the partition is stale
+
+	gm.updateMainDBMap(MainDBIndexStale, func(stale map[string]string) bool {
+		marks := make(map[string]string)
+
+		for k, v := range stale {
+			if strings.HasPrefix(k, part+"#") {
+				marks[name+k[len(part):]] = v
+			}
+		}
+
+		for k, v := range marks {
+			stale[k] = v
+		}
+
+		return len(marks) > 0
+	})
+
+	// Flush changes
+
+	for _, sm := range copied {
+		if err := sm.Flush(); err != nil {
+			return &util.GraphError{Type: util.ErrFlushing, Detail: err.Error()}
+		}
+	}
+
+	return gm.flushMain()
+}
+
+/*
+MergeSnapshot merges a snapshot back into its source partition and discards
+the snapshot afterwards. After the merge the source partition contains the
+same nodes and edges as the snapshot. All changes are written to the source
+partition in a single transaction so they are recorded in the graph history
+and trigger the usual graph rules.
+*/
+func (gm *Manager) MergeSnapshot(name string) error {
+
+	part, ok := gm.Snapshots()[name]
+	if !ok {
+		return &util.GraphError{Type: util.ErrInvalidData, Detail: fmt.Sprint("Unknown snapshot: ", name)}
+	}
+
+	trans := NewGraphTrans(gm)
+
+	// Update all nodes of the source partition
+
+	for _, kind := range gm.NodeKinds() {
+
+		snapKeys, err := gm.storageKeys(name, kind, StorageSuffixNodes)
+		if err != nil {
+			return err
+		}
+
+		partKeys, err := gm.storageKeys(part, kind, StorageSuffixNodes)
+		if err != nil {
+			return err
+		}
+
+		for key := range snapKeys {
+
+			node, err := gm.FetchNode(name, key, kind)
+			if err != nil {
+				return err
+			}
+
+			// Revisions of the snapshot and the source partition are not related
+
+			node.SetAttr(data.NodeRevision, nil)
+
+			oldNode, err := gm.FetchNode(part, key, kind)
+			if err != nil {
+				return err
+			} else if oldNode != nil {
+				oldNode.SetAttr(data.NodeRevision, nil)
+			}
+
+			if oldNode == nil || !reflect.DeepEqual(oldNode.Data(), node.Data()) {
+				trans.StoreNode(part, node)
+			}
+		}
+
+		for key := range partKeys {
+			if _, ok := snapKeys[key]; !ok {
+				trans.RemoveNode(part, key, kind)
+			}
+		}
+	}
+
+	// Update all edges of the source partition
+
+	for _, kind := range gm.EdgeKinds() {
+
+		snapKeys, err := gm.storageKeys(name, kind, StorageSuffixEdges)
+		if err != nil {
+			return err
+		}
+
+		partKeys, err := gm.storageKeys(part, kind, StorageSuffixEdges)
+		if err != nil {
+			return err
+		}
+
+		for key := range snapKeys {
+
+			edge, err := gm.FetchEdge(name, key, kind)
+			if err != nil {
+				return err
+			}
+
+			edge.SetAttr(data.NodeRevision, nil)
+
+			oldEdge, err := gm.FetchEdge(part, key, kind)
+			if err != nil {
+				return err
+			} else if oldEdge != nil {
+				oldEdge.SetAttr(data.NodeRevision, nil)
+			}
+
+			if oldEdge == nil || !reflect.DeepEqual(oldEdge.Data(), edge.Data()) {
+				trans.StoreEdge(part, edge)
+			}
+		}
+
+		for key := range partKeys {
+			if _, ok := snapKeys[key]; !ok {
+				trans.RemoveEdge(part, key, kind)
+			}
+		}
+	}
+
+	if err := trans.Commit(); err != nil {
+		return err
+	}
+
+	return gm.DiscardSnapshot(name)
+}
+
+/*
+DiscardSnapshot removes a snapshot and all its data.
+*/
+func (gm *Manager) DiscardSnapshot(name string) error {
+
+	// Take writer lock
+
+	gm.mutex.Lock()
+	defer gm.mutex.Unlock()
+
+	snapshots := gm.getMainDBMap(MainDBSnapshots)
+
+	if _, ok := snapshots[name]; !ok {
+		return &util.GraphError{Type: util.ErrInvalidData, Detail: fmt.Sprint("Unknown snapshot: ", name)}
+	}
+
+	// Clear all storages of the snapshot
+
+	clearStorages := func(kinds []string, suffixes []string, countFunc func(string) uint64,
+		writeCountFunc func(string, uint64, bool) error) error {
+
+		for _, kind := range kinds {
+			for i, suffix := range suffixes {
+
+				sm := gm.gs.StorageManager(gm.indexStorageName(name, kind, suffix), false)
+				if sm == nil {
+					continue
+				}
+
+				count, err := gm.clearStorageHTrees(sm)
+				if err != nil {
+					sm.Rollback()
+					return err
+				}
+
+				if err := sm.Flush(); err != nil {
+					return &util.GraphError{Type: util.ErrFlushing, Detail: err.Error()}
+				}
+
+				if i == 0 {
+					writeCountFunc(kind, countFunc(kind)-count, false)
+				}
+			}
+		}
+
+		return nil
+	}
+
+	if err := clearStorages(gm.mainStringList(MainDBNodeKinds), snapshotNodeSuffixes,
+		gm.NodeCount, gm.writeNodeCount); err != nil {
+		return err
+	}
+
+	if err := clearStorages(gm.mainStringList(MainDBEdgeKinds), snapshotEdgeSuffixes,
+		gm.EdgeCount, gm.writeEdgeCount); err != nil {
+		return err
+	}
+
+	// Unregister the snapshot and its rebuilt indices
+
+	nodeKinds, edgeKinds := gm.mainStringList(MainDBNodeKinds), gm.mainStringList(MainDBEdgeKinds)
+
+	gm.updateMainDBMap(MainDBIndexStorages, func(storages map[string]string) bool {
+		for _, kind := range nodeKinds {
+			delete(storages, name+kind+StorageSuffixNodesIndex)
+		}
+		for _, kind := range edgeKinds {
+			delete(storages, name+kind+StorageSuffixEdgesIndex)
+		}
+		return true
+	})
+
+	gm.updateMainDBMap(MainDBIndexStale, func(stale map[string]string) bool {
+		for k := range stale {
+			if strings.HasPrefix(k, name+"#") {
+				delete(stale, k)
+			}
+		}
+		return true
+	})
+
+	parts := gm.getMainDBMap(MainDBParts)
+	delete(parts, name)
+	gm.storeMainDBMap(MainDBParts, parts)
+
+	delete(snapshots, name)
+	gm.storeMainDBMap(MainDBSnapshots, snapshots)
+
+	return gm.flushMain()
+}
+
+/*
+storageKeys returns all node or edge keys of a given kind which are stored in
+a partition.
+*/
+func (gm *Manager) storageKeys(part string, kind string, suffix string) (map[string]string, error) {
+
+	// Take reader lock
+
+	gm.rlockPartition(part)
+	defer gm.runlockPartition(part)
+
+	return gm.readStorageKeys(part, kind, suffix)
+}
+
+/*
+readStorageKeys reads all node or edge keys of a given kind which are stored
+in a partition. It is assumed that the caller holds a lock.
+*/
+func (gm *Manager) readStorageKeys(part string, kind string, suffix string) (map[string]string, error) {
+
+	ret := make(map[string]string)
+
+	sm := gm.gs.StorageManager(part+kind+suffix, false)
+	if sm == nil || sm.Root(RootIDNodeHTree) == 0 {
+		return ret, nil
+	}
+
+	tree, err := gm.getHTree(sm, RootIDNodeHTree)
+	if err != nil {
+		return nil, err
+	}
+
+	it := hash.NewHTreeIterator(tree)
+
+	for it.HasNext() {
+		key, _ := it.Next()
+
+		if it.LastError != nil {
+			return nil, &util.GraphError{Type: util.ErrReading, Detail: it.LastError.Error()}
+		}
+
+		if skey := string(key); strings.HasPrefix(skey, PrefixNSAttrs) {
+			ret[skey[len(PrefixNSAttrs):]] = ""
+		}
+	}
+
+	return ret, nil
+}
+
+/*
+copyStorageHTrees copies all HTrees of a storage manager into another storage
+manager. Returns the number of copied nodes or edges.
+*/
+func (gm *Manager) copyStorageHTrees(src storage.Manager, dst storage.Manager) (uint64, error) {
+	var count uint64
+
+	for _, slot := range []int{RootIDNodeHTree, RootIDNodeHTreeSecond} {
+
+		if src.Root(slot) == 0 {
+			continue
+		}
+
+		srcTree, err := gm.getHTree(src, slot)
+		if err != nil {
+			return 0, err
+		}
+
+		dstTree, err := gm.getHTree(dst, slot)
+		if err != nil {
+			return 0, err
+		}
+
+		it := hash.NewHTreeIterator(srcTree)
+
+		for it.HasNext() {
+			key, val := it.Next()
+
+			if it.LastError != nil {
+				return 0, &util.GraphError{Type: util.ErrReading, Detail: it.LastError.Error()}
+			}
+
+			if _, err := dstTree.Put(key, val); err != nil {
+				return 0, &util.GraphError{Type: util.ErrWriting, Detail: err.Error()}
+			}
+
+			if slot == RootIDNodeHTree && strings.HasPrefix(string(key), PrefixNSAttrs) {
+				count++
+			}
+		}
+	}
+
+	return count, nil
+}
+
+/*
+clearStorageHTrees removes all entries from all HTrees of a storage manager.
+Returns the number of removed nodes or edges.
+*/
+func (gm *Manager) clearStorageHTrees(sm storage.Manager) (uint64, error) {
+	var count uint64
+
+	for _, slot := range []int{RootIDNodeHTree, RootIDNodeHTreeSecond} {
+
+		if sm.Root(slot) == 0 {
+			continue
+		}
+
+		tree, err := gm.getHTree(sm, slot)
+		if err != nil {
+			return 0, err
+		}
+
+		// Collect all keys first since the tree should not be modified
+		// while it is iterated
+
+		var keys [][]byte
+
+		it := hash.NewHTreeIterator(tree)
+
+		for it.HasNext() {
+			key, _ := it.Next()
+
+			if it.LastError != nil {
+				return 0, &util.GraphError{Type: util.ErrReading, Detail: it.LastError.Error()}
+			}
+
+			keys = append(keys, key)
+		}
+
+		for _, key := range keys {
+			if _, err := tree.Remove(key); err != nil {
+				return 0, &util.GraphError{Type: util.ErrWriting, Detail: err.Error()}
+			}
+
+			if slot == RootIDNodeHTree && strings.HasPrefix(string(key), PrefixNSAttrs) {
+				count++
+			}
+		}
+	}
+
+	return count, nil
+}
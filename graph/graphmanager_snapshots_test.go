@@ -0,0 +1,225 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+)
+
+func TestSnapshots(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	constructNode := func(key string, name string) data.Node {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "mykind")
+		node.SetAttr("Name", name)
+		return node
+	}
+
+	constructEdge := func(key string, key1 string, key2 string) data.Edge {
+		edge := data.NewGraphEdge()
+
+		edge.SetAttr("key", key)
+		edge.SetAttr("kind", "myedge")
+
+		edge.SetAttr(data.EdgeEnd1Key, key1)
+		edge.SetAttr(data.EdgeEnd1Kind, "mykind")
+		edge.SetAttr(data.EdgeEnd1Role, "node1")
+		edge.SetAttr(data.EdgeEnd1Cascading, false)
+
+		edge.SetAttr(data.EdgeEnd2Key, key2)
+		edge.SetAttr(data.EdgeEnd2Kind, "mykind")
+		edge.SetAttr(data.EdgeEnd2Role, "node2")
+		edge.SetAttr(data.EdgeEnd2Cascading, false)
+
+		return edge
+	}
+
+	gm.StoreNode("main", constructNode("1", "Node1"))
+	gm.StoreNode("main", constructNode("2", "Node2"))
+	gm.StoreNode("main", constructNode("3", "Node3"))
+	gm.StoreEdge("main", constructEdge("a", "1", "2"))
+	gm.StoreEdge("main", constructEdge("b", "2", "3"))
+
+	// Test error cases
+
+	if err := gm.CreateSnapshot("main", "my snap"); err == nil || err.Error() !=
+		"GraphError: Invalid data (Partition name my snap is not alphanumeric - can only contain [a-zA-Z0-9_])" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if err := gm.CreateSnapshot("foo", "snap"); err == nil || err.Error() !=
+		"GraphError: Invalid data (Unknown partition: foo)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if err := gm.CreateSnapshot("main", "main"); err == nil || err.Error() !=
+		"GraphError: Invalid data (Partition already exists: main)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if err := gm.MergeSnapshot("snap"); err == nil || err.Error() !=
+		"GraphError: Invalid data (Unknown snapshot: snap)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if err := gm.DiscardSnapshot("snap"); err == nil || err.Error() !=
+		"GraphError: Invalid data (Unknown snapshot: snap)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	// Create a snapshot
+
+	if err := gm.CreateSnapshot("main", "snap"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := fmt.Sprint(gm.Snapshots(), gm.Partitions()); res != "map[snap:main] [main snap]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if gm.NodeCount("mykind") != 6 || gm.EdgeCount("myedge") != 4 {
+		t.Error("Unexpected counts:", gm.NodeCount("mykind"), gm.EdgeCount("myedge"))
+		return
+	}
+
+	// The snapshot can be queried like a partition
+
+	node, err := gm.FetchNode("snap", "2", "mykind")
+	if err != nil || node.Attr("Name") != "Node2" {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	nodes, _, err := gm.TraverseMulti("snap", "2", "mykind", ":::", true)
+	if err != nil || len(nodes) != 2 {
+		t.Error("Unexpected result:", nodes, err)
+		return
+	}
+
+	iq, _ := gm.NodeIndexQuery("snap", "mykind")
+	if res, err := iq.LookupValue("Name", "Node3"); err != nil || fmt.Sprint(res) != "[3]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Changes to the source partition do not change the snapshot and vice versa
+
+	gm.StoreNode("main", constructNode("2", "Node2 updated"))
+	gm.StoreNode("snap", constructNode("4", "Node4"))
+	gm.StoreEdge("snap", constructEdge("c", "3", "4"))
+	gm.RemoveNode("snap", "1", "mykind")
+
+	if node, err := gm.FetchNode("snap", "2", "mykind"); err != nil || node.Attr("Name") != "Node2" {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	if node, err := gm.FetchNode("main", "4", "mykind"); err != nil || node != nil {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	if edge, err := gm.FetchEdge("snap", "a", "myedge"); err != nil || edge != nil {
+		t.Error("Unexpected result:", edge, err)
+		return
+	}
+
+	if edge, err := gm.FetchEdge("main", "a", "myedge"); err != nil || edge == nil {
+		t.Error("Unexpected result:", edge, err)
+		return
+	}
+
+	// Merge the snapshot back into its source partition
+
+	if err := gm.MergeSnapshot("snap"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := fmt.Sprint(gm.Snapshots(), gm.Partitions()); res != "map[] [main]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if gm.NodeCount("mykind") != 3 || gm.EdgeCount("myedge") != 2 {
+		t.Error("Unexpected counts:", gm.NodeCount("mykind"), gm.EdgeCount("myedge"))
+		return
+	}
+
+	for key, name := range map[string]interface{}{"1": nil, "2": "Node2", "3": "Node3", "4": "Node4"} {
+		node, err := gm.FetchNode("main", key, "mykind")
+		if err != nil || (name == nil && node != nil) || (name != nil && node.Attr("Name") != name) {
+			t.Error("Unexpected result:", key, node, err)
+			return
+		}
+	}
+
+	nodes, _, err = gm.TraverseMulti("main", "3", "mykind", ":::", true)
+	if err != nil || len(nodes) != 2 {
+		t.Error("Unexpected result:", nodes, err)
+		return
+	}
+
+	if node, err := gm.FetchNode("snap", "4", "mykind"); err != nil || node != nil {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	// Create and discard a snapshot
+
+	if err := gm.CreateSnapshot("main", "snap2"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	gm.StoreNode("snap2", constructNode("5", "Node5"))
+
+	if gm.NodeCount("mykind") != 7 {
+		t.Error("Unexpected counts:", gm.NodeCount("mykind"))
+		return
+	}
+
+	if err := gm.DiscardSnapshot("snap2"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := fmt.Sprint(gm.Snapshots(), gm.Partitions()); res != "map[] [main]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if gm.NodeCount("mykind") != 3 || gm.EdgeCount("myedge") != 2 {
+		t.Error("Unexpected counts:", gm.NodeCount("mykind"), gm.EdgeCount("myedge"))
+		return
+	}
+
+	if node, err := gm.FetchNode("snap2", "5", "mykind"); err != nil || node != nil {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	if node, err := gm.FetchNode("main", "5", "mykind"); err != nil || node != nil {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+}
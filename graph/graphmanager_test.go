@@ -29,10 +29,11 @@ const GraphManagerTestDBDir3 = "gmtest3"
 const GraphManagerTestDBDir4 = "gmtest4"
 const GraphManagerTestDBDir5 = "gmtest5"
 const GraphManagerTestDBDir6 = "gmtest6"
+const GraphManagerTestDBDir7 = "gmtest7"
 
 var DBDIRS = []string{GraphManagerTestDBDir1, GraphManagerTestDBDir2,
 	GraphManagerTestDBDir3, GraphManagerTestDBDir4, GraphManagerTestDBDir5,
-	GraphManagerTestDBDir6}
+	GraphManagerTestDBDir6, GraphManagerTestDBDir7}
 
 const InvlaidFileName = "**" + "\x00"
 
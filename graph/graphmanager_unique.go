@@ -0,0 +1,430 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+	"github.com/Fisch-Labs/FishDB/hash"
+)
+
+/*
+UniqueConstraints returns all unique constraints of a node kind in a partition.
+Each constraint is a sorted list of attributes.
+*/
+func (gm *Manager) UniqueConstraints(part string, kind string) [][]string {
+
+	// Take reader lock
+
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+
+	return gm.uniqueConstraints(part, kind)
+}
+
+/*
+AddUniqueConstraint adds a unique constraint to a node kind in a partition. No
+two nodes of the kind may have the same values for all the given attributes.
+Nodes which do not have all the attributes are not checked. The constraint is
+checked against all existing nodes of the kind and is rejected if two of them
+have the same values. The values of a constraint are kept in a separate
+storage so the constraint does not depend on the index schema.
+*/
+func (gm *Manager) AddUniqueConstraint(part string, kind string, attrs ...string) error {
+
+	key, err := gm.uniqueConstraintKey(part, kind, attrs)
+	if err != nil {
+		return err
+	}
+
+	// Take writer lock
+
+	gm.mutex.Lock()
+	defer gm.mutex.Unlock()
+
+	// Record the values of all existing nodes
+
+	if err := gm.buildUniqueConstraint(part, kind, key); err != nil {
+		gm.rollbackNodeUnique(part, kind)
+		return err
+	}
+
+	constraints := gm.getMainDBMap(MainDBUniqueConstraints)
+	if constraints == nil {
+		constraints = make(map[string]string)
+	}
+
+	constraints[key] = ""
+
+	gm.storeMainDBMap(MainDBUniqueConstraints, constraints)
+
+	if err := gm.flushNodeUnique(part, kind); err != nil {
+		return err
+	}
+
+	return gm.flushMain()
+}
+
+/*
+RemoveUniqueConstraint removes a unique constraint from a node kind in a
+partition.
+*/
+func (gm *Manager) RemoveUniqueConstraint(part string, kind string, attrs ...string) error {
+
+	key, err := gm.uniqueConstraintKey(part, kind, attrs)
+	if err != nil {
+		return err
+	}
+
+	// Take writer lock
+
+	gm.mutex.Lock()
+	defer gm.mutex.Unlock()
+
+	constraints := gm.getMainDBMap(MainDBUniqueConstraints)
+
+	if _, ok := constraints[key]; !ok {
+		return nil
+	}
+
+	// Remove the recorded values of the constraint
+
+	if err := gm.clearUniqueConstraint(part, kind, key); err != nil {
+		gm.rollbackNodeUnique(part, kind)
+		return err
+	}
+
+	delete(constraints, key)
+
+	gm.storeMainDBMap(MainDBUniqueConstraints, constraints)
+
+	if err := gm.flushNodeUnique(part, kind); err != nil {
+		return err
+	}
+
+	return gm.flushMain()
+}
+
+/*
+buildUniqueConstraint records the values of all existing nodes of a kind for
+a unique constraint. Returns an error if two nodes have the same values. It is
+assumed that the caller holds the writer lock and flushes or rollbacks the
+unique constraint storage afterwards.
+*/
+func (gm *Manager) buildUniqueConstraint(part string, kind string, key string) error {
+
+	if err := gm.clearUniqueConstraint(part, kind, key); err != nil {
+		return err
+	}
+
+	attht, valht, err := gm.getNodeStorageHTree(part, kind, false)
+	if err != nil || attht == nil || valht == nil {
+		return err
+	}
+
+	keys, err := gm.readStorageKeys(part, kind, StorageSuffixNodes)
+	if err != nil {
+		return err
+	}
+
+	// Iterate the keys in a fixed order so errors are reproducible
+
+	sortedKeys := make([]string, 0, len(keys))
+	for nodeKey := range keys {
+		sortedKeys = append(sortedKeys, nodeKey)
+	}
+	sort.Strings(sortedKeys)
+
+	attrs := strings.Split(key[strings.LastIndex(key, "#")+1:], ",")
+
+	uht, err := gm.getNodeUniqueHTree(part, kind, true)
+	if err != nil {
+		return err
+	}
+
+	for _, nodeKey := range sortedKeys {
+
+		node, err := gm.readNode(nodeKey, kind, attrs, attht, valht)
+		if err != nil {
+			return err
+		}
+
+		entry, ok := uniqueEntry(attrs, node)
+		if !ok {
+			continue
+		}
+
+		other, err := uht.Put([]byte(entry), nodeKey)
+		if err != nil {
+			return &util.GraphError{Type: util.ErrWriting, Detail: err.Error()}
+		} else if other != nil {
+			return &util.GraphError{Type: util.ErrUnique,
+				Detail: fmt.Sprintf("Node %v of kind %v has the same values for %v as node %v in partition %v",
+					nodeKey, kind, attrs, other, part)}
+		}
+	}
+
+	return nil
+}
+
+/*
+clearUniqueConstraint removes all recorded values of a unique constraint. It is
+assumed that the caller holds the writer lock and flushes or rollbacks the
+unique constraint storage afterwards.
+*/
+func (gm *Manager) clearUniqueConstraint(part string, kind string, key string) error {
+
+	uht, err := gm.getNodeUniqueHTree(part, kind, false)
+	if err != nil || uht == nil {
+		return err
+	}
+
+	prefix := PrefixNSUnique + key[strings.LastIndex(key, "#")+1:] + "#"
+
+	// Collect all entries first since the tree should not be modified
+	// while it is iterated
+
+	var entries [][]byte
+
+	it := hash.NewHTreeIterator(uht)
+
+	for it.HasNext() {
+		entry, _ := it.Next()
+
+		if it.LastError != nil {
+			return &util.GraphError{Type: util.ErrReading, Detail: it.LastError.Error()}
+		}
+
+		if strings.HasPrefix(string(entry), prefix) {
+			entries = append(entries, entry)
+		}
+	}
+
+	for _, entry := range entries {
+		if _, err := uht.Remove(entry); err != nil {
+			return &util.GraphError{Type: util.ErrWriting, Detail: err.Error()}
+		}
+	}
+
+	return nil
+}
+
+/*
+uniqueConstraintKey checks the parameters of a unique constraint and returns
+the main database key of the constraint.
+*/
+func (gm *Manager) uniqueConstraintKey(part string, kind string, attrs []string) (string, error) {
+
+	if err := gm.checkPartitionName(part); err != nil {
+		return "", err
+	}
+
+	if kind == "" || strings.Contains(kind, "#") {
+		return "", &util.GraphError{Type: util.ErrInvalidData, Detail: fmt.Sprint("Invalid node kind: ", kind)}
+	}
+
+	if len(attrs) == 0 {
+		return "", &util.GraphError{Type: util.ErrInvalidData, Detail: "Unique constraint needs at least one attribute"}
+	}
+
+	sorted := make([]string, len(attrs))
+	copy(sorted, attrs)
+	sort.Strings(sorted)
+
+	for _, attr := range sorted {
+		if attr == "" || attr == data.NodeKey || attr == data.NodeKind || strings.Contains(attr, ",") {
+			return "", &util.GraphError{Type: util.ErrInvalidData,
+				Detail: fmt.Sprint("Invalid attribute for a unique constraint: ", attr)}
+		}
+	}
+
+	return part + "#" + kind + "#" + strings.Join(sorted, ","), nil
+}
+
+/*
+uniqueConstraints returns all unique constraints of a node kind in a
+partition. It is assumed that the caller holds a lock.
+*/
+func (gm *Manager) uniqueConstraints(part string, kind string) [][]string {
+	var ret [][]string
+
+	prefix := part + "#" + kind + "#"
+
+	for key := range gm.getMainDBMap(MainDBUniqueConstraints) {
+		if strings.HasPrefix(key, prefix) {
+			ret = append(ret, strings.Split(key[len(prefix):], ","))
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return strings.Join(ret[i], ",") < strings.Join(ret[j], ",")
+	})
+
+	return ret
+}
+
+/*
+uniqueLookupAttr returns the first attribute of a list of attributes which is
+part of the value index. Returns an empty string if none of the attributes is
+indexed. It is assumed that the caller holds a lock.
+*/
+func (gm *Manager) uniqueLookupAttr(kind string, attrs []string) string {
+	im := util.NewIndexManager(nil)
+
+	for attr, mode := range gm.indexSchema(kind) {
+		im.SetIndexMode(attr, mode)
+	}
+
+	for _, attr := range attrs {
+		if im.IndexMode(attr) != util.IndexModeNone {
+			return attr
+		}
+	}
+
+	return ""
+}
+
+/*
+uniqueEntry returns the entry of the unique constraint storage which holds the
+values of a node for the given constraint attributes. Returns false if the node
+does not have all the attributes. Values are compared exactly.
+*/
+func uniqueEntry(attrs []string, node data.Node) (string, bool) {
+
+	if node == nil {
+		return "", false
+	}
+
+	vals := node.IndexMap()
+	quoted := make([]string, 0, len(attrs))
+
+	for _, attr := range attrs {
+		val, ok := vals[attr]
+		if !ok {
+			return "", false
+		}
+		quoted = append(quoted, strconv.Quote(val))
+	}
+
+	return PrefixNSUnique + strings.Join(attrs, ",") + "#" + strings.Join(quoted, ","), true
+}
+
+/*
+checkUniqueConstraints checks if a node can be written without violating a
+unique constraint of its kind. Only updated attributes are given if the
+onlyUpdate flag is set. Other nodes for which the optional ignore function
+returns true (e.g. nodes which are about to be removed) are not considered.
+It is assumed that the caller holds the writer lock.
+*/
+func (gm *Manager) checkUniqueConstraints(part string, node data.Node, onlyUpdate bool,
+	ignore func(key string) bool, attht *hash.HTree, valht *hash.HTree) error {
+
+	constraints := gm.uniqueConstraints(part, node.Kind())
+	if len(constraints) == 0 {
+		return nil
+	}
+
+	uht, err := gm.getNodeUniqueHTree(part, node.Kind(), false)
+	if err != nil || uht == nil {
+		return err
+	}
+
+	if onlyUpdate {
+		oldnode, err := gm.readNode(node.Key(), node.Kind(), nil, attht, valht)
+		if err != nil {
+			return err
+		} else if oldnode != nil {
+			node = data.NodeMerge(oldnode, node)
+		}
+	}
+
+	for _, attrs := range constraints {
+
+		entry, ok := uniqueEntry(attrs, node)
+		if !ok {
+			continue
+		}
+
+		other, err := uht.Get([]byte(entry))
+		if err != nil {
+			return &util.GraphError{Type: util.ErrReading, Detail: err.Error()}
+		}
+
+		if key, ok := other.(string); ok && key != node.Key() && (ignore == nil || !ignore(key)) {
+			return &util.GraphError{Type: util.ErrUnique,
+				Detail: fmt.Sprintf("Node %v of kind %v has the same values for %v as node %v in partition %v",
+					node.Key(), node.Kind(), attrs, key, part)}
+		}
+	}
+
+	return nil
+}
+
+/*
+updateNodeUniques updates the unique constraint storage after a node was
+written or deleted. The old node should contain all attributes which were
+overwritten or removed. The current state of the node is read from the given
+node storage. It is assumed that the caller holds the writer lock and flushes
+the unique constraint storage afterwards.
+*/
+func (gm *Manager) updateNodeUniques(part string, key string, kind string, oldnode data.Node,
+	attht *hash.HTree, valht *hash.HTree) error {
+
+	constraints := gm.uniqueConstraints(part, kind)
+	if len(constraints) == 0 {
+		return nil
+	}
+
+	node, err := gm.readNode(key, kind, nil, attht, valht)
+	if err != nil {
+		return err
+	}
+
+	// Restore the old state by putting the old values on top of the current state
+
+	if node != nil && oldnode != nil {
+		oldnode = data.NodeMerge(node, oldnode)
+	}
+
+	uht, err := gm.getNodeUniqueHTree(part, kind, true)
+	if err != nil {
+		return err
+	}
+
+	for _, attrs := range constraints {
+
+		newEntry, hasNew := uniqueEntry(attrs, node)
+		oldEntry, hasOld := uniqueEntry(attrs, oldnode)
+
+		if hasOld && (!hasNew || oldEntry != newEntry) {
+
+			// Only remove the entry if it still belongs to this node
+
+			if other, err := uht.Get([]byte(oldEntry)); err != nil {
+				return &util.GraphError{Type: util.ErrReading, Detail: err.Error()}
+			} else if other == key {
+				if _, err := uht.Remove([]byte(oldEntry)); err != nil {
+					return &util.GraphError{Type: util.ErrWriting, Detail: err.Error()}
+				}
+			}
+		}
+
+		if hasNew {
+			if _, err := uht.Put([]byte(newEntry), key); err != nil {
+				return &util.GraphError{Type: util.ErrWriting, Detail: err.Error()}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,264 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+)
+
+func TestUniqueConstraints(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	if err := gm.AddUniqueConstraint("main", "Person", "extid"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.AddUniqueConstraint("main", "Person", "name", "birthday"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := gm.UniqueConstraints("main", "Person"); fmt.Sprint(res) != "[[birthday name] [extid]]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := gm.UniqueConstraints("second", "Person"); len(res) != 0 {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	constructNode := func(key string, attrs map[string]interface{}) data.Node {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "Person")
+		for k, v := range attrs {
+			node.SetAttr(k, v)
+		}
+		return node
+	}
+
+	if err := gm.StoreNode("main", constructNode("1", map[string]interface{}{
+		"extid": "A1", "name": "John", "birthday": "2000-01-01"})); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Storing the same node again is fine
+
+	if err := gm.StoreNode("main", constructNode("1", map[string]interface{}{
+		"extid": "A1", "name": "John", "birthday": "2000-01-01"})); err != nil {
+		t.Error(err)
+		return
+	}
+
+	err := gm.StoreNode("main", constructNode("2", map[string]interface{}{"extid": "A1"}))
+	if gerr, ok := err.(*util.GraphError); !ok || gerr.Type != util.ErrUnique ||
+		err.Error() != "GraphError: Unique constraint violation (Node 2 of kind Person has the same values for [extid] as node 1 in partition main)" {
+		t.Error(err)
+		return
+	}
+
+	// Values are compared exactly
+
+	if err := gm.StoreNode("main", constructNode("2", map[string]interface{}{"extid": "a1"})); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Composite constraints need all values to be the same
+
+	if err := gm.StoreNode("main", constructNode("3", map[string]interface{}{
+		"name": "John", "birthday": "2001-01-01"})); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.UpdateNode("main", constructNode("3", map[string]interface{}{"birthday": "2000-01-01"})); err == nil ||
+		err.Error() != "GraphError: Unique constraint violation (Node 3 of kind Person has the same values for [birthday name] as node 1 in partition main)" {
+		t.Error(err)
+		return
+	}
+
+	// Constraints are checked when a transaction is committed
+
+	trans := NewGraphTrans(gm)
+	trans.StoreNode("main", constructNode("4", map[string]interface{}{"extid": "B1"}))
+	trans.StoreNode("main", constructNode("5", map[string]interface{}{"extid": "B1"}))
+
+	if err := trans.Commit(); err == nil || err.(*util.GraphError).Type != util.ErrUnique {
+		t.Error(err)
+		return
+	}
+
+	// Nodes which are removed in the same transaction do not count
+
+	trans = NewGraphTrans(gm)
+	trans.RemoveNode("main", "2", "Person")
+	trans.StoreNode("main", constructNode("6", map[string]interface{}{"extid": "a1"}))
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Other partitions are not affected
+
+	if err := gm.StoreNode("second", constructNode("2", map[string]interface{}{"extid": "A1"})); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.RemoveUniqueConstraint("main", "Person", "extid"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.StoreNode("main", constructNode("7", map[string]interface{}{"extid": "A1"})); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Test error cases
+
+	if err := gm.AddUniqueConstraint("main", "Person"); err == nil ||
+		err.Error() != "GraphError: Invalid data (Unique constraint needs at least one attribute)" {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.AddUniqueConstraint("main", "Person", "key"); err == nil ||
+		err.Error() != "GraphError: Invalid data (Invalid attribute for a unique constraint: key)" {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.AddUniqueConstraint("main", ""); err == nil ||
+		err.Error() != "GraphError: Invalid data (Invalid node kind: )" {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.AddUniqueConstraint("ma in", "Person", "extid"); err == nil ||
+		err.Error() != "GraphError: Invalid data (Partition name ma in is not alphanumeric - can only contain [a-zA-Z0-9_])" {
+		t.Error(err)
+		return
+	}
+
+}
+
+func TestUniqueConstraintsExistingNodes(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	// Constraints do not depend on the index
+
+	gm.SetIndexMode("Person", "extid", "none")
+
+	constructNode := func(key string, extid string) data.Node {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "Person")
+		node.SetAttr("extid", extid)
+		return node
+	}
+
+	for i, extid := range []string{"A1", "A2", "A1"} {
+		if err := gm.StoreNode("main", constructNode(fmt.Sprint(i+1), extid)); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	// Existing nodes are checked when a constraint is added
+
+	if err := gm.AddUniqueConstraint("main", "Person", "extid"); err == nil ||
+		err.Error() != "GraphError: Unique constraint violation (Node 3 of kind Person has the same values for [extid] as node 1 in partition main)" {
+		t.Error(err)
+		return
+	}
+
+	if res := gm.UniqueConstraints("main", "Person"); len(res) != 0 {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if err := gm.UpdateNode("main", constructNode("3", "A3")); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.AddUniqueConstraint("main", "Person", "extid"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.StoreNode("main", constructNode("4", "A2")); err == nil ||
+		err.Error() != "GraphError: Unique constraint violation (Node 4 of kind Person has the same values for [extid] as node 2 in partition main)" {
+		t.Error(err)
+		return
+	}
+
+	// Values which were changed or removed can be used again
+
+	if err := gm.UpdateNode("main", constructNode("2", "B2")); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err := gm.RemoveNode("main", "3", "Person"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.StoreNode("main", constructNode("4", "A2")); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.StoreNode("main", constructNode("5", "A3")); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.StoreNode("main", constructNode("6", "B2")); err == nil ||
+		err.(*util.GraphError).Type != util.ErrUnique {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.StoreNode("main", constructNode("6", "C1")); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Removing a constraint removes its recorded values
+
+	if err := gm.RemoveUniqueConstraint("main", "Person", "extid"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.StoreNode("main", constructNode("7", "C1")); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.AddUniqueConstraint("main", "Person", "extid"); err == nil ||
+		err.Error() != "GraphError: Unique constraint violation (Node 7 of kind Person has the same values for [extid] as node 6 in partition main)" {
+		t.Error(err)
+		return
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+)
+
+/*
+VectorQuery returns an object to query the vector index of a node attribute.
+Returns nil if no node of the given kind in the partition has vectors.
+*/
+func (gm *Manager) VectorQuery(part string, kind string, attr string) (VectorQuery, error) {
+	vht, err := gm.getNodeVectorHTree(part, kind, false)
+	if err != nil || vht == nil {
+		return nil, err
+	}
+
+	return &vectorQuery{gm, util.NewVectorIndexManager(vht), part, attr}, nil
+}
+
+/*
+vectorQuery queries the vector index of a single node attribute.
+*/
+type vectorQuery struct {
+	gm   *Manager                 // Graph manager which owns the index
+	vim  *util.VectorIndexManager // Vector index of the node kind
+	part string                   // Partition of the index
+	attr string                   // Attribute to query
+}
+
+/*
+Nearest finds the k nodes with the closest vectors to a given vector.
+*/
+func (vq *vectorQuery) Nearest(vector []float32, k int) ([]string, []float32, error) {
+
+	// Take reader lock
+
+	vq.gm.rlockPartition(vq.part)
+	defer vq.gm.runlockPartition(vq.part)
+
+	return vq.vim.Nearest(vq.attr, vector, k)
+}
+
+/*
+convertVectors converts the values of all attributes which are declared as
+vectors in the schema of the node kind into native vectors. Lists of numbers
+which are sent over the REST API, from ECAL or from an import are otherwise
+stored as plain lists and not indexed as vectors.
+*/
+func (gm *Manager) convertVectors(node data.Node) {
+	schema := gm.kindSchema(node.Kind())
+
+	if schema == nil {
+		return
+	}
+
+	for attr, as := range schema.Attrs {
+		if val := node.Attr(attr); as.Type == "vector" && val != nil {
+			if _, ok := val.([]float32); !ok {
+				if vec, ok := util.ToVector(val); ok {
+					node.SetAttr(attr, vec)
+				}
+			}
+		}
+	}
+}
+
+/*
+nodeVectors returns all vector attributes of a given node.
+*/
+func nodeVectors(node data.Node) map[string][]float32 {
+	ret := make(map[string][]float32)
+
+	if node != nil {
+		for attr, val := range node.Data() {
+			if vec, ok := val.([]float32); ok {
+				ret[attr] = vec
+			}
+		}
+	}
+
+	return ret
+}
+
+/*
+updateNodeVectors updates the vector index after a node was written or
+deleted. The node should be nil if it was deleted. The old node should contain
+all attributes which were overwritten or removed. It is assumed that the
+caller holds the writer lock and flushes the vector index afterwards. The
+vector index is only created once a node has vectors.
+*/
+func (gm *Manager) updateNodeVectors(part string, key string, kind string,
+	node data.Node, oldnode data.Node) error {
+
+	newVecs := nodeVectors(node)
+	oldVecs := nodeVectors(oldnode)
+
+	if len(newVecs) == 0 && len(oldVecs) == 0 {
+		return nil
+	}
+
+	vht, err := gm.getNodeVectorHTree(part, kind, len(newVecs) > 0)
+	if err != nil || vht == nil {
+		return err
+	}
+
+	return util.NewVectorIndexManager(vht).Reindex(key, newVecs, oldVecs)
+}
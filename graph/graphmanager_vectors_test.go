@@ -0,0 +1,207 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+)
+
+func TestVectorQuery(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	constructNode := func(key string, vec []float32) data.Node {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "chunk")
+		node.SetAttr("text", "Chunk "+key)
+		node.SetAttr("embedding", vec)
+		return node
+	}
+
+	if vq, err := gm.VectorQuery("main", "chunk", "embedding"); vq != nil || err != nil {
+		t.Error("Unexpected result:", vq, err)
+		return
+	}
+
+	gm.StoreNode("main", constructNode("1", []float32{1, 0, 0}))
+	gm.StoreNode("main", constructNode("2", []float32{0, 1, 0}))
+
+	trans := NewGraphTrans(gm)
+	trans.StoreNode("main", constructNode("3", []float32{0, 0, 1}))
+	trans.StoreNode("main", constructNode("4", []float32{1, 1, 0}))
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	vq, err := gm.VectorQuery("main", "chunk", "embedding")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res, dists, err := vq.Nearest([]float32{1, 0.1, 0}, 2); err != nil ||
+		fmt.Sprint(res) != "[1 4]" || len(dists) != 2 {
+		t.Error("Unexpected result:", res, dists, err)
+		return
+	}
+
+	// Vectors are stored natively and are not part of the full text index
+
+	node, err := gm.FetchNode("main", "3", "chunk")
+	if vec, ok := node.Attr("embedding").([]float32); err != nil || !ok || fmt.Sprint(vec) != "[0 0 1]" {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	iq, _ := gm.NodeIndexQuery("main", "chunk")
+	if res, err := iq.LookupValue("embedding", "[0,0,1]"); err != nil || len(res) != 0 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Update and remove vectors
+
+	update := data.NewGraphNode()
+	update.SetAttr("key", "1")
+	update.SetAttr("kind", "chunk")
+	update.SetAttr("embedding", []float32{0, 0, 1})
+
+	if err := gm.UpdateNode("main", update); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res, _, err := vq.Nearest([]float32{1, 0.1, 0}, 1); err != nil || fmt.Sprint(res) != "[4]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Overwriting a node without a vector removes it from the index
+
+	node = data.NewGraphNode()
+	node.SetAttr("key", "4")
+	node.SetAttr("kind", "chunk")
+
+	gm.StoreNode("main", node)
+
+	if res, _, err := vq.Nearest([]float32{1, 0.1, 0}, 1); err != nil || fmt.Sprint(res) != "[2]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	gm.RemoveNode("main", "2", "chunk")
+
+	trans = NewGraphTrans(gm)
+	trans.RemoveNode("main", "3", "chunk")
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res, _, err := vq.Nearest([]float32{1, 0.1, 0}, 5); err != nil || fmt.Sprint(res) != "[1]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Test error case
+
+	if _, _, err := vq.Nearest([]float32{1, 0}, 5); err == nil || err.Error() !=
+		"GraphError: Index error (Vector has 2 dimensions - index of attribute embedding has 3 dimensions)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	// Snapshots contain the vector index
+
+	if err := gm.CreateSnapshot("main", "snap"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	gm.StoreNode("snap", constructNode("5", []float32{1, 0, 0}))
+
+	svq, _ := gm.VectorQuery("snap", "chunk", "embedding")
+
+	if res, _, err := svq.Nearest([]float32{1, 0.1, 0}, 5); err != nil || fmt.Sprint(res) != "[5 1]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, _, err := vq.Nearest([]float32{1, 0.1, 0}, 5); err != nil || fmt.Sprint(res) != "[1]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+}
+
+func TestVectorConversion(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	if err := gm.SetSchema("chunk", &KindSchema{Attrs: map[string]*AttrSchema{
+		"embedding": {Type: "vector"},
+	}}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	constructNode := func(key string, vec interface{}) data.Node {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "chunk")
+		node.SetAttr("embedding", vec)
+		return node
+	}
+
+	// Lists of numbers of declared vector attributes are stored as vectors
+
+	if err := gm.StoreNode("main", constructNode("1", []interface{}{1, 0, 0})); err != nil {
+		t.Error(err)
+		return
+	}
+
+	trans := NewGraphTrans(gm)
+	trans.StoreNode("main", constructNode("2", []interface{}{0.0, 1.0, 0.0}))
+	trans.StoreNode("main", constructNode("3", []float64{0, 0, 1}))
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	node, err := gm.FetchNode("main", "2", "chunk")
+	if vec, ok := node.Attr("embedding").([]float32); err != nil || !ok || fmt.Sprint(vec) != "[0 1 0]" {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	vq, err := gm.VectorQuery("main", "chunk", "embedding")
+	if err != nil || vq == nil {
+		t.Error("Unexpected result:", vq, err)
+		return
+	}
+
+	if res, _, err := vq.Nearest([]float32{0.1, 0, 1}, 2); err != nil || fmt.Sprint(res) != "[3 1]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Lists which are not numeric are rejected by the schema
+
+	if err := gm.StoreNode("main", constructNode("4", []interface{}{"a", 1})); err == nil {
+		t.Error("Unexpected result:", err)
+		return
+	}
+}
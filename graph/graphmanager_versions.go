@@ -0,0 +1,758 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"encoding/binary"
+	"encoding/gob"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+	"github.com/Fisch-Labs/FishDB/hash"
+)
+
+/*
+HistoryEntry models a single version of a node or edge in the graph history.
+*/
+type HistoryEntry struct {
+	Version uint64    // Graph version which produced this entry
+	Time    time.Time // Commit time of the version
+	TransID string    // ID of the committing transaction (empty if no transaction was used)
+	Deleted bool      // Flag if the node or edge was removed in this version
+	Node    data.Node // Node or edge in this version (nil if it was removed)
+}
+
+/*
+AttrChange models the change of a single attribute between two versions of
+a node or edge.
+*/
+type AttrChange struct {
+	Attr string      // Name of the changed attribute
+	Old  interface{} // Value in the older version (nil if the attribute was added)
+	New  interface{} // Value in the newer version (nil if the attribute was removed)
+}
+
+/*
+versionEntry is an internal structure which stores a single version of a node or edge
+*/
+type versionEntry struct {
+	Version uint64                 // Graph version which produced this entry
+	Time    int64                  // Commit time of the version (Unix time in nanoseconds)
+	TransID string                 // ID of the committing transaction
+	Deleted bool                   // Flag if the node or edge was removed in this version
+	Data    map[string]interface{} // Data of the node or edge in this version
+}
+
+/*
+versionCommit is an internal structure which holds the information of a
+commit which produces a new graph version.
+*/
+type versionCommit struct {
+	version uint64                  // Graph version which is produced by the commit
+	time    int64                   // Commit time (Unix time in nanoseconds)
+	transID string                  // ID of the committing transaction
+	changes []*changeEntry          // Changes of the commit in the order they were made
+	lookup  map[string]*changeEntry // Lookup for changes of single nodes and edges
+}
+
+func init() {
+
+	// Make sure we can use the relevant types in a gob operation
+
+	gob.Register(&versionEntry{})
+}
+
+/*
+CurrentVersion returns the graph version of the last committed change. Every
+committed write operation produces a new graph version.
+*/
+func (gm *Manager) CurrentVersion() uint64 {
+	gm.mainMutex.Lock()
+	defer gm.mainMutex.Unlock()
+
+	return gm.currentVersion()
+}
+
+/*
+currentVersion returns the graph version of the last committed change. It is
+assumed that the caller holds the main database mutex.
+*/
+func (gm *Manager) currentVersion() uint64 {
+
+	if val, ok := gm.gs.MainDB()[MainDBGraphVersion]; ok {
+		return binary.LittleEndian.Uint64([]byte(val))
+	}
+
+	return 0
+}
+
+/*
+newVersion increases the graph version and returns the new version. It is
+assumed that, after the function returns, the main database is flushed.
+Concurrent commits get distinct versions.
+*/
+func (gm *Manager) newVersion() uint64 {
+	gm.mainMutex.Lock()
+	defer gm.mainMutex.Unlock()
+
+	version := gm.currentVersion() + 1
+
+	numstr := make([]byte, 8)
+	binary.LittleEndian.PutUint64(numstr, version)
+	gm.gs.MainDB()[MainDBGraphVersion] = string(numstr)
+
+	return version
+}
+
+/*
+revertVersion reverts the graph version increase of a failed commit. The graph
+version is only reverted if no later commit has taken a new version since.
+*/
+func (gm *Manager) revertVersion(version uint64) {
+	gm.mainMutex.Lock()
+	defer gm.mainMutex.Unlock()
+
+	if gm.currentVersion() == version {
+		numstr := make([]byte, 8)
+		binary.LittleEndian.PutUint64(numstr, version-1)
+		gm.gs.MainDB()[MainDBGraphVersion] = string(numstr)
+	}
+}
+
+/*
+newVersionCommit starts a new commit which produces a new graph version. The
+same assumptions as for newVersion apply.
+*/
+func (gm *Manager) newVersionCommit(transID string) *versionCommit {
+	return &versionCommit{gm.newVersion(), time.Now().UnixNano(), transID, nil,
+		make(map[string]*changeEntry)}
+}
+
+/*
+FetchNodeAsOf fetches a single node from a partition of the graph as it was
+in a given graph version. Returns nil if the node did not exist in the
+given version.
+*/
+func (gm *Manager) FetchNodeAsOf(part string, key string, kind string, version uint64) (data.Node, error) {
+
+	// Get the HTree which stores the node history
+
+	hht, err := gm.getNodeHistoryHTree(part, kind, false)
+	if err != nil || hht == nil {
+		return nil, err
+	}
+
+	// Take reader lock
+
+	gm.rlockPartition(part)
+	defer gm.runlockPartition(part)
+
+	return gm.readNodeAsOf(key, version, hht)
+}
+
+/*
+FetchEdgeAsOf fetches a single edge from a partition of the graph as it was
+in a given graph version. Returns nil if the edge did not exist in the
+given version.
+*/
+func (gm *Manager) FetchEdgeAsOf(part string, key string, kind string, version uint64) (data.Edge, error) {
+
+	// Get the HTree which stores the edge history
+
+	hht, err := gm.getEdgeHistoryHTree(part, kind, false)
+	if err != nil || hht == nil {
+		return nil, err
+	}
+
+	// Take reader lock
+
+	gm.rlockPartition(part)
+	defer gm.runlockPartition(part)
+
+	node, err := gm.readNodeAsOf(key, version, hht)
+	if err != nil || node == nil {
+		return nil, err
+	}
+
+	return data.NewGraphEdgeFromNode(node), nil
+}
+
+/*
+TraverseAsOf traverses from a given node to other nodes following a given edge
+spec as the graph was in a given graph version. The last parameter allData
+specifies if all data should be retrieved for the connected nodes and edges.
+If set to false only the minimal set of attributes will be populated.
+*/
+func (gm *Manager) TraverseAsOf(part string, key string, kind string,
+	spec string, allData bool, version uint64) ([]data.Node, []data.Edge, error) {
+
+	if !IsFullSpec(spec) {
+		return nil, nil, &util.GraphError{Type: util.ErrInvalidData, Detail: "Invalid spec: " + spec +
+			" - spec needs to be fully specified for direct traversal"}
+	}
+
+	return gm.TraverseMultiAsOf(part, key, kind, spec, allData, version)
+}
+
+/*
+TraverseMultiAsOf traverses from a given node to other nodes following a given
+partial edge spec as the graph was in a given graph version. Since the edge
+spec can be partial it is possible to traverse multiple edge kinds. A spec
+with the value ":::" would follow all relationships. The last parameter
+allData specifies if all data should be retrieved for the connected nodes
+and edges. If set to false only the minimal set of attributes will be populated.
+*/
+func (gm *Manager) TraverseMultiAsOf(part string, key string, kind string,
+	spec string, allData bool, version uint64) ([]data.Node, []data.Edge, error) {
+
+	sspec := strings.Split(spec, ":")
+	if len(sspec) != 4 {
+		return nil, nil, &util.GraphError{Type: util.ErrInvalidData, Detail: "Invalid spec: " + spec}
+	}
+
+	// Get the HTree which stores the node history
+
+	hht, err := gm.getNodeHistoryHTree(part, kind, false)
+	if err != nil || hht == nil {
+		return nil, nil, err
+	}
+
+	// Take reader lock
+
+	gm.rlockPartition(part)
+	defer gm.runlockPartition(part)
+
+	// Check that the start node existed in the given version
+
+	if node, err := gm.readNodeAsOf(key, version, hht); err != nil || node == nil {
+		return nil, nil, err
+	}
+
+	// Lookup all edges which were ever connected to the start node
+
+	obj, err := hht.Get([]byte(PrefixNSHistEdges + key))
+	if err != nil {
+		return nil, nil, &util.GraphError{Type: util.ErrReading, Detail: err.Error()}
+	} else if obj == nil {
+		return nil, nil, nil
+	}
+
+	histEdges := make([]string, 0, len(obj.(map[string]string)))
+	for histEdge := range obj.(map[string]string) {
+		histEdges = append(histEdges, histEdge)
+	}
+
+	// Ensure the output is deterministic
+
+	sort.StringSlice(histEdges).Sort()
+
+	var nodes []data.Node
+	var edges []data.Edge
+
+	for _, histEdge := range histEdges {
+		kindAndKey := strings.SplitN(histEdge, "#", 2)
+
+		edgehht, err := gm.getEdgeHistoryHTree(part, kindAndKey[0], false)
+		if err != nil {
+			return nil, nil, err
+		} else if edgehht == nil {
+			continue
+		}
+
+		edgenode, err := gm.readNodeAsOf(kindAndKey[1], version, edgehht)
+		if err != nil {
+			return nil, nil, err
+		} else if edgenode == nil {
+			continue
+		}
+
+		edge := data.NewGraphEdgeFromNode(edgenode)
+
+		// Exchange ends if necessary
+
+		if edge.End1Key() != key || edge.End1Kind() != kind {
+			swap := func(attr1 string, attr2 string) {
+				tmp := edge.Attr(attr1)
+				edge.SetAttr(attr1, edge.Attr(attr2))
+				edge.SetAttr(attr2, tmp)
+			}
+
+			swap(data.EdgeEnd1Key, data.EdgeEnd2Key)
+			swap(data.EdgeEnd1Kind, data.EdgeEnd2Kind)
+			swap(data.EdgeEnd1Role, data.EdgeEnd2Role)
+			swap(data.EdgeEnd1Cascading, data.EdgeEnd2Cascading)
+			swap(data.EdgeEnd1CascadingLast, data.EdgeEnd2CascadingLast)
+		}
+
+		// Check spec components
+
+		if (sspec[0] != "" && edge.End1Role() != sspec[0]) ||
+			(sspec[1] != "" && edge.Kind() != sspec[1]) ||
+			(sspec[2] != "" && edge.End2Role() != sspec[2]) ||
+			(sspec[3] != "" && edge.End2Kind() != sspec[3]) {
+
+			continue
+		}
+
+		// Read the node on the other end
+
+		nodehht, err := gm.getNodeHistoryHTree(part, edge.End2Kind(), false)
+		if err != nil {
+			return nil, nil, err
+		} else if nodehht == nil {
+			continue
+		}
+
+		node, err := gm.readNodeAsOf(edge.End2Key(), version, nodehht)
+		if err != nil {
+			return nil, nil, err
+		} else if node == nil {
+			continue
+		}
+
+		if !allData {
+
+			// Reduce nodes and edges to the minimal set of attributes
+
+			minEdge := data.NewGraphEdge()
+
+			for _, attr := range []string{data.NodeKey, data.NodeKind,
+				data.EdgeEnd1Key, data.EdgeEnd1Kind, data.EdgeEnd1Role,
+				data.EdgeEnd1Cascading, data.EdgeEnd1CascadingLast,
+				data.EdgeEnd2Key, data.EdgeEnd2Kind, data.EdgeEnd2Role,
+				data.EdgeEnd2Cascading, data.EdgeEnd2CascadingLast} {
+
+				minEdge.SetAttr(attr, edge.Attr(attr))
+			}
+
+			minNode := data.NewGraphNode()
+
+			minNode.SetAttr(data.NodeKey, node.Key())
+			minNode.SetAttr(data.NodeKind, node.Kind())
+
+			edge = minEdge
+			node = minNode
+		}
+
+		nodes = append(nodes, node)
+		edges = append(edges, edge)
+	}
+
+	return nodes, edges, nil
+}
+
+/*
+NodeHistory returns all recorded versions of a node, ordered from the oldest
+to the newest version. Returns nil if there is no history for the node.
+*/
+func (gm *Manager) NodeHistory(part string, key string, kind string) ([]*HistoryEntry, error) {
+
+	// Get the HTree which stores the node history
+
+	hht, err := gm.getNodeHistoryHTree(part, kind, false)
+	if err != nil || hht == nil {
+		return nil, err
+	}
+
+	// Take reader lock
+
+	gm.rlockPartition(part)
+	defer gm.runlockPartition(part)
+
+	return gm.readHistory(key, hht, false)
+}
+
+/*
+EdgeHistory returns all recorded versions of an edge, ordered from the oldest
+to the newest version. Returns nil if there is no history for the edge.
+*/
+func (gm *Manager) EdgeHistory(part string, key string, kind string) ([]*HistoryEntry, error) {
+
+	// Get the HTree which stores the edge history
+
+	hht, err := gm.getEdgeHistoryHTree(part, kind, false)
+	if err != nil || hht == nil {
+		return nil, err
+	}
+
+	// Take reader lock
+
+	gm.rlockPartition(part)
+	defer gm.runlockPartition(part)
+
+	return gm.readHistory(key, hht, true)
+}
+
+/*
+DiffNodeVersions computes the attribute-level changes of a node between two
+graph versions. A node which did not exist in a version is treated as a node
+without attributes.
+*/
+func (gm *Manager) DiffNodeVersions(part string, key string, kind string,
+	fromVersion uint64, toVersion uint64) ([]*AttrChange, error) {
+
+	fromNode, err := gm.FetchNodeAsOf(part, key, kind, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	toNode, err := gm.FetchNodeAsOf(part, key, kind, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffNodes(fromNode, toNode), nil
+}
+
+/*
+DiffEdgeVersions computes the attribute-level changes of an edge between two
+graph versions. An edge which did not exist in a version is treated as an
+edge without attributes.
+*/
+func (gm *Manager) DiffEdgeVersions(part string, key string, kind string,
+	fromVersion uint64, toVersion uint64) ([]*AttrChange, error) {
+
+	fromEdge, err := gm.FetchEdgeAsOf(part, key, kind, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	toEdge, err := gm.FetchEdgeAsOf(part, key, kind, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	// Avoid typed nil values
+
+	var fromNode, toNode data.Node
+
+	if fromEdge != nil {
+		fromNode = fromEdge
+	}
+	if toEdge != nil {
+		toNode = toEdge
+	}
+
+	return diffNodes(fromNode, toNode), nil
+}
+
+/*
+readHistory reads all version entries of a given node or edge from a
+history datastore.
+*/
+func (gm *Manager) readHistory(key string, histTree *hash.HTree, isEdge bool) ([]*HistoryEntry, error) {
+
+	obj, err := histTree.Get([]byte(PrefixNSVersions + key))
+	if err != nil {
+		return nil, &util.GraphError{Type: util.ErrReading, Detail: err.Error()}
+	} else if obj == nil {
+		return nil, nil
+	}
+
+	versions := obj.([]uint64)
+	res := make([]*HistoryEntry, 0, len(versions))
+
+	for _, version := range versions {
+
+		obj, err := histTree.Get([]byte(PrefixNSVersion + key + versionToString(version)))
+		if err != nil {
+			return nil, &util.GraphError{Type: util.ErrReading, Detail: err.Error()}
+		} else if obj == nil {
+			continue
+		}
+
+		entry := obj.(*versionEntry)
+		hentry := &HistoryEntry{entry.Version, time.Unix(0, entry.Time), entry.TransID, entry.Deleted, nil}
+
+		if !entry.Deleted {
+			node := data.NewGraphNode()
+			for attr, val := range entry.Data {
+				node.SetAttr(attr, val)
+			}
+
+			if isEdge {
+				hentry.Node = data.NewGraphEdgeFromNode(node)
+			} else {
+				hentry.Node = node
+			}
+		}
+
+		res = append(res, hentry)
+	}
+
+	return res, nil
+}
+
+/*
+diffNodes computes the attribute-level changes between two nodes. The result
+is sorted by attribute name.
+*/
+func diffNodes(fromNode data.Node, toNode data.Node) []*AttrChange {
+	var fromData, toData map[string]interface{}
+
+	if fromNode != nil {
+		fromData = fromNode.Data()
+	}
+	if toNode != nil {
+		toData = toNode.Data()
+	}
+
+	res := make([]*AttrChange, 0)
+
+	for attr, oldVal := range fromData {
+		newVal, ok := toData[attr]
+		if !ok || !reflect.DeepEqual(oldVal, newVal) {
+			res = append(res, &AttrChange{attr, oldVal, newVal})
+		}
+	}
+
+	for attr, newVal := range toData {
+		if _, ok := fromData[attr]; !ok {
+			res = append(res, &AttrChange{attr, nil, newVal})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Attr < res[j].Attr
+	})
+
+	return res
+}
+
+/*
+readNodeAsOf reads a given node or edge from a history datastore as it was in
+a given graph version.
+*/
+func (gm *Manager) readNodeAsOf(key string, version uint64, histTree *hash.HTree) (data.Node, error) {
+
+	entry, err := gm.readVersionEntry(key, version, histTree)
+	if err != nil || entry == nil || entry.Deleted {
+		return nil, err
+	}
+
+	node := data.NewGraphNode()
+	for attr, val := range entry.Data {
+		node.SetAttr(attr, val)
+	}
+
+	return node, nil
+}
+
+/*
+readVersionEntry reads the latest version entry of a given node or edge
+which is not newer than the given graph version.
+*/
+func (gm *Manager) readVersionEntry(key string, version uint64, histTree *hash.HTree) (*versionEntry, error) {
+
+	obj, err := histTree.Get([]byte(PrefixNSVersions + key))
+	if err != nil {
+		return nil, &util.GraphError{Type: util.ErrReading, Detail: err.Error()}
+	} else if obj == nil {
+		return nil, nil
+	}
+
+	versions := obj.([]uint64)
+
+	// Find the first version which is newer than the requested version
+
+	i := sort.Search(len(versions), func(i int) bool {
+		return versions[i] > version
+	})
+
+	if i == 0 {
+		return nil, nil
+	}
+
+	obj, err = histTree.Get([]byte(PrefixNSVersion + key + versionToString(versions[i-1])))
+	if err != nil {
+		return nil, &util.GraphError{Type: util.ErrReading, Detail: err.Error()}
+	} else if obj == nil {
+		return nil, nil
+	}
+
+	return obj.(*versionEntry), nil
+}
+
+/*
+writeNodeVersion records the current state of a node in the node history.
+It is assumed that the caller holds the writer lock before calling the
+functions and that, after the function returns, the changes are flushed to
+the storage.
+*/
+func (gm *Manager) writeNodeVersion(part string, key string, kind string, commit *versionCommit,
+	attrTree *hash.HTree, valTree *hash.HTree) error {
+
+	hht, err := gm.getNodeHistoryHTree(part, kind, true)
+	if err != nil {
+		return err
+	}
+
+	node, err := gm.readNode(key, kind, nil, attrTree, valTree)
+	if err != nil {
+		return err
+	}
+
+	return gm.writeVersionEntry(part, key, kind, false, commit, node, hht)
+}
+
+/*
+writeEdgeVersion records the current state of an edge in the edge history.
+It is assumed that the caller holds the writer lock before calling the
+functions and that, after the function returns, the changes are flushed to
+the storage.
+*/
+func (gm *Manager) writeEdgeVersion(part string, key string, kind string, commit *versionCommit,
+	edgeTree *hash.HTree) error {
+
+	hht, err := gm.getEdgeHistoryHTree(part, kind, true)
+	if err != nil {
+		return err
+	}
+
+	node, err := gm.readNode(key, kind, nil, edgeTree, edgeTree)
+	if err != nil {
+		return err
+	}
+
+	return gm.writeVersionEntry(part, key, kind, true, commit, node, hht)
+}
+
+/*
+writeVersionEntry writes a new version entry for a given node or edge. A nil
+node records the removal of the node or edge. The change is also recorded in
+the change set of the commit.
+*/
+func (gm *Manager) writeVersionEntry(part string, key string, kind string, isEdge bool,
+	commit *versionCommit, node data.Node, histTree *hash.HTree) error {
+
+	version := commit.version
+	entry := &versionEntry{version, commit.time, commit.transID, node == nil, nil}
+
+	if node != nil {
+
+		// Copy the data so later changes to the node do not change the history
+
+		entry.Data = make(map[string]interface{}, len(node.Data()))
+		for attr, val := range node.Data() {
+			entry.Data[attr] = val
+		}
+	}
+
+	versionsKey := PrefixNSVersions + key
+
+	obj, err := histTree.Get([]byte(versionsKey))
+	if err != nil {
+		return &util.GraphError{Type: util.ErrReading, Detail: err.Error()}
+	}
+
+	versions, _ := obj.([]uint64)
+
+	// Record the change - the state before the commit is the last version
+	// which was not produced by the commit
+
+	change, ok := commit.lookup[changeKey(part, key, kind, isEdge)]
+
+	if !ok {
+		var before map[string]interface{}
+
+		prev := len(versions) - 1
+		if prev >= 0 && versions[prev] == version {
+			prev--
+		}
+
+		if prev >= 0 {
+			obj, err := histTree.Get([]byte(PrefixNSVersion + key + versionToString(versions[prev])))
+			if err != nil {
+				return &util.GraphError{Type: util.ErrReading, Detail: err.Error()}
+			} else if obj != nil && !obj.(*versionEntry).Deleted {
+				before = obj.(*versionEntry).Data
+			}
+		}
+
+		change = commit.recordChange(part, key, kind, isEdge, before)
+	}
+
+	change.After = entry.Data
+
+	// An item which is written multiple times in the same version only
+	// keeps its last state
+
+	if len(versions) == 0 || versions[len(versions)-1] != version {
+		versions = append(versions, version)
+
+		if _, err := histTree.Put([]byte(versionsKey), versions); err != nil {
+			return &util.GraphError{Type: util.ErrWriting, Detail: err.Error()}
+		}
+	}
+
+	if _, err := histTree.Put([]byte(PrefixNSVersion+key+versionToString(version)), entry); err != nil {
+		return &util.GraphError{Type: util.ErrWriting, Detail: err.Error()}
+	}
+
+	return nil
+}
+
+/*
+writeHistoryEdge records on both ends of a given edge that the edge was
+connected to them. It is assumed that the caller holds the writer lock
+before calling the functions and that, after the function returns, the
+changes are flushed to the storage.
+*/
+func (gm *Manager) writeHistoryEdge(part string, edge data.Edge) error {
+
+	updateHistEdges := func(key string, kind string) error {
+		var histEdges map[string]string
+
+		hht, err := gm.getNodeHistoryHTree(part, kind, true)
+		if err != nil {
+			return err
+		}
+
+		obj, err := hht.Get([]byte(PrefixNSHistEdges + key))
+
+		if err != nil {
+			return &util.GraphError{Type: util.ErrReading, Detail: err.Error()}
+		} else if obj == nil {
+			histEdges = make(map[string]string)
+		} else {
+			histEdges = obj.(map[string]string)
+		}
+
+		histEdge := edge.Kind() + "#" + edge.Key()
+
+		if _, ok := histEdges[histEdge]; !ok {
+			histEdges[histEdge] = ""
+
+			if _, err = hht.Put([]byte(PrefixNSHistEdges+key), histEdges); err != nil {
+				return &util.GraphError{Type: util.ErrWriting, Detail: err.Error()}
+			}
+		}
+
+		return nil
+	}
+
+	if err := updateHistEdges(edge.End1Key(), edge.End1Kind()); err != nil {
+		return err
+	}
+
+	return updateHistEdges(edge.End2Key(), edge.End2Kind())
+}
+
+/*
+versionToString converts a graph version into a string which can be used
+in storage keys.
+*/
+func versionToString(version uint64) string {
+	numstr := make([]byte, 8)
+	binary.BigEndian.PutUint64(numstr, version)
+	return string(numstr)
+}
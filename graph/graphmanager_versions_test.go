@@ -0,0 +1,419 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+)
+
+func TestNodeVersions(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := newGraphManagerNoRules(mgs)
+
+	if v := gm.CurrentVersion(); v != 0 {
+		t.Error("Unexpected initial version:", v)
+		return
+	}
+
+	node1 := data.NewGraphNode()
+	node1.SetAttr("key", "123")
+	node1.SetAttr("kind", "mykind")
+	node1.SetAttr("Name", "Node1")
+
+	if err := gm.StoreNode("main", node1); err != nil {
+		t.Error(err)
+		return
+	}
+
+	v1 := gm.CurrentVersion()
+
+	node1 = data.NewGraphNode()
+	node1.SetAttr("key", "123")
+	node1.SetAttr("kind", "mykind")
+	node1.SetAttr("Name", "Node1 updated")
+	node1.SetAttr("Extra", "foo")
+
+	if err := gm.StoreNode("main", node1); err != nil {
+		t.Error(err)
+		return
+	}
+
+	v2 := gm.CurrentVersion()
+
+	if _, err := gm.RemoveNode("main", "123", "mykind"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	v3 := gm.CurrentVersion()
+
+	if v1 != 1 || v2 != 2 || v3 != 3 {
+		t.Error("Unexpected versions:", v1, v2, v3)
+		return
+	}
+
+	if n, err := gm.FetchNodeAsOf("main", "123", "mykind", 0); n != nil || err != nil {
+		t.Error("Unexpected result:", n, err)
+		return
+	}
+
+	n, err := gm.FetchNodeAsOf("main", "123", "mykind", v1)
+	if err != nil || n.Attr("Name") != "Node1" || n.Attr("Extra") != nil {
+		t.Error("Unexpected result:", n, err)
+		return
+	}
+
+	n, err = gm.FetchNodeAsOf("main", "123", "mykind", v2)
+	if err != nil || n.Attr("Name") != "Node1 updated" || n.Attr("Extra") != "foo" {
+		t.Error("Unexpected result:", n, err)
+		return
+	}
+
+	if n, err := gm.FetchNodeAsOf("main", "123", "mykind", v3); n != nil || err != nil {
+		t.Error("Unexpected result:", n, err)
+		return
+	}
+
+	// Unknown kinds and keys have no history
+
+	if n, err := gm.FetchNodeAsOf("main", "123", "foo", v2); n != nil || err != nil {
+		t.Error("Unexpected result:", n, err)
+		return
+	}
+
+	if n, err := gm.FetchNodeAsOf("main", "456", "mykind", v2); n != nil || err != nil {
+		t.Error("Unexpected result:", n, err)
+		return
+	}
+
+	// A transaction produces a single version for all its changes
+
+	trans := NewGraphTrans(gm)
+
+	for _, key := range []string{"1", "2", "3"} {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "mykind")
+		trans.StoreNode("main", node)
+	}
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if v := gm.CurrentVersion(); v != v3+1 {
+		t.Error("Unexpected version:", v)
+		return
+	}
+
+	for _, key := range []string{"1", "2", "3"} {
+		if n, err := gm.FetchNodeAsOf("main", key, "mykind", v3); n != nil || err != nil {
+			t.Error("Unexpected result:", n, err)
+			return
+		}
+		if n, err := gm.FetchNodeAsOf("main", key, "mykind", v3+1); n == nil || err != nil {
+			t.Error("Unexpected result:", n, err)
+			return
+		}
+	}
+}
+
+func TestEdgeVersions(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := newGraphManagerNoRules(mgs)
+
+	constructNode := func(key string, name string) data.Node {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "mykind")
+		node.SetAttr("Name", name)
+		return node
+	}
+
+	constructEdge := func(key string, key1 string, key2 string, name string) data.Edge {
+		edge := data.NewGraphEdge()
+
+		edge.SetAttr("key", key)
+		edge.SetAttr("kind", "myedge")
+		edge.SetAttr("Name", name)
+
+		edge.SetAttr(data.EdgeEnd1Key, key1)
+		edge.SetAttr(data.EdgeEnd1Kind, "mykind")
+		edge.SetAttr(data.EdgeEnd1Role, "node1")
+		edge.SetAttr(data.EdgeEnd1Cascading, false)
+
+		edge.SetAttr(data.EdgeEnd2Key, key2)
+		edge.SetAttr(data.EdgeEnd2Kind, "mykind")
+		edge.SetAttr(data.EdgeEnd2Role, "node2")
+		edge.SetAttr(data.EdgeEnd2Cascading, false)
+
+		return edge
+	}
+
+	gm.StoreNode("main", constructNode("1", "Node1"))
+	gm.StoreNode("main", constructNode("2", "Node2"))
+	gm.StoreNode("main", constructNode("3", "Node3"))
+
+	v1 := gm.CurrentVersion()
+
+	if err := gm.StoreEdge("main", constructEdge("a", "1", "2", "Edge1")); err != nil {
+		t.Error(err)
+		return
+	}
+
+	v2 := gm.CurrentVersion()
+
+	if err := gm.StoreEdge("main", constructEdge("b", "3", "1", "Edge2")); err != nil {
+		t.Error(err)
+		return
+	}
+
+	gm.StoreNode("main", constructNode("2", "Node2 updated"))
+
+	v3 := gm.CurrentVersion()
+
+	if _, err := gm.RemoveEdge("main", "a", "myedge"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	v4 := gm.CurrentVersion()
+
+	e, err := gm.FetchEdgeAsOf("main", "a", "myedge", v2)
+	if err != nil || e.Attr("Name") != "Edge1" || e.End2Key() != "2" {
+		t.Error("Unexpected result:", e, err)
+		return
+	}
+
+	if e, err := gm.FetchEdgeAsOf("main", "a", "myedge", v1); e != nil || err != nil {
+		t.Error("Unexpected result:", e, err)
+		return
+	}
+
+	if e, err := gm.FetchEdgeAsOf("main", "a", "myedge", v4); e != nil || err != nil {
+		t.Error("Unexpected result:", e, err)
+		return
+	}
+
+	// Traverse the graph as it was in different versions
+
+	checkTraversal := func(version uint64, spec string, allData bool, expected ...string) {
+		t.Helper()
+
+		nodes, edges, err := gm.TraverseMultiAsOf("main", "1", "mykind", spec, allData, version)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if len(nodes) != len(expected) || len(edges) != len(expected) {
+			t.Error("Unexpected traversal result:", version, nodes, edges)
+			return
+		}
+
+		for i, name := range expected {
+			if nodes[i].Key()+":"+edges[i].Key() != name {
+				t.Error("Unexpected traversal result:", version, nodes, edges)
+				return
+			}
+			if edges[i].End1Key() != "1" {
+				t.Error("Edge ends should have been exchanged:", edges[i])
+				return
+			}
+			if allData && nodes[i].Attr("Name") == nil {
+				t.Error("Node should have all data:", nodes[i])
+				return
+			} else if !allData && nodes[i].Attr("Name") != nil {
+				t.Error("Node should have minimal data:", nodes[i])
+				return
+			}
+		}
+	}
+
+	checkTraversal(v1, ":::", true)
+	checkTraversal(v2, ":::", true, "2:a")
+	checkTraversal(v3, ":::", false, "2:a", "3:b")
+	checkTraversal(v3, "node1:myedge:node2:mykind", true, "2:a")
+	checkTraversal(v3, "node2:::", true, "3:b")
+	checkTraversal(v3, "foo:::", true)
+	checkTraversal(v4, ":::", true, "3:b")
+
+	nodes, _, err := gm.TraverseAsOf("main", "1", "mykind", "node1:myedge:node2:mykind", true, v2)
+	if err != nil || len(nodes) != 1 || nodes[0].Attr("Name") != "Node2" {
+		t.Error("Unexpected result:", nodes, err)
+		return
+	}
+
+	nodes, _, err = gm.TraverseAsOf("main", "1", "mykind", "node1:myedge:node2:mykind", true, v3)
+	if err != nil || len(nodes) != 1 || nodes[0].Attr("Name") != "Node2 updated" {
+		t.Error("Unexpected result:", nodes, err)
+		return
+	}
+
+	if _, _, err := gm.TraverseAsOf("main", "1", "mykind", ":::", true, v3); err == nil ||
+		err.Error() != "GraphError: Invalid data (Invalid spec: ::: - spec needs to be fully specified for direct traversal)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if _, _, err := gm.TraverseMultiAsOf("main", "1", "mykind", "::", true, v3); err == nil ||
+		err.Error() != "GraphError: Invalid data (Invalid spec: ::)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+}
+
+func TestHistory(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := newGraphManagerNoRules(mgs)
+
+	node1 := data.NewGraphNode()
+	node1.SetAttr("key", "123")
+	node1.SetAttr("kind", "mykind")
+	node1.SetAttr("Name", "Node1")
+	node1.SetAttr("Text", "foo")
+
+	if err := gm.StoreNode("main", node1); err != nil {
+		t.Error(err)
+		return
+	}
+
+	node2 := data.NewGraphNode()
+	node2.SetAttr("key", "456")
+	node2.SetAttr("kind", "mykind")
+
+	trans := NewGraphTrans(gm)
+
+	node1 = data.NewGraphNode()
+	node1.SetAttr("key", "123")
+	node1.SetAttr("kind", "mykind")
+	node1.SetAttr("Name", "Node1 updated")
+	node1.SetAttr("Extra", "bar")
+
+	trans.StoreNode("main", node1)
+	trans.StoreNode("main", node2)
+
+	edge := data.NewGraphEdge()
+
+	edge.SetAttr("key", "abc")
+	edge.SetAttr("kind", "myedge")
+
+	edge.SetAttr(data.EdgeEnd1Key, node1.Key())
+	edge.SetAttr(data.EdgeEnd1Kind, node1.Kind())
+	edge.SetAttr(data.EdgeEnd1Role, "node1")
+	edge.SetAttr(data.EdgeEnd1Cascading, false)
+
+	edge.SetAttr(data.EdgeEnd2Key, node2.Key())
+	edge.SetAttr(data.EdgeEnd2Kind, node2.Kind())
+	edge.SetAttr(data.EdgeEnd2Role, "node2")
+	edge.SetAttr(data.EdgeEnd2Cascading, false)
+
+	trans.StoreEdge("main", edge)
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err := gm.RemoveEdge("main", "abc", "myedge"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err := gm.RemoveNode("main", "123", "mykind"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	hist, err := gm.NodeHistory("main", "123", "mykind")
+	if err != nil || len(hist) != 3 {
+		t.Error("Unexpected result:", hist, err)
+		return
+	}
+
+	if hist[0].Version != 1 || hist[0].TransID != "" || hist[0].Deleted ||
+		hist[0].Node.Attr("Name") != "Node1" {
+		t.Error("Unexpected history entry:", hist[0])
+		return
+	}
+
+	if hist[1].Version != 2 || hist[1].TransID != trans.ID() || hist[1].Deleted ||
+		hist[1].Node.Attr("Name") != "Node1 updated" {
+		t.Error("Unexpected history entry:", hist[1])
+		return
+	}
+
+	if hist[0].Time.After(hist[1].Time) || hist[1].Time.After(hist[2].Time) {
+		t.Error("Unexpected history times:", hist[0].Time, hist[1].Time, hist[2].Time)
+		return
+	}
+
+	if hist[2].Version != 4 || !hist[2].Deleted || hist[2].Node != nil {
+		t.Error("Unexpected history entry:", hist[2])
+		return
+	}
+
+	ehist, err := gm.EdgeHistory("main", "abc", "myedge")
+	if err != nil || len(ehist) != 2 || ehist[0].TransID != trans.ID() ||
+		ehist[0].Node.(data.Edge).End2Key() != "456" || !ehist[1].Deleted {
+		t.Error("Unexpected result:", ehist, err)
+		return
+	}
+
+	if hist, err := gm.NodeHistory("main", "789", "mykind"); hist != nil || err != nil {
+		t.Error("Unexpected result:", hist, err)
+		return
+	}
+
+	if hist, err := gm.EdgeHistory("main", "abc", "foo"); hist != nil || err != nil {
+		t.Error("Unexpected result:", hist, err)
+		return
+	}
+
+	// Compute diffs between versions
+
+	diffString := func(diff []*AttrChange) string {
+		var res []string
+		for _, c := range diff {
+			res = append(res, fmt.Sprintf("%v:%v->%v", c.Attr, c.Old, c.New))
+		}
+		return strings.Join(res, " ")
+	}
+
+	diff, err := gm.DiffNodeVersions("main", "123", "mykind", 1, 2)
+	if res := diffString(diff); err != nil ||
+		res != "Extra:<nil>->bar Name:Node1->Node1 updated Text:foo-><nil>" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	diff, err = gm.DiffNodeVersions("main", "123", "mykind", 0, 1)
+	if res := diffString(diff); err != nil ||
+		res != "Name:<nil>->Node1 Text:<nil>->foo key:<nil>->123 kind:<nil>->mykind" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	diff, err = gm.DiffNodeVersions("main", "123", "mykind", 2, 2)
+	if res := diffString(diff); err != nil || res != "" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	diff, err = gm.DiffEdgeVersions("main", "abc", "myedge", 2, 3)
+	if err != nil || len(diff) != 10 || diff[0].Old == nil || diff[0].New != nil {
+		t.Error("Unexpected result:", diffString(diff), err)
+		return
+	}
+}
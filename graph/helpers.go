@@ -70,7 +70,9 @@ func (gm *Manager) checkItemGeneral(node data.Node, name string) error {
 		}
 	}
 
-	return nil
+	_, _, err := expectedRevision(node)
+
+	return err
 }
 
 /*
@@ -130,13 +132,10 @@ func (gm *Manager) checkEdge(edge data.Edge) error {
 writeNodeCount writes a new node count for a specific kind to the datastore.
 */
 func (gm *Manager) writeNodeCount(kind string, count uint64, flush bool) error {
-	numstr := make([]byte, 8)
-
-	binary.LittleEndian.PutUint64(numstr, count)
-	gm.gs.MainDB()[MainDBNodeCount+kind] = string(numstr)
+	gm.writeCount(MainDBNodeCount+kind, count)
 
 	if flush {
-		return gm.gs.FlushMain()
+		return gm.flushMain()
 	}
 
 	return nil
@@ -146,16 +145,128 @@ func (gm *Manager) writeNodeCount(kind string, count uint64, flush bool) error {
 writeEdgeCount writes a new edge count for a specific kind to the datastore.
 */
 func (gm *Manager) writeEdgeCount(kind string, count uint64, flush bool) error {
+	gm.writeCount(MainDBEdgeCount+kind, count)
+
+	if flush {
+		return gm.flushMain()
+	}
+
+	return nil
+}
+
+/*
+readCount reads a count from the main database.
+*/
+func (gm *Manager) readCount(key string) uint64 {
+	gm.mainMutex.Lock()
+	defer gm.mainMutex.Unlock()
+
+	if val, ok := gm.gs.MainDB()[key]; ok {
+		return binary.LittleEndian.Uint64([]byte(val))
+	}
+
+	return 0
+}
+
+/*
+writeCount writes a count to the main database.
+*/
+func (gm *Manager) writeCount(key string, count uint64) {
+	gm.mainMutex.Lock()
+	defer gm.mainMutex.Unlock()
+
 	numstr := make([]byte, 8)
 
 	binary.LittleEndian.PutUint64(numstr, count)
-	gm.gs.MainDB()[MainDBEdgeCount+kind] = string(numstr)
+	gm.gs.MainDB()[key] = string(numstr)
+}
 
-	if flush {
-		return gm.gs.FlushMain()
+/*
+updateCount changes a count in the main database by a given delta. The change
+is atomic so concurrent writers of different partitions can update the same count.
+*/
+func (gm *Manager) updateCount(key string, delta int64) {
+	gm.mainMutex.Lock()
+	defer gm.mainMutex.Unlock()
+
+	var count uint64
+
+	if val, ok := gm.gs.MainDB()[key]; ok {
+		count = binary.LittleEndian.Uint64([]byte(val))
 	}
 
-	return nil
+	numstr := make([]byte, 8)
+
+	binary.LittleEndian.PutUint64(numstr, count+uint64(delta))
+	gm.gs.MainDB()[key] = string(numstr)
+}
+
+/*
+initCount makes sure a count exists in the main database.
+*/
+func (gm *Manager) initCount(key string) {
+	gm.mainMutex.Lock()
+	defer gm.mainMutex.Unlock()
+
+	if _, ok := gm.gs.MainDB()[key]; !ok {
+		gm.gs.MainDB()[key] = string(make([]byte, 8, 8))
+	}
+}
+
+/*
+flushMain flushes the main database.
+*/
+func (gm *Manager) flushMain() error {
+	gm.mainMutex.Lock()
+	defer gm.mainMutex.Unlock()
+
+	return gm.gs.FlushMain()
+}
+
+/*
+snapshotMain returns a copy of the main database.
+*/
+func (gm *Manager) snapshotMain() map[string]string {
+	gm.mainMutex.Lock()
+	defer gm.mainMutex.Unlock()
+
+	mdb := gm.gs.MainDB()
+	snapshot := make(map[string]string, len(mdb))
+
+	for k, v := range mdb {
+		snapshot[k] = v
+	}
+
+	return snapshot
+}
+
+/*
+restoreMain restores the main database from a copy which was returned by
+snapshotMain. Cached maps of changed entries are dropped. Change log cursors
+are not part of the graph and are never restored.
+*/
+func (gm *Manager) restoreMain(snapshot map[string]string) {
+	gm.mainMutex.Lock()
+	defer gm.mainMutex.Unlock()
+
+	mdb := gm.gs.MainDB()
+
+	for k, v := range mdb {
+		if k == MainDBChangeCursors {
+			continue
+		}
+
+		if sv, ok := snapshot[k]; !ok || sv != v {
+			delete(mdb, k)
+			delete(gm.mapCache, k)
+		}
+	}
+
+	for k, v := range snapshot {
+		if _, ok := mdb[k]; !ok && k != MainDBChangeCursors {
+			mdb[k] = v
+		}
+	}
 }
 
 /*
@@ -201,9 +312,7 @@ func (gm *Manager) getNodeStorageHTree(part string, kind string,
 		gm.storeMainDBMap(MainDBNodeEdges+kind, make(map[string]string))
 	}
 
-	if _, ok := gm.gs.MainDB()[MainDBNodeCount+kind]; !ok {
-		gm.gs.MainDB()[MainDBNodeCount+kind] = string(make([]byte, 8, 8))
-	}
+	gm.initCount(MainDBNodeCount + kind)
 
 	// Return the actual storage
 
@@ -259,9 +368,7 @@ func (gm *Manager) getEdgeStorageHTree(part string, kind string, create bool) (*
 		gm.storeMainDBMap(MainDBEdgeAttrs+kind, make(map[string]string))
 	}
 
-	if _, ok := gm.gs.MainDB()[MainDBEdgeCount+kind]; !ok {
-		gm.gs.MainDB()[MainDBEdgeCount+kind] = string(make([]byte, 8, 8))
-	}
+	gm.initCount(MainDBEdgeCount + kind)
 
 	// Return the actual storage
 
@@ -287,6 +394,35 @@ func (gm *Manager) getEdgeIndexHTree(part string, kind string, create bool) (*ha
 	return gm.getIndexHTree(part, kind, create, "Edge", StorageSuffixEdgesIndex)
 }
 
+/*
+getNodeHistoryHTree gets a HTree which can be used to store the history of nodes.
+*/
+func (gm *Manager) getNodeHistoryHTree(part string, kind string, create bool) (*hash.HTree, error) {
+	return gm.getIndexHTree(part, kind, create, "Node", StorageSuffixNodesHistory)
+}
+
+/*
+getEdgeHistoryHTree gets a HTree which can be used to store the history of edges.
+*/
+func (gm *Manager) getEdgeHistoryHTree(part string, kind string, create bool) (*hash.HTree, error) {
+	return gm.getIndexHTree(part, kind, create, "Edge", StorageSuffixEdgesHistory)
+}
+
+/*
+getNodeVectorHTree gets a HTree which can be used to index node vectors.
+*/
+func (gm *Manager) getNodeVectorHTree(part string, kind string, create bool) (*hash.HTree, error) {
+	return gm.getIndexHTree(part, kind, create, "Node", StorageSuffixNodesVector)
+}
+
+/*
+getNodeUniqueHTree gets a HTree which can be used to check unique constraints
+of nodes.
+*/
+func (gm *Manager) getNodeUniqueHTree(part string, kind string, create bool) (*hash.HTree, error) {
+	return gm.getIndexHTree(part, kind, create, "Node", StorageSuffixNodesUnique)
+}
+
 /*
 getIndexHTree gets a HTree which can be used to index items.
 */
@@ -310,7 +446,38 @@ func (gm *Manager) getIndexHTree(part string, kind string, create bool, name str
 		}
 	}
 
-	gs := gm.gs.StorageManager(part+kind+suffix, create)
+	gs := gm.gs.StorageManager(gm.indexStorageName(part, kind, suffix), create)
+	if gs == nil {
+		return nil, nil
+	}
+
+	return gm.getHTree(gs, RootIDNodeHTree)
+}
+
+/*
+indexStorageName returns the name of the storage which currently holds an
+index of a kind. A rebuilt index replaces the existing index by switching to
+a second storage (see RebuildIndex).
+*/
+func (gm *Manager) indexStorageName(part string, kind string, suffix string) string {
+	name := part + kind + suffix
+
+	if active, ok := gm.getMainDBMap(MainDBIndexStorages)[name]; ok {
+		return active
+	}
+
+	return name
+}
+
+/*
+getChangeLogHTree gets the HTree which stores the change log.
+*/
+func (gm *Manager) getChangeLogHTree(create bool) (*hash.HTree, error) {
+
+	gm.storageMutex.Lock()
+	defer gm.storageMutex.Unlock()
+
+	gs := gm.gs.StorageManager(StorageChangeLog, create)
 	if gs == nil {
 		return nil, nil
 	}
@@ -318,11 +485,22 @@ func (gm *Manager) getIndexHTree(part string, kind string, create bool, name str
 	return gm.getHTree(gs, RootIDNodeHTree)
 }
 
+/*
+storageManager returns an existing storage manager. The storage manager table
+of the graph storage is shared by all partitions.
+*/
+func (gm *Manager) storageManager(name string) storage.Manager {
+	gm.storageMutex.Lock()
+	defer gm.storageMutex.Unlock()
+
+	return gm.gs.StorageManager(name, false)
+}
+
 /*
 flushNodeStorage flushes a node storage.
 */
 func (gm *Manager) flushNodeStorage(part string, kind string) error {
-	if sm := gm.gs.StorageManager(part+kind+StorageSuffixNodes, false); sm != nil {
+	if sm := gm.storageManager(part + kind + StorageSuffixNodes); sm != nil {
 		if err := sm.Flush(); err != nil {
 			return &util.GraphError{Type: util.ErrFlushing, Detail: err.Error()}
 		}
@@ -334,7 +512,7 @@ func (gm *Manager) flushNodeStorage(part string, kind string) error {
 flushNodeIndex flushes a node index.
 */
 func (gm *Manager) flushNodeIndex(part string, kind string) error {
-	if sm := gm.gs.StorageManager(part+kind+StorageSuffixNodesIndex, false); sm != nil {
+	if sm := gm.storageManager(gm.indexStorageName(part, kind, StorageSuffixNodesIndex)); sm != nil {
 		if err := sm.Flush(); err != nil {
 			return &util.GraphError{Type: util.ErrFlushing, Detail: err.Error()}
 		}
@@ -346,7 +524,7 @@ func (gm *Manager) flushNodeIndex(part string, kind string) error {
 flushEdgeStorage flushes an edge storage.
 */
 func (gm *Manager) flushEdgeStorage(part string, kind string) error {
-	if sm := gm.gs.StorageManager(part+kind+StorageSuffixEdges, false); sm != nil {
+	if sm := gm.storageManager(part + kind + StorageSuffixEdges); sm != nil {
 		if err := sm.Flush(); err != nil {
 			return &util.GraphError{Type: util.ErrFlushing, Detail: err.Error()}
 		}
@@ -358,7 +536,67 @@ func (gm *Manager) flushEdgeStorage(part string, kind string) error {
 flushEdgeIndex flushes an edge index.
 */
 func (gm *Manager) flushEdgeIndex(part string, kind string) error {
-	if sm := gm.gs.StorageManager(part+kind+StorageSuffixEdgesIndex, false); sm != nil {
+	if sm := gm.storageManager(gm.indexStorageName(part, kind, StorageSuffixEdgesIndex)); sm != nil {
+		if err := sm.Flush(); err != nil {
+			return &util.GraphError{Type: util.ErrFlushing, Detail: err.Error()}
+		}
+	}
+	return nil
+}
+
+/*
+flushNodeHistory flushes a node history.
+*/
+func (gm *Manager) flushNodeHistory(part string, kind string) error {
+	if sm := gm.storageManager(part + kind + StorageSuffixNodesHistory); sm != nil {
+		if err := sm.Flush(); err != nil {
+			return &util.GraphError{Type: util.ErrFlushing, Detail: err.Error()}
+		}
+	}
+	return nil
+}
+
+/*
+flushNodeVector flushes a node vector index.
+*/
+func (gm *Manager) flushNodeVector(part string, kind string) error {
+	if sm := gm.storageManager(part + kind + StorageSuffixNodesVector); sm != nil {
+		if err := sm.Flush(); err != nil {
+			return &util.GraphError{Type: util.ErrFlushing, Detail: err.Error()}
+		}
+	}
+	return nil
+}
+
+/*
+flushNodeUnique flushes a node unique constraint storage.
+*/
+func (gm *Manager) flushNodeUnique(part string, kind string) error {
+	if sm := gm.storageManager(part + kind + StorageSuffixNodesUnique); sm != nil {
+		if err := sm.Flush(); err != nil {
+			return &util.GraphError{Type: util.ErrFlushing, Detail: err.Error()}
+		}
+	}
+	return nil
+}
+
+/*
+flushEdgeHistory flushes an edge history.
+*/
+func (gm *Manager) flushEdgeHistory(part string, kind string) error {
+	if sm := gm.storageManager(part + kind + StorageSuffixEdgesHistory); sm != nil {
+		if err := sm.Flush(); err != nil {
+			return &util.GraphError{Type: util.ErrFlushing, Detail: err.Error()}
+		}
+	}
+	return nil
+}
+
+/*
+flushChangeLog flushes the change log.
+*/
+func (gm *Manager) flushChangeLog() error {
+	if sm := gm.storageManager(StorageChangeLog); sm != nil {
 		if err := sm.Flush(); err != nil {
 			return &util.GraphError{Type: util.ErrFlushing, Detail: err.Error()}
 		}
@@ -370,7 +608,7 @@ func (gm *Manager) flushEdgeIndex(part string, kind string) error {
 rollbackNodeStorage rollbacks a node storage.
 */
 func (gm *Manager) rollbackNodeStorage(part string, kind string) error {
-	if sm := gm.gs.StorageManager(part+kind+StorageSuffixNodes, false); sm != nil {
+	if sm := gm.storageManager(part + kind + StorageSuffixNodes); sm != nil {
 		if err := sm.Rollback(); err != nil {
 			return &util.GraphError{Type: util.ErrRollback, Detail: err.Error()}
 		}
@@ -382,7 +620,7 @@ func (gm *Manager) rollbackNodeStorage(part string, kind string) error {
 rollbackNodeIndex rollbacks a node index.
 */
 func (gm *Manager) rollbackNodeIndex(part string, kind string) error {
-	if sm := gm.gs.StorageManager(part+kind+StorageSuffixNodesIndex, false); sm != nil {
+	if sm := gm.storageManager(gm.indexStorageName(part, kind, StorageSuffixNodesIndex)); sm != nil {
 		if err := sm.Rollback(); err != nil {
 			return &util.GraphError{Type: util.ErrRollback, Detail: err.Error()}
 		}
@@ -394,7 +632,7 @@ func (gm *Manager) rollbackNodeIndex(part string, kind string) error {
 rollbackEdgeStorage rollbacks an edge storage.
 */
 func (gm *Manager) rollbackEdgeStorage(part string, kind string) error {
-	if sm := gm.gs.StorageManager(part+kind+StorageSuffixEdges, false); sm != nil {
+	if sm := gm.storageManager(part + kind + StorageSuffixEdges); sm != nil {
 		if err := sm.Rollback(); err != nil {
 			return &util.GraphError{Type: util.ErrRollback, Detail: err.Error()}
 		}
@@ -406,7 +644,67 @@ func (gm *Manager) rollbackEdgeStorage(part string, kind string) error {
 rollbackEdgeIndex rollbacks an edge index.
 */
 func (gm *Manager) rollbackEdgeIndex(part string, kind string) error {
-	if sm := gm.gs.StorageManager(part+kind+StorageSuffixEdgesIndex, false); sm != nil {
+	if sm := gm.storageManager(gm.indexStorageName(part, kind, StorageSuffixEdgesIndex)); sm != nil {
+		if err := sm.Rollback(); err != nil {
+			return &util.GraphError{Type: util.ErrRollback, Detail: err.Error()}
+		}
+	}
+	return nil
+}
+
+/*
+rollbackNodeHistory rollbacks a node history.
+*/
+func (gm *Manager) rollbackNodeHistory(part string, kind string) error {
+	if sm := gm.storageManager(part + kind + StorageSuffixNodesHistory); sm != nil {
+		if err := sm.Rollback(); err != nil {
+			return &util.GraphError{Type: util.ErrRollback, Detail: err.Error()}
+		}
+	}
+	return nil
+}
+
+/*
+rollbackNodeVector rollbacks a node vector index.
+*/
+func (gm *Manager) rollbackNodeVector(part string, kind string) error {
+	if sm := gm.storageManager(part + kind + StorageSuffixNodesVector); sm != nil {
+		if err := sm.Rollback(); err != nil {
+			return &util.GraphError{Type: util.ErrRollback, Detail: err.Error()}
+		}
+	}
+	return nil
+}
+
+/*
+rollbackNodeUnique rollbacks a node unique constraint storage.
+*/
+func (gm *Manager) rollbackNodeUnique(part string, kind string) error {
+	if sm := gm.storageManager(part + kind + StorageSuffixNodesUnique); sm != nil {
+		if err := sm.Rollback(); err != nil {
+			return &util.GraphError{Type: util.ErrRollback, Detail: err.Error()}
+		}
+	}
+	return nil
+}
+
+/*
+rollbackEdgeHistory rollbacks an edge history.
+*/
+func (gm *Manager) rollbackEdgeHistory(part string, kind string) error {
+	if sm := gm.storageManager(part + kind + StorageSuffixEdgesHistory); sm != nil {
+		if err := sm.Rollback(); err != nil {
+			return &util.GraphError{Type: util.ErrRollback, Detail: err.Error()}
+		}
+	}
+	return nil
+}
+
+/*
+rollbackChangeLog rollbacks the change log.
+*/
+func (gm *Manager) rollbackChangeLog() error {
+	if sm := gm.storageManager(StorageChangeLog); sm != nil {
 		if err := sm.Rollback(); err != nil {
 			return &util.GraphError{Type: util.ErrRollback, Detail: err.Error()}
 		}
@@ -450,9 +748,22 @@ func (gm *Manager) getHTree(sm storage.Manager, slot int) (*hash.HTree, error) {
 }
 
 /*
-getMainDBMap gets a map from the main database.
+getMainDBMap gets a map from the main database. The returned map may only be
+changed and stored with storeMainDBMap by a caller which holds the writer lock -
+all other changes must be done with updateMainDBMap.
 */
 func (gm *Manager) getMainDBMap(key string) map[string]string {
+	gm.mainMutex.Lock()
+	defer gm.mainMutex.Unlock()
+
+	return gm.lookupMainDBMap(key)
+}
+
+/*
+lookupMainDBMap looks up a map from the main database. It is assumed that the
+caller holds the main database mutex.
+*/
+func (gm *Manager) lookupMainDBMap(key string) map[string]string {
 
 	// First try to cache
 
@@ -477,13 +788,57 @@ storeMainDBMap stores a map in the main database. The map is stored as a gob byt
 Once it has been decoded it is cached for read operations.
 */
 func (gm *Manager) storeMainDBMap(key string, mapval map[string]string) {
+	gm.mainMutex.Lock()
+	defer gm.mainMutex.Unlock()
+
 	gm.mapCache[key] = mapval
 	gm.gs.MainDB()[key] = mapToString(mapval)
 }
 
+/*
+updateMainDBMap atomically updates a map in the main database. The update function
+gets a copy of the stored map and should return true if the copy was changed.
+Maps which were returned by getMainDBMap before are not changed. Returns false
+if the map does not exist.
+*/
+func (gm *Manager) updateMainDBMap(key string, update func(map[string]string) bool) bool {
+	gm.mainMutex.Lock()
+	defer gm.mainMutex.Unlock()
+
+	mapval := gm.lookupMainDBMap(key)
+	if mapval == nil {
+		return false
+	}
+
+	newval := make(map[string]string, len(mapval))
+	for k, v := range mapval {
+		newval[k] = v
+	}
+
+	if update(newval) {
+		gm.mapCache[key] = newval
+		gm.gs.MainDB()[key] = mapToString(newval)
+	}
+
+	return true
+}
+
 // Static helper functions
 // =======================
 
+/*
+firstError returns the first error of a given list of errors.
+*/
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 /*
 IsFullSpec is a function to determine if a given spec is a fully specified spec
 (i.e. all spec components are specified)
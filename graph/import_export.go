@@ -97,6 +97,10 @@ func ExportPartition(out io.Writer, part string, gm *Manager) error {
 				return err
 			}
 
+			// Revisions are not exported - they belong to the stored node
+
+			node.SetAttr(data.NodeRevision, nil)
+
 			// Fetch all connected relationships and store their key and kind
 
 			_, edges, err := gm.TraverseMulti(part, key, kinds[ik], ":::", false)
@@ -137,6 +141,8 @@ func ExportPartition(out io.Writer, part string, gm *Manager) error {
 			return err
 		}
 
+		edge.SetAttr(data.NodeRevision, nil)
+
 		// Write out JSON object
 
 		fmt.Fprint(out, "    {\n")
@@ -117,7 +117,7 @@ func TestImportExportError(t *testing.T) {
 
 	delete(msm.AccessMap, 6)
 
-	msm.AccessMap[5] = storage.AccessCacheAndFetchSeriousError
+	msm.AccessMap[7] = storage.AccessCacheAndFetchSeriousError
 
 	res.Reset()
 	err = ExportPartition(&res, "main", gm)
@@ -126,7 +126,7 @@ func TestImportExportError(t *testing.T) {
 		return
 	}
 
-	delete(msm.AccessMap, 5)
+	delete(msm.AccessMap, 7)
 
 	gm.StoreEdge("main", data.NewGraphEdgeFromNode(data.NewGraphNodeFromMap(map[string]interface{}{
 		"end1cascading": false,
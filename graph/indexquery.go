@@ -7,6 +7,13 @@
 
 package graph
 
+import (
+	"fmt"
+	"sync"
+
+	"github.com/Fisch-Labs/FishDB/graph/util"
+)
+
 /*
 IndexQuery models the interface to the full text search index.
 */
@@ -30,4 +37,201 @@ type IndexQuery interface {
 		This call returns a list of node keys.
 	*/
 	LookupValue(attr, value string) ([]string, error)
+
+	/*
+		LookupRange finds all nodes where an attribute has a number or date
+		value between lo and hi (inclusive). An empty bound is unbounded. Only
+		attributes which are indexed in range mode can be looked up. This call
+		returns a list of node keys ordered by value.
+	*/
+	LookupRange(attr, lo, hi string) ([]string, error)
+
+	/*
+		Scores calculates the BM25 relevance scores of all nodes where an
+		attribute contains at least one word of a given query. This call
+		returns a map which maps node key to score.
+	*/
+	Scores(attr, query string) (map[string]float64, error)
+
+	/*
+		Score calculates the BM25 relevance score of a single node for a given
+		query. The score is 0 if the attribute of the node contains no word of
+		the query.
+	*/
+	Score(attr, query, key string) (float64, error)
+}
+
+/*
+VectorQuery models the interface to the vector index of a node attribute.
+*/
+type VectorQuery interface {
+
+	/*
+		Nearest finds the k nodes with the closest vectors to a given vector.
+		This call returns a list of node keys and a list of distances. Both
+		lists are sorted by distance (nearest first).
+	*/
+	Nearest(vector []float32, k int) ([]string, []float32, error)
+}
+
+/*
+lockedIndexQuery is an IndexQuery which waits with every lookup while the index
+is replaced by a rebuilt index. Lookups always use the current index storage.
+*/
+type lockedIndexQuery struct {
+	gm      *Manager           // Graph manager which owns the index
+	part    string             // Partition of the index
+	kind    string             // Node or edge kind of the index
+	suffix  string             // Storage suffix of the index
+	storage string             // Storage of the current index manager
+	im      *util.IndexManager // Current index manager
+	lock    *sync.RWMutex      // Reader / writer lock of the index
+	mutex   *sync.Mutex        // Mutex to protect the current index manager
+}
+
+/*
+newLockedIndexQuery creates a new index query for the node or edge index of
+a kind.
+*/
+func newLockedIndexQuery(gm *Manager, part string, kind string, suffix string) *lockedIndexQuery {
+	return &lockedIndexQuery{gm, part, kind, suffix, "", nil,
+		gm.indexLocks.get(part + kind + suffix), &sync.Mutex{}}
+}
+
+/*
+index returns an index manager for the current index storage. It is assumed
+that the caller holds the reader lock of the index.
+*/
+func (liq *lockedIndexQuery) index() (*util.IndexManager, error) {
+	liq.mutex.Lock()
+	defer liq.mutex.Unlock()
+
+	storage := liq.gm.indexStorageName(liq.part, liq.kind, liq.suffix)
+
+	if storage != liq.storage {
+
+		if liq.suffix == StorageSuffixEdgesIndex {
+
+			iht, err := liq.gm.getEdgeIndexHTree(liq.part, liq.kind, true)
+			if err != nil {
+				return nil, err
+			}
+
+			liq.im = liq.gm.edgeIndexManager(iht, liq.kind)
+
+		} else {
+
+			iht, err := liq.gm.getNodeIndexHTree(liq.part, liq.kind, true)
+			if err != nil {
+				return nil, err
+			}
+
+			liq.im = liq.gm.nodeIndexManager(iht, liq.kind)
+		}
+
+		liq.storage = storage
+	}
+
+	return liq.im, nil
+}
+
+/*
+LookupPhrase finds all nodes where an attribute contains a certain phrase.
+*/
+func (liq *lockedIndexQuery) LookupPhrase(attr, phrase string) ([]string, error) {
+	liq.lock.RLock()
+	defer liq.lock.RUnlock()
+
+	im, err := liq.index()
+	if err != nil {
+		return nil, err
+	}
+
+	return im.LookupPhrase(attr, phrase)
+}
+
+/*
+LookupWord finds all nodes where an attribute contains a certain word.
+*/
+func (liq *lockedIndexQuery) LookupWord(attr, word string) (map[string][]uint64, error) {
+	liq.lock.RLock()
+	defer liq.lock.RUnlock()
+
+	im, err := liq.index()
+	if err != nil {
+		return nil, err
+	}
+
+	return im.LookupWord(attr, word)
+}
+
+/*
+LookupValue finds all nodes where an attribute has a certain value.
+*/
+func (liq *lockedIndexQuery) LookupValue(attr, value string) ([]string, error) {
+	liq.lock.RLock()
+	defer liq.lock.RUnlock()
+
+	im, err := liq.index()
+	if err != nil {
+		return nil, err
+	}
+
+	return im.LookupValue(attr, value)
+}
+
+/*
+LookupRange finds all nodes where an attribute has a number or date value
+between lo and hi (inclusive).
+*/
+func (liq *lockedIndexQuery) LookupRange(attr, lo, hi string) ([]string, error) {
+	liq.lock.RLock()
+	defer liq.lock.RUnlock()
+
+	// Values which were indexed before the attribute was indexed in range
+	// mode are missing until the index was rebuilt
+
+	if liq.gm.IndexStale(liq.part, liq.kind, attr) {
+		return nil, &util.GraphError{Type: util.ErrIndexError, Detail: fmt.Sprintf(
+			"Index of attribute %v is stale until the index of kind %v in partition %v is rebuilt",
+			attr, liq.kind, liq.part)}
+	}
+
+	im, err := liq.index()
+	if err != nil {
+		return nil, err
+	}
+
+	return im.LookupRange(attr, lo, hi)
+}
+
+/*
+Scores calculates the BM25 relevance scores of all nodes where an attribute
+contains at least one word of a given query.
+*/
+func (liq *lockedIndexQuery) Scores(attr, query string) (map[string]float64, error) {
+	liq.lock.RLock()
+	defer liq.lock.RUnlock()
+
+	im, err := liq.index()
+	if err != nil {
+		return nil, err
+	}
+
+	return im.Scores(attr, query)
+}
+
+/*
+Score calculates the BM25 relevance score of a single node for a given query.
+*/
+func (liq *lockedIndexQuery) Score(attr, query, key string) (float64, error) {
+	liq.lock.RLock()
+	defer liq.lock.RUnlock()
+
+	im, err := liq.index()
+	if err != nil {
+		return 0, err
+	}
+
+	return im.Score(attr, query, key)
 }
@@ -17,6 +17,7 @@ NodeKeyIterator can be used to iterate node keys of a certain node kind.
 */
 type NodeKeyIterator struct {
 	gm        *Manager            // GraphManager which created the iterator
+	part      string              // Partition of the iterated nodes
 	it        *hash.HTreeIterator // Internal HTree iterator
 	LastError error               // Last encountered error
 }
@@ -28,8 +29,8 @@ func (it *NodeKeyIterator) Next() string {
 
 	// Take reader lock
 
-	it.gm.mutex.RLock()
-	defer it.gm.mutex.RUnlock()
+	it.gm.rlockPartition(it.part)
+	defer it.gm.runlockPartition(it.part)
 
 	k, _ := it.it.Next()
 
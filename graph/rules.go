@@ -8,6 +8,7 @@
 package graph
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 	"sync"
@@ -20,9 +21,10 @@ import (
 GraphRulesManager data structure
 */
 type graphRulesManager struct {
-	gm       *Manager                // GraphManager which provides events
-	rules    map[string]Rule         // Map of graph rules
-	eventMap map[int]map[string]Rule // Map of events to graph rules
+	gm             *Manager                // GraphManager which provides events
+	rules          map[string]Rule         // Map of graph rules
+	eventMap       map[int]map[string]Rule // Map of events to graph rules
+	protectedKinds map[string]bool         // Protected kinds in the system partition
 }
 
 /*
@@ -97,10 +99,15 @@ func (gr *graphRulesManager) graphEvent(trans Trans, event int, data ...interfac
 }
 
 /*
-Clone a given graph manager and insert a new RWMutex.
+Clone a given graph manager and insert a new RWMutex and new partition locks.
+The storage and main database mutexes, the running write operations, the index
+locks, the index rebuilds and the change log are shared with the original graph
+manager.
 */
 func (gr *graphRulesManager) cloneGraphManager() *Manager {
-	return &Manager{gr.gm.gs, gr, gr.gm.nm, gr.gm.mapCache, &sync.RWMutex{}, &sync.Mutex{}}
+	return &Manager{gr.gm.gs, gr, gr.gm.nm, gr.gm.mapCache, &sync.RWMutex{}, gr.gm.storageMutex,
+		gr.gm.mainMutex, newPartitionLocks(), gr.gm.writeOps, gr.gm.indexLocks, gr.gm.rebuilds,
+		gr.gm.rebuildMutex, gr.gm.changeLog}
 }
 
 /*
@@ -276,14 +283,14 @@ func (r *SystemRuleUpdateNodeStats) Handle(gm *Manager, trans Trans, event int,
 
 		updateNodeRels := func(key string, kind string) {
 			spec := edge.Spec(key)
-			specs := gm.getMainDBMap(MainDBNodeEdges + kind)
 
-			if specs != nil {
+			gm.updateMainDBMap(MainDBNodeEdges+kind, func(specs map[string]string) bool {
 				if _, ok := specs[spec]; !ok {
 					specs[spec] = ""
-					gm.storeMainDBMap(MainDBNodeEdges+kind, specs)
+					return true
 				}
-			}
+				return false
+			})
 		}
 
 		// Update stored relationships for both ends
@@ -303,11 +310,13 @@ func (r *SystemRuleUpdateNodeStats) Handle(gm *Manager, trans Trans, event int,
 		part := ed[0].(string)
 
 		updateMainDB := func(entry string, val string) {
-			vals := gm.getMainDBMap(entry)
-			if _, ok := vals[val]; !ok {
-				vals[val] = ""
-				gm.storeMainDBMap(entry, vals)
-			}
+			gm.updateMainDBMap(entry, func(vals map[string]string) bool {
+				if _, ok := vals[val]; !ok {
+					vals[val] = ""
+					return true
+				}
+				return false
+			})
 		}
 
 		updateMainDB(MainDBParts, part)
@@ -319,27 +328,168 @@ func (r *SystemRuleUpdateNodeStats) Handle(gm *Manager, trans Trans, event int,
 		}
 	}
 
-	storeAttrs := false
+	// Update stored node attributes - the map is only changed if a new
+	// attribute was used
 
-	attrs := gm.getMainDBMap(attrMap + kind)
-
-	if attrs != nil {
-
-		// Update stored node attributes
+	if attrs := gm.getMainDBMap(attrMap + kind); attrs != nil {
 
 		for attr := range node.Data() {
 			if _, ok := attrs[attr]; !ok {
-				attrs[attr] = ""
-				storeAttrs = true
+
+				gm.updateMainDBMap(attrMap+kind, func(attrs map[string]string) bool {
+					for attr := range node.Data() {
+						attrs[attr] = ""
+					}
+					return true
+				})
+
+				break
 			}
 		}
+	}
 
-		// Store attribute map if something was changed
+	return nil
+}
+
+// System rule SystemRuleValidateSchema
+// ====================================
+
+/*
+SystemRuleValidateSchema is a system rule to check nodes and edges against the
+schema of their kind. Nodes and edges which are stored directly are checked
+before they are written. Nodes and edges which are stored in a transaction are
+checked after they were written - a violation causes a rollback of the
+transaction.
+*/
+type SystemRuleValidateSchema struct {
+}
+
+/*
+Name returns the name of the rule.
+*/
+func (r *SystemRuleValidateSchema) Name() string {
+	return "system.validateschema"
+}
 
-		if storeAttrs {
-			gm.storeMainDBMap(attrMap+kind, attrs)
+/*
+Handles returns a list of events which are handled by this rule.
+*/
+func (r *SystemRuleValidateSchema) Handles() []int {
+	return []int{EventNodeStore, EventNodeUpdate, EventEdgeStore,
+		EventNodeCreated, EventNodeUpdated, EventEdgeCreated, EventEdgeUpdated}
+}
+
+/*
+Handle handles an event.
+*/
+func (r *SystemRuleValidateSchema) Handle(gm *Manager, trans Trans, event int, ed ...interface{}) error {
+	part := ed[0].(string)
+	node := ed[1].(data.Node)
+
+	if gm.kindSchema(node.Kind()) == nil {
+		return nil
+	}
+
+	switch event {
+
+	case EventNodeStore:
+		return gm.validateNode(node)
+
+	case EventEdgeStore:
+		return gm.validateEdge(part, ed[1].(data.Edge))
+
+	case EventNodeUpdate:
+
+		// Check the node as it will be after the update
+
+		stored, err := gm.FetchNode(part, node.Key(), node.Kind())
+		if err != nil {
+			return err
+		} else if stored != nil {
+			node = data.NodeMerge(stored, node)
 		}
+
+		return gm.validateNode(node)
+
+	case EventNodeCreated, EventNodeUpdated:
+
+		// Check the node as it was written
+
+		stored, err := gm.FetchNode(part, node.Key(), node.Kind())
+		if err != nil || stored == nil {
+			return err
+		}
+
+		return gm.validateNode(stored)
 	}
 
-	return nil
+	// Check the edge as it was written
+
+	stored, err := gm.FetchEdge(part, node.Key(), node.Kind())
+	if err != nil || stored == nil {
+		return err
+	}
+
+	return gm.validateEdge(part, stored)
+}
+
+// System rule SystemRuleProtectSystemKinds
+// ========================================
+
+/*
+SystemRuleProtectSystemKinds is a system rule to protect nodes and edges of
+protected kinds in the system partition. They can only be written in system
+transactions. Nodes and edges which are written directly are rejected before
+they are written - a transaction which writes them is rolled back.
+*/
+type SystemRuleProtectSystemKinds struct {
+}
+
+/*
+Name returns the name of the rule.
+*/
+func (r *SystemRuleProtectSystemKinds) Name() string {
+	return "system.protectsystemkinds"
+}
+
+/*
+Handles returns a list of events which are handled by this rule.
+*/
+func (r *SystemRuleProtectSystemKinds) Handles() []int {
+	return []int{EventNodeStore, EventNodeUpdate, EventNodeDelete, EventEdgeStore, EventEdgeDelete,
+		EventNodeCreated, EventNodeUpdated, EventNodeDeleted, EventEdgeCreated, EventEdgeUpdated,
+		EventEdgeDeleted}
+}
+
+/*
+Handle handles an event.
+*/
+func (r *SystemRuleProtectSystemKinds) Handle(gm *Manager, trans Trans, event int, ed ...interface{}) error {
+	var kind string
+
+	if ed[0].(string) != SystemPartition {
+		return nil
+	}
+
+	if event == EventNodeDelete || event == EventEdgeDelete {
+		kind = ed[2].(string)
+	} else {
+		kind = ed[1].(data.Node).Kind()
+	}
+
+	if !gm.gr.protectedKinds[kind] {
+		return nil
+	}
+
+	// Only system transactions can write protected kinds - direct writes
+	// always use an internal transaction
+
+	if gt, ok := trans.(*baseTrans); ok && gt.system {
+		return nil
+	}
+
+	return &util.GraphError{
+		Type:   util.ErrProtected,
+		Detail: fmt.Sprintf("%v can only be written in a system transaction", kind),
+	}
 }
@@ -445,7 +445,7 @@ func TestRulesErrors(t *testing.T) {
 	// Check that the test rule was added
 
 	if rules := fmt.Sprint(gm.GraphRules()); rules !=
-		"[system.deletenodeedges system.updatenodestats testrule]" {
+		"[system.deletenodeedges system.protectsystemkinds system.updatenodestats system.validateschema testrule]" {
 		t.Error("unexpected graph rule list:", rules)
 		return
 	}
@@ -733,7 +733,7 @@ func TestRulesErrorsPreChange(t *testing.T) {
 	// Check that the test rule was added
 
 	if rules := fmt.Sprint(gm.GraphRules()); rules !=
-		"[system.deletenodeedges system.updatenodestats testrule]" {
+		"[system.deletenodeedges system.protectsystemkinds system.updatenodestats system.validateschema testrule]" {
 		t.Error("unexpected graph rule list:", rules)
 		return
 	}
@@ -793,3 +793,87 @@ func TestRulesErrorsPreChange(t *testing.T) {
 		return
 	}
 }
+
+func TestSystemRuleProtectSystemKinds(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	gm.ProtectSystemKind("Secret")
+
+	node := data.NewGraphNode()
+	node.SetAttr("key", "123")
+	node.SetAttr("kind", "Secret")
+
+	// Other partitions are not protected
+
+	if err := gm.StoreNode("main", node); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Direct writes are rejected
+
+	expected := "GraphError: Graph rule error (GraphError: Protected system kind " +
+		"(Secret can only be written in a system transaction))"
+
+	if err := gm.StoreNode(SystemPartition, node); err == nil || err.Error() != expected {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if err := gm.UpdateNode(SystemPartition, node); err == nil || err.Error() != expected {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	// Normal transactions are rolled back
+
+	trans := NewGraphTrans(gm)
+	trans.StoreNode(SystemPartition, node)
+
+	if err := trans.Commit(); err == nil || err.Error() != expected {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	// System transactions can write protected kinds
+
+	trans = NewSystemGraphTrans(gm)
+	trans.StoreNode(SystemPartition, node)
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if n, err := gm.FetchNode(SystemPartition, "123", "Secret"); n == nil || err != nil {
+		t.Error("Unexpected result:", n, err)
+		return
+	}
+
+	if _, err := gm.RemoveNode(SystemPartition, "123", "Secret"); err == nil || err.Error() != expected {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	trans = NewGraphTrans(gm)
+	trans.RemoveNode(SystemPartition, "123", "Secret")
+
+	if err := trans.Commit(); err == nil || err.Error() != expected {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	trans = NewSystemGraphTrans(gm)
+	trans.RemoveNode(SystemPartition, "123", "Secret")
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if n, err := gm.FetchNode(SystemPartition, "123", "Secret"); n != nil || err != nil {
+		t.Error("Unexpected result:", n, err)
+		return
+	}
+}
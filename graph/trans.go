@@ -52,13 +52,17 @@ type Trans interface {
 
 	/*
 	   StoreNode stores a single node in a partition of the graph. This function will
-	   overwrites any existing node.
+	   overwrites any existing node. If the node has a data.NodeRevision attribute
+	   the commit fails with a conflict error if the stored node has a different
+	   revision.
 	*/
 	StoreNode(part string, node data.Node) error
 
 	/*
 	   UpdateNode updates a single node in a partition of the graph. This function will
-	   only update the given values of the node.
+	   only update the given values of the node. If the node has a data.NodeRevision
+	   attribute the commit fails with a conflict error if the stored node has a
+	   different revision.
 	*/
 	UpdateNode(part string, node data.Node) error
 
@@ -77,6 +81,62 @@ type Trans interface {
 	   RemoveEdge removes a single edge from a partition of the graph.
 	*/
 	RemoveEdge(part string, ekey string, ekind string) error
+
+	/*
+	   MergeNode merges a node into a partition of the graph. An existing node is
+	   matched by a set of attributes (or by its key if no attributes are given)
+	   and its attributes are merged according to the given merge policies. The
+	   node is created if no node matches. A match by attributes is checked again
+	   on commit. Returns the node which will be stored.
+	*/
+	MergeNode(part string, node data.Node, match []string, policies map[string]string) (data.Node, error)
+
+	/*
+	   MergeEdge merges an edge into a partition of the graph. An existing edge is
+	   matched by its kind and ends and its attributes are merged according to the
+	   given merge policies. The edge is created if no edge matches. Returns the
+	   edge which will be stored.
+	*/
+	MergeEdge(part string, edge data.Edge, policies map[string]string) (data.Edge, error)
+
+	/*
+	   Rollback discards all operations of this transaction which have not
+	   been committed yet.
+	*/
+	Rollback() error
+
+	/*
+	   Savepoint records the current state of this transaction under a given
+	   name. An existing savepoint with the same name is replaced.
+	*/
+	Savepoint(name string) error
+
+	/*
+	   RollbackTo discards all operations of this transaction which were added
+	   after a given savepoint. The savepoint itself is kept while all later
+	   savepoints are removed.
+	*/
+	RollbackTo(name string) error
+
+	/*
+	   FetchNode fetches a single node from a partition of the graph as it will
+	   be after this transaction was committed.
+	*/
+	FetchNode(part string, key string, kind string) (data.Node, error)
+
+	/*
+	   FetchEdge fetches a single edge from a partition of the graph as it will
+	   be after this transaction was committed.
+	*/
+	FetchEdge(part string, key string, kind string) (data.Edge, error)
+
+	/*
+	   Traverse traverses from a given node to other nodes following a given
+	   partial edge spec as the graph will be after this transaction was
+	   committed. The last parameter allData specifies if all data should be
+	   retrieved for the connected nodes and edges.
+	*/
+	Traverse(part string, key string, kind string, spec string, allData bool) ([]data.Node, []data.Edge, error)
 }
 
 /*
@@ -88,6 +148,17 @@ func NewGraphTrans(gm *Manager) Trans {
 	return newInternalGraphTrans(gm)
 }
 
+/*
+NewSystemGraphTrans creates a new graph transaction which can write nodes and
+edges of protected kinds in the system partition (see ProtectSystemKind).
+*/
+func NewSystemGraphTrans(gm *Manager) Trans {
+	gt := newInternalGraphTrans(gm)
+	gt.system = true
+
+	return gt
+}
+
 /*
 NewConcurrentGraphTrans creates a new thread-safe graph transaction.
 */
@@ -145,8 +216,8 @@ func newInternalGraphTrans(gm *Manager) *baseTrans {
 
 	idCounter++
 
-	return &baseTrans{fmt.Sprint(idCounter), gm, false, make(map[string]data.Node), make(map[string]data.Node),
-		make(map[string]data.Edge), make(map[string]data.Edge)}
+	return &baseTrans{fmt.Sprint(idCounter), gm, false, false, nil, nil, nil, make(map[string]data.Node),
+		make(map[string]data.Node), make(map[string]data.Edge), make(map[string]data.Edge), nil}
 }
 
 /*
@@ -159,14 +230,21 @@ var idCounterLock = &sync.Mutex{}
 baseTrans is the main data structure for a graph transaction
 */
 type baseTrans struct {
-	id       string   // Unique transaction ID - not used by FishDB
-	gm       *Manager // Graph manager which created this transaction
-	subtrans bool     // Flag if the transaction is a subtransaction
+	id       string         // Unique transaction ID (recorded in the graph history)
+	gm       *Manager       // Graph manager which created this transaction
+	subtrans bool           // Flag if the transaction is a subtransaction
+	system   bool           // Flag if the transaction can write protected system kinds
+	commit   *versionCommit // Graph version which is produced by the current commit
+
+	locks  *partitionLockSet // Partition locks which are held by the current commit
+	counts map[string]int64  // Node and edge count changes of the current commit
 
 	storeNodes  map[string]data.Node // Nodes which should be stored
 	removeNodes map[string]data.Node // Nodes which should be removed
 	storeEdges  map[string]data.Edge // Edges which should be stored
 	removeEdges map[string]data.Edge // Edges which should be removed
+
+	savepoints []*transSavepoint // Savepoints of this transaction
 }
 
 /*
@@ -210,25 +288,64 @@ Serious write errors which may corrupt the database will cause a panic.
 */
 func (gt *baseTrans) Commit() error {
 
-	// Take writer lock if we are not in a subtransaction
+	// Exclude operations which change the whole graph if we are not in a
+	// subtransaction
 
 	if !gt.subtrans {
-		gt.gm.mutex.Lock()
-		defer gt.gm.mutex.Unlock()
+		gt.gm.mutex.RLock()
+		defer gt.gm.mutex.RUnlock()
 	}
 
+	// Savepoints cannot be used once the transaction is committed
+
+	gt.savepoints = nil
+
 	// Return if there is nothing to do
 
 	if gt.IsEmpty() {
 		return nil
 	}
 
+	// Take the writer locks of all partitions which are written to - a
+	// subtransaction may inherit the locks of the operation which runs it
+
+	if gt.locks == nil {
+		gt.locks = newPartitionLockSet(gt.gm)
+
+		defer func() {
+			gt.locks.unlock()
+			gt.locks = nil
+		}()
+	}
+
+	gt.counts = make(map[string]int64)
+
+	// Keep a copy of the main database if no other write operation is
+	// running - all changes of this commit can then be undone on a rollback
+
+	var mainDB map[string]string
+
+	gt.locks.alone(func() {
+		mainDB = gt.gm.snapshotMain()
+	})
+
 	doRollback := func(nodePartsAndKinds map[string]string,
 		edgePartsAndKinds map[string]string) {
 
-		// Rollback main database
+		// Rollback the main database if no other write operation ran at the
+		// same time - otherwise only the node and edge counts and the graph
+		// version are reverted. Kind and partition registrations are kept in
+		// this case since concurrent write operations may rely on them (the
+		// same entries are kept if all nodes of a kind are removed).
 
-		gt.gm.gs.RollbackMain()
+		if mainDB == nil || !gt.locks.alone(func() { gt.gm.restoreMain(mainDB) }) {
+
+			for key, delta := range gt.counts {
+				gt.gm.updateCount(key, -delta)
+			}
+
+			gt.gm.revertVersion(gt.commit.version)
+		}
 
 		// Rollback node storages
 
@@ -237,6 +354,9 @@ func (gt *baseTrans) Commit() error {
 
 			gt.gm.rollbackNodeIndex(partAndKind[0], partAndKind[1])
 			gt.gm.rollbackNodeStorage(partAndKind[0], partAndKind[1])
+			gt.gm.rollbackNodeHistory(partAndKind[0], partAndKind[1])
+			gt.gm.rollbackNodeVector(partAndKind[0], partAndKind[1])
+			gt.gm.rollbackNodeUnique(partAndKind[0], partAndKind[1])
 		}
 
 		gt.storeNodes = make(map[string]data.Node)
@@ -250,6 +370,7 @@ func (gt *baseTrans) Commit() error {
 
 				gt.gm.rollbackEdgeIndex(partAndKind[0], partAndKind[1])
 				gt.gm.rollbackEdgeStorage(partAndKind[0], partAndKind[1])
+				gt.gm.rollbackEdgeHistory(partAndKind[0], partAndKind[1])
 			}
 		}
 
@@ -257,6 +378,10 @@ func (gt *baseTrans) Commit() error {
 		gt.removeEdges = make(map[string]data.Edge)
 	}
 
+	// Every commit produces a new graph version
+
+	gt.commit = gt.gm.newVersionCommit(gt.id)
+
 	// Write nodes and edges until everything has been written
 
 	nodePartsAndKinds := make(map[string]string)
@@ -264,6 +389,13 @@ func (gt *baseTrans) Commit() error {
 
 	for !gt.IsEmpty() {
 
+		// Lock partitions which were added by rules
+
+		if err := gt.locks.lock(gt.partitions()); err != nil {
+			doRollback(nodePartsAndKinds, edgePartsAndKinds)
+			return err
+		}
+
 		// Write the nodes first
 
 		if err := gt.commitNodes(nodePartsAndKinds, edgePartsAndKinds); err != nil {
@@ -271,6 +403,11 @@ func (gt *baseTrans) Commit() error {
 			return err
 		}
 
+		if err := gt.locks.lock(gt.partitions()); err != nil {
+			doRollback(nodePartsAndKinds, edgePartsAndKinds)
+			return err
+		}
+
 		// After the nodes write the edges
 
 		if err := gt.commitEdges(nodePartsAndKinds, edgePartsAndKinds); err != nil {
@@ -279,6 +416,15 @@ func (gt *baseTrans) Commit() error {
 		}
 	}
 
+	// Store the changes of this commit as a pending change set before
+	// anything is flushed so they can be recovered if the database stops
+	// before they were appended to the change log
+
+	if err := gt.gm.writePendingChangeSet(gt.commit); err != nil {
+		doRollback(nodePartsAndKinds, edgePartsAndKinds)
+		return err
+	}
+
 	// Flush changes - panic instead of error reporting since the database
 	// may be inconsistent
 
@@ -288,7 +434,7 @@ func (gt *baseTrans) Commit() error {
 		}
 	}
 
-	panicIfError(gt.gm.gs.FlushMain())
+	panicIfError(gt.gm.flushMain())
 
 	for kkey := range nodePartsAndKinds {
 
@@ -296,6 +442,9 @@ func (gt *baseTrans) Commit() error {
 
 		panicIfError(gt.gm.flushNodeIndex(partAndKind[0], partAndKind[1]))
 		panicIfError(gt.gm.flushNodeStorage(partAndKind[0], partAndKind[1]))
+		panicIfError(gt.gm.flushNodeHistory(partAndKind[0], partAndKind[1]))
+		panicIfError(gt.gm.flushNodeVector(partAndKind[0], partAndKind[1]))
+		panicIfError(gt.gm.flushNodeUnique(partAndKind[0], partAndKind[1]))
 	}
 
 	for kkey := range edgePartsAndKinds {
@@ -304,8 +453,14 @@ func (gt *baseTrans) Commit() error {
 
 		panicIfError(gt.gm.flushEdgeIndex(partAndKind[0], partAndKind[1]))
 		panicIfError(gt.gm.flushEdgeStorage(partAndKind[0], partAndKind[1]))
+		panicIfError(gt.gm.flushEdgeHistory(partAndKind[0], partAndKind[1]))
 	}
 
+	// Append the changes of this commit to the change log once they were
+	// flushed - the commit cannot be rolled back at this point
+
+	panicIfError(gt.gm.writeChangeSet(gt.commit))
+
 	return nil
 }
 
@@ -321,6 +476,14 @@ func (gt *baseTrans) commitNodes(nodePartsAndKinds map[string]string, edgePartsA
 		// Get partition and kind
 
 		partAndKind := strings.Split(tkey, "#")
+
+		if !gt.locks.holds(partAndKind[0]) {
+
+			// Partition was added by a rule - it is written once it is locked
+
+			continue
+		}
+
 		nodePartsAndKinds[partAndKind[0]+"#"+partAndKind[1]] = ""
 
 		part := partAndKind[0]
@@ -337,6 +500,25 @@ func (gt *baseTrans) commitNodes(nodePartsAndKinds map[string]string, edgePartsA
 			return err
 		}
 
+		// Check the match of merged nodes - another transaction may have stored
+		// a matching node after the node was merged
+
+		if mnode, ok := node.(*mergedNode); ok {
+			if err := gt.checkMergedNode(part, mnode, iht, attht, valht); err != nil {
+				return err
+			}
+		}
+
+		// Check unique constraints - nodes which are removed by this transaction
+		// do not count
+
+		if err := gt.gm.checkUniqueConstraints(part, node, false, func(key string) bool {
+			_, ok := gt.removeNodes[gt.createKey(part, key, node.Kind())]
+			return ok
+		}, attht, valht); err != nil {
+			return err
+		}
+
 		// Write the node to the datastore
 
 		oldnode, err := gt.gm.writeNode(node, false, attht, valht, nodeAttributeFilter)
@@ -345,15 +527,20 @@ func (gt *baseTrans) commitNodes(nodePartsAndKinds map[string]string, edgePartsA
 			return err
 		}
 
+		// Record the new state of the node in the node history
+
+		if err := gt.gm.writeNodeVersion(part, node.Key(), node.Kind(), gt.commit, attht, valht); err != nil {
+			return err
+		}
+
 		// Increase node count if the node was inserted and write the changes
 		// to the index.
 
 		if oldnode == nil {
-			currentCount := gt.gm.NodeCount(node.Kind())
-			gt.gm.writeNodeCount(node.Kind(), currentCount+1, false)
+			gt.updateCount(MainDBNodeCount+node.Kind(), 1)
 
 			if iht != nil {
-				err := util.NewIndexManager(iht).Index(node.Key(), node.IndexMap())
+				err := gt.gm.nodeIndexManager(iht, node.Kind()).Index(node.Key(), node.IndexMap())
 				if err != nil {
 
 					// The node was written at this point and the model is
@@ -365,7 +552,7 @@ func (gt *baseTrans) commitNodes(nodePartsAndKinds map[string]string, edgePartsA
 
 		} else if iht != nil {
 
-			err := util.NewIndexManager(iht).Reindex(node.Key(), node.IndexMap(),
+			err := gt.gm.nodeIndexManager(iht, node.Kind()).Reindex(node.Key(), node.IndexMap(),
 				oldnode.IndexMap())
 
 			if err != nil {
@@ -377,6 +564,16 @@ func (gt *baseTrans) commitNodes(nodePartsAndKinds map[string]string, edgePartsA
 			}
 		}
 
+		// Update the vector index and the unique constraint storage
+
+		if err := gt.gm.updateNodeVectors(part, node.Key(), node.Kind(), node, oldnode); err != nil {
+			return err
+		}
+
+		if err := gt.gm.updateNodeUniques(part, node.Key(), node.Kind(), oldnode, attht, valht); err != nil {
+			return err
+		}
+
 		// Execute rules
 
 		var event int
@@ -400,6 +597,14 @@ func (gt *baseTrans) commitNodes(nodePartsAndKinds map[string]string, edgePartsA
 		// Get partition and kind
 
 		partAndKind := strings.Split(tkey, "#")
+
+		if !gt.locks.holds(partAndKind[0]) {
+
+			// Partition was added by a rule - it is written once it is locked
+
+			continue
+		}
+
 		nodePartsAndKinds[partAndKind[0]+"#"+partAndKind[1]] = ""
 
 		part := partAndKind[0]
@@ -435,18 +640,31 @@ func (gt *baseTrans) commitNodes(nodePartsAndKinds map[string]string, edgePartsA
 
 		if oldnode != nil {
 
+			// Record the removal of the node in the node history
+
+			if err := gt.gm.writeNodeVersion(part, node.Key(), node.Kind(), gt.commit, attTree, valTree); err != nil {
+				return err
+			}
+
 			if iht != nil {
-				err := util.NewIndexManager(iht).Deindex(node.Key(), oldnode.IndexMap())
+				err := gt.gm.nodeIndexManager(iht, node.Kind()).Deindex(node.Key(), oldnode.IndexMap())
 
 				if err != nil {
 					return err
 				}
 			}
 
+			if err := gt.gm.updateNodeVectors(part, node.Key(), node.Kind(), nil, oldnode); err != nil {
+				return err
+			}
+
+			if err := gt.gm.updateNodeUniques(part, node.Key(), node.Kind(), oldnode, attTree, valTree); err != nil {
+				return err
+			}
+
 			// Decrease the node count
 
-			currentCount := gt.gm.NodeCount(node.Kind())
-			gt.gm.writeNodeCount(node.Kind(), currentCount-1, false)
+			gt.updateCount(MainDBNodeCount+node.Kind(), -1)
 
 			// Execute rules
 
@@ -473,6 +691,14 @@ func (gt *baseTrans) commitEdges(nodePartsAndKinds map[string]string, edgePartsA
 		// Get partition and kind
 
 		partAndKind := strings.Split(tkey, "#")
+
+		if !gt.locks.holds(partAndKind[0]) {
+
+			// Partition was added by a rule - it is written once it is locked
+
+			continue
+		}
+
 		edgePartsAndKinds[partAndKind[0]+"#"+partAndKind[1]] = ""
 
 		nodePartsAndKinds[partAndKind[0]+"#"+edge.End1Kind()] = ""
@@ -533,21 +759,32 @@ func (gt *baseTrans) commitEdges(nodePartsAndKinds map[string]string, edgePartsA
 			return err
 		}
 
+		// Record the new state of the edge in the edge history
+
+		if err := gt.gm.writeEdgeVersion(part, edge.Key(), edge.Kind(), gt.commit, edgeht); err != nil {
+			return err
+		}
+
 		// Increase edge count if the edge was inserted and write the changes
 		// to the index.
 
 		if oldedge == nil {
 
+			// Record the new edge on both ends in the node history
+
+			if err := gt.gm.writeHistoryEdge(part, edge); err != nil {
+				return err
+			}
+
 			// Increase edge count
 
-			currentCount := gt.gm.EdgeCount(edge.Kind())
-			gt.gm.writeEdgeCount(edge.Kind(), currentCount+1, false)
+			gt.updateCount(MainDBEdgeCount+edge.Kind(), 1)
 
 			// Write edge data to the index
 
 			if iht != nil {
 
-				if err := util.NewIndexManager(iht).Index(edge.Key(), edge.IndexMap()); err != nil {
+				if err := gt.gm.edgeIndexManager(iht, edge.Kind()).Index(edge.Key(), edge.IndexMap()); err != nil {
 
 					// The edge was written at this point and the model is
 					// consistent only the index is missing entries
@@ -558,7 +795,7 @@ func (gt *baseTrans) commitEdges(nodePartsAndKinds map[string]string, edgePartsA
 
 		} else if iht != nil {
 
-			err := util.NewIndexManager(iht).Reindex(edge.Key(), edge.IndexMap(),
+			err := gt.gm.edgeIndexManager(iht, edge.Kind()).Reindex(edge.Key(), edge.IndexMap(),
 				oldedge.IndexMap())
 
 			if err != nil {
@@ -593,6 +830,14 @@ func (gt *baseTrans) commitEdges(nodePartsAndKinds map[string]string, edgePartsA
 		// Get partition and kind
 
 		partAndKind := strings.Split(tkey, "#")
+
+		if !gt.locks.holds(partAndKind[0]) {
+
+			// Partition was added by a rule - it is written once it is locked
+
+			continue
+		}
+
 		edgePartsAndKinds[partAndKind[0]+"#"+partAndKind[1]] = ""
 
 		nodePartsAndKinds[partAndKind[0]+"#"+edge.End1Kind()] = ""
@@ -640,9 +885,15 @@ func (gt *baseTrans) commitEdges(nodePartsAndKinds map[string]string, edgePartsA
 				return err
 			}
 
+			// Record the removal of the edge in the edge history
+
+			if err := gt.gm.writeEdgeVersion(part, edge.Key(), edge.Kind(), gt.commit, edgeht); err != nil {
+				return err
+			}
+
 			if iht != nil {
 
-				err := util.NewIndexManager(iht).Deindex(edge.Key(), oldedge.IndexMap())
+				err := gt.gm.edgeIndexManager(iht, edge.Kind()).Deindex(edge.Key(), oldedge.IndexMap())
 				if err != nil {
 					return err
 				}
@@ -650,8 +901,7 @@ func (gt *baseTrans) commitEdges(nodePartsAndKinds map[string]string, edgePartsA
 
 			// Decrease edge count
 
-			currentCount := gt.gm.EdgeCount(oldedge.Kind())
-			gt.gm.writeEdgeCount(oldedge.Kind(), currentCount-1, false)
+			gt.updateCount(MainDBEdgeCount+oldedge.Kind(), -1)
 
 			// Execute rules
 
@@ -666,6 +916,47 @@ func (gt *baseTrans) commitEdges(nodePartsAndKinds map[string]string, edgePartsA
 	return nil
 }
 
+/*
+partitions returns all partitions which are written to by this transaction.
+*/
+func (gt *baseTrans) partitions() []string {
+	var parts []string
+
+	seen := make(map[string]bool)
+
+	addPart := func(tkey string) {
+		part := strings.SplitN(tkey, "#", 2)[0]
+		if !seen[part] {
+			seen[part] = true
+			parts = append(parts, part)
+		}
+	}
+
+	for tkey := range gt.storeNodes {
+		addPart(tkey)
+	}
+	for tkey := range gt.removeNodes {
+		addPart(tkey)
+	}
+	for tkey := range gt.storeEdges {
+		addPart(tkey)
+	}
+	for tkey := range gt.removeEdges {
+		addPart(tkey)
+	}
+
+	return parts
+}
+
+/*
+updateCount changes a node or edge count and records the change so it can be
+reverted if the commit fails.
+*/
+func (gt *baseTrans) updateCount(key string, delta int64) {
+	gt.gm.updateCount(key, delta)
+	gt.counts[key] += delta
+}
+
 /*
 StoreNode stores a single node in a partition of the graph. This function will
 overwrites any existing node.
@@ -713,6 +1004,10 @@ func (gt *baseTrans) UpdateNode(part string, node data.Node) error {
 		if err != nil {
 			return err
 		} else if storeNode != nil {
+
+			// Only the revision of the given node is checked
+
+			storeNode.SetAttr(data.NodeRevision, nil)
 			node = data.NodeMerge(storeNode, node)
 		}
 	}
@@ -911,6 +1206,95 @@ func (gt *concurrentTrans) RemoveEdge(part string, ekey string, ekind string) er
 	return gt.Trans.RemoveEdge(part, ekey, ekind)
 }
 
+/*
+MergeNode merges a node into a partition of the graph.
+*/
+func (gt *concurrentTrans) MergeNode(part string, node data.Node, match []string,
+	policies map[string]string) (data.Node, error) {
+
+	gt.transLock.Lock()
+	defer gt.transLock.Unlock()
+
+	return gt.Trans.MergeNode(part, node, match, policies)
+}
+
+/*
+MergeEdge merges an edge into a partition of the graph.
+*/
+func (gt *concurrentTrans) MergeEdge(part string, edge data.Edge, policies map[string]string) (data.Edge, error) {
+	gt.transLock.Lock()
+	defer gt.transLock.Unlock()
+
+	return gt.Trans.MergeEdge(part, edge, policies)
+}
+
+/*
+Rollback discards all operations of this transaction which have not been
+committed yet.
+*/
+func (gt *concurrentTrans) Rollback() error {
+	gt.transLock.Lock()
+	defer gt.transLock.Unlock()
+
+	return gt.Trans.Rollback()
+}
+
+/*
+Savepoint records the current state of this transaction under a given name.
+*/
+func (gt *concurrentTrans) Savepoint(name string) error {
+	gt.transLock.Lock()
+	defer gt.transLock.Unlock()
+
+	return gt.Trans.Savepoint(name)
+}
+
+/*
+RollbackTo discards all operations of this transaction which were added after
+a given savepoint.
+*/
+func (gt *concurrentTrans) RollbackTo(name string) error {
+	gt.transLock.Lock()
+	defer gt.transLock.Unlock()
+
+	return gt.Trans.RollbackTo(name)
+}
+
+/*
+FetchNode fetches a single node from a partition of the graph as it will be
+after this transaction was committed.
+*/
+func (gt *concurrentTrans) FetchNode(part string, key string, kind string) (data.Node, error) {
+	gt.transLock.RLock()
+	defer gt.transLock.RUnlock()
+
+	return gt.Trans.FetchNode(part, key, kind)
+}
+
+/*
+FetchEdge fetches a single edge from a partition of the graph as it will be
+after this transaction was committed.
+*/
+func (gt *concurrentTrans) FetchEdge(part string, key string, kind string) (data.Edge, error) {
+	gt.transLock.RLock()
+	defer gt.transLock.RUnlock()
+
+	return gt.Trans.FetchEdge(part, key, kind)
+}
+
+/*
+Traverse traverses from a given node to other nodes following a given partial
+edge spec as the graph will be after this transaction was committed.
+*/
+func (gt *concurrentTrans) Traverse(part string, key string, kind string,
+	spec string, allData bool) ([]data.Node, []data.Edge, error) {
+
+	gt.transLock.RLock()
+	defer gt.transLock.RUnlock()
+
+	return gt.Trans.Traverse(part, key, kind, spec, allData)
+}
+
 /*
 rollingTrans is a rolling transaction which will commit itself after
 n operations.
@@ -1154,3 +1538,113 @@ func (gt *rollingTrans) RemoveEdge(part string, ekey string, ekind string) error
 
 	return err
 }
+
+/*
+MergeNode merges a node into a partition of the graph. Only nodes of the
+current subtransaction and nodes which are already stored are considered.
+*/
+func (gt *rollingTrans) MergeNode(part string, node data.Node, match []string,
+	policies map[string]string) (data.Node, error) {
+
+	gt.transLock.Lock()
+	defer gt.transLock.Unlock()
+
+	res, err := gt.currentTrans.MergeNode(part, node, match, policies)
+
+	if err == nil {
+		gt.checkNewSubTrans()
+	}
+
+	return res, err
+}
+
+/*
+MergeEdge merges an edge into a partition of the graph. Only edges of the
+current subtransaction and edges which are already stored are considered.
+*/
+func (gt *rollingTrans) MergeEdge(part string, edge data.Edge, policies map[string]string) (data.Edge, error) {
+	gt.transLock.Lock()
+	defer gt.transLock.Unlock()
+
+	res, err := gt.currentTrans.MergeEdge(part, edge, policies)
+
+	if err == nil {
+		gt.checkNewSubTrans()
+	}
+
+	return res, err
+}
+
+/*
+Rollback discards all operations of the current subtransaction. Operations
+of subtransactions which were already committed cannot be rolled back.
+*/
+func (gt *rollingTrans) Rollback() error {
+	gt.transLock.Lock()
+	defer gt.transLock.Unlock()
+
+	gt.opCount = 0
+
+	return gt.currentTrans.Rollback()
+}
+
+/*
+Savepoint records the current state of the current subtransaction under a
+given name. The savepoint is lost once the subtransaction is committed.
+*/
+func (gt *rollingTrans) Savepoint(name string) error {
+	gt.transLock.Lock()
+	defer gt.transLock.Unlock()
+
+	return gt.currentTrans.Savepoint(name)
+}
+
+/*
+RollbackTo discards all operations of the current subtransaction which were
+added after a given savepoint. It is an error if the savepoint was recorded
+in a subtransaction which was already committed.
+*/
+func (gt *rollingTrans) RollbackTo(name string) error {
+	gt.transLock.Lock()
+	defer gt.transLock.Unlock()
+
+	return gt.currentTrans.RollbackTo(name)
+}
+
+/*
+FetchNode fetches a single node from a partition of the graph as it will be
+after the current subtransaction was committed. Subtransactions which are
+still committing are not considered.
+*/
+func (gt *rollingTrans) FetchNode(part string, key string, kind string) (data.Node, error) {
+	gt.transLock.RLock()
+	defer gt.transLock.RUnlock()
+
+	return gt.currentTrans.FetchNode(part, key, kind)
+}
+
+/*
+FetchEdge fetches a single edge from a partition of the graph as it will be
+after the current subtransaction was committed. Subtransactions which are
+still committing are not considered.
+*/
+func (gt *rollingTrans) FetchEdge(part string, key string, kind string) (data.Edge, error) {
+	gt.transLock.RLock()
+	defer gt.transLock.RUnlock()
+
+	return gt.currentTrans.FetchEdge(part, key, kind)
+}
+
+/*
+Traverse traverses from a given node to other nodes following a given partial
+edge spec as the graph will be after the current subtransaction was committed.
+Subtransactions which are still committing are not considered.
+*/
+func (gt *rollingTrans) Traverse(part string, key string, kind string,
+	spec string, allData bool) ([]data.Node, []data.Edge, error) {
+
+	gt.transLock.RLock()
+	defer gt.transLock.RUnlock()
+
+	return gt.currentTrans.Traverse(part, key, kind, spec, allData)
+}
@@ -0,0 +1,516 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+	"github.com/Fisch-Labs/FishDB/hash"
+)
+
+/*
+Merge policies which define how attribute values are merged
+*/
+const (
+	MergeOverwrite = "overwrite" // New values replace existing values (default)
+	MergeKeepFirst = "keepfirst" // Existing values are kept
+	MergeAppend    = "append"    // New values are appended to a list of existing values
+	MergeMax       = "max"       // The larger value is kept
+)
+
+/*
+MergePolicies is a list of all valid merge policies.
+*/
+var MergePolicies = []string{MergeOverwrite, MergeKeepFirst, MergeAppend, MergeMax}
+
+/*
+MergeNode merges a node into a partition of the graph. An existing node of the
+same kind is matched by the values of the given attributes or by its key if no
+attributes are given. The node is created with its own key if no node matches.
+Otherwise the attributes are merged into the existing node according to the
+given policies (map of attribute to merge policy - the policy for all
+attributes without a policy of their own is stored under the empty attribute
+name). Nodes which are stored or removed in this transaction are considered.
+A match by attributes is checked again when the transaction is committed - the
+commit fails with a conflict error if another transaction has stored a
+matching node in the meantime. The revision of a stored existing node is kept
+so the commit also fails if the existing node was changed in the meantime.
+Returns the node which will be stored.
+*/
+func (gt *baseTrans) MergeNode(part string, node data.Node, match []string,
+	policies map[string]string) (data.Node, error) {
+
+	if err := gt.gm.checkPartitionName(part); err != nil {
+		return nil, err
+	} else if err := checkMergePolicies(policies); err != nil {
+		return nil, err
+	}
+
+	var existing data.Node
+	var err error
+
+	byKey := len(match) == 0 || (len(match) == 1 && match[0] == data.NodeKey)
+
+	if byKey {
+		existing, err = gt.pendingOrStoredNode(part, node.Key(), node.Kind())
+	} else {
+		existing, err = gt.matchNode(part, node, match)
+	}
+
+	if err == nil {
+
+		if existing != nil {
+			node = mergeAttrs(existing, node, policies, data.NodeKey, data.NodeKind, data.NodeRevision)
+		}
+
+		if byKey {
+			err = gt.StoreNode(part, node)
+		} else {
+			err = gt.StoreNode(part, &mergedNode{node, match})
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+/*
+MergeEdge merges an edge into a partition of the graph. An existing edge of the
+same kind is matched by its ends (key and kind of both ends). The edge is
+created with its own key if no edge matches. Otherwise the attributes are
+merged into the existing edge according to the given policies (see MergeNode).
+Edges which are stored or removed in this transaction are considered. Returns
+the edge which will be stored.
+*/
+func (gt *baseTrans) MergeEdge(part string, edge data.Edge, policies map[string]string) (data.Edge, error) {
+
+	if err := gt.gm.checkPartitionName(part); err != nil {
+		return nil, err
+	} else if err := checkMergePolicies(policies); err != nil {
+		return nil, err
+	} else if err := gt.gm.checkEdge(edge); err != nil {
+		return nil, err
+	}
+
+	sameEnds := func(e data.Edge) bool {
+		return e.Kind() == edge.Kind() &&
+			e.End1Key() == edge.End1Key() && e.End1Kind() == edge.End1Kind() &&
+			e.End2Key() == edge.End2Key() && e.End2Kind() == edge.End2Kind()
+	}
+
+	var candidates []data.Edge
+
+	// Look for matching edges in this transaction
+
+	for tkey, e := range gt.storeEdges {
+		if strings.HasPrefix(tkey, part+"#") && sameEnds(e) {
+			candidates = append(candidates, e)
+		}
+	}
+
+	// Look for matching edges in the datastore
+
+	_, edges, err := gt.gm.TraverseMulti(part, edge.End1Key(), edge.End1Kind(), ":"+edge.Kind()+"::", false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range edges {
+		tkey := gt.createKey(part, e.Key(), e.Kind())
+
+		if _, ok := gt.storeEdges[tkey]; ok {
+			continue
+		} else if _, ok := gt.removeEdges[tkey]; ok {
+			continue
+		}
+
+		if e, err = gt.gm.FetchEdge(part, e.Key(), e.Kind()); err != nil {
+			return nil, err
+		} else if e != nil && sameEnds(e) {
+			candidates = append(candidates, e)
+		}
+	}
+
+	if len(candidates) > 1 {
+		var keys []string
+
+		for _, e := range candidates {
+			keys = append(keys, e.Key())
+		}
+
+		sort.Strings(keys)
+
+		return nil, &util.GraphError{Type: util.ErrInvalidData,
+			Detail: fmt.Sprintf("Edge of kind %v matches more than one edge: %v", edge.Kind(), strings.Join(keys, ", "))}
+	}
+
+	if len(candidates) == 1 {
+		edge = data.NewGraphEdgeFromNode(mergeAttrs(candidates[0], edge, policies, data.NodeKey,
+			data.NodeKind, data.NodeRevision, data.EdgeEnd1Key, data.EdgeEnd1Kind, data.EdgeEnd1Role, data.EdgeEnd2Key,
+			data.EdgeEnd2Kind, data.EdgeEnd2Role))
+	}
+
+	if err := gt.StoreEdge(part, edge); err != nil {
+		return nil, err
+	}
+
+	return edge, nil
+}
+
+/*
+pendingOrStoredNode returns a node as it is after this transaction was
+committed. Returns nil if the node does not exist or is removed by this
+transaction.
+*/
+func (gt *baseTrans) pendingOrStoredNode(part string, key string, kind string) (data.Node, error) {
+	tkey := gt.createKey(part, key, kind)
+
+	if node, ok := gt.storeNodes[tkey]; ok {
+		return node, nil
+	} else if _, ok := gt.removeNodes[tkey]; ok {
+		return nil, nil
+	}
+
+	return gt.gm.FetchNode(part, key, kind)
+}
+
+/*
+matchNode finds the node of the same kind which has the same values for a
+given list of attributes. Returns nil if no node matches.
+*/
+func (gt *baseTrans) matchNode(part string, node data.Node, match []string) (data.Node, error) {
+	var candidates []data.Node
+
+	for _, attr := range match {
+		if node.Attr(attr) == nil {
+			return nil, &util.GraphError{Type: util.ErrInvalidData,
+				Detail: fmt.Sprintf("Node is missing a value for matching attribute %v", attr)}
+		}
+	}
+
+	sameValues := func(n data.Node) bool {
+		return sameMatchValues(n, node, match)
+	}
+
+	// Look for matching nodes in this transaction
+
+	for tkey, n := range gt.storeNodes {
+		if strings.HasPrefix(tkey, part+"#") && sameValues(n) {
+			candidates = append(candidates, n)
+		}
+	}
+
+	// Look for matching nodes in the datastore - use the value index if
+	// possible otherwise check all nodes
+
+	var keys []string
+
+	gt.gm.mutex.RLock()
+	lookupAttr := gt.gm.uniqueLookupAttr(node.Kind(), match)
+	gt.gm.mutex.RUnlock()
+
+	lookupVal, ok := node.IndexMap()[lookupAttr]
+
+	if lookupAttr != "" && ok {
+
+		iq, err := gt.gm.NodeIndexQuery(part, node.Kind())
+		if err != nil {
+			return nil, err
+		} else if iq != nil {
+			if keys, err = iq.LookupValue(lookupAttr, lookupVal); err != nil {
+				return nil, err
+			}
+		}
+
+	} else {
+
+		it, err := gt.gm.NodeKeyIterator(part, node.Kind())
+		if err != nil {
+			return nil, err
+		}
+
+		for it != nil && it.HasNext() {
+			keys = append(keys, it.Next())
+			if err := it.Error(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, key := range keys {
+		tkey := gt.createKey(part, key, node.Kind())
+
+		if _, ok := gt.storeNodes[tkey]; ok {
+			continue
+		} else if _, ok := gt.removeNodes[tkey]; ok {
+			continue
+		}
+
+		n, err := gt.gm.FetchNode(part, key, node.Kind())
+		if err != nil {
+			return nil, err
+		} else if n != nil && sameValues(n) {
+			candidates = append(candidates, n)
+		}
+	}
+
+	if len(candidates) > 1 {
+		keys = nil
+
+		for _, n := range candidates {
+			keys = append(keys, n.Key())
+		}
+
+		sort.Strings(keys)
+
+		return nil, &util.GraphError{Type: util.ErrInvalidData,
+			Detail: fmt.Sprintf("Node of kind %v matches more than one node: %v", node.Kind(), strings.Join(keys, ", "))}
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	return nil, nil
+}
+
+/*
+mergedNode is a node which was matched by attributes in MergeNode. The match is
+checked again when the node is committed.
+*/
+type mergedNode struct {
+	data.Node
+	match []string // Attributes which were used to match an existing node
+}
+
+/*
+checkMergedNode checks the match of a merged node again before it is written.
+No node other than the merged node itself may have the same values for the
+matching attributes. It is assumed that the caller holds the writer lock of
+the partition.
+*/
+func (gt *baseTrans) checkMergedNode(part string, node *mergedNode, iht *hash.HTree,
+	attht *hash.HTree, valht *hash.HTree) error {
+
+	var keys []string
+
+	// Use the value index if possible otherwise check all nodes
+
+	lookupAttr := gt.gm.uniqueLookupAttr(node.Kind(), node.match)
+	lookupVal, ok := node.IndexMap()[lookupAttr]
+
+	if lookupAttr != "" && ok && iht != nil {
+		var err error
+
+		if keys, err = gt.gm.nodeIndexManager(iht, node.Kind()).LookupValue(lookupAttr, lookupVal); err != nil {
+			return err
+		}
+
+	} else {
+
+		storedKeys, err := gt.gm.readStorageKeys(part, node.Kind(), StorageSuffixNodes)
+		if err != nil {
+			return err
+		}
+
+		for key := range storedKeys {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+	}
+
+	for _, key := range keys {
+
+		if key == node.Key() {
+			continue
+		} else if _, ok := gt.removeNodes[gt.createKey(part, key, node.Kind())]; ok {
+			continue
+		}
+
+		n, err := gt.gm.readNode(key, node.Kind(), node.match, attht, valht)
+		if err != nil {
+			return err
+		}
+
+		if n != nil {
+
+			// Only the matching attributes were read
+
+			n.SetAttr(data.NodeKey, key)
+			n.SetAttr(data.NodeKind, node.Kind())
+
+			if sameMatchValues(n, node, node.match) {
+				return &util.GraphError{Type: util.ErrConflict,
+					Detail: fmt.Sprintf("Merged node %v of kind %v has the same values for %v as node %v in partition %v",
+						node.Key(), node.Kind(), node.match, key, part)}
+			}
+		}
+	}
+
+	return nil
+}
+
+/*
+sameMatchValues checks if a node has the same kind and the same values for a
+list of matching attributes as another node.
+*/
+func sameMatchValues(n data.Node, node data.Node, match []string) bool {
+	same := n.Kind() == node.Kind()
+	for _, attr := range match {
+		same = same && n.Attr(attr) != nil && fmt.Sprint(n.Attr(attr)) == fmt.Sprint(node.Attr(attr))
+	}
+	return same
+}
+
+/*
+checkMergePolicies checks that all given merge policies are known.
+*/
+func checkMergePolicies(policies map[string]string) error {
+
+	for _, policy := range policies {
+		var known bool
+
+		for _, p := range MergePolicies {
+			known = known || p == policy
+		}
+
+		if !known {
+			return &util.GraphError{Type: util.ErrInvalidData, Detail: fmt.Sprint("Unknown merge policy: ", policy)}
+		}
+	}
+
+	return nil
+}
+
+/*
+mergeAttrs merges the attributes of a new node into a copy of an existing node
+according to the given merge policies. The given fixed attributes are always
+taken from the existing node.
+*/
+func mergeAttrs(existing data.Node, node data.Node, policies map[string]string,
+	fixed ...string) data.Node {
+
+	ret := data.CopyNode(existing)
+
+	isFixed := func(attr string) bool {
+		for _, f := range fixed {
+			if f == attr {
+				return true
+			}
+		}
+		return false
+	}
+
+	for attr, val := range node.Data() {
+
+		if isFixed(attr) {
+			continue
+		}
+
+		policy, ok := policies[attr]
+		if !ok {
+			if policy, ok = policies[""]; !ok {
+				policy = MergeOverwrite
+			}
+		}
+
+		old := ret.Attr(attr)
+
+		if old == nil {
+			ret.SetAttr(attr, val)
+			continue
+		}
+
+		switch policy {
+
+		case MergeOverwrite:
+			ret.SetAttr(attr, val)
+
+		case MergeAppend:
+			ret.SetAttr(attr, appendValues(old, val))
+
+		case MergeMax:
+			if compareValues(val, old) > 0 {
+				ret.SetAttr(attr, val)
+			}
+		}
+
+		// The existing value is kept with MergeKeepFirst
+	}
+
+	return ret
+}
+
+/*
+appendValues appends a value or a list of values to a list of existing values.
+Values which are already in the list are not added again.
+*/
+func appendValues(old interface{}, val interface{}) []interface{} {
+
+	toList := func(v interface{}) []interface{} {
+		var ret []interface{}
+
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			for i := 0; i < rv.Len(); i++ {
+				ret = append(ret, rv.Index(i).Interface())
+			}
+		} else {
+			ret = append(ret, v)
+		}
+
+		return ret
+	}
+
+	ret := toList(old)
+
+	for _, v := range toList(val) {
+		var found bool
+
+		for _, o := range ret {
+			found = found || reflect.DeepEqual(o, v)
+		}
+
+		if !found {
+			ret = append(ret, v)
+		}
+	}
+
+	return ret
+}
+
+/*
+compareValues compares two values. Numbers are compared by their value and all
+other values by their string representation.
+*/
+func compareValues(v1 interface{}, v2 interface{}) int {
+	s1, s2 := fmt.Sprint(v1), fmt.Sprint(v2)
+
+	f1, err1 := strconv.ParseFloat(s1, 64)
+	f2, err2 := strconv.ParseFloat(s2, 64)
+
+	if err1 == nil && err2 == nil {
+		if f1 < f2 {
+			return -1
+		} else if f1 > f2 {
+			return 1
+		}
+		return 0
+	}
+
+	return strings.Compare(s1, s2)
+}
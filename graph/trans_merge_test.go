@@ -0,0 +1,377 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+)
+
+func TestMergeNode(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	gm.StoreNode("main", data.NewGraphNodeFromMap(map[string]interface{}{
+		"key":     "1",
+		"kind":    "Person",
+		"extid":   "A1",
+		"name":    "John",
+		"aliases": []interface{}{"Johnny"},
+		"score":   5,
+		"source":  "first",
+	}))
+
+	policies := map[string]string{
+		"aliases": MergeAppend,
+		"score":   MergeMax,
+		"source":  MergeKeepFirst,
+	}
+
+	// Match by attribute - the key of the existing node is kept
+
+	trans := NewGraphTrans(gm)
+
+	res, err := trans.MergeNode("main", data.NewGraphNodeFromMap(map[string]interface{}{
+		"key":     "x",
+		"kind":    "Person",
+		"extid":   "A1",
+		"name":    "John Doe",
+		"aliases": []interface{}{"Johnny", "JD"},
+		"score":   3,
+		"source":  "second",
+		"email":   "john@example.com",
+	}), []string{"extid"}, policies)
+
+	if err != nil || res.Key() != "1" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if node, err := gm.FetchNode("main", "1", "Person"); err != nil || fmt.Sprint(node.Data()) !=
+		"map[_rev:2 aliases:[Johnny JD] email:john@example.com extid:A1 key:1 kind:Person name:John Doe score:5 source:first]" {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	if res := gm.NodeCount("Person"); res != 1 {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// Merging the same data again does not change anything
+
+	trans = NewGraphTrans(gm)
+
+	trans.MergeNode("main", data.NewGraphNodeFromMap(map[string]interface{}{
+		"key":     "y",
+		"kind":    "Person",
+		"extid":   "A1",
+		"aliases": "JD",
+		"score":   5.0,
+	}), []string{"extid"}, policies)
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if node, err := gm.FetchNode("main", "1", "Person"); err != nil ||
+		fmt.Sprint(node.Attr("aliases"), node.Attr("score")) != "[Johnny JD] 5" {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	// Nodes which do not match are created - nodes in the same transaction
+	// are matched as well
+
+	trans = NewGraphTrans(gm)
+
+	for _, score := range []int{1, 7, 4} {
+		if _, err := trans.MergeNode("main", data.NewGraphNodeFromMap(map[string]interface{}{
+			"key":   fmt.Sprint("n", score),
+			"kind":  "Person",
+			"extid": "B1",
+			"score": score,
+		}), []string{"extid"}, map[string]string{"": MergeMax}); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if node, err := gm.FetchNode("main", "n1", "Person"); err != nil || node.Attr("score") != 7 {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	// Match by key
+
+	trans = NewGraphTrans(gm)
+
+	if res, err := trans.MergeNode("main", data.NewGraphNodeFromMap(map[string]interface{}{
+		"key":   "n1",
+		"kind":  "Person",
+		"score": 2,
+	}), nil, nil); err != nil || fmt.Sprint(res.Data()) != "map[_rev:1 extid:B1 key:n1 kind:Person score:2]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Test error cases
+
+	if _, err := trans.MergeNode("main", data.NewGraphNodeFromMap(map[string]interface{}{
+		"key":  "n1",
+		"kind": "Person",
+	}), []string{"extid"}, nil); err == nil ||
+		err.Error() != "GraphError: Invalid data (Node is missing a value for matching attribute extid)" {
+		t.Error(err)
+		return
+	}
+
+	if _, err := trans.MergeNode("main", data.NewGraphNodeFromMap(map[string]interface{}{
+		"key":  "n1",
+		"kind": "Person",
+	}), nil, map[string]string{"score": "min"}); err == nil ||
+		err.Error() != "GraphError: Invalid data (Unknown merge policy: min)" {
+		t.Error(err)
+		return
+	}
+
+	gm.StoreNode("main", data.NewGraphNodeFromMap(map[string]interface{}{
+		"key":   "2",
+		"kind":  "Person",
+		"extid": "A1",
+	}))
+
+	if _, err := trans.MergeNode("main", data.NewGraphNodeFromMap(map[string]interface{}{
+		"key":   "3",
+		"kind":  "Person",
+		"extid": "A1",
+	}), []string{"extid"}, nil); err == nil ||
+		err.Error() != "GraphError: Invalid data (Node of kind Person matches more than one node: 1, 2)" {
+		t.Error(err)
+		return
+	}
+
+	if _, err := trans.MergeNode("ma in", data.NewGraphNodeFromMap(map[string]interface{}{
+		"key":  "3",
+		"kind": "Person",
+	}), nil, nil); err == nil {
+		t.Error("Unexpected result:", err)
+		return
+	}
+}
+
+func TestMergeNodeConcurrent(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	merge := func(trans Trans, key string) {
+		if _, err := trans.MergeNode("main", data.NewGraphNodeFromMap(map[string]interface{}{
+			"key":   key,
+			"kind":  "Person",
+			"extid": "A1",
+		}), []string{"extid"}, nil); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// Both transactions do not find a matching node when merging
+
+	trans1 := NewGraphTrans(gm)
+	trans2 := NewGraphTrans(gm)
+
+	merge(trans1, "1")
+	merge(trans2, "2")
+
+	if err := trans1.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// The match is checked again on commit
+
+	if err := trans2.Commit(); err == nil ||
+		err.Error() != "GraphError: Revision conflict (Merged node 2 of kind Person has the same values for [extid] as node 1 in partition main)" {
+		t.Error(err)
+		return
+	}
+
+	if n, err := gm.FetchNode("main", "2", "Person"); n != nil || err != nil {
+		t.Error("Unexpected result:", n, err)
+		return
+	}
+
+	// Nodes which are removed in the same transaction do not count
+
+	trans3 := NewGraphTrans(gm)
+	trans3.RemoveNode("main", "1", "Person")
+	merge(trans3, "3")
+
+	if err := trans3.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// The value index is used if possible
+
+	gm.SetIndexMode("Person", "extid", "value")
+
+	trans4 := NewGraphTrans(gm)
+	merge(trans4, "4")
+
+	gm.StoreNode("main", data.NewGraphNodeFromMap(map[string]interface{}{
+		"key":   "5",
+		"kind":  "Person",
+		"extid": "A1",
+	}))
+
+	if err := trans4.Commit(); err == nil ||
+		err.Error() != "GraphError: Revision conflict (Merged node 3 of kind Person has the same values for [extid] as node 5 in partition main)" {
+		t.Error(err)
+		return
+	}
+
+	// The matched node must not be changed before the commit
+
+	gm.StoreNode("main", data.NewGraphNodeFromMap(map[string]interface{}{
+		"key":   "6",
+		"kind":  "Person",
+		"extid": "B1",
+	}))
+
+	trans5 := NewGraphTrans(gm)
+
+	if res, err := trans5.MergeNode("main", data.NewGraphNodeFromMap(map[string]interface{}{
+		"key":   "7",
+		"kind":  "Person",
+		"extid": "B1",
+		"name":  "John",
+	}), []string{"extid"}, nil); err != nil || res.Key() != "6" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	gm.UpdateNode("main", data.NewGraphNodeFromMap(map[string]interface{}{
+		"key":  "6",
+		"kind": "Person",
+		"name": "Jane",
+	}))
+
+	if err := trans5.Commit(); err == nil ||
+		err.Error() != "GraphError: Revision conflict (6 of kind Person has revision 2 but revision 1 was expected)" {
+		t.Error(err)
+		return
+	}
+}
+
+func TestMergeEdge(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	for _, key := range []string{"1", "2"} {
+		gm.StoreNode("main", data.NewGraphNodeFromMap(map[string]interface{}{
+			"key":  key,
+			"kind": "Person",
+		}))
+	}
+
+	constructEdge := func(key string, end1 string, end2 string, attrs map[string]interface{}) data.Edge {
+		edge := data.NewGraphEdge()
+		edge.SetAttr("key", key)
+		edge.SetAttr("kind", "Knows")
+		edge.SetAttr(data.EdgeEnd1Key, end1)
+		edge.SetAttr(data.EdgeEnd1Kind, "Person")
+		edge.SetAttr(data.EdgeEnd1Role, "Friend")
+		edge.SetAttr(data.EdgeEnd1Cascading, false)
+		edge.SetAttr(data.EdgeEnd2Key, end2)
+		edge.SetAttr(data.EdgeEnd2Kind, "Person")
+		edge.SetAttr(data.EdgeEnd2Role, "Friend")
+		edge.SetAttr(data.EdgeEnd2Cascading, false)
+		for k, v := range attrs {
+			edge.SetAttr(k, v)
+		}
+		return edge
+	}
+
+	trans := NewGraphTrans(gm)
+
+	if _, err := trans.MergeEdge("main", constructEdge("a", "1", "2",
+		map[string]interface{}{"since": 2010, "sources": "x"}), nil); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// The edge of the transaction is matched
+
+	if res, err := trans.MergeEdge("main", constructEdge("b", "1", "2",
+		map[string]interface{}{"since": 2005, "sources": "y"}),
+		map[string]string{"sources": MergeAppend, "since": MergeKeepFirst}); err != nil ||
+		res.Key() != "a" || fmt.Sprint(res.Attr("since"), res.Attr("sources")) != "2010 [x y]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// The stored edge is matched
+
+	trans = NewGraphTrans(gm)
+
+	if res, err := trans.MergeEdge("main", constructEdge("c", "1", "2",
+		map[string]interface{}{"since": 2020}), map[string]string{"": MergeMax}); err != nil ||
+		res.Key() != "a" || fmt.Sprint(res.Attr("since"), res.Attr("sources")) != "2020 [x y]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Edges in the other direction do not match
+
+	if res, err := trans.MergeEdge("main", constructEdge("d", "2", "1", nil), nil); err != nil || res.Key() != "d" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := gm.EdgeCount("Knows"); res != 2 {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if edge, err := gm.FetchEdge("main", "a", "Knows"); err != nil || edge.Attr("since") != 2020 {
+		t.Error("Unexpected result:", edge, err)
+		return
+	}
+
+	// Test error cases
+
+	if _, err := trans.MergeEdge("main", constructEdge("", "1", "2", nil), nil); err == nil ||
+		err.Error() != "GraphError: Invalid data (Edge is missing a key value)" {
+		t.Error(err)
+		return
+	}
+}
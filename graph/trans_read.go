@@ -0,0 +1,222 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+)
+
+/*
+FetchNode fetches a single node from a partition of the graph as it will be
+after this transaction was committed. Nodes which are stored by this
+transaction are returned as a copy. Returns nil if the node does not exist or
+is removed by this transaction.
+*/
+func (gt *baseTrans) FetchNode(part string, key string, kind string) (data.Node, error) {
+
+	if err := gt.gm.checkPartitionName(part); err != nil {
+		return nil, err
+	}
+
+	if node, ok := gt.storeNodes[gt.createKey(part, key, kind)]; ok {
+		return data.CopyNode(node), nil
+	}
+
+	return gt.pendingOrStoredNode(part, key, kind)
+}
+
+/*
+FetchEdge fetches a single edge from a partition of the graph as it will be
+after this transaction was committed. Edges which are stored by this
+transaction are returned as a copy. Returns nil if the edge does not exist or
+if the edge or one of its ends is removed by this transaction.
+*/
+func (gt *baseTrans) FetchEdge(part string, key string, kind string) (data.Edge, error) {
+	var edge data.Edge
+	var err error
+
+	if err = gt.gm.checkPartitionName(part); err != nil {
+		return nil, err
+	}
+
+	tkey := gt.createKey(part, key, kind)
+
+	if pedge, ok := gt.storeEdges[tkey]; ok {
+		edge = data.NewGraphEdgeFromNode(data.CopyNode(pedge))
+	} else if _, ok := gt.removeEdges[tkey]; ok {
+		return nil, nil
+	} else if edge, err = gt.gm.FetchEdge(part, key, kind); err != nil || edge == nil {
+		return nil, err
+	}
+
+	// Edges of removed nodes are removed as well
+
+	if gt.isNodeRemoved(part, edge.End1Key(), edge.End1Kind()) ||
+		gt.isNodeRemoved(part, edge.End2Key(), edge.End2Kind()) {
+		return nil, nil
+	}
+
+	return edge, nil
+}
+
+/*
+Traverse traverses from a given node to other nodes following a given partial
+edge spec as the graph will be after this transaction was committed. A spec
+with the value ":::" would follow all relationships. The last parameter
+allData specifies if all data should be retrieved for the connected nodes and
+edges. If set to false only the minimal set of attributes will be populated.
+*/
+func (gt *baseTrans) Traverse(part string, key string, kind string,
+	spec string, allData bool) ([]data.Node, []data.Edge, error) {
+
+	if err := gt.gm.checkPartitionName(part); err != nil {
+		return nil, nil, err
+	}
+
+	sspec := strings.Split(spec, ":")
+	if len(sspec) != 4 {
+		return nil, nil, &util.GraphError{Type: util.ErrInvalidData, Detail: "Invalid spec: " + spec}
+	}
+
+	if gt.isNodeRemoved(part, key, kind) {
+		return nil, nil, nil
+	}
+
+	var nodes []data.Node
+	var edges []data.Edge
+
+	// Traverse the stored graph - edges which are changed by this transaction
+	// are added afterwards
+
+	snodes, sedges, err := gt.gm.TraverseMulti(part, key, kind, spec, allData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, edge := range sedges {
+		tkey := gt.createKey(part, edge.Key(), edge.Kind())
+
+		if _, ok := gt.storeEdges[tkey]; ok {
+			continue
+		} else if _, ok := gt.removeEdges[tkey]; ok {
+			continue
+		}
+
+		node := snodes[i]
+
+		if gt.isNodeRemoved(part, node.Key(), node.Kind()) {
+			continue
+		} else if pnode, ok := gt.storeNodes[gt.createKey(part, node.Key(), node.Kind())]; ok && allData {
+			node = data.CopyNode(pnode)
+		}
+
+		nodes = append(nodes, node)
+		edges = append(edges, edge)
+	}
+
+	// Add the matching edges of this transaction
+
+	var pkeys []string
+
+	for tkey, edge := range gt.storeEdges {
+		if strings.HasPrefix(tkey, part+"#") &&
+			((edge.End1Key() == key && edge.End1Kind() == kind) ||
+				(edge.End2Key() == key && edge.End2Kind() == kind)) {
+
+			pkeys = append(pkeys, tkey)
+		}
+	}
+
+	// Ensure the output is deterministic
+
+	sort.Strings(pkeys)
+
+	for _, tkey := range pkeys {
+		edge := data.NewGraphEdgeFromNode(data.CopyNode(gt.storeEdges[tkey]))
+
+		// Exchange ends if necessary
+
+		if edge.End1Key() != key || edge.End1Kind() != kind {
+			swap := func(attr1 string, attr2 string) {
+				tmp := edge.Attr(attr1)
+				edge.SetAttr(attr1, edge.Attr(attr2))
+				edge.SetAttr(attr2, tmp)
+			}
+
+			swap(data.EdgeEnd1Key, data.EdgeEnd2Key)
+			swap(data.EdgeEnd1Kind, data.EdgeEnd2Kind)
+			swap(data.EdgeEnd1Role, data.EdgeEnd2Role)
+			swap(data.EdgeEnd1Cascading, data.EdgeEnd2Cascading)
+			swap(data.EdgeEnd1CascadingLast, data.EdgeEnd2CascadingLast)
+		}
+
+		// Check spec components
+
+		if (sspec[0] != "" && edge.End1Role() != sspec[0]) ||
+			(sspec[1] != "" && edge.Kind() != sspec[1]) ||
+			(sspec[2] != "" && edge.End2Role() != sspec[2]) ||
+			(sspec[3] != "" && edge.End2Kind() != sspec[3]) {
+
+			continue
+		}
+
+		// Lookup the node on the other end - edges to nodes which do not
+		// exist cannot be committed and are ignored
+
+		node, err := gt.pendingOrStoredNode(part, edge.End2Key(), edge.End2Kind())
+		if err != nil {
+			return nil, nil, err
+		} else if node == nil {
+			continue
+		}
+
+		if !allData {
+
+			// Reduce nodes and edges to the minimal set of attributes
+
+			minEdge := data.NewGraphEdge()
+
+			for _, attr := range []string{data.NodeKey, data.NodeKind,
+				data.EdgeEnd1Key, data.EdgeEnd1Kind, data.EdgeEnd1Role,
+				data.EdgeEnd1Cascading, data.EdgeEnd1CascadingLast,
+				data.EdgeEnd2Key, data.EdgeEnd2Kind, data.EdgeEnd2Role,
+				data.EdgeEnd2Cascading, data.EdgeEnd2CascadingLast} {
+
+				minEdge.SetAttr(attr, edge.Attr(attr))
+			}
+
+			edge = minEdge
+
+			minNode := data.NewGraphNode()
+			minNode.SetAttr(data.NodeKey, node.Key())
+			minNode.SetAttr(data.NodeKind, node.Kind())
+
+			node = minNode
+
+		} else {
+			node = data.CopyNode(node)
+		}
+
+		nodes = append(nodes, node)
+		edges = append(edges, edge)
+	}
+
+	return nodes, edges, nil
+}
+
+/*
+isNodeRemoved checks if a node is removed by this transaction.
+*/
+func (gt *baseTrans) isNodeRemoved(part string, key string, kind string) bool {
+	_, ok := gt.removeNodes[gt.createKey(part, key, kind)]
+	return ok
+}
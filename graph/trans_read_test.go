@@ -0,0 +1,185 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+)
+
+func TestTransReads(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	constructNode := func(key string, name string) data.Node {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "mynode")
+		node.SetAttr("name", name)
+		return node
+	}
+
+	constructEdge := func(key string, end1 string, end2 string) data.Edge {
+		edge := data.NewGraphEdge()
+		edge.SetAttr("key", key)
+		edge.SetAttr("kind", "myedge")
+		edge.SetAttr(data.EdgeEnd1Key, end1)
+		edge.SetAttr(data.EdgeEnd1Kind, "mynode")
+		edge.SetAttr(data.EdgeEnd1Role, "parent")
+		edge.SetAttr(data.EdgeEnd1Cascading, false)
+		edge.SetAttr(data.EdgeEnd2Key, end2)
+		edge.SetAttr(data.EdgeEnd2Kind, "mynode")
+		edge.SetAttr(data.EdgeEnd2Role, "child")
+		edge.SetAttr(data.EdgeEnd2Cascading, false)
+		return edge
+	}
+
+	traverse := func(trans Trans, key string, spec string, allData bool) string {
+		nodes, edges, err := trans.Traverse("main", key, "mynode", spec, allData)
+		if err != nil {
+			return err.Error()
+		}
+
+		var res []string
+		for i, n := range nodes {
+			res = append(res, fmt.Sprintf("%v:%v-%v", edges[i].Key(), n.Key(), n.Attr("name")))
+		}
+		sort.Strings(res)
+
+		return fmt.Sprint(res)
+	}
+
+	gm.StoreNode("main", constructNode("1", "Anne"))
+	gm.StoreNode("main", constructNode("2", "Bob"))
+	gm.StoreNode("main", constructNode("3", "Carl"))
+	gm.StoreEdge("main", constructEdge("a", "1", "2"))
+	gm.StoreEdge("main", constructEdge("b", "1", "3"))
+
+	trans := NewConcurrentGraphTrans(gm)
+
+	// Pending changes are visible in the transaction
+
+	trans.UpdateNode("main", constructNode("2", "Bobby"))
+	trans.StoreNode("main", constructNode("4", "Dave"))
+	trans.StoreEdge("main", constructEdge("c", "1", "4"))
+	trans.RemoveEdge("main", "b", "myedge")
+
+	if node, err := trans.FetchNode("main", "2", "mynode"); err != nil || node.Attr("name") != "Bobby" {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	if node, err := gm.FetchNode("main", "2", "mynode"); err != nil || node.Attr("name") != "Bob" {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	if node, err := trans.FetchNode("main", "4", "mynode"); err != nil || node.Attr("name") != "Dave" {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	if edge, err := trans.FetchEdge("main", "c", "myedge"); err != nil || edge.End2Key() != "4" {
+		t.Error("Unexpected result:", edge, err)
+		return
+	}
+
+	if edge, err := trans.FetchEdge("main", "b", "myedge"); err != nil || edge != nil {
+		t.Error("Unexpected result:", edge, err)
+		return
+	}
+
+	if res := traverse(trans, "1", ":::", true); res != "[a:2-Bobby c:4-Dave]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := traverse(trans, "1", "parent:myedge:child:mynode", false); res != "[a:2-<nil> c:4-<nil>]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// Edges are traversed from both ends
+
+	if nodes, edges, err := trans.Traverse("main", "4", "mynode", "child::parent:", true); err != nil ||
+		len(nodes) != 1 || nodes[0].Key() != "1" || edges[0].End1Key() != "4" || edges[0].End2Role() != "parent" {
+		t.Error("Unexpected result:", nodes, edges, err)
+		return
+	}
+
+	if res := traverse(trans, "4", "parent:::", true); res != "[]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// Removed nodes and their edges disappear
+
+	trans.RemoveNode("main", "2", "mynode")
+
+	if node, err := trans.FetchNode("main", "2", "mynode"); err != nil || node != nil {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	if edge, err := trans.FetchEdge("main", "a", "myedge"); err != nil || edge != nil {
+		t.Error("Unexpected result:", edge, err)
+		return
+	}
+
+	if res := traverse(trans, "1", ":::", true); res != "[c:4-Dave]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := traverse(trans, "2", ":::", true); res != "[]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// Changing a returned node does not change the transaction
+
+	node, _ := trans.FetchNode("main", "4", "mynode")
+	node.SetAttr("name", "Eve")
+
+	if node, _ := trans.FetchNode("main", "4", "mynode"); node.Attr("name") != "Dave" {
+		t.Error("Unexpected result:", node)
+		return
+	}
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := traverse(trans, "1", ":::", true); res != "[c:4-Dave]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// Test error cases
+
+	if _, _, err := trans.Traverse("main", "1", "mynode", "::", true); err == nil ||
+		err.Error() != "GraphError: Invalid data (Invalid spec: ::)" {
+		t.Error(err)
+		return
+	}
+
+	if _, err := trans.FetchNode("ma in", "1", "mynode"); err == nil {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if _, err := trans.FetchEdge("ma in", "1", "myedge"); err == nil {
+		t.Error("Unexpected result:", err)
+		return
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+)
+
+/*
+transSavepoint is the recorded state of a transaction.
+*/
+type transSavepoint struct {
+	name        string               // Name of the savepoint
+	storeNodes  map[string]data.Node // Nodes which should be stored
+	removeNodes map[string]data.Node // Nodes which should be removed
+	storeEdges  map[string]data.Edge // Edges which should be stored
+	removeEdges map[string]data.Edge // Edges which should be removed
+}
+
+/*
+Rollback discards all operations of this transaction which have not been
+committed yet. All savepoints are removed. The transaction can be used again
+afterwards.
+*/
+func (gt *baseTrans) Rollback() error {
+
+	gt.storeNodes = make(map[string]data.Node)
+	gt.removeNodes = make(map[string]data.Node)
+	gt.storeEdges = make(map[string]data.Edge)
+	gt.removeEdges = make(map[string]data.Edge)
+
+	gt.savepoints = nil
+
+	return nil
+}
+
+/*
+Savepoint records the current state of this transaction under a given name.
+An existing savepoint with the same name is replaced.
+*/
+func (gt *baseTrans) Savepoint(name string) error {
+
+	if name == "" {
+		return &util.GraphError{Type: util.ErrInvalidData, Detail: "Savepoint needs a name"}
+	}
+
+	if i := gt.savepointIndex(name); i != -1 {
+		gt.savepoints = append(gt.savepoints[:i], gt.savepoints[i+1:]...)
+	}
+
+	sp := &transSavepoint{name, make(map[string]data.Node), make(map[string]data.Node),
+		make(map[string]data.Edge), make(map[string]data.Edge)}
+
+	copyNodes(sp.storeNodes, gt.storeNodes)
+	copyNodes(sp.removeNodes, gt.removeNodes)
+	copyEdges(sp.storeEdges, gt.storeEdges)
+	copyEdges(sp.removeEdges, gt.removeEdges)
+
+	gt.savepoints = append(gt.savepoints, sp)
+
+	return nil
+}
+
+/*
+RollbackTo discards all operations of this transaction which were added after
+a given savepoint. The savepoint itself is kept so it is possible to roll back
+to it again. All savepoints which were recorded after it are removed.
+*/
+func (gt *baseTrans) RollbackTo(name string) error {
+
+	i := gt.savepointIndex(name)
+	if i == -1 {
+		return &util.GraphError{Type: util.ErrInvalidData, Detail: fmt.Sprint("Unknown savepoint: ", name)}
+	}
+
+	sp := gt.savepoints[i]
+	gt.savepoints = gt.savepoints[:i+1]
+
+	gt.storeNodes = make(map[string]data.Node)
+	gt.removeNodes = make(map[string]data.Node)
+	gt.storeEdges = make(map[string]data.Edge)
+	gt.removeEdges = make(map[string]data.Edge)
+
+	copyNodes(gt.storeNodes, sp.storeNodes)
+	copyNodes(gt.removeNodes, sp.removeNodes)
+	copyEdges(gt.storeEdges, sp.storeEdges)
+	copyEdges(gt.removeEdges, sp.removeEdges)
+
+	return nil
+}
+
+/*
+savepointIndex returns the position of a savepoint or -1 if there is no
+savepoint with the given name.
+*/
+func (gt *baseTrans) savepointIndex(name string) int {
+
+	for i, sp := range gt.savepoints {
+		if sp.name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+/*
+copyNodes copies all entries of a node map into another node map.
+*/
+func copyNodes(dst map[string]data.Node, src map[string]data.Node) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+/*
+copyEdges copies all entries of an edge map into another edge map.
+*/
+func copyEdges(dst map[string]data.Edge, src map[string]data.Edge) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
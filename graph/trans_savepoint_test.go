@@ -0,0 +1,163 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package graph
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+)
+
+func TestTransSavepoints(t *testing.T) {
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := NewGraphManager(mgs)
+
+	constructNode := func(key string) data.Node {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "mynode")
+		return node
+	}
+
+	trans := NewConcurrentGraphTrans(gm)
+
+	trans.StoreNode("main", constructNode("1"))
+
+	if err := trans.Savepoint("a"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	trans.StoreNode("main", constructNode("2"))
+	trans.RemoveNode("main", "1", "mynode")
+
+	if err := trans.Savepoint("b"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	trans.StoreNode("main", constructNode("3"))
+
+	if res := trans.String(); res != fmt.Sprintf("Transaction %v - Nodes: I:2 R:1 - Edges: I:0 R:0", trans.ID()) {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if err := trans.RollbackTo("b"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if ns, _, nr, _ := trans.Counts(); ns != 1 || nr != 1 {
+		t.Error("Unexpected result:", ns, nr)
+		return
+	}
+
+	// A savepoint can be used more than once - later savepoints are removed
+
+	if err := trans.RollbackTo("a"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := trans.RollbackTo("a"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := trans.RollbackTo("b"); err == nil || err.Error() != "GraphError: Invalid data (Unknown savepoint: b)" {
+		t.Error(err)
+		return
+	}
+
+	if ns, _, nr, _ := trans.Counts(); ns != 1 || nr != 0 {
+		t.Error("Unexpected result:", ns, nr)
+		return
+	}
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := gm.NodeCount("mynode"); res != 1 {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// Savepoints are removed on commit
+
+	if err := trans.RollbackTo("a"); err == nil || err.Error() != "GraphError: Invalid data (Unknown savepoint: a)" {
+		t.Error(err)
+		return
+	}
+
+	// Rollback discards everything
+
+	trans.StoreNode("main", constructNode("4"))
+	trans.Savepoint("c")
+	trans.RemoveNode("main", "1", "mynode")
+
+	if err := trans.Rollback(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if !trans.IsEmpty() {
+		t.Error("Transaction should be empty:", trans)
+		return
+	}
+
+	if err := trans.RollbackTo("c"); err == nil {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if err := trans.Savepoint(""); err == nil || err.Error() != "GraphError: Invalid data (Savepoint needs a name)" {
+		t.Error(err)
+		return
+	}
+
+	// Savepoints of rolling transactions only cover the current subtransaction
+
+	rtrans := NewRollingTrans(NewConcurrentGraphTrans(gm), 2, gm, NewConcurrentGraphTrans)
+
+	rtrans.StoreNode("main", constructNode("5"))
+	rtrans.Savepoint("d")
+	rtrans.StoreNode("main", constructNode("6"))
+
+	if err := rtrans.RollbackTo("d"); err == nil || err.Error() != "GraphError: Invalid data (Unknown savepoint: d)" {
+		t.Error(err)
+		return
+	}
+
+	rtrans.StoreNode("main", constructNode("7"))
+	rtrans.Savepoint("e")
+
+	if err := rtrans.RollbackTo("e"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := rtrans.Rollback(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := rtrans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := gm.NodeCount("mynode"); res != 3 {
+		t.Error("Unexpected result:", res)
+		return
+	}
+}
@@ -867,7 +867,7 @@ func TestTransErrors(t *testing.T) {
 		return
 	}
 
-	if storage.MsmCallNumRollback != 6 {
+	if storage.MsmCallNumRollback != 9 {
 		t.Error("Unexpected number of rollback calls:", storage.MsmCallNumRollback)
 	}
 
@@ -1242,12 +1242,12 @@ func TestTransErrors(t *testing.T) {
 	}
 
 	sm = mgs.StorageManager("main"+deleteEdge.End2Kind()+StorageSuffixNodes, false).(*storage.MemoryStorageManager)
-	sm.AccessMap[5] = storage.AccessCacheAndFetchError
+	sm.AccessMap[6] = storage.AccessCacheAndFetchError
 	if err := trans2.Commit(); !strings.Contains(fmt.Sprint(err), "GraphError: Could not read graph information") {
 		t.Error("Unexpected error return:", err)
 		return
 	}
-	delete(sm.AccessMap, 5)
+	delete(sm.AccessMap, 6)
 
 	resetTransAndStorage()
 
@@ -0,0 +1,395 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Fisch-Labs/Toolkit/stringutil"
+)
+
+/*
+Token is a single word of an analyzed text.
+*/
+type Token struct {
+	Word string // Word which is indexed
+	Pos  uint64 // Position of the word in the text (starting with 1)
+}
+
+/*
+TokenFilter transforms a list of tokens. A filter may remove, change or add
+tokens. Tokens which share a position are alternatives for the same word.
+*/
+type TokenFilter func(tokens []Token) []Token
+
+/*
+tokenFilters is a map of all known token filters. Each entry creates a filter
+from an optional parameter.
+*/
+var tokenFilters = map[string]func(param string) (TokenFilter, error){
+	"lowercase": newLowercaseFilter,
+	"fold":      newFoldFilter,
+	"stop":      newStopFilter,
+	"stem":      newStemFilter,
+	"ngram":     newNgramFilter,
+	"cjk":       newCJKFilter,
+}
+
+/*
+Analyzer splits a text into words and runs them through a chain of token
+filters. An analyzer is defined by a specification which is a comma separated
+list of filters. A filter can have a parameter which is separated by a colon.
+Available filters are:
+
+lowercase - Convert all words to lower case.
+
+fold - Remove diacritics and convert full width characters (e.g. é -> e).
+
+stop[:<lang>] - Remove stop words (languages: en, de, fr, es - default is en).
+
+stem[:<lang>] - Reduce words to their stem (languages: en - default is en).
+
+ngram[:<n>] - Split words into n-grams (default is 3).
+
+cjk - Split Chinese, Japanese and Korean text into bigrams.
+
+Example: lowercase,fold,stop:en,stem:en
+*/
+type Analyzer struct {
+	spec    string        // Specification of this analyzer
+	filters []TokenFilter // Filters which are applied in order
+}
+
+/*
+NewAnalyzer creates a new analyzer from a given specification.
+*/
+func NewAnalyzer(spec string) (*Analyzer, error) {
+	var filters []TokenFilter
+	var specs []string
+
+	for _, s := range strings.Split(spec, ",") {
+
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+
+		name, param := s, ""
+		if i := strings.Index(s, ":"); i != -1 {
+			name, param = s[:i], s[i+1:]
+		}
+
+		newFilter, ok := tokenFilters[name]
+		if !ok {
+			return nil, &GraphError{ErrInvalidData, fmt.Sprint("Unknown analyzer filter: ", name)}
+		}
+
+		filter, err := newFilter(param)
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, filter)
+		specs = append(specs, s)
+	}
+
+	return &Analyzer{strings.Join(specs, ","), filters}, nil
+}
+
+/*
+Analyze splits a given text into tokens.
+*/
+func (a *Analyzer) Analyze(text string) []Token {
+	tokens := tokenize(text)
+
+	for _, f := range a.filters {
+		tokens = f(tokens)
+	}
+
+	return tokens
+}
+
+/*
+String returns the specification of this analyzer.
+*/
+func (a *Analyzer) String() string {
+	return a.spec
+}
+
+/*
+words returns a word set of all tokens of a given text.
+*/
+func (a *Analyzer) words(text string) *wordSet {
+	ws := newWordSet(4)
+
+	for _, t := range a.Analyze(text) {
+		ws.Add(t.Word, t.Pos)
+	}
+
+	return ws
+}
+
+/*
+isWordSeparator checks if a given rune separates two words.
+*/
+func isWordSeparator(r rune) bool {
+	return !stringutil.IsAlphaNumeric(string(r)) && (unicode.IsSpace(r) || unicode.IsControl(r) || unicode.IsPunct(r))
+}
+
+/*
+tokenize splits a given text into words.
+*/
+func tokenize(text string) []Token {
+	var ret []Token
+
+	for i, word := range strings.FieldsFunc(text, isWordSeparator) {
+		ret = append(ret, Token{word, uint64(i + 1)})
+	}
+
+	return ret
+}
+
+/*
+mapFilter creates a filter which changes every word with a given function.
+Words which become empty are removed.
+*/
+func mapFilter(f func(string) string) TokenFilter {
+	return func(tokens []Token) []Token {
+		ret := tokens[:0]
+
+		for _, t := range tokens {
+			if t.Word = f(t.Word); t.Word != "" {
+				ret = append(ret, t)
+			}
+		}
+
+		return ret
+	}
+}
+
+// Lowercase filter
+// ================
+
+/*
+newLowercaseFilter creates a filter which converts all words to lower case.
+*/
+func newLowercaseFilter(param string) (TokenFilter, error) {
+	return mapFilter(strings.ToLower), nil
+}
+
+// Fold filter
+// ===========
+
+/*
+foldTable maps characters to their folded representation.
+*/
+var foldTable = make(map[rune]string)
+
+func init() {
+	for chars, folded := range map[string]string{
+		"àáâãäåāăą": "a", "ÀÁÂÃÄÅĀĂĄ": "A", "çćĉċč": "c", "ÇĆĈĊČ": "C",
+		"ďđð": "d", "ĎĐÐ": "D", "èéêëēĕėęě": "e", "ÈÉÊËĒĔĖĘĚ": "E",
+		"ĝğġģ": "g", "ĜĞĠĢ": "G", "ĥħ": "h", "ĤĦ": "H",
+		"ìíîïĩīĭįı": "i", "ÌÍÎÏĨĪĬĮİ": "I", "ĵ": "j", "Ĵ": "J", "ķ": "k", "Ķ": "K",
+		"ĺļľŀł": "l", "ĹĻĽĿŁ": "L", "ñńņňŉ": "n", "ÑŃŅŇ": "N",
+		"òóôõöøōŏő": "o", "ÒÓÔÕÖØŌŎŐ": "O", "ŕŗř": "r", "ŔŖŘ": "R",
+		"śŝşšș": "s", "ŚŜŞŠȘ": "S", "ţťŧț": "t", "ŢŤŦȚ": "T",
+		"ùúûüũūŭůűų": "u", "ÙÚÛÜŨŪŬŮŰŲ": "U", "ŵ": "w", "Ŵ": "W",
+		"ýÿŷ": "y", "ÝŸŶ": "Y", "źżž": "z", "ŹŻŽ": "Z",
+		"ß": "ss", "æ": "ae", "Æ": "AE", "œ": "oe", "Œ": "OE", "þ": "th", "Þ": "TH",
+	} {
+		for _, c := range chars {
+			foldTable[c] = folded
+		}
+	}
+}
+
+/*
+newFoldFilter creates a filter which removes diacritics and converts full
+width characters into their normal width representation.
+*/
+func newFoldFilter(param string) (TokenFilter, error) {
+	return mapFilter(func(word string) string {
+		var buf strings.Builder
+
+		for _, r := range word {
+			if folded, ok := foldTable[r]; ok {
+				buf.WriteString(folded)
+			} else if r >= 0xFF01 && r <= 0xFF5E {
+				buf.WriteRune(r - 0xFEE0)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+
+		return buf.String()
+	}), nil
+}
+
+// Stop word filter
+// ================
+
+/*
+stopWords are lists of stop words for different languages.
+*/
+var stopWords = map[string]string{
+	"en": "a an and are as at be but by for if in into is it no not of on or " +
+		"such that the their then there these they this to was will with",
+	"de": "aber als am an auch auf aus bei bin bis das dass dem den der des die " +
+		"du ein eine einem einen einer eines er es für hat im in ist mit nach " +
+		"nicht noch oder sich sie sind so und von vom war wie wir zu zum zur",
+	"fr": "au aux avec ce ces dans de des du elle en est et il ils je la le les " +
+		"leur lui mais me ne nous on ou par pas pour qu que qui sa se son sur ta " +
+		"te un une vous",
+	"es": "a al como con de del el en es la las lo los más no o para pero por " +
+		"que se sin su sus un una unas uno unos y",
+}
+
+/*
+newStopFilter creates a filter which removes stop words. The position of all
+other words is not changed.
+*/
+func newStopFilter(param string) (TokenFilter, error) {
+
+	if param == "" {
+		param = "en"
+	}
+
+	words, ok := stopWords[param]
+	if !ok {
+		return nil, &GraphError{ErrInvalidData, fmt.Sprint("Unknown stop word language: ", param)}
+	}
+
+	stop := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		stop[w] = true
+	}
+
+	return mapFilter(func(word string) string {
+		if stop[strings.ToLower(word)] {
+			return ""
+		}
+		return word
+	}), nil
+}
+
+// Stem filter
+// ===========
+
+/*
+newStemFilter creates a filter which reduces words to their stem.
+*/
+func newStemFilter(param string) (TokenFilter, error) {
+
+	if param != "" && param != "en" {
+		return nil, &GraphError{ErrInvalidData, fmt.Sprint("Unknown stemming language: ", param)}
+	}
+
+	return mapFilter(stemPorter), nil
+}
+
+// N-gram filter
+// =============
+
+/*
+newNgramFilter creates a filter which splits words into n-grams. All n-grams
+of a word have the position of the word. Words which are not longer than n
+are not changed.
+*/
+func newNgramFilter(param string) (TokenFilter, error) {
+	n := 3
+
+	if param != "" {
+		var err error
+
+		if n, err = strconv.Atoi(param); err != nil || n < 1 {
+			return nil, &GraphError{ErrInvalidData, fmt.Sprint("Invalid n-gram size: ", param)}
+		}
+	}
+
+	return func(tokens []Token) []Token {
+		var ret []Token
+
+		for _, t := range tokens {
+			runes := []rune(t.Word)
+
+			if len(runes) <= n {
+				ret = append(ret, t)
+				continue
+			}
+
+			for i := 0; i+n <= len(runes); i++ {
+				ret = append(ret, Token{string(runes[i : i+n]), t.Pos})
+			}
+		}
+
+		return ret
+	}, nil
+}
+
+// CJK filter
+// ==========
+
+/*
+isCJK checks if a given rune is a Chinese, Japanese or Korean character.
+*/
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+/*
+newCJKFilter creates a filter which splits Chinese, Japanese and Korean text
+into overlapping bigrams. These languages do not separate words with spaces.
+Every bigram and every other part of a word gets its own position. The
+positions of all following words are shifted accordingly.
+*/
+func newCJKFilter(param string) (TokenFilter, error) {
+	return func(tokens []Token) []Token {
+		var ret []Token
+		var shift uint64
+
+		for _, t := range tokens {
+			var parts []string
+			var run []rune
+
+			addRun := func() {
+				if len(run) > 0 && isCJK(run[0]) && len(run) > 1 {
+					for i := 0; i < len(run)-1; i++ {
+						parts = append(parts, string(run[i:i+2]))
+					}
+				} else if len(run) > 0 {
+					parts = append(parts, string(run))
+				}
+				run = nil
+			}
+
+			// Split the word into runs of CJK and other characters
+
+			for _, r := range t.Word {
+				if len(run) > 0 && isCJK(run[0]) != isCJK(r) {
+					addRun()
+				}
+				run = append(run, r)
+			}
+
+			addRun()
+
+			for i, p := range parts {
+				ret = append(ret, Token{p, t.Pos + shift + uint64(i)})
+			}
+
+			if len(parts) > 1 {
+				shift += uint64(len(parts) - 1)
+			}
+		}
+
+		return ret
+	}, nil
+}
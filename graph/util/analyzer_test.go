@@ -0,0 +1,204 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package util
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/hash"
+	"github.com/Fisch-Labs/FishDB/storage"
+)
+
+func TestAnalyzer(t *testing.T) {
+
+	analyze := func(spec string, text string) string {
+		a, err := NewAnalyzer(spec)
+		if err != nil {
+			return err.Error()
+		}
+		return fmt.Sprint(a.Analyze(text))
+	}
+
+	if res := analyze("", "The Quick, brown-fox"); res != "[{The 1} {Quick 2} {brown 3} {fox 4}]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := analyze(" lowercase , stop ", "The Quick, brown-fox"); res != "[{quick 2} {brown 3} {fox 4}]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := analyze("lowercase,fold", "Crème BRÛLÉE Straße ＡＢＣ"); res != "[{creme 1} {brulee 2} {strasse 3} {abc 4}]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := analyze("lowercase,stop:de,stem", "Die jumping foxes und connections"); res != "[{jump 2} {fox 3} {connect 5}]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := analyze("ngram", "to fish"); res != "[{to 1} {fis 2} {ish 2}]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := analyze("ngram:2", "ab"); res != "[{ab 1}]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := analyze("cjk", "東京都 abc東京 x"); res != "[{東京 1} {京都 2} {abc 3} {東京 4} {x 5}]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if a, _ := NewAnalyzer(" lowercase ,, stem:en"); a.String() != "lowercase,stem:en" {
+		t.Error("Unexpected result:", a.String())
+		return
+	}
+
+	// Test error cases
+
+	if res := analyze("lowercase,foo", ""); res != "GraphError: Invalid data (Unknown analyzer filter: foo)" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := analyze("stop:xx", ""); res != "GraphError: Invalid data (Unknown stop word language: xx)" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := analyze("stem:de", ""); res != "GraphError: Invalid data (Unknown stemming language: de)" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := analyze("ngram:0", ""); res != "GraphError: Invalid data (Invalid n-gram size: 0)" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+}
+
+func TestIndexManagerAnalyzer(t *testing.T) {
+	sm := storage.NewMemoryStorageManager("testsm")
+	htree, _ := hash.NewHTree(sm)
+
+	im := NewIndexManager(htree)
+
+	a, _ := NewAnalyzer("lowercase,fold,stop,stem")
+	im.SetAnalyzer("", a)
+
+	a, _ = NewAnalyzer("cjk")
+	im.SetAnalyzer("ja", a)
+
+	a, _ = NewAnalyzer("lowercase,ngram")
+	im.SetAnalyzer("code", a)
+
+	im.Index("1", map[string]string{
+		"text": "The Café is running the connections of the city",
+		"ja":   "東京都に住んでいます",
+		"code": "FishDB",
+	})
+
+	im.Index("2", map[string]string{
+		"text": "A cafe connected to the city",
+		"ja":   "京都に行きました",
+		"code": "GraphDB",
+	})
+
+	// Words are analyzed on indexing and on lookup
+
+	if res, err := im.LookupWord("text", "CAFÉS"); err != nil || fmt.Sprint(res) != "map[1:[2] 2:[2]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := im.LookupWord("text", "the"); err != nil || res != nil {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := im.Count("text", "connection"); err != nil || res != 2 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Stop words keep their position in phrases
+
+	if res, err := im.LookupPhrase("text", "connected to the city"); err != nil || fmt.Sprint(res) != "[1 2]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := im.LookupPhrase("text", "cafés connected"); err != nil || fmt.Sprint(res) != "[2]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := im.LookupPhrase("text", "connected city"); err != nil || len(res) != 0 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// CJK text is found by its bigrams
+
+	if res, err := im.LookupPhrase("ja", "京都"); err != nil || fmt.Sprint(res) != "[1 2]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := im.LookupPhrase("ja", "東京都"); err != nil || fmt.Sprint(res) != "[1]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// N-grams find parts of words
+
+	if res, err := im.LookupWord("code", "db"); err != nil || res != nil {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := im.LookupWord("code", "shdb"); err != nil || fmt.Sprint(res) != "map[1:[1]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := im.LookupWord("code", "phdb"); err != nil || fmt.Sprint(res) != "map[2:[1]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := im.Count("code", "xdb"); err != nil || res != 0 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Removing all values leaves an empty index
+
+	im.Deindex("1", map[string]string{
+		"text": "The Café is running the connections of the city",
+		"ja":   "東京都に住んでいます",
+		"code": "FishDB",
+	})
+
+	im.Deindex("2", map[string]string{
+		"text": "A cafe connected to the city",
+		"ja":   "京都に行きました",
+		"code": "GraphDB",
+	})
+
+	if res := countChildren(htree); res != 0 {
+		t.Error("Unexpected number of children:", res)
+		return
+	}
+}
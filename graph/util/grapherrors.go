@@ -84,4 +84,8 @@ var (
 	ErrReading     = errors.New("Could not read graph information")
 	ErrWriting     = errors.New("Could not write graph information")
 	ErrRule        = errors.New("Graph rule error")
+	ErrUnique      = errors.New("Unique constraint violation")
+	ErrConflict    = errors.New("Revision conflict")
+	ErrLocked      = errors.New("Could not lock partition")
+	ErrProtected   = errors.New("Protected system kind")
 )
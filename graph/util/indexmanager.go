@@ -15,12 +15,10 @@ import (
 	"math"
 	"sort"
 	"strings"
-	"unicode"
 
 	"github.com/Fisch-Labs/FishDB/hash"
 	"github.com/Fisch-Labs/Toolkit/bitutil"
 	"github.com/Fisch-Labs/Toolkit/sortutil"
-	"github.com/Fisch-Labs/Toolkit/stringutil"
 )
 
 /*
@@ -43,11 +41,48 @@ PrefixAttrHash is the prefix used for hashes of attribute values
 */
 const PrefixAttrHash = "\x01"
 
+/*
+PrefixAttrStats is the prefix used for the statistics of an attribute
+*/
+const PrefixAttrStats = "\x02"
+
+/*
+PrefixAttrLength is the prefix used for the word counts of attribute values
+*/
+const PrefixAttrLength = "\x03"
+
+/*
+BM25K1 is the term frequency saturation parameter used for relevance scores.
+*/
+var BM25K1 = 1.2
+
+/*
+BM25B is the document length normalization parameter used for relevance scores.
+*/
+var BM25B = 0.75
+
+/*
+Index modes which define how an attribute is indexed
+*/
+const (
+	IndexModeNone     = "none"     // Attribute is not indexed
+	IndexModeValue    = "value"    // Only whole values are indexed
+	IndexModeFullText = "fulltext" // Whole values and all words are indexed (default)
+	IndexModeRange    = "range"    // Whole values are indexed and can be looked up by range
+)
+
+/*
+IndexModes is a list of all valid index modes.
+*/
+var IndexModes = []string{IndexModeNone, IndexModeValue, IndexModeFullText, IndexModeRange}
+
 /*
 IndexManager data structure
 */
 type IndexManager struct {
-	htree *hash.HTree // Persistent HTree which stores this index
+	htree     *hash.HTree          // Persistent HTree which stores this index
+	analyzers map[string]*Analyzer // Analyzers for attributes
+	modes     map[string]string    // Index modes of attributes
 }
 
 /*
@@ -57,18 +92,103 @@ type indexEntry struct {
 	WordPos map[string]string // Node id to word position array
 }
 
+/*
+indexStats data structure
+*/
+type indexStats struct {
+	Docs  uint64 // Number of indexed values of an attribute
+	Words uint64 // Total number of words of all indexed values
+}
+
 func init() {
 
-	// Make sure we can use indexEntry in a gob operation
+	// Make sure we can use indexEntry and indexStats in a gob operation
 
 	gob.Register(&indexEntry{})
+	gob.Register(&indexStats{})
 }
 
 /*
 NewIndexManager creates a new index manager instance.
 */
 func NewIndexManager(htree *hash.HTree) *IndexManager {
-	return &IndexManager{htree}
+	return &IndexManager{htree, make(map[string]*Analyzer), make(map[string]string)}
+}
+
+/*
+SetIndexMode sets the index mode of an attribute (see IndexModes). If the
+attribute is empty then the mode is used for all attributes which have no mode
+of their own. Attributes without a mode are indexed in full text mode. Changing
+the mode does not change existing index entries.
+*/
+func (im *IndexManager) SetIndexMode(attr string, mode string) {
+	im.modes[attr] = mode
+}
+
+/*
+IndexMode returns the index mode of an attribute.
+*/
+func (im *IndexManager) IndexMode(attr string) string {
+	if mode, ok := im.modes[attr]; ok {
+		return mode
+	} else if mode, ok := im.modes[""]; ok {
+		return mode
+	}
+	return IndexModeFullText
+}
+
+/*
+SetAnalyzer sets the analyzer for the word index of an attribute. If the
+attribute is empty then the analyzer is used for all attributes which have no
+analyzer of their own. Attributes without an analyzer are split into lower
+case words (see CaseSensitiveWordIndex). The hash index of whole values is not
+affected by analyzers.
+*/
+func (im *IndexManager) SetAnalyzer(attr string, analyzer *Analyzer) {
+	im.analyzers[attr] = analyzer
+}
+
+/*
+analyzer returns the analyzer of an attribute or nil if the attribute uses
+the default analysis.
+*/
+func (im *IndexManager) analyzer(attr string) *Analyzer {
+	if a, ok := im.analyzers[attr]; ok {
+		return a
+	}
+	return im.analyzers[""]
+}
+
+/*
+analyze splits a text of an attribute into tokens.
+*/
+func (im *IndexManager) analyze(attr, text string) []Token {
+
+	if a := im.analyzer(attr); a != nil {
+		return a.Analyze(text)
+	}
+
+	tokens := tokenize(text)
+
+	if !CaseSensitiveWordIndex {
+		for i, t := range tokens {
+			tokens[i].Word = strings.ToLower(t.Word)
+		}
+	}
+
+	return tokens
+}
+
+/*
+words extracts all words of a text of an attribute.
+*/
+func (im *IndexManager) words(attr, text string) *wordSet {
+
+	if a := im.analyzer(attr); a != nil {
+		return a.words(text)
+	}
+
+	return extractWords(text)
 }
 
 /*
@@ -99,44 +219,53 @@ LookupPhrase finds all nodes where an attribute contains a certain phrase. This
 call returns a list of node keys which contain the phrase at least once.
 */
 func (im *IndexManager) LookupPhrase(attr, phrase string) ([]string, error) {
+	ret, _, err := im.lookupTokens(attr, im.analyze(attr, phrase))
+	return ret, err
+}
 
-	// Chop up the phrase into words
-
-	phraseWords := strings.FieldsFunc(phrase, func(r rune) bool {
-		return !stringutil.IsAlphaNumeric(string(r)) && (unicode.IsSpace(r) || unicode.IsControl(r) || unicode.IsPunct(r))
-	})
+/*
+lookupTokens finds all nodes where an attribute contains a certain sequence of
+tokens. The distances between the token positions must match. This call
+returns a list of node keys and the lookup results of all tokens.
+*/
+func (im *IndexManager) lookupTokens(attr string, tokens []Token) ([]string, []map[string][]uint64, error) {
 
-	// Lookup every phrase word
+	// Lookup every token and calculate the distance to the previous token
 
-	results := make([]map[string][]uint64, len(phraseWords))
+	results := make([]map[string][]uint64, len(tokens))
+	gaps := make([]uint64, len(tokens))
 
-	for i, phraseWord := range phraseWords {
+	for i, token := range tokens {
 
-		res, err := im.LookupWord(attr, phraseWord)
+		res, err := im.lookupWord(attr, token.Word)
 		if err != nil {
-			return nil, &GraphError{ErrIndexError, err.Error()}
+			return nil, nil, &GraphError{ErrIndexError, err.Error()}
 		}
 
 		results[i] = res
+
+		if i > 0 {
+			gaps[i] = token.Pos - tokens[i-1].Pos
+		}
 	}
 
 	if len(results) == 0 || len(results[0]) == 0 {
-		return nil, nil
+		return nil, results, nil
 	}
 
 	ret := make([]string, 0, len(results[0]))
 
 	// Go through all found nodes and try to find a path
 
-	path := make([]uint64, 0, len(phraseWords))
+	path := make([]uint64, 0, len(tokens))
 
 	for key := range results[0] {
 
 		path = path[:0]
 
-		foundWords := im.findPhrasePath(key, 0, path, phraseWords, results)
+		foundWords := im.findPhrasePath(key, 0, path, gaps, results)
 
-		if foundWords == len(phraseWords) {
+		if foundWords == len(tokens) {
 
 			// Add key to results if a path was found
 
@@ -148,14 +277,15 @@ func (im *IndexManager) LookupPhrase(attr, phrase string) ([]string, error) {
 
 	sort.StringSlice(ret).Sort()
 
-	return ret, nil
+	return ret, results, nil
 }
 
 /*
-findPhrasePath tries to find a phrase in a given set of lookup results.
+findPhrasePath tries to find a phrase in a given set of lookup results. The
+gaps are the expected distances between the positions of consecutive words.
 */
 func (im *IndexManager) findPhrasePath(key string, index int, path []uint64,
-	phraseWords []string, results []map[string][]uint64) int {
+	gaps []uint64, results []map[string][]uint64) int {
 
 	// Get the results for this word index
 
@@ -175,14 +305,14 @@ func (im *IndexManager) findPhrasePath(key string, index int, path []uint64,
 
 				// Check if the position array contains the expected next word position
 
-				if pos == path[index-1]+1 {
+				if pos == path[index-1]+gaps[index] {
 					path = append(path, pos)
 					break
 				}
 
 				// Abort if the expected position cannot be there
 
-				if pos > path[index-1] {
+				if pos > path[index-1]+gaps[index] {
 					return len(path)
 				}
 
@@ -191,11 +321,11 @@ func (im *IndexManager) findPhrasePath(key string, index int, path []uint64,
 			// Do the next iteration if a position was found and
 			// there are more words in the phrase to match
 
-			if len(path) == index+1 && index < len(phraseWords)-1 {
-				return im.findPhrasePath(key, index+1, path, phraseWords, results)
+			if len(path) == index+1 && index < len(gaps)-1 {
+				return im.findPhrasePath(key, index+1, path, gaps, results)
 			}
 
-			return index + 1
+			return len(path)
 
 		}
 
@@ -208,15 +338,15 @@ func (im *IndexManager) findPhrasePath(key string, index int, path []uint64,
 
 			// Test if the phrase only contained one word
 
-			if len(phraseWords) == 1 {
+			if len(gaps) == 1 {
 				return 1
 			}
 
 			// Find the rest
 
-			ret := im.findPhrasePath(key, 1, path, phraseWords, results)
+			ret := im.findPhrasePath(key, 1, path, gaps, results)
 
-			if ret == len(phraseWords) {
+			if ret == len(gaps) {
 				return ret
 			}
 		}
@@ -227,18 +357,40 @@ func (im *IndexManager) findPhrasePath(key string, index int, path []uint64,
 
 /*
 LookupWord finds all nodes where an attribute contains a certain word. This call returns
-a map which maps node key to a list of word positions.
+a map which maps node key to a list of word positions. If the analyzer of the
+attribute splits the word into several tokens (e.g. n-grams) then all tokens must
+be found and the positions of the first token are returned.
 */
 func (im *IndexManager) LookupWord(attr, word string) (map[string][]uint64, error) {
-	var s string
 
-	if CaseSensitiveWordIndex {
-		s = word
-	} else {
-		s = strings.ToLower(word)
+	tokens := im.analyze(attr, word)
+
+	if len(tokens) == 0 {
+		return nil, nil
+	} else if len(tokens) == 1 {
+		return im.lookupWord(attr, tokens[0].Word)
+	}
+
+	keys, results, err := im.lookupTokens(attr, tokens)
+	if err != nil || len(keys) == 0 {
+		return nil, err
 	}
 
-	entry, err := im.htree.Get([]byte(PrefixAttrWord + attr + s))
+	ret := make(map[string][]uint64)
+
+	for _, key := range keys {
+		ret[key] = results[0][key]
+	}
+
+	return ret, nil
+}
+
+/*
+lookupWord looks up a single analyzed word in the index.
+*/
+func (im *IndexManager) lookupWord(attr, word string) (map[string][]uint64, error) {
+
+	entry, err := im.htree.Get([]byte(PrefixAttrWord + attr + word))
 
 	if err != nil {
 		return nil, &GraphError{ErrIndexError, err.Error()}
@@ -296,19 +448,59 @@ func (im *IndexManager) LookupValue(attr, value string) ([]string, error) {
 	return ret, nil
 }
 
+/*
+LookupRange finds all nodes where an attribute has a value between lo and hi
+(inclusive). The values can be numbers or dates. An empty bound is unbounded.
+Only attributes which are indexed in range mode can be looked up. This call
+returns a list of node keys ordered by value.
+*/
+func (im *IndexManager) LookupRange(attr, lo, hi string) ([]string, error) {
+	lonum, hinum := math.Inf(-1), math.Inf(1)
+
+	for i, bound := range []string{lo, hi} {
+
+		if bound == "" {
+			continue
+		}
+
+		num, ok := RangeValue(bound)
+		if !ok {
+			return nil, &GraphError{ErrInvalidData, fmt.Sprint("Range bound is not a number or date: ", bound)}
+		}
+
+		if i == 0 {
+			lonum = num
+		} else {
+			hinum = num
+		}
+	}
+
+	tree, err := newRangeTree(im.htree, attr)
+	if err != nil {
+		return nil, &GraphError{ErrIndexError, err.Error()}
+	}
+
+	ret, err := tree.lookup(lonum, hinum)
+	if err != nil {
+		return nil, &GraphError{ErrIndexError, err.Error()}
+	}
+
+	return ret, nil
+}
+
 /*
 Count returns the number of found nodes for a given word in a given attribute.
 */
 func (im *IndexManager) Count(attr, word string) (int, error) {
-	var s string
 
-	if CaseSensitiveWordIndex {
-		s = word
-	} else {
-		s = strings.ToLower(word)
+	tokens := im.analyze(attr, word)
+
+	if len(tokens) != 1 {
+		res, err := im.LookupWord(attr, word)
+		return len(res), err
 	}
 
-	entry, err := im.htree.Get([]byte(PrefixAttrWord + attr + s))
+	entry, err := im.htree.Get([]byte(PrefixAttrWord + attr + tokens[0].Word))
 
 	if err != nil {
 		return 0, &GraphError{ErrIndexError, err.Error()}
@@ -319,6 +511,105 @@ func (im *IndexManager) Count(attr, word string) (int, error) {
 	return len(entry.(*indexEntry).WordPos), nil
 }
 
+/*
+Scores calculates the BM25 relevance scores of all nodes where an attribute
+contains at least one word of a given query. This call returns a map which
+maps node key to score.
+*/
+func (im *IndexManager) Scores(attr, query string) (map[string]float64, error) {
+	return im.scores(attr, query, "")
+}
+
+/*
+Score calculates the BM25 relevance score of a single node for a given query.
+The score is 0 if the attribute of the node contains no word of the query.
+*/
+func (im *IndexManager) Score(attr, query, key string) (float64, error) {
+	res, err := im.scores(attr, query, key)
+	return res[key], err
+}
+
+/*
+scores calculates BM25 relevance scores. Only the given key is scored if it
+is not empty.
+*/
+func (im *IndexManager) scores(attr, query, key string) (map[string]float64, error) {
+	var docs, avgLen float64
+
+	ret := make(map[string]float64)
+
+	// Get the statistics of the attribute
+
+	obj, err := im.htree.Get([]byte(PrefixAttrStats + attr))
+	if err != nil {
+		return nil, &GraphError{ErrIndexError, err.Error()}
+	}
+
+	if obj != nil {
+		stats := obj.(*indexStats)
+
+		docs = float64(stats.Docs)
+		if stats.Docs > 0 {
+			avgLen = float64(stats.Words) / docs
+		}
+	}
+
+	for word := range im.words(attr, query).set {
+
+		entry, err := im.htree.Get([]byte(PrefixAttrWord + attr + word))
+		if err != nil {
+			return nil, &GraphError{ErrIndexError, err.Error()}
+		} else if entry == nil {
+			continue
+		}
+
+		wordPos := entry.(*indexEntry).WordPos
+
+		// Indexes which were created without statistics are scored on a
+		// best effort basis
+
+		df := float64(len(wordPos))
+		n := math.Max(docs, df)
+
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+		for k, l := range wordPos {
+
+			if key != "" && k != key {
+				continue
+			}
+
+			tf := float64(len(bitutil.UnpackList(l)))
+
+			dl, err := im.valueLength(attr, k)
+			if err != nil {
+				return nil, &GraphError{ErrIndexError, err.Error()}
+			}
+
+			norm := 1.0
+			if avgLen > 0 && dl > 0 {
+				norm = 1 - BM25B + BM25B*dl/avgLen
+			}
+
+			ret[k] += idf * tf * (BM25K1 + 1) / (tf + BM25K1*norm)
+		}
+	}
+
+	return ret, nil
+}
+
+/*
+valueLength returns the number of words in the indexed value of an attribute.
+*/
+func (im *IndexManager) valueLength(attr, key string) (float64, error) {
+	obj, err := im.htree.Get([]byte(PrefixAttrLength + attr + "\x00" + key))
+	if err != nil || obj == nil {
+		return 0, err
+	}
+
+	return float64(obj.(uint64)), nil
+}
+
 /*
 updateIndex updates the index for a specific object. Depending on the
 new and old arguments being set a given object is either indexed/added
@@ -363,25 +654,35 @@ func (im *IndexManager) updateIndex(key string, newObj map[string]string,
 	for attr := range attrMap {
 		var newwords, toadd, oldwords, toremove *wordSet
 
+		mode := im.IndexMode(attr)
+		if mode == IndexModeNone {
+			continue
+		}
+
+		fulltext := mode == IndexModeFullText
+
 		newval, newok := newObj[attr]
 		oldval, oldok := oldObj[attr]
 
-		// Calculate which words to add or remove
+		// Calculate which words to add or remove - only full text attributes
+		// have words
 
 		newwords = emptyws
 		oldwords = emptyws
 
-		if newok {
-			newwords = extractWords(newval)
+		if newok && fulltext {
+			newwords = im.words(attr, newval)
 		}
 
+		newlen := newwords.Len()
+
 		// At this point we have only words to add
 
 		toadd = newwords
 		toremove = emptyws
 
-		if oldok {
-			oldwords = extractWords(oldval)
+		if oldok && fulltext {
+			oldwords = im.words(attr, oldval)
 
 			if !oldwords.Empty() && !newwords.Empty() {
 
@@ -415,6 +716,12 @@ func (im *IndexManager) updateIndex(key string, newObj map[string]string,
 			}
 		}
 
+		// Update statistics
+
+		if err := im.updateStats(key, attr, newok && fulltext, newlen); err != nil {
+			return &GraphError{ErrIndexError, err.Error()}
+		}
+
 		// Update hash lookup
 
 		if newok && oldok {
@@ -443,11 +750,111 @@ func (im *IndexManager) updateIndex(key string, newObj map[string]string,
 				return &GraphError{ErrIndexError, err.Error()}
 			}
 		}
+
+		// Update range index
+
+		if mode == IndexModeRange {
+			if err := im.updateRange(key, attr, newval, newok, oldval, oldok); err != nil {
+				return &GraphError{ErrIndexError, err.Error()}
+			}
+		}
 	}
 
 	return nil
 }
 
+/*
+updateRange updates the range index entry of an attribute value. Values which
+are neither numbers nor dates are not part of the range index.
+*/
+func (im *IndexManager) updateRange(key string, attr string, newval string, newok bool,
+	oldval string, oldok bool) error {
+
+	newnum, newok := rangeValueIf(newval, newok)
+	oldnum, oldok := rangeValueIf(oldval, oldok)
+
+	if (!newok && !oldok) || (newok && oldok && newnum == oldnum) {
+		return nil
+	}
+
+	tree, err := newRangeTree(im.htree, attr)
+
+	if err == nil && oldok {
+		err = tree.remove(oldnum, key)
+	}
+
+	if err == nil && newok {
+		err = tree.insert(newnum, key)
+	}
+
+	return err
+}
+
+/*
+rangeValueIf converts a value into a value of the range index if it is set.
+*/
+func rangeValueIf(val string, ok bool) (float64, bool) {
+	if !ok {
+		return 0, false
+	}
+	return RangeValue(val)
+}
+
+/*
+updateStats updates the statistics of an attribute and the stored word count
+of the attribute value of a given key. The stored word count is used to undo
+the previous update so that indexing the same value twice does not skew the
+statistics.
+*/
+func (im *IndexManager) updateStats(key string, attr string, newok bool, newlen uint64) error {
+	var stats *indexStats
+
+	statskey := []byte(PrefixAttrStats + attr)
+	lenkey := []byte(PrefixAttrLength + attr + "\x00" + key)
+
+	oldlen, err := im.htree.Get(lenkey)
+	if err != nil || (oldlen == nil && !newok) {
+		return err
+	}
+
+	obj, err := im.htree.Get(statskey)
+	if err != nil {
+		return err
+	}
+
+	if obj == nil {
+		stats = &indexStats{}
+	} else {
+		stats = obj.(*indexStats)
+	}
+
+	if oldlen != nil && stats.Docs > 0 {
+		stats.Docs--
+		stats.Words -= oldlen.(uint64)
+	}
+
+	if newok {
+		stats.Docs++
+		stats.Words += newlen
+
+		_, err = im.htree.Put(lenkey, newlen)
+
+	} else {
+
+		_, err = im.htree.Remove(lenkey)
+	}
+
+	if err == nil {
+		if stats.Docs == 0 {
+			_, err = im.htree.Remove(statskey)
+		} else {
+			_, err = im.htree.Put(statskey, stats)
+		}
+	}
+
+	return err
+}
+
 /*
 addIndexHashEntry add a hash entry from the index. A hash entry stores a whole
 value as MD5 sum.
@@ -666,8 +1073,18 @@ func (im *IndexManager) String() string {
 	for it.HasNext() {
 		key, value := it.Next()
 
+		entry, ok := value.(*indexEntry)
+
+		if !ok {
+
+			// Statistics entries are written as they are
+
+			buf.WriteString(fmt.Sprintf("    %v%q %v\n", key[0], string(key[1:]), value))
+			continue
+		}
+
 		posmap := make(map[string][]uint64)
-		for k, v := range value.(*indexEntry).WordPos {
+		for k, v := range entry.WordPos {
 			posmap[k] = bitutil.UnpackList(v)
 		}
 
@@ -703,7 +1120,7 @@ func extractWords(s string) *wordSet {
 
 	for i, rune := range text {
 
-		if !stringutil.IsAlphaNumeric(string(rune)) && (unicode.IsSpace(rune) || unicode.IsControl(rune) || unicode.IsPunct(rune)) {
+		if isWordSeparator(rune) {
 
 			if wstart >= 0 {
 				ws.Add(text[wstart:i], pos+1)
@@ -803,6 +1220,19 @@ func (ws *wordSet) Empty() bool {
 	return len(ws.set) == 0
 }
 
+/*
+Len returns the number of word positions in this word set.
+*/
+func (ws *wordSet) Len() uint64 {
+	var ret uint64
+
+	for _, pos := range ws.set {
+		ret += uint64(len(pos))
+	}
+
+	return ret
+}
+
 /*
 Has checks if this word set has a certain word.
 */
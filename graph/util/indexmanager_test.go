@@ -67,7 +67,7 @@ func TestIndexManager(t *testing.T) {
 		return
 	}
 
-	for i := 0; i < 6; i++ {
+	for i := 0; i < 10; i++ {
 		sm.AccessMap[uint64(i)] = storage.AccessCacheAndFetchError
 	}
 
@@ -86,7 +86,7 @@ func TestIndexManager(t *testing.T) {
 		return
 	}
 
-	for i := 0; i < 6; i++ {
+	for i := 0; i < 10; i++ {
 		delete(sm.AccessMap, uint64(i))
 	}
 
@@ -99,7 +99,7 @@ func TestIndexManager(t *testing.T) {
 		return
 	}
 
-	if res := countChildren(htree); res != 5 {
+	if res := countChildren(htree); res != 9 {
 		t.Error("Unexpected number of children:", res)
 		return
 	}
@@ -252,7 +252,7 @@ func TestUpdateIndex(t *testing.T) {
 		return
 	}
 
-	if res := countChildren(htree); res != 5 {
+	if res := countChildren(htree); res != 9 {
 		t.Error("Unexpected number of children:", res)
 		return
 	}
@@ -261,7 +261,7 @@ func TestUpdateIndex(t *testing.T) {
 
 	im.updateIndex("123", obj1, obj2)
 
-	if res := countChildren(htree); res != 5 {
+	if res := countChildren(htree); res != 9 {
 		t.Error("Unexpected number of children:", res)
 		return
 	}
@@ -759,10 +759,183 @@ func TestIndexManagerString(t *testing.T) {
 
 	if res := im.String(); res != "IndexManager: 1\n"+
 		"    1\"aaa\\b\\xf8\\xe0&\\fdA\\x85\\x10\\xce\\xfb+\\x06\\xee\\xe5\\xcd\" map[testkey:[]]\n"+
-		"    1\"aaabbb\" map[testkey:[1]]\n" && res != "IndexManager: 1\n"+
+		"    3\"aaa\\x00testkey\" 1\n"+
 		"    1\"aaabbb\" map[testkey:[1]]\n"+
-		"    2\"aaa\\b\\xf8\\xe0&\\fdA\\x85\\x10\\xce\\xfb+\\x06\\xee\\xe5\\xcd\" map[testkey:[]]\n" {
+		"    2\"aaa\" &{1 1}\n" {
 		t.Error("Unexpected string output:", res)
 		return
 	}
 }
+
+func TestIndexModes(t *testing.T) {
+	sm := storage.NewMemoryStorageManager("testsm")
+	htree, _ := hash.NewHTree(sm)
+
+	im := NewIndexManager(htree)
+
+	im.SetIndexMode("", IndexModeValue)
+	im.SetIndexMode("text", IndexModeFullText)
+	im.SetIndexMode("blob", IndexModeNone)
+
+	im.Index("1", map[string]string{
+		"text": "Hello world",
+		"name": "Hello world",
+		"blob": "Hello world",
+	})
+
+	// Only full text attributes have words
+
+	if res, err := im.LookupWord("text", "hello"); err != nil || fmt.Sprint(res) != "map[1:[1]]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := im.LookupWord("name", "hello"); err != nil || res != nil {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := im.LookupValue("name", "Hello world"); err != nil || fmt.Sprint(res) != "[1]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := im.LookupValue("blob", "Hello world"); err != nil || len(res) != 0 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := im.Scores("name", "hello"); err != nil || len(res) != 0 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	im.Reindex("1", map[string]string{
+		"text": "Hello",
+		"name": "Hello",
+		"blob": "Hello",
+	}, map[string]string{
+		"text": "Hello world",
+		"name": "Hello world",
+		"blob": "Hello world",
+	})
+
+	if res, err := im.LookupValue("name", "hello"); err != nil || fmt.Sprint(res) != "[1]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := im.LookupWord("text", "world"); err != nil || res != nil {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	im.Deindex("1", map[string]string{
+		"text": "Hello",
+		"name": "Hello",
+		"blob": "Hello",
+	})
+
+	// The index should be empty now
+
+	if it := hash.NewHTreeIterator(htree); it.HasNext() {
+		k, v := it.Next()
+		t.Error("Unexpected index entry:", string(k), v)
+		return
+	}
+}
+
+func TestIndexScores(t *testing.T) {
+	sm := storage.NewMemoryStorageManager("testsm")
+	htree, _ := hash.NewHTree(sm)
+
+	im := NewIndexManager(htree)
+
+	if res, err := im.Scores("text", "fish"); err != nil || len(res) != 0 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	im.Index("a", map[string]string{"text": "The fish swims in the pond"})
+	im.Index("b", map[string]string{"text": "Fish fish fish"})
+	im.Index("c", map[string]string{"text": "A bird sits on a very long branch of a very old tree"})
+	im.Index("d", map[string]string{"text": "The bird and the fish"})
+
+	// Indexing the same value twice does not change the statistics
+
+	im.Index("d", map[string]string{"text": "The bird and the fish"})
+
+	if res, _ := htree.Get([]byte(PrefixAttrStats + "text")); fmt.Sprint(res) != "&{4 27}" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	res, err := im.Scores("text", "FISH")
+	if err != nil || len(res) != 3 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Higher term frequency and shorter values score higher
+
+	if !(res["b"] > res["d"] && res["d"] > res["a"]) {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// Rare words score higher than common words
+
+	res, _ = im.Scores("text", "bird fish")
+
+	if !(res["d"] > res["c"] && res["c"] > res["a"]) {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if score, err := im.Score("text", "bird fish", "d"); err != nil || score != res["d"] {
+		t.Error("Unexpected result:", score, err)
+		return
+	}
+
+	if score, err := im.Score("text", "pond", "d"); err != nil || score != 0 {
+		t.Error("Unexpected result:", score, err)
+		return
+	}
+
+	// Statistics follow updates and removals
+
+	im.Reindex("b", map[string]string{"text": "Fish"}, map[string]string{"text": "Fish fish fish"})
+
+	if res, _ := htree.Get([]byte(PrefixAttrStats + "text")); fmt.Sprint(res) != "&{4 25}" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	im.Deindex("a", map[string]string{"text": "The fish swims in the pond"})
+	im.Deindex("b", map[string]string{"text": "Fish"})
+	im.Deindex("c", map[string]string{"text": "A bird sits on a very long branch of a very old tree"})
+	im.Deindex("d", map[string]string{"text": "The bird and the fish"})
+
+	if res := countChildren(htree); res != 0 {
+		t.Error("Unexpected number of children:", res)
+		return
+	}
+
+	// Test error case
+
+	sm = storage.NewMemoryStorageManager("testsm")
+	htree, _ = hash.NewHTree(sm)
+
+	im = NewIndexManager(htree)
+
+	im.Index("a", map[string]string{"text": "fish"})
+
+	for i := 0; i < 10; i++ {
+		sm.AccessMap[uint64(i)] = storage.AccessCacheAndFetchError
+	}
+
+	if _, err := im.Scores("text", "fish"); err == nil || !strings.Contains(err.Error(), "Slot not found") {
+		t.Error("Unexpected result:", err)
+		return
+	}
+}
@@ -7,7 +7,10 @@
 
 package util
 
-import "encoding/binary"
+import (
+	"encoding/binary"
+	"sync"
+)
 
 /*
 PrefixCode is the prefix for entries storing codes
@@ -39,13 +42,22 @@ NamesManager data structure
 */
 type NamesManager struct {
 	nameDB map[string]string // Database storing names
+	mutex  sync.Locker       // Mutex to protect the names database
 }
 
 /*
 NewNamesManager creates a new names manager instance.
 */
 func NewNamesManager(nameDB map[string]string) *NamesManager {
-	return &NamesManager{nameDB}
+	return &NamesManager{nameDB, &sync.Mutex{}}
+}
+
+/*
+NewSharedNamesManager creates a new names manager instance which shares its
+names database and the mutex protecting it with other writers.
+*/
+func NewSharedNamesManager(nameDB map[string]string, mutex sync.Locker) *NamesManager {
+	return &NamesManager{nameDB, mutex}
 }
 
 /*
@@ -82,6 +94,9 @@ func (gs *NamesManager) Decode16(val string) string {
 encode encodes a name to a code.
 */
 func (gs *NamesManager) encode(prefix string, name string, create bool) string {
+	gs.mutex.Lock()
+	defer gs.mutex.Unlock()
+
 	codekey := string(PrefixCode) + prefix + name
 
 	code, ok := gs.nameDB[codekey]
@@ -108,6 +123,9 @@ func (gs *NamesManager) encode(prefix string, name string, create bool) string {
 decode decodes a name from a code.
 */
 func (gs *NamesManager) decode(prefix string, code string) string {
+	gs.mutex.Lock()
+	defer gs.mutex.Unlock()
+
 	namekey := string(PrefixName) + prefix + code
 
 	return gs.nameDB[namekey]
@@ -0,0 +1,544 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package util
+
+import (
+	"encoding/gob"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fisch-Labs/FishDB/hash"
+)
+
+/*
+PrefixAttrRangeMeta is the prefix used for the meta data entry of a range index
+*/
+const PrefixAttrRangeMeta = "\x04"
+
+/*
+PrefixAttrRangePage is the prefix used for the pages of a range index
+*/
+const PrefixAttrRangePage = "\x05"
+
+/*
+RangeIndexPageSize is the maximum number of entries of a single page of the
+range index.
+*/
+var RangeIndexPageSize = 64
+
+/*
+rangeDateLayouts are the date formats which are understood by the range index.
+*/
+var rangeDateLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05", "2006-01-02"}
+
+/*
+rangeIndexMeta data structure
+*/
+type rangeIndexMeta struct {
+	Root   uint64 // Id of the root page (0 if the tree is empty)
+	NextID uint64 // Next free page id
+	Count  uint64 // Number of entries in the tree
+}
+
+/*
+rangeIndexPage data structure
+
+A page is either a leaf page which holds entries or an inner page which holds
+separators and child pages. The entries of child i are smaller than separator i
+and the entries of child i+1 are greater or equal. Entries are ordered by value
+and key.
+*/
+type rangeIndexPage struct {
+	Values   []float64 // Values of all entries or separators
+	Keys     []string  // Keys of all entries or separators
+	Children []uint64  // Child pages (inner pages only)
+	Prev     uint64    // Previous leaf page (leaf pages only)
+	Next     uint64    // Next leaf page (leaf pages only)
+}
+
+func init() {
+
+	// Make sure we can use the range index structures in a gob operation
+
+	gob.Register(&rangeIndexMeta{})
+	gob.Register(&rangeIndexPage{})
+}
+
+/*
+RangeValue converts a given string into a value of the range index. Numbers
+are used as they are and dates are converted into seconds since 1970-01-01 UTC.
+Returns false if the string is neither a number nor a date.
+*/
+func RangeValue(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+
+	if num, err := strconv.ParseFloat(s, 64); err == nil && !math.IsNaN(num) {
+		return num, true
+	}
+
+	for _, layout := range rangeDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return float64(t.Unix()) + float64(t.Nanosecond())/1e9, true
+		}
+	}
+
+	return 0, false
+}
+
+/*
+rangeTree is a B+tree which stores the range index of an attribute. All pages
+are stored in the HTree of the index.
+*/
+type rangeTree struct {
+	htree *hash.HTree     // HTree which stores the pages
+	attr  string          // Attribute of the tree
+	meta  *rangeIndexMeta // Meta data of the tree
+}
+
+/*
+newRangeTree loads the range index of an attribute.
+*/
+func newRangeTree(htree *hash.HTree, attr string) (*rangeTree, error) {
+	meta := &rangeIndexMeta{0, 1, 0}
+
+	obj, err := htree.Get([]byte(PrefixAttrRangeMeta + attr))
+	if err != nil {
+		return nil, err
+	} else if obj != nil {
+		meta = obj.(*rangeIndexMeta)
+	}
+
+	return &rangeTree{htree, attr, meta}, nil
+}
+
+/*
+pageKey returns the HTree key of a page.
+*/
+func (rt *rangeTree) pageKey(id uint64) []byte {
+	return []byte(fmt.Sprint(PrefixAttrRangePage, rt.attr, "\x00", id))
+}
+
+/*
+page loads a page.
+*/
+func (rt *rangeTree) page(id uint64) (*rangeIndexPage, error) {
+
+	obj, err := rt.htree.Get(rt.pageKey(id))
+	if err != nil {
+		return nil, err
+	} else if obj == nil {
+		return nil, fmt.Errorf("Range index page %v of attribute %v is missing", id, rt.attr)
+	}
+
+	return obj.(*rangeIndexPage), nil
+}
+
+/*
+storePage stores a page.
+*/
+func (rt *rangeTree) storePage(id uint64, page *rangeIndexPage) error {
+	_, err := rt.htree.Put(rt.pageKey(id), page)
+	return err
+}
+
+/*
+removePage removes a page.
+*/
+func (rt *rangeTree) removePage(id uint64) error {
+	_, err := rt.htree.Remove(rt.pageKey(id))
+	return err
+}
+
+/*
+newPage stores a new page and returns its id.
+*/
+func (rt *rangeTree) newPage(page *rangeIndexPage) (uint64, error) {
+	id := rt.meta.NextID
+	rt.meta.NextID++
+	return id, rt.storePage(id, page)
+}
+
+/*
+storeMeta stores the meta data of the tree. The meta data is removed if the
+tree is empty.
+*/
+func (rt *rangeTree) storeMeta() error {
+	var err error
+
+	if rt.meta.Count == 0 {
+		_, err = rt.htree.Remove([]byte(PrefixAttrRangeMeta + rt.attr))
+	} else {
+		_, err = rt.htree.Put([]byte(PrefixAttrRangeMeta+rt.attr), rt.meta)
+	}
+
+	return err
+}
+
+/*
+compareRangeEntries compares two entries. Returns -1, 0 or 1.
+*/
+func compareRangeEntries(v1 float64, k1 string, v2 float64, k2 string) int {
+	if v1 < v2 {
+		return -1
+	} else if v1 > v2 {
+		return 1
+	}
+	return strings.Compare(k1, k2)
+}
+
+/*
+childIndex returns the index of the child of an inner page which contains a
+given entry.
+*/
+func (page *rangeIndexPage) childIndex(value float64, key string) int {
+	return sort.Search(len(page.Values), func(i int) bool {
+		return compareRangeEntries(page.Values[i], page.Keys[i], value, key) > 0
+	})
+}
+
+/*
+entryIndex returns the index of the first entry of a leaf page which is greater
+or equal to a given entry.
+*/
+func (page *rangeIndexPage) entryIndex(value float64, key string) int {
+	return sort.Search(len(page.Values), func(i int) bool {
+		return compareRangeEntries(page.Values[i], page.Keys[i], value, key) >= 0
+	})
+}
+
+/*
+findLeaf finds the leaf page which contains a given entry. Returns the id of
+the leaf page, the page itself and the ids and child indices of all inner
+pages on the path from the root.
+*/
+func (rt *rangeTree) findLeaf(value float64, key string) (uint64, *rangeIndexPage, []uint64, []int, error) {
+	var path []uint64
+	var indices []int
+
+	id := rt.meta.Root
+
+	for {
+		page, err := rt.page(id)
+		if err != nil || len(page.Children) == 0 {
+			return id, page, path, indices, err
+		}
+
+		i := page.childIndex(value, key)
+
+		path = append(path, id)
+		indices = append(indices, i)
+
+		id = page.Children[i]
+	}
+}
+
+/*
+insert adds an entry to the tree. Adding an existing entry has no effect.
+*/
+func (rt *rangeTree) insert(value float64, key string) error {
+
+	if rt.meta.Root == 0 {
+
+		id, err := rt.newPage(&rangeIndexPage{[]float64{value}, []string{key}, nil, 0, 0})
+		if err == nil {
+			rt.meta.Root = id
+			rt.meta.Count++
+			err = rt.storeMeta()
+		}
+
+		return err
+	}
+
+	id, page, path, indices, err := rt.findLeaf(value, key)
+	if err != nil {
+		return err
+	}
+
+	i := page.entryIndex(value, key)
+
+	if i < len(page.Values) && compareRangeEntries(page.Values[i], page.Keys[i], value, key) == 0 {
+		return nil
+	}
+
+	page.Values = append(page.Values[:i], append([]float64{value}, page.Values[i:]...)...)
+	page.Keys = append(page.Keys[:i], append([]string{key}, page.Keys[i:]...)...)
+
+	rt.meta.Count++
+
+	// Split pages which are too big - the split goes up the path as long
+	// as parent pages become too big
+
+	for len(page.Values) > RangeIndexPageSize {
+		var sepValue float64
+		var sepKey string
+
+		mid := len(page.Values) / 2
+		right := &rangeIndexPage{}
+
+		if len(page.Children) == 0 {
+
+			right.Values = append([]float64(nil), page.Values[mid:]...)
+			right.Keys = append([]string(nil), page.Keys[mid:]...)
+			page.Values = page.Values[:mid:mid]
+			page.Keys = page.Keys[:mid:mid]
+
+			sepValue, sepKey = right.Values[0], right.Keys[0]
+
+		} else {
+
+			sepValue, sepKey = page.Values[mid], page.Keys[mid]
+
+			right.Values = append([]float64(nil), page.Values[mid+1:]...)
+			right.Keys = append([]string(nil), page.Keys[mid+1:]...)
+			right.Children = append([]uint64(nil), page.Children[mid+1:]...)
+			page.Values = page.Values[:mid:mid]
+			page.Keys = page.Keys[:mid:mid]
+			page.Children = page.Children[: mid+1 : mid+1]
+		}
+
+		rightID, err := rt.newPage(right)
+		if err != nil {
+			return err
+		}
+
+		if len(page.Children) == 0 {
+
+			// Link the new leaf page
+
+			if page.Next != 0 {
+				next, err := rt.page(page.Next)
+				if err != nil {
+					return err
+				}
+
+				next.Prev = rightID
+
+				if err := rt.storePage(page.Next, next); err != nil {
+					return err
+				}
+			}
+
+			right.Prev, right.Next = id, page.Next
+			page.Next = rightID
+
+			if err := rt.storePage(rightID, right); err != nil {
+				return err
+			}
+		}
+
+		if err := rt.storePage(id, page); err != nil {
+			return err
+		}
+
+		if len(path) == 0 {
+
+			// The root page was split - create a new root
+
+			rootID, err := rt.newPage(&rangeIndexPage{[]float64{sepValue}, []string{sepKey},
+				[]uint64{id, rightID}, 0, 0})
+
+			if err != nil {
+				return err
+			}
+
+			rt.meta.Root = rootID
+
+			return rt.storeMeta()
+		}
+
+		// Add the separator to the parent page
+
+		ci := indices[len(indices)-1]
+
+		id = path[len(path)-1]
+		path, indices = path[:len(path)-1], indices[:len(indices)-1]
+
+		if page, err = rt.page(id); err != nil {
+			return err
+		}
+
+		page.Values = append(page.Values[:ci], append([]float64{sepValue}, page.Values[ci:]...)...)
+		page.Keys = append(page.Keys[:ci], append([]string{sepKey}, page.Keys[ci:]...)...)
+		page.Children = append(page.Children[:ci+1], append([]uint64{rightID}, page.Children[ci+1:]...)...)
+	}
+
+	if err := rt.storePage(id, page); err != nil {
+		return err
+	}
+
+	return rt.storeMeta()
+}
+
+/*
+remove removes an entry from the tree. Removing an entry which does not exist
+has no effect. Pages which become empty are removed.
+*/
+func (rt *rangeTree) remove(value float64, key string) error {
+
+	if rt.meta.Root == 0 {
+		return nil
+	}
+
+	id, page, path, indices, err := rt.findLeaf(value, key)
+	if err != nil {
+		return err
+	}
+
+	i := page.entryIndex(value, key)
+
+	if i == len(page.Values) || compareRangeEntries(page.Values[i], page.Keys[i], value, key) != 0 {
+		return nil
+	}
+
+	page.Values = append(page.Values[:i], page.Values[i+1:]...)
+	page.Keys = append(page.Keys[:i], page.Keys[i+1:]...)
+
+	rt.meta.Count--
+
+	if len(page.Values) > 0 {
+
+		if err := rt.storePage(id, page); err != nil {
+			return err
+		}
+
+		return rt.storeMeta()
+	}
+
+	// Unlink and remove the empty leaf page
+
+	if page.Prev != 0 {
+		prev, err := rt.page(page.Prev)
+		if err == nil {
+			prev.Next = page.Next
+			err = rt.storePage(page.Prev, prev)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if page.Next != 0 {
+		next, err := rt.page(page.Next)
+		if err == nil {
+			next.Prev = page.Prev
+			err = rt.storePage(page.Next, next)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := rt.removePage(id); err != nil {
+		return err
+	}
+
+	if len(path) == 0 {
+		rt.meta.Root = 0
+	}
+
+	// Remove the page from its parents - parents which become empty are
+	// removed as well
+
+	for len(path) > 0 {
+
+		id, i = path[len(path)-1], indices[len(indices)-1]
+		path, indices = path[:len(path)-1], indices[:len(indices)-1]
+
+		if page, err = rt.page(id); err != nil {
+			return err
+		}
+
+		page.Children = append(page.Children[:i], page.Children[i+1:]...)
+
+		if len(page.Values) > 0 {
+			if i > 0 {
+				i--
+			}
+			page.Values = append(page.Values[:i], page.Values[i+1:]...)
+			page.Keys = append(page.Keys[:i], page.Keys[i+1:]...)
+		}
+
+		if len(page.Children) > 0 {
+			if err := rt.storePage(id, page); err != nil {
+				return err
+			}
+			break
+		}
+
+		if err := rt.removePage(id); err != nil {
+			return err
+		}
+
+		if len(path) == 0 {
+			rt.meta.Root = 0
+		}
+	}
+
+	// Remove root pages which have only a single child
+
+	for rt.meta.Root != 0 {
+
+		root, err := rt.page(rt.meta.Root)
+		if err != nil {
+			return err
+		} else if len(root.Children) != 1 {
+			break
+		}
+
+		if err := rt.removePage(rt.meta.Root); err != nil {
+			return err
+		}
+
+		rt.meta.Root = root.Children[0]
+	}
+
+	return rt.storeMeta()
+}
+
+/*
+lookup returns the keys of all entries with a value between lo and hi
+(inclusive). The keys are ordered by value.
+*/
+func (rt *rangeTree) lookup(lo float64, hi float64) ([]string, error) {
+	ret := make([]string, 0)
+
+	if rt.meta.Root == 0 || lo > hi {
+		return ret, nil
+	}
+
+	_, page, _, _, err := rt.findLeaf(lo, "")
+	if err != nil {
+		return nil, err
+	}
+
+	i := page.entryIndex(lo, "")
+
+	for {
+		for ; i < len(page.Values); i++ {
+			if page.Values[i] > hi {
+				return ret, nil
+			}
+			ret = append(ret, page.Keys[i])
+		}
+
+		if page.Next == 0 {
+			return ret, nil
+		}
+
+		if page, err = rt.page(page.Next); err != nil {
+			return nil, err
+		}
+
+		i = 0
+	}
+}
@@ -0,0 +1,253 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package util
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/hash"
+	"github.com/Fisch-Labs/FishDB/storage"
+)
+
+func TestRangeValue(t *testing.T) {
+
+	for _, test := range []struct {
+		in  string
+		val float64
+		ok  bool
+	}{
+		{"42", 42, true},
+		{" -1.5 ", -1.5, true},
+		{"1e3", 1000, true},
+		{"NaN", 0, false},
+		{"abc", 0, false},
+		{"", 0, false},
+		{"1970-01-02", 86400, true},
+		{"1970-01-01 00:01:00", 60, true},
+		{"1970-01-01T00:00:01.5Z", 1.5, true},
+		{"1970-01-01T01:00:00+01:00", 0, true},
+	} {
+		if val, ok := RangeValue(test.in); val != test.val || ok != test.ok {
+			t.Error("Unexpected result for", test.in, ":", val, ok)
+			return
+		}
+	}
+}
+
+func TestRangeTree(t *testing.T) {
+	sm := storage.NewMemoryStorageManager("testsm")
+	htree, _ := hash.NewHTree(sm)
+
+	oldPageSize := RangeIndexPageSize
+	RangeIndexPageSize = 4
+	defer func() {
+		RangeIndexPageSize = oldPageSize
+	}()
+
+	tree, _ := newRangeTree(htree, "attr")
+
+	if res, err := tree.lookup(math.Inf(-1), math.Inf(1)); err != nil || fmt.Sprint(res) != "[]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if err := tree.remove(1, "1"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Insert entries in random order - every value has two keys
+
+	rnd := rand.New(rand.NewSource(1))
+
+	for _, i := range rnd.Perm(200) {
+		tree, _ = newRangeTree(htree, "attr")
+
+		if err := tree.insert(float64(i/2), fmt.Sprint(i)); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	// Inserting an existing entry has no effect
+
+	tree.insert(5, "10")
+
+	if tree.meta.Count != 200 {
+		t.Error("Unexpected count:", tree.meta.Count)
+		return
+	}
+
+	if res, err := tree.lookup(5, 7); err != nil || fmt.Sprint(res) != "[10 11 12 13 14 15]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := tree.lookup(98.5, math.Inf(1)); err != nil || fmt.Sprint(res) != "[198 199]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := tree.lookup(math.Inf(-1), 0.5); err != nil || fmt.Sprint(res) != "[0 1]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := tree.lookup(7, 5); err != nil || len(res) != 0 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := tree.lookup(math.Inf(-1), math.Inf(1)); err != nil || len(res) != 200 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Remove entries in random order and check the remaining entries
+
+	perm := rnd.Perm(200)
+
+	for j, i := range perm {
+		tree, _ = newRangeTree(htree, "attr")
+
+		if err := tree.remove(float64(i/2), fmt.Sprint(i)); err != nil {
+			t.Error(err)
+			return
+		}
+
+		if j%50 == 0 {
+			res, err := tree.lookup(math.Inf(-1), math.Inf(1))
+			if err != nil || len(res) != 199-j {
+				t.Error("Unexpected result:", len(res), err)
+				return
+			}
+
+			for k := 1; k < len(res); k++ {
+				var v1, v2 int
+				fmt.Sscan(res[k-1], &v1)
+				fmt.Sscan(res[k], &v2)
+				if v1/2 > v2/2 {
+					t.Error("Unexpected order:", res)
+					return
+				}
+			}
+		}
+	}
+
+	// The tree should be empty now
+
+	if it := hash.NewHTreeIterator(htree); it.HasNext() {
+		k, v := it.Next()
+		t.Error("Unexpected index entry:", string(k), v)
+		return
+	}
+
+	// Test error cases
+
+	tree, _ = newRangeTree(htree, "attr")
+	tree.insert(1, "1")
+	tree.meta.Root = 99
+
+	if _, err := tree.lookup(0, 1); err == nil || err.Error() != "Range index page 99 of attribute attr is missing" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+}
+
+func TestIndexManagerRange(t *testing.T) {
+	sm := storage.NewMemoryStorageManager("testsm")
+	htree, _ := hash.NewHTree(sm)
+
+	im := NewIndexManager(htree)
+
+	im.SetIndexMode("age", IndexModeRange)
+	im.SetIndexMode("born", IndexModeRange)
+
+	im.Index("1", map[string]string{"age": "30", "born": "1990-05-01", "name": "30"})
+	im.Index("2", map[string]string{"age": "25", "born": "1995-01-01"})
+	im.Index("3", map[string]string{"age": "unknown"})
+	im.Index("4", map[string]string{"age": "41.5"})
+
+	if res, err := im.LookupRange("age", "26", ""); err != nil || fmt.Sprint(res) != "[1 4]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := im.LookupRange("age", "", "30"); err != nil || fmt.Sprint(res) != "[2 1]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := im.LookupRange("born", "1991-01-01", "2000-01-01"); err != nil || fmt.Sprint(res) != "[2]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Only attributes in range mode are part of the range index
+
+	if res, err := im.LookupRange("name", "", ""); err != nil || len(res) != 0 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Range attributes can still be looked up by value
+
+	if res, err := im.LookupValue("age", "unknown"); err != nil || fmt.Sprint(res) != "[3]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	im.Reindex("3", map[string]string{"age": "20"}, map[string]string{"age": "unknown"})
+	im.Reindex("4", map[string]string{"age": "50"}, map[string]string{"age": "41.5"})
+
+	if res, err := im.LookupRange("age", "", ""); err != nil || fmt.Sprint(res) != "[3 2 1 4]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, err := im.LookupRange("age", "40", "45"); err != nil || len(res) != 0 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	im.Deindex("1", map[string]string{"age": "30", "born": "1990-05-01", "name": "30"})
+	im.Deindex("2", map[string]string{"age": "25", "born": "1995-01-01"})
+	im.Deindex("3", map[string]string{"age": "20"})
+	im.Deindex("4", map[string]string{"age": "50"})
+
+	// The index should be empty now
+
+	if it := hash.NewHTreeIterator(htree); it.HasNext() {
+		k, v := it.Next()
+		t.Error("Unexpected index entry:", string(k), v)
+		return
+	}
+
+	// Test error cases
+
+	if _, err := im.LookupRange("age", "foo", ""); err == nil ||
+		err.Error() != "GraphError: Invalid data (Range bound is not a number or date: foo)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	im.Index("1", map[string]string{"age": "30"})
+
+	for i := uint64(0); i < sm.LocCount; i++ {
+		sm.AccessMap[i] = storage.AccessCacheAndFetchError
+	}
+
+	if _, err := im.LookupRange("age", "", ""); err == nil || !strings.Contains(err.Error(), "Slot not found") {
+		t.Error("Unexpected result:", err)
+		return
+	}
+}
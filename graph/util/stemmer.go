@@ -0,0 +1,327 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package util
+
+/*
+stemPorter reduces an English word to its stem using the Porter stemming
+algorithm. Words which contain characters other than a-z are not changed.
+*/
+func stemPorter(word string) string {
+
+	if len(word) <= 2 {
+		return word
+	}
+
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	ps := &porterStemmer{[]byte(word), len(word) - 1, 0}
+
+	ps.step1ab()
+
+	if ps.k > 0 {
+		ps.step1c()
+		ps.step2()
+		ps.step3()
+		ps.step4()
+		ps.step5()
+	}
+
+	return string(ps.b[:ps.k+1])
+}
+
+/*
+porterStemmer holds the state of a single stemming operation.
+*/
+type porterStemmer struct {
+	b []byte // Word which is stemmed
+	k int    // End of the stem
+	j int    // General offset into the word
+}
+
+/*
+cons checks if the character at a given position is a consonant.
+*/
+func (ps *porterStemmer) cons(i int) bool {
+	switch ps.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !ps.cons(i-1)
+	}
+	return true
+}
+
+/*
+m measures the number of consonant sequences between the start and j.
+*/
+func (ps *porterStemmer) m() int {
+	n, i := 0, 0
+
+	for ; ; i++ {
+		if i > ps.j {
+			return n
+		} else if !ps.cons(i) {
+			break
+		}
+	}
+
+	for i++; ; i++ {
+
+		for ; ; i++ {
+			if i > ps.j {
+				return n
+			} else if ps.cons(i) {
+				break
+			}
+		}
+
+		n++
+
+		for i++; ; i++ {
+			if i > ps.j {
+				return n
+			} else if !ps.cons(i) {
+				break
+			}
+		}
+	}
+}
+
+/*
+vowelInStem checks if the stem up to j contains a vowel.
+*/
+func (ps *porterStemmer) vowelInStem() bool {
+	for i := 0; i <= ps.j; i++ {
+		if !ps.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+doublec checks if the given position and the one before contain the same
+consonant.
+*/
+func (ps *porterStemmer) doublec(i int) bool {
+	return i >= 1 && ps.b[i] == ps.b[i-1] && ps.cons(i)
+}
+
+/*
+cvc checks if the positions i-2, i-1, i have the form consonant - vowel -
+consonant and the last consonant is not w, x or y.
+*/
+func (ps *porterStemmer) cvc(i int) bool {
+	if i < 2 || !ps.cons(i) || ps.cons(i-1) || !ps.cons(i-2) {
+		return false
+	}
+	ch := ps.b[i]
+	return ch != 'w' && ch != 'x' && ch != 'y'
+}
+
+/*
+ends checks if the stem ends with a given suffix. Sets j to the position
+before the suffix.
+*/
+func (ps *porterStemmer) ends(s string) bool {
+	l := len(s)
+
+	if l > ps.k+1 || string(ps.b[ps.k-l+1:ps.k+1]) != s {
+		return false
+	}
+
+	ps.j = ps.k - l
+
+	return true
+}
+
+/*
+setTo replaces the characters after j with a given string.
+*/
+func (ps *porterStemmer) setTo(s string) {
+	ps.b = append(ps.b[:ps.j+1], s...)
+	ps.k = ps.j + len(s)
+}
+
+/*
+r replaces the characters after j with a given string if m() > 0.
+*/
+func (ps *porterStemmer) r(s string) {
+	if ps.m() > 0 {
+		ps.setTo(s)
+	}
+}
+
+/*
+replaceFirst replaces the first matching suffix of a list of suffix pairs.
+*/
+func (ps *porterStemmer) replaceFirst(pairs ...string) {
+	for i := 0; i < len(pairs); i += 2 {
+		if ps.ends(pairs[i]) {
+			ps.r(pairs[i+1])
+			return
+		}
+	}
+}
+
+/*
+step1ab removes plurals and -ed or -ing.
+*/
+func (ps *porterStemmer) step1ab() {
+
+	if ps.b[ps.k] == 's' {
+		if ps.ends("sses") {
+			ps.k -= 2
+		} else if ps.ends("ies") {
+			ps.setTo("i")
+		} else if ps.b[ps.k-1] != 's' {
+			ps.k--
+		}
+	}
+
+	if ps.ends("eed") {
+		if ps.m() > 0 {
+			ps.k--
+		}
+	} else if (ps.ends("ed") || ps.ends("ing")) && ps.vowelInStem() {
+		ps.k = ps.j
+
+		if ps.ends("at") {
+			ps.setTo("ate")
+		} else if ps.ends("bl") {
+			ps.setTo("ble")
+		} else if ps.ends("iz") {
+			ps.setTo("ize")
+		} else if ps.doublec(ps.k) {
+			ps.k--
+			if ch := ps.b[ps.k]; ch == 'l' || ch == 's' || ch == 'z' {
+				ps.k++
+			}
+		} else if ps.m() == 1 && ps.cvc(ps.k) {
+			ps.setTo("e")
+		}
+	}
+}
+
+/*
+step1c turns a terminal y into i if there is another vowel in the stem.
+*/
+func (ps *porterStemmer) step1c() {
+	if ps.ends("y") && ps.vowelInStem() {
+		ps.b[ps.k] = 'i'
+	}
+}
+
+/*
+step2 maps double suffixes to single ones.
+*/
+func (ps *porterStemmer) step2() {
+	switch ps.b[ps.k-1] {
+	case 'a':
+		ps.replaceFirst("ational", "ate", "tional", "tion")
+	case 'c':
+		ps.replaceFirst("enci", "ence", "anci", "ance")
+	case 'e':
+		ps.replaceFirst("izer", "ize")
+	case 'l':
+		ps.replaceFirst("bli", "ble", "alli", "al", "entli", "ent", "eli", "e", "ousli", "ous")
+	case 'o':
+		ps.replaceFirst("ization", "ize", "ation", "ate", "ator", "ate")
+	case 's':
+		ps.replaceFirst("alism", "al", "iveness", "ive", "fulness", "ful", "ousness", "ous")
+	case 't':
+		ps.replaceFirst("aliti", "al", "iviti", "ive", "biliti", "ble")
+	case 'g':
+		ps.replaceFirst("logi", "log")
+	}
+}
+
+/*
+step3 handles -ic-, -full, -ness etc.
+*/
+func (ps *porterStemmer) step3() {
+	switch ps.b[ps.k] {
+	case 'e':
+		ps.replaceFirst("icate", "ic", "ative", "", "alize", "al")
+	case 'i':
+		ps.replaceFirst("iciti", "ic")
+	case 'l':
+		ps.replaceFirst("ical", "ic", "ful", "")
+	case 's':
+		ps.replaceFirst("ness", "")
+	}
+}
+
+/*
+step4 removes -ant, -ence etc. in context <c>vcvc<v>.
+*/
+func (ps *porterStemmer) step4() {
+	var found bool
+
+	endsAny := func(suffixes ...string) bool {
+		for _, s := range suffixes {
+			if ps.ends(s) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch ps.b[ps.k-1] {
+	case 'a':
+		found = endsAny("al")
+	case 'c':
+		found = endsAny("ance", "ence")
+	case 'e':
+		found = endsAny("er")
+	case 'i':
+		found = endsAny("ic")
+	case 'l':
+		found = endsAny("able", "ible")
+	case 'n':
+		found = endsAny("ant", "ement", "ment", "ent")
+	case 'o':
+		found = (ps.ends("ion") && ps.j >= 0 && (ps.b[ps.j] == 's' || ps.b[ps.j] == 't')) ||
+			endsAny("ou")
+	case 's':
+		found = endsAny("ism")
+	case 't':
+		found = endsAny("ate", "iti")
+	case 'u':
+		found = endsAny("ous")
+	case 'v':
+		found = endsAny("ive")
+	case 'z':
+		found = endsAny("ize")
+	}
+
+	if found && ps.m() > 1 {
+		ps.k = ps.j
+	}
+}
+
+/*
+step5 removes a final -e if m() > 1 and changes -ll to -l if m() > 1.
+*/
+func (ps *porterStemmer) step5() {
+	ps.j = ps.k
+
+	if ps.b[ps.k] == 'e' {
+		if a := ps.m(); a > 1 || a == 1 && !ps.cvc(ps.k-1) {
+			ps.k--
+		}
+	}
+
+	if ps.b[ps.k] == 'l' && ps.doublec(ps.k) && ps.m() > 1 {
+		ps.k--
+	}
+}
@@ -0,0 +1,47 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package util
+
+import (
+	"testing"
+)
+
+func TestStemPorter(t *testing.T) {
+
+	for word, stem := range map[string]string{
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"cats":           "cat",
+		"feed":           "feed",
+		"agreed":         "agre",
+		"plastered":      "plaster",
+		"motoring":       "motor",
+		"sing":           "sing",
+		"conflated":      "conflat",
+		"hopping":        "hop",
+		"falling":        "fall",
+		"filing":         "file",
+		"happy":          "happi",
+		"relational":     "relat",
+		"conditional":    "condit",
+		"generalization": "gener",
+		"electrical":     "electr",
+		"hopefulness":    "hope",
+		"adjustment":     "adjust",
+		"adoption":       "adopt",
+		"controlling":    "control",
+		"roll":           "roll",
+		"is":             "is",
+		"Running":        "Running",
+		"fish-db":        "fish-db",
+	} {
+		if res := stemPorter(word); res != stem {
+			t.Error("Unexpected stem for", word, ":", res, "expected:", stem)
+		}
+	}
+}
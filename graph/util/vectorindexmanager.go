@@ -0,0 +1,850 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package util
+
+import (
+	"encoding/gob"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Fisch-Labs/FishDB/hash"
+)
+
+/*
+PrefixVectorMeta is the prefix used for the meta data entry of a vector index
+*/
+const PrefixVectorMeta = "\x01"
+
+/*
+PrefixVectorNode is the prefix used for vector index entries
+*/
+const PrefixVectorNode = "\x02"
+
+/*
+VectorIndexM is the number of connections which are established for each new
+entry on each layer of the vector index. The bottom layer allows twice as many
+connections.
+*/
+var VectorIndexM = 16
+
+/*
+VectorIndexEfConstruction is the size of the candidate list when inserting
+new entries into the vector index.
+*/
+var VectorIndexEfConstruction = 100
+
+/*
+VectorIndexEfSearch is the minimum size of the candidate list when searching
+the vector index.
+*/
+var VectorIndexEfSearch = 50
+
+/*
+VectorDistance is the distance function which is used by the vector index.
+*/
+var VectorDistance = CosineDistance
+
+/*
+VectorIndexManager data structure
+
+The vector index is a persistent Hierarchical Navigable Small World (HNSW)
+graph. Every indexed vector is stored together with its connections on each
+layer of the graph. Each node attribute has its own independent graph.
+*/
+type VectorIndexManager struct {
+	htree *hash.HTree // Persistent HTree which stores this index
+}
+
+/*
+vectorIndexMeta data structure
+*/
+type vectorIndexMeta struct {
+	EntryPoint string // Key of the entry point of the graph
+	MaxLevel   int    // Highest layer of the graph
+	Dims       int    // Number of dimensions of all vectors in the graph
+	Count      uint64 // Number of vectors in the graph
+}
+
+/*
+vectorIndexEntry data structure
+*/
+type vectorIndexEntry struct {
+	Vector     []float32  // Indexed vector
+	Neighbours [][]string // Connections of the entry on each layer
+}
+
+func init() {
+
+	// Make sure we can use the index structures in a gob operation
+
+	gob.Register(&vectorIndexMeta{})
+	gob.Register(&vectorIndexEntry{})
+}
+
+/*
+NewVectorIndexManager creates a new vector index manager instance.
+*/
+func NewVectorIndexManager(htree *hash.HTree) *VectorIndexManager {
+	return &VectorIndexManager{htree}
+}
+
+/*
+Index indexes (inserts) the vectors of a given object.
+*/
+func (vim *VectorIndexManager) Index(key string, obj map[string][]float32) error {
+	return vim.Reindex(key, obj, nil)
+}
+
+/*
+Reindex reindexes (updates) the vectors of a given object.
+*/
+func (vim *VectorIndexManager) Reindex(key string, newObj map[string][]float32,
+	oldObj map[string][]float32) error {
+
+	attrs := make(map[string]bool)
+
+	for attr := range newObj {
+		attrs[attr] = true
+	}
+	for attr := range oldObj {
+		attrs[attr] = true
+	}
+
+	for attr := range attrs {
+
+		op, err := vim.newVectorIndexOp(attr)
+		if err != nil {
+			return &GraphError{ErrIndexError, err.Error()}
+		}
+
+		newVec, ok := newObj[attr]
+
+		if ok {
+
+			// Nothing to do if the vector is already indexed
+
+			entry, err := op.get(key)
+			if err != nil {
+				return &GraphError{ErrIndexError, err.Error()}
+			} else if entry != nil && vectorEquals(entry.Vector, newVec) {
+				continue
+			}
+		}
+
+		if err := op.remove(key); err != nil {
+			return &GraphError{ErrIndexError, err.Error()}
+		}
+
+		if ok {
+			if err := op.insert(key, newVec); err != nil {
+				return err
+			}
+		}
+
+		if err := op.flush(); err != nil {
+			return &GraphError{ErrIndexError, err.Error()}
+		}
+	}
+
+	return nil
+}
+
+/*
+Deindex deindexes (removes) the vectors of a given object.
+*/
+func (vim *VectorIndexManager) Deindex(key string, obj map[string][]float32) error {
+	return vim.Reindex(key, nil, obj)
+}
+
+/*
+Nearest finds the k nearest neighbours of a given vector in the index of a
+given attribute. This call returns a list of node keys and a list of
+distances. Both lists are sorted by distance (nearest first).
+*/
+func (vim *VectorIndexManager) Nearest(attr string, vector []float32, k int) ([]string, []float32, error) {
+
+	op, err := vim.newVectorIndexOp(attr)
+	if err != nil {
+		return nil, nil, &GraphError{ErrIndexError, err.Error()}
+	} else if op.meta == nil || op.meta.Count == 0 || k < 1 {
+		return nil, nil, nil
+	}
+
+	if len(vector) != op.meta.Dims {
+		return nil, nil, &GraphError{ErrIndexError,
+			fmt.Sprintf("Vector has %v dimensions - index of attribute %v has %v dimensions",
+				len(vector), attr, op.meta.Dims)}
+	}
+
+	// Descend greedily to the bottom layer
+
+	ep := []string{op.meta.EntryPoint}
+
+	for l := op.meta.MaxLevel; l > 0; l-- {
+
+		res, err := op.searchLayer(vector, ep, 1, l)
+		if err != nil {
+			return nil, nil, &GraphError{ErrIndexError, err.Error()}
+		} else if len(res) > 0 {
+			ep = []string{res[0].key}
+		}
+	}
+
+	ef := VectorIndexEfSearch
+	if k > ef {
+		ef = k
+	}
+
+	res, err := op.searchLayer(vector, ep, ef, 0)
+	if err != nil {
+		return nil, nil, &GraphError{ErrIndexError, err.Error()}
+	}
+
+	if len(res) > k {
+		res = res[:k]
+	}
+
+	keys := make([]string, len(res))
+	dists := make([]float32, len(res))
+
+	for i, c := range res {
+		keys[i] = c.key
+		dists[i] = c.dist
+	}
+
+	return keys, dists, nil
+}
+
+/*
+Count returns the number of indexed vectors for a given attribute.
+*/
+func (vim *VectorIndexManager) Count(attr string) (uint64, error) {
+
+	obj, err := vim.htree.Get([]byte(PrefixVectorMeta + attr))
+	if err != nil {
+		return 0, &GraphError{ErrIndexError, err.Error()}
+	} else if obj == nil {
+		return 0, nil
+	}
+
+	return obj.(*vectorIndexMeta).Count, nil
+}
+
+// Vector index operations
+// =======================
+
+/*
+vectorCandidate is a search candidate with its distance to a query vector.
+*/
+type vectorCandidate struct {
+	key  string
+	dist float32
+}
+
+/*
+vectorIndexOp is a single operation on the vector index of one attribute. It
+caches all read entries and writes all changes once the operation is flushed.
+*/
+type vectorIndexOp struct {
+	vim     *VectorIndexManager          // Manager which created the operation
+	attr    string                       // Attribute of the index
+	meta    *vectorIndexMeta             // Meta data of the index (nil if the index is empty)
+	entries map[string]*vectorIndexEntry // Cache of read entries (nil for removed entries)
+	dirty   map[string]bool              // Entries which were changed
+}
+
+/*
+newVectorIndexOp creates a new operation on the vector index of an attribute.
+*/
+func (vim *VectorIndexManager) newVectorIndexOp(attr string) (*vectorIndexOp, error) {
+
+	op := &vectorIndexOp{vim, attr, nil, make(map[string]*vectorIndexEntry), make(map[string]bool)}
+
+	obj, err := vim.htree.Get([]byte(PrefixVectorMeta + attr))
+	if err != nil {
+		return nil, err
+	} else if obj != nil {
+		op.meta = obj.(*vectorIndexMeta)
+	}
+
+	return op, nil
+}
+
+/*
+entryKey returns the storage key of an index entry.
+*/
+func (op *vectorIndexOp) entryKey(key string) []byte {
+	return []byte(PrefixVectorNode + op.attr + "\x00" + key)
+}
+
+/*
+get returns an index entry. Returns nil if the entry does not exist.
+*/
+func (op *vectorIndexOp) get(key string) (*vectorIndexEntry, error) {
+
+	if entry, ok := op.entries[key]; ok {
+		return entry, nil
+	}
+
+	obj, err := op.vim.htree.Get(op.entryKey(key))
+	if err != nil {
+		return nil, err
+	}
+
+	var entry *vectorIndexEntry
+
+	if obj != nil {
+		entry = obj.(*vectorIndexEntry)
+	}
+
+	op.entries[key] = entry
+
+	return entry, nil
+}
+
+/*
+set stores an index entry. A nil entry removes the entry.
+*/
+func (op *vectorIndexOp) set(key string, entry *vectorIndexEntry) {
+	op.entries[key] = entry
+	op.dirty[key] = true
+}
+
+/*
+flush writes all changes of this operation to the index.
+*/
+func (op *vectorIndexOp) flush() error {
+
+	for key := range op.dirty {
+
+		if entry := op.entries[key]; entry != nil {
+			if _, err := op.vim.htree.Put(op.entryKey(key), entry); err != nil {
+				return err
+			}
+		} else if _, err := op.vim.htree.Remove(op.entryKey(key)); err != nil {
+			return err
+		}
+	}
+
+	op.dirty = make(map[string]bool)
+
+	if op.meta != nil && op.meta.Count > 0 {
+		_, err := op.vim.htree.Put([]byte(PrefixVectorMeta+op.attr), op.meta)
+		return err
+	}
+
+	_, err := op.vim.htree.Remove([]byte(PrefixVectorMeta + op.attr))
+
+	return err
+}
+
+/*
+maxConnections returns the maximum number of connections of an entry on a given layer.
+*/
+func (op *vectorIndexOp) maxConnections(level int) int {
+	if level == 0 {
+		return 2 * VectorIndexM
+	}
+	return VectorIndexM
+}
+
+/*
+insert adds a new vector to the index.
+*/
+func (op *vectorIndexOp) insert(key string, vector []float32) error {
+
+	if op.meta != nil && op.meta.Count > 0 && len(vector) != op.meta.Dims {
+		return &GraphError{ErrIndexError,
+			fmt.Sprintf("Vector has %v dimensions - index of attribute %v has %v dimensions",
+				len(vector), op.attr, op.meta.Dims)}
+	}
+
+	level := vectorLevel(key)
+
+	vec := make([]float32, len(vector))
+	copy(vec, vector)
+
+	entry := &vectorIndexEntry{vec, make([][]string, level+1)}
+	for l := range entry.Neighbours {
+		entry.Neighbours[l] = []string{}
+	}
+
+	// Handle the first entry
+
+	if op.meta == nil || op.meta.Count == 0 {
+		op.meta = &vectorIndexMeta{key, level, len(vec), 1}
+		op.set(key, entry)
+		return nil
+	}
+
+	op.set(key, entry)
+
+	// Descend greedily to the insertion layer
+
+	ep := []string{op.meta.EntryPoint}
+
+	for l := op.meta.MaxLevel; l > level; l-- {
+
+		res, err := op.searchLayer(vec, ep, 1, l)
+		if err != nil {
+			return &GraphError{ErrIndexError, err.Error()}
+		} else if len(res) > 0 {
+			ep = []string{res[0].key}
+		}
+	}
+
+	// Connect the new entry on all layers from the insertion layer downwards
+
+	startLevel := level
+	if op.meta.MaxLevel < startLevel {
+		startLevel = op.meta.MaxLevel
+	}
+
+	for l := startLevel; l >= 0; l-- {
+
+		res, err := op.searchLayer(vec, ep, VectorIndexEfConstruction, l)
+		if err != nil {
+			return &GraphError{ErrIndexError, err.Error()}
+		}
+
+		ep = ep[:0]
+
+		for _, c := range res {
+			if c.key == key {
+				continue
+			}
+
+			ep = append(ep, c.key)
+
+			if len(entry.Neighbours[l]) < VectorIndexM {
+				entry.Neighbours[l] = append(entry.Neighbours[l], c.key)
+			}
+		}
+
+		for _, nkey := range entry.Neighbours[l] {
+			if err := op.connect(nkey, key, l); err != nil {
+				return &GraphError{ErrIndexError, err.Error()}
+			}
+		}
+
+		if len(ep) == 0 {
+			ep = []string{op.meta.EntryPoint}
+		}
+	}
+
+	if level > op.meta.MaxLevel {
+		op.meta.EntryPoint = key
+		op.meta.MaxLevel = level
+	}
+
+	op.meta.Count++
+
+	return nil
+}
+
+/*
+connect adds a connection from one entry to another entry on a given layer.
+The connections of the entry are pruned if necessary.
+*/
+func (op *vectorIndexOp) connect(from string, to string, level int) error {
+
+	entry, err := op.get(from)
+	if err != nil || entry == nil || level >= len(entry.Neighbours) {
+		return err
+	}
+
+	for _, nkey := range entry.Neighbours[level] {
+		if nkey == to {
+			return nil
+		}
+	}
+
+	entry.Neighbours[level] = append(entry.Neighbours[level], to)
+
+	if len(entry.Neighbours[level]) > op.maxConnections(level) {
+		if err := op.prune(entry, level); err != nil {
+			return err
+		}
+	}
+
+	op.set(from, entry)
+
+	return nil
+}
+
+/*
+prune reduces the connections of an entry on a given layer to the closest entries.
+*/
+func (op *vectorIndexOp) prune(entry *vectorIndexEntry, level int) error {
+	var cands []vectorCandidate
+
+	for _, nkey := range entry.Neighbours[level] {
+
+		nentry, err := op.get(nkey)
+		if err != nil {
+			return err
+		} else if nentry == nil {
+			continue
+		}
+
+		cands = append(cands, vectorCandidate{nkey, VectorDistance(entry.Vector, nentry.Vector)})
+	}
+
+	sortCandidates(cands)
+
+	if max := op.maxConnections(level); len(cands) > max {
+		cands = cands[:max]
+	}
+
+	entry.Neighbours[level] = make([]string, len(cands))
+	for i, c := range cands {
+		entry.Neighbours[level][i] = c.key
+	}
+
+	return nil
+}
+
+/*
+remove removes a vector from the index. The connections of all neighbours are
+repaired by connecting them with the other neighbours of the removed entry.
+*/
+func (op *vectorIndexOp) remove(key string) error {
+
+	entry, err := op.get(key)
+	if err != nil || entry == nil {
+		return err
+	}
+
+	op.set(key, nil)
+
+	for l, neighbours := range entry.Neighbours {
+
+		for _, nkey := range neighbours {
+
+			nentry, err := op.get(nkey)
+			if err != nil {
+				return err
+			} else if nentry == nil || l >= len(nentry.Neighbours) {
+				continue
+			}
+
+			// Remove the connection to the removed entry and add all other
+			// neighbours of the removed entry as new candidates
+
+			cands := make([]string, 0, len(nentry.Neighbours[l])+len(neighbours))
+			seen := map[string]bool{nkey: true, key: true}
+
+			for _, ckey := range append(nentry.Neighbours[l], neighbours...) {
+				if !seen[ckey] {
+					seen[ckey] = true
+					cands = append(cands, ckey)
+				}
+			}
+
+			nentry.Neighbours[l] = cands
+
+			if err := op.prune(nentry, l); err != nil {
+				return err
+			}
+
+			op.set(nkey, nentry)
+		}
+	}
+
+	op.meta.Count--
+
+	if op.meta.Count == 0 {
+		op.meta = nil
+		return nil
+	}
+
+	if op.meta.EntryPoint == key {
+
+		// Choose a new entry point - prefer the neighbour on the highest layer
+
+		op.meta.EntryPoint = ""
+
+		for l := len(entry.Neighbours) - 1; l >= 0 && op.meta.EntryPoint == ""; l-- {
+			for _, nkey := range entry.Neighbours[l] {
+				if nentry, err := op.get(nkey); err != nil {
+					return err
+				} else if nentry != nil {
+					op.meta.EntryPoint = nkey
+					op.meta.MaxLevel = len(nentry.Neighbours) - 1
+					break
+				}
+			}
+		}
+
+		if op.meta.EntryPoint == "" {
+			return op.findEntryPoint()
+		}
+	}
+
+	return nil
+}
+
+/*
+findEntryPoint scans the whole index for the entry on the highest layer and
+makes it the new entry point.
+*/
+func (op *vectorIndexOp) findEntryPoint() error {
+	prefix := string(op.entryKey(""))
+
+	op.meta.MaxLevel = -1
+
+	it := hash.NewHTreeIterator(op.vim.htree)
+
+	for it.HasNext() {
+		k, v := it.Next()
+
+		if it.LastError != nil {
+			return it.LastError
+		}
+
+		if key := string(k); strings.HasPrefix(key, prefix) {
+			key = key[len(prefix):]
+
+			if _, ok := op.entries[key]; ok {
+				continue // Use the cached version
+			}
+
+			if entry := v.(*vectorIndexEntry); len(entry.Neighbours)-1 > op.meta.MaxLevel {
+				op.meta.EntryPoint = key
+				op.meta.MaxLevel = len(entry.Neighbours) - 1
+			}
+		}
+	}
+
+	for key, entry := range op.entries {
+		if entry != nil && len(entry.Neighbours)-1 > op.meta.MaxLevel {
+			op.meta.EntryPoint = key
+			op.meta.MaxLevel = len(entry.Neighbours) - 1
+		}
+	}
+
+	return nil
+}
+
+/*
+searchLayer searches a single layer of the graph for the closest entries to a
+given vector. Returns at most ef candidates sorted by distance.
+*/
+func (op *vectorIndexOp) searchLayer(vector []float32, ep []string, ef int, level int) ([]vectorCandidate, error) {
+	var cands, res []vectorCandidate
+
+	visited := make(map[string]bool)
+
+	for _, key := range ep {
+
+		if visited[key] {
+			continue
+		}
+
+		visited[key] = true
+
+		entry, err := op.get(key)
+		if err != nil {
+			return nil, err
+		} else if entry == nil {
+			continue
+		}
+
+		c := vectorCandidate{key, VectorDistance(vector, entry.Vector)}
+		cands = insertCandidate(cands, c)
+		res = insertCandidate(res, c)
+	}
+
+	if len(res) > ef {
+		res = res[:ef]
+	}
+
+	for len(cands) > 0 {
+
+		c := cands[0]
+		cands = cands[1:]
+
+		if len(res) >= ef && c.dist > res[len(res)-1].dist {
+			break
+		}
+
+		entry, err := op.get(c.key)
+		if err != nil {
+			return nil, err
+		} else if entry == nil || level >= len(entry.Neighbours) {
+			continue
+		}
+
+		for _, nkey := range entry.Neighbours[level] {
+
+			if visited[nkey] {
+				continue
+			}
+
+			visited[nkey] = true
+
+			nentry, err := op.get(nkey)
+			if err != nil {
+				return nil, err
+			} else if nentry == nil {
+				continue
+			}
+
+			dist := VectorDistance(vector, nentry.Vector)
+
+			if len(res) < ef || dist < res[len(res)-1].dist {
+				nc := vectorCandidate{nkey, dist}
+
+				cands = insertCandidate(cands, nc)
+				res = insertCandidate(res, nc)
+
+				if len(res) > ef {
+					res = res[:ef]
+				}
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// Helper functions
+// ================
+
+/*
+ToVector converts a given value into a vector. Lists of numbers are accepted
+in any representation (e.g. lists which were decoded from JSON). Returns false
+if the value is not a list of numbers.
+*/
+func ToVector(val interface{}) ([]float32, bool) {
+
+	switch v := val.(type) {
+	case []float32:
+		return v, true
+
+	case []float64:
+		ret := make([]float32, len(v))
+		for i, num := range v {
+			ret[i] = float32(num)
+		}
+		return ret, true
+
+	case []interface{}:
+		ret := make([]float32, len(v))
+		for i, item := range v {
+			num, err := strconv.ParseFloat(fmt.Sprint(item), 64)
+			if err != nil {
+				return nil, false
+			}
+			ret[i] = float32(num)
+		}
+		return ret, true
+	}
+
+	return nil, false
+}
+
+/*
+CosineDistance calculates the cosine distance (1 - cosine similarity) between
+two vectors.
+*/
+func CosineDistance(v1 []float32, v2 []float32) float32 {
+	var dot, norm1, norm2 float64
+
+	for i := 0; i < len(v1) && i < len(v2); i++ {
+		dot += float64(v1[i]) * float64(v2[i])
+		norm1 += float64(v1[i]) * float64(v1[i])
+		norm2 += float64(v2[i]) * float64(v2[i])
+	}
+
+	if norm1 == 0 || norm2 == 0 {
+		return 1
+	}
+
+	return float32(1 - dot/(math.Sqrt(norm1)*math.Sqrt(norm2)))
+}
+
+/*
+EuclideanDistance calculates the euclidean distance between two vectors.
+*/
+func EuclideanDistance(v1 []float32, v2 []float32) float32 {
+	var sum float64
+
+	for i := 0; i < len(v1) && i < len(v2); i++ {
+		d := float64(v1[i]) - float64(v2[i])
+		sum += d * d
+	}
+
+	return float32(math.Sqrt(sum))
+}
+
+/*
+vectorLevel determines the highest layer of a new index entry. The level is
+derived from the entry key so the index structure is reproducible.
+*/
+func vectorLevel(key string) int {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+
+	// Uniformly distributed number in (0, 1)
+
+	u := (float64(h.Sum64()>>11) + 0.5) / float64(uint64(1)<<53)
+
+	level := int(-math.Log(u) / math.Log(float64(VectorIndexM)))
+
+	if level > 16 {
+		level = 16
+	}
+
+	return level
+}
+
+/*
+vectorEquals checks if two vectors are equal.
+*/
+func vectorEquals(v1 []float32, v2 []float32) bool {
+	if len(v1) != len(v2) {
+		return false
+	}
+
+	for i := range v1 {
+		if v1[i] != v2[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+insertCandidate inserts a candidate into a sorted list of candidates.
+*/
+func insertCandidate(cands []vectorCandidate, c vectorCandidate) []vectorCandidate {
+	i := sort.Search(len(cands), func(i int) bool {
+		return cands[i].dist > c.dist
+	})
+
+	cands = append(cands, vectorCandidate{})
+	copy(cands[i+1:], cands[i:])
+	cands[i] = c
+
+	return cands
+}
+
+/*
+sortCandidates sorts a list of candidates by distance.
+*/
+func sortCandidates(cands []vectorCandidate) {
+	sort.SliceStable(cands, func(i, j int) bool {
+		return cands[i].dist < cands[j].dist
+	})
+}
@@ -0,0 +1,217 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package util
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/Fisch-Labs/FishDB/hash"
+	"github.com/Fisch-Labs/FishDB/storage"
+)
+
+func TestVectorIndexManager(t *testing.T) {
+	sm := storage.NewMemoryStorageManager("testsm")
+	htree, _ := hash.NewHTree(sm)
+
+	vim := NewVectorIndexManager(htree)
+
+	if res, dists, err := vim.Nearest("vec", []float32{1, 0}, 3); res != nil || dists != nil || err != nil {
+		t.Error("Unexpected result:", res, dists, err)
+		return
+	}
+
+	vim.Index("a", map[string][]float32{"vec": {1, 0}, "vec2": {1, 1, 1}})
+	vim.Index("b", map[string][]float32{"vec": {0, 1}})
+	vim.Index("c", map[string][]float32{"vec": {1, 1}})
+
+	if res, dists, err := vim.Nearest("vec", []float32{1, 0.1}, 2); err != nil ||
+		fmt.Sprint(res) != "[a c]" || dists[0] > dists[1] {
+		t.Error("Unexpected result:", res, dists, err)
+		return
+	}
+
+	if res, _ := vim.Count("vec"); res != 3 {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res, _ := vim.Count("vec2"); res != 1 {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// Test error cases
+
+	if _, _, err := vim.Nearest("vec", []float32{1, 0, 0}, 2); err == nil || err.Error() !=
+		"GraphError: Index error (Vector has 3 dimensions - index of attribute vec has 2 dimensions)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if err := vim.Index("d", map[string][]float32{"vec": {1}}); err == nil || err.Error() !=
+		"GraphError: Index error (Vector has 1 dimensions - index of attribute vec has 2 dimensions)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	// Update and remove vectors
+
+	vim.Reindex("a", map[string][]float32{"vec": {0, 1}}, map[string][]float32{"vec": {1, 0}, "vec2": {1, 1, 1}})
+
+	if res, _, err := vim.Nearest("vec", []float32{1, 0}, 1); err != nil || fmt.Sprint(res) != "[c]" {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	if res, _ := vim.Count("vec2"); res != 0 {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	vim.Deindex("c", map[string][]float32{"vec": {1, 1}})
+
+	if res, _, err := vim.Nearest("vec", []float32{1, 0}, 5); err != nil || len(res) != 2 {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	vim.Deindex("a", map[string][]float32{"vec": {0, 1}})
+	vim.Deindex("b", map[string][]float32{"vec": {0, 1}})
+
+	if res, _ := vim.Count("vec"); res != 0 {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// The index should be empty now
+
+	if it := hash.NewHTreeIterator(htree); it.HasNext() {
+		k, v := it.Next()
+		t.Error("Unexpected index entry:", string(k), v)
+		return
+	}
+}
+
+func TestVectorIndexManagerRecall(t *testing.T) {
+	sm := storage.NewMemoryStorageManager("testsm")
+	htree, _ := hash.NewHTree(sm)
+
+	vim := NewVectorIndexManager(htree)
+
+	r := rand.New(rand.NewSource(1))
+
+	randVector := func() []float32 {
+		vec := make([]float32, 16)
+		for i := range vec {
+			vec[i] = r.Float32()*2 - 1
+		}
+		return vec
+	}
+
+	vectors := make(map[string][]float32)
+
+	for i := 0; i < 500; i++ {
+		key := fmt.Sprint("key", i)
+		vectors[key] = randVector()
+
+		if err := vim.Index(key, map[string][]float32{"vec": vectors[key]}); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	// Remove some vectors
+
+	for i := 0; i < 500; i += 5 {
+		key := fmt.Sprint("key", i)
+
+		if err := vim.Deindex(key, map[string][]float32{"vec": vectors[key]}); err != nil {
+			t.Error(err)
+			return
+		}
+
+		delete(vectors, key)
+	}
+
+	if res, _ := vim.Count("vec"); res != 400 {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	// Compare the results with an exact search
+
+	var found, total int
+
+	for q := 0; q < 20; q++ {
+		query := randVector()
+
+		keys := make([]string, 0, len(vectors))
+		for key := range vectors {
+			keys = append(keys, key)
+		}
+
+		sort.Slice(keys, func(i, j int) bool {
+			return CosineDistance(query, vectors[keys[i]]) < CosineDistance(query, vectors[keys[j]])
+		})
+
+		exact := make(map[string]bool)
+		for _, key := range keys[:10] {
+			exact[key] = true
+		}
+
+		res, dists, err := vim.Nearest("vec", query, 10)
+		if err != nil || len(res) != 10 {
+			t.Error("Unexpected result:", res, err)
+			return
+		}
+
+		for i, key := range res {
+			if _, ok := vectors[key]; !ok {
+				t.Error("Removed vector was found:", key)
+				return
+			}
+
+			if i > 0 && dists[i-1] > dists[i] {
+				t.Error("Result is not sorted:", dists)
+				return
+			}
+
+			if exact[key] {
+				found++
+			}
+		}
+
+		total += 10
+	}
+
+	if recall := float64(found) / float64(total); recall < 0.9 {
+		t.Error("Unexpected recall:", recall)
+		return
+	}
+}
+
+func TestVectorDistance(t *testing.T) {
+
+	if res := CosineDistance([]float32{1, 0}, []float32{2, 0}); res != 0 {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := CosineDistance([]float32{1, 0}, []float32{0, 0}); res != 1 {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if res := EuclideanDistance([]float32{1, 0}, []float32{4, 4}); res != 5 {
+		t.Error("Unexpected result:", res)
+		return
+	}
+}
@@ -140,6 +140,40 @@ func dataSort(list []map[string]interface{}, attr string, ascending bool) {
 	sort.Sort(&DataSlice{list, attr, ascending})
 }
 
+/*
+scoreSort sorts a list of maps in descending order by a given list of scores.
+*/
+func scoreSort(list []map[string]interface{}, scores []float64) {
+	sort.Stable(&scoreSlice{list, scores})
+}
+
+/*
+scoreSlice attaches the methods of sort.Interface to []map[string]interface{},
+sorting in descending order by a parallel list of scores.
+*/
+type scoreSlice struct {
+	data   []map[string]interface{}
+	scores []float64
+}
+
+/*
+Len belongs to the sort.Interface.
+*/
+func (s scoreSlice) Len() int { return len(s.data) }
+
+/*
+Less belongs to the sort.Interface.
+*/
+func (s scoreSlice) Less(i, j int) bool { return s.scores[i] > s.scores[j] }
+
+/*
+Swap belongs to the sort.Interface.
+*/
+func (s scoreSlice) Swap(i, j int) {
+	s.data[i], s.data[j] = s.data[j], s.data[i]
+	s.scores[i], s.scores[j] = s.scores[j], s.scores[i]
+}
+
 /*
 DataSlice attaches the methods of sort.Interface to []map[string]interface{},
 sorting in ascending or descending order by a given attribute.
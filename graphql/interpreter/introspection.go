@@ -169,6 +169,7 @@ func (rt *selectionSetRuntime) GetFieldTypesIntrospection(action string, lookupA
 		args = []interface{}{
 			rt.newArg("key", "Lookup a particular node by key.", rt.newType("SCALAR", "String", nil), nil),
 			rt.newArg("matches", "Lookup nodes matching this template.", rt.newType("OBJECT", "NodeTemplate", nil), nil),
+			rt.newArg("search", "Lookup nodes by full text search and order them by relevance (the relevance is available as _score field).", rt.newType("OBJECT", "NodeTemplate", nil), nil),
 			rt.newArg("storeNode", "Store a node according to this template.", rt.newType("OBJECT", "NodeTemplate", nil), nil),
 			rt.newArg("removeNode", "Remove a node according to this template (only kind is needed).", rt.newType("OBJECT", "NodeTemplate", nil), nil),
 			rt.newArg("storeEdge", "Store an edge according to this template.", rt.newType("OBJECT", "NodeTemplate", nil), nil),
@@ -226,6 +227,7 @@ func (rt *selectionSetRuntime) GetFishDBTypesIntrospection() interface{} {
 			fields = append(fields, rt.newField(edgeName, fmt.Sprintf("The %s edge of a %s node to a %s node.", edge, kind, edgeTargetKind), []interface{}{
 				rt.newArg("traverse", fmt.Sprintf("Use %s to traverse from %s to %s.", edge, kind, edgeTargetKind), rt.newType("NON_NULL", nil, rt.newType("SCALAR", "String", nil)), nil),
 				rt.newArg("matches", "Lookup nodes matching this template.", rt.newType("OBJECT", "NodeTemplate", nil), nil),
+				rt.newArg("search", "Lookup nodes by full text search and order them by relevance (the relevance is available as _score field).", rt.newType("OBJECT", "NodeTemplate", nil), nil),
 				rt.newArg("ascending", "Sort resuting data ascending using the values of the specified key.", rt.newType("SCALAR", "String", nil), nil),
 				rt.newArg("descending", "Sort resuting data descending using the values of the specified key.", rt.newType("SCALAR", "String", nil), nil),
 				rt.newArg("from", "Retrieve data after the first n entries.", rt.newType("SCALAR", "Int", nil), nil),
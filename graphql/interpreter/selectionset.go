@@ -10,6 +10,7 @@ package interpreter
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -22,6 +23,12 @@ import (
 // SelectionSet Runtime
 // ====================
 
+/*
+ScoreField is the name of the field which holds the relevance score of a node
+which was found by a search expression.
+*/
+const ScoreField = "_score"
+
 /*
 Runtime for SelectionSets.
 */
@@ -118,7 +125,7 @@ func (ti *traversalIterator) Error() error {
 }
 
 func (rt *selectionSetRuntime) checkArgs(path []string, args map[string]interface{}) {
-	knownArgs := []string{"key", "matches", "traverse", "storeNode",
+	knownArgs := []string{"key", "matches", "search", "traverse", "storeNode",
 		"storeEdge", "removeNode", "removeEdge", "ascending", "descending",
 		"from", "items", "last"}
 
@@ -139,10 +146,12 @@ func (rt *selectionSetRuntime) ProcessNodes(path []string, kind string,
 
 	var from, items, last int
 	var ascending, descending string
+	var score float64
 
 	var err error
 
 	res := make([]map[string]interface{}, 0)
+	scores := make([]float64, 0)
 
 	// Get only the attributes which were specified
 
@@ -170,6 +179,8 @@ func (rt *selectionSetRuntime) ProcessNodes(path []string, kind string,
 							&traversalIterator{0, nodes})
 					}
 
+				} else if attr == ScoreField {
+					r[alias] = score
 				} else {
 					r[alias] = node.Attr(attr)
 				}
@@ -178,6 +189,7 @@ func (rt *selectionSetRuntime) ProcessNodes(path []string, kind string,
 
 		if err == nil {
 			res = append(res, r)
+			scores = append(scores, score)
 		}
 
 		return err
@@ -247,6 +259,16 @@ func (rt *selectionSetRuntime) ProcessNodes(path []string, kind string,
 					}
 				}
 
+				// Handle search expression
+
+				search, searchOk := args["search"]
+				searchMap, searchMapOk := search.(map[string]interface{})
+
+				if searchOk && !searchMapOk {
+					rt.rtp.handleRuntimeError(fmt.Errorf("Search expression is not a map"),
+						path, rt.node)
+				}
+
 				// Lookup a list of nodes
 
 				if it == nil {
@@ -257,6 +279,10 @@ func (rt *selectionSetRuntime) ProcessNodes(path []string, kind string,
 					}
 				}
 
+				// Relevance scores of the search expression for each node kind
+
+				kindScores := make(map[string]map[string]float64)
+
 				if it != nil && err == nil {
 
 					for err == nil && it.HasNext() {
@@ -273,6 +299,21 @@ func (rt *selectionSetRuntime) ProcessNodes(path []string, kind string,
 								attrs, aliasMap, traversalMap = rt.GetPlainFieldsAndAliases(path, nkind)
 							}
 
+							if searchMapOk {
+								nodeScores, ok := kindScores[nkind]
+
+								if !ok {
+									if nodeScores, err = rt.searchScores(nkind, searchMap); err != nil {
+										continue
+									}
+									kindScores[nkind] = nodeScores
+								}
+
+								if score = nodeScores[nkey]; score == 0 {
+									continue
+								}
+							}
+
 							if node, err = rt.rtp.FetchNodePart(rt.rtp.part, nkey,
 								nkind, append(attrs, matchAttrs...)); err == nil && node != nil {
 
@@ -295,6 +336,8 @@ func (rt *selectionSetRuntime) ProcessNodes(path []string, kind string,
 					dataSort(res, ascending, true)
 				} else if _, dok := args["descending"]; dok {
 					dataSort(res, descending, false)
+				} else if _, sok := args["search"]; sok {
+					scoreSort(res, scores)
 				}
 			}
 
@@ -322,6 +365,40 @@ func (rt *selectionSetRuntime) ProcessNodes(path []string, kind string,
 	return res
 }
 
+/*
+searchScores calculates the relevance scores of all nodes of a given kind for a
+search expression. The search expression maps attributes to full text queries.
+The score of a node is the sum of the scores of all attributes.
+*/
+func (rt *selectionSetRuntime) searchScores(kind string, searchMap map[string]interface{}) (map[string]float64, error) {
+	ret := make(map[string]float64)
+
+	iq, err := rt.rtp.gm.NodeIndexQuery(rt.rtp.part, kind)
+	if err != nil || iq == nil {
+		return ret, err
+	}
+
+	attrs := make([]string, 0, len(searchMap))
+	for attr := range searchMap {
+		attrs = append(attrs, attr)
+	}
+
+	sort.Strings(attrs)
+
+	for _, attr := range attrs {
+		scores, err := iq.Scores(attr, fmt.Sprint(searchMap[attr]))
+		if err != nil {
+			return nil, err
+		}
+
+		for key, score := range scores {
+			ret[key] += score
+		}
+	}
+
+	return ret, nil
+}
+
 /*
 handleOutputModifyingArgs handles arguments which modify the output presentation.
 */
@@ -10,6 +10,8 @@ package interpreter
 import (
 	"encoding/json"
 	"testing"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
 )
 
 func TestSortingAndLimiting(t *testing.T) {
@@ -1378,3 +1380,127 @@ func TestShortcutListQueries(t *testing.T) {
 		return
 	}
 }
+
+func TestSearchQueries(t *testing.T) {
+	gm, _ := songGraph()
+
+	for key, desc := range map[string]string{
+		"Aria1":       "A slow song about a fish",
+		"LoveSong3":   "Fish fish fish",
+		"MyOnlySong3": "A song about birds",
+	} {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "Song")
+		node.SetAttr("desc", desc)
+		gm.UpdateNode("main", node)
+	}
+
+	query := map[string]interface{}{
+		"operationName": nil,
+		"query": `
+{
+  Song(search : { desc : "fish" }) {
+	key
+	_score
+  }
+}
+`,
+		"variables": nil,
+	}
+
+	if rerr := checkResult(`
+{
+  "data": {
+    "Song": [
+      {
+        "_score": 0.7907119880251786,
+        "key": "LoveSong3"
+      },
+      {
+        "_score": 0.40610585487698,
+        "key": "Aria1"
+      }
+    ]
+  }
+}`[1:], query, gm); rerr != nil {
+		t.Error(rerr)
+		return
+	}
+
+	// Search results can be ordered and combined with other arguments
+
+	query = map[string]interface{}{
+		"operationName": nil,
+		"query": `
+{
+  Song(search : { desc : "fish song" }, matches : { name : "^[^M]" }, ascending : "key") {
+	key
+  }
+}
+`,
+		"variables": nil,
+	}
+
+	if rerr := checkResult(`
+{
+  "data": {
+    "Song": [
+      {
+        "key": "Aria1"
+      },
+      {
+        "key": "LoveSong3"
+      }
+    ]
+  }
+}`[1:], query, gm); rerr != nil {
+		t.Error(rerr)
+		return
+	}
+
+	query = map[string]interface{}{
+		"operationName": nil,
+		"query": `
+{
+  Song(search : "fish") {
+  }
+}
+`,
+		"variables": nil,
+	}
+
+	if rerr := checkResult(`
+{
+  "data": {
+    "Song": [
+      {},
+      {},
+      {},
+      {},
+      {},
+      {},
+      {},
+      {},
+      {}
+    ]
+  },
+  "errors": [
+    {
+      "locations": [
+        {
+          "column": 25,
+          "line": 3
+        }
+      ],
+      "message": "Search expression is not a map",
+      "path": [
+        "Song"
+      ]
+    }
+  ]
+}`[1:], query, gm); rerr != nil {
+		t.Error(rerr)
+		return
+	}
+}
@@ -39,6 +39,7 @@ package hash
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/Fisch-Labs/FishDB/storage"
 )
@@ -89,6 +90,21 @@ type htreeNode struct {
 	BucketSize byte          // Bucket size (only used for buckets)
 }
 
+/*
+cacheHits and cacheMisses count how often HTree nodes were found in the cache
+of their storage manager and how often they had to be fetched from storage.
+*/
+var cacheHits, cacheMisses uint64
+
+/*
+CacheStats returns how often HTree nodes were found in the cache of their
+storage manager (hits) and how often they had to be fetched from storage
+(misses). The counters are shared by all HTrees.
+*/
+func CacheStats() (uint64, uint64) {
+	return atomic.LoadUint64(&cacheHits), atomic.LoadUint64(&cacheMisses)
+}
+
 /*
 Fetch a HTree node from the storage.
 */
@@ -97,11 +113,13 @@ func (n *htreeNode) fetchNode(loc uint64) (*htreeNode, error) {
 
 	if obj, _ := n.sm.FetchCached(loc); obj == nil {
 		var res htreeNode
+		atomic.AddUint64(&cacheMisses, 1)
 		if err := n.sm.Fetch(loc, &res); err != nil {
 			return nil, err
 		}
 		node = &res
 	} else {
+		atomic.AddUint64(&cacheHits, 1)
 		node = obj.(*htreeNode)
 	}
 
@@ -149,11 +167,13 @@ func LoadHTree(sm storage.Manager, loc uint64) (*HTree, error) {
 
 	if obj, _ := sm.FetchCached(loc); obj == nil {
 		var res htreeNode
+		atomic.AddUint64(&cacheMisses, 1)
 		if err := sm.Fetch(loc, &res); err != nil {
 			return nil, err
 		}
 		tree = &HTree{&htreePage{&res}, nil}
 	} else {
+		atomic.AddUint64(&cacheHits, 1)
 		tree = &HTree{&htreePage{obj.(*htreeNode)}, nil}
 	}
 
@@ -126,11 +126,20 @@ func TestHTree(t *testing.T) {
 
 	delete(sm.AccessMap, 1)
 
+	hits, misses := CacheStats()
+
 	sm.AccessMap[1] = storage.AccessNotInCache
 	htreeFetched, _ := LoadHTree(sm, page.Location())
 
 	delete(sm.AccessMap, 1)
 
+	LoadHTree(sm, page.Location())
+
+	if h, m := CacheStats(); h != hits+1 || m != misses+1 {
+		t.Error("Unexpected cache stats:", h-hits, m-misses)
+		return
+	}
+
 	if htreeCached.Location() != htreeFetched.Location() {
 		t.Error("Trees have different locations:", htreeCached.Location(), htreeFetched.Location())
 		return
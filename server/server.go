@@ -30,6 +30,7 @@ import (
 	"github.com/Fisch-Labs/FishDB/ecal"
 	"github.com/Fisch-Labs/FishDB/graph"
 	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+	"github.com/Fisch-Labs/FishDB/webhook"
 	"github.com/Fisch-Labs/Toolkit/cryptutil"
 	"github.com/Fisch-Labs/Toolkit/datautil"
 	"github.com/Fisch-Labs/Toolkit/errorutil"
@@ -182,6 +183,14 @@ func StartServerWithSingleOp(singleOperation func(*graph.Manager) bool) {
 	api.GS = gs
 	api.GM = graph.NewGraphManager(gs)
 
+	api.GM.SetChangeLogRetention(time.Duration(config.Int(config.ChangeLogMaxAgeSeconds))*time.Second,
+		uint64(config.Int(config.ChangeLogMaxSize)))
+
+	// Triggers can only be changed through the ECAL interpreter - even if
+	// ECAL scripting is disabled
+
+	api.GM.ProtectSystemKind(ecal.KindTrigger)
+
 	defer func() {
 
 		print("Closing datastore")
@@ -212,6 +221,28 @@ func StartServerWithSingleOp(singleOperation func(*graph.Manager) bool) {
 		}
 	}
 
+	// Create webhook Dispatcher instance and start the delivery of events
+
+	if config.Bool(config.EnableWebhooks) {
+
+		print("Starting webhooks")
+
+		api.WH = webhook.NewDispatcher(api.GM)
+		api.WH.Logger = print
+
+		if err := api.WH.Run(); err != nil {
+			fatal("Failed to start webhooks:", err)
+			return
+		}
+
+		defer func() {
+
+			print("Stopping webhooks")
+
+			api.WH.Close()
+		}()
+	}
+
 	// Handle single operation - these are operations which work on the GraphManager
 	// and then exit.
 
@@ -0,0 +1,372 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Fisch-Labs/FishDB/graph/data"
+)
+
+/*
+deadLetterCounter is used to make the keys of dead letters unique
+*/
+var deadLetterCounter uint64
+
+/*
+hookQueue holds the events of a webhook which are waiting for delivery.
+*/
+type hookQueue struct {
+	hook      *Webhook      // Webhook of this queue
+	events    []*Event      // Events which are waiting for delivery
+	read      uint64        // Change log cursor of the last queued change set
+	delivered uint64        // Change log cursor of the last delivered change set
+	mutex     *sync.Mutex   // Mutex to protect the events
+	stop      chan struct{} // Channel which is closed to stop the delivery
+	done      chan struct{} // Channel which is closed once the delivery has stopped
+}
+
+/*
+newHookQueue creates a new event queue for a given webhook which starts
+after a given change log cursor.
+*/
+func newHookQueue(hook *Webhook, cursor uint64) *hookQueue {
+	return &hookQueue{hook, nil, cursor, cursor, &sync.Mutex{},
+		make(chan struct{}), make(chan struct{})}
+}
+
+/*
+push adds an event to the queue.
+*/
+func (q *hookQueue) push(ev *Event) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.events = append(q.events, ev)
+}
+
+/*
+take removes up to max events from the queue.
+*/
+func (q *hookQueue) take(max int) []*Event {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if max <= 0 || max > len(q.events) {
+		max = len(q.events)
+	}
+
+	res := q.events[:max]
+	q.events = q.events[max:]
+
+	return res
+}
+
+/*
+size returns the number of queued events.
+*/
+func (q *hookQueue) size() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return len(q.events)
+}
+
+/*
+close stops the delivery of the queue and waits until it has stopped. Events
+which were not delivered remain in the queue.
+*/
+func (q *hookQueue) close() {
+	close(q.stop)
+	<-q.done
+}
+
+/*
+handOver returns a new queue for a given webhook which takes over the pending
+events and the change log cursors of this closed queue.
+*/
+func (q *hookQueue) handOver(hook *Webhook) *hookQueue {
+	nq := newHookQueue(hook, q.delivered)
+	nq.read = q.read
+
+	for _, ev := range q.events {
+		if hook.matches(ev) {
+			nq.events = append(nq.events, ev)
+		}
+	}
+
+	return nq
+}
+
+/*
+deliver reads committed changes from the change log and delivers their events
+in batches until the queue is closed. The cursor of the webhook is stored once
+a batch was delivered.
+*/
+func (d *Dispatcher) deliver(q *hookQueue) {
+	defer close(q.done)
+
+	for {
+		notify := d.GM.ChangeNotify()
+
+		more := d.readChanges(q)
+
+		if q.size() == 0 {
+
+			// Nothing to deliver - wait for the next change set
+
+			q.delivered = q.read
+
+			if more {
+				select {
+				case <-q.stop:
+					return
+				default:
+					continue
+				}
+			}
+
+			select {
+			case <-notify:
+				continue
+			case <-q.stop:
+				return
+			}
+		}
+
+		// Wait for more events unless the batch is already full
+
+		if q.size() < d.BatchSize && !more {
+			timer := time.NewTimer(d.BatchDelay)
+
+			select {
+			case <-timer.C:
+			case <-q.stop:
+				timer.Stop()
+				return
+			}
+
+			d.readChanges(q)
+		}
+
+		for events := q.take(d.BatchSize); len(events) > 0; events = q.take(d.BatchSize) {
+			if !d.send(q, events) {
+
+				// Keep the events in the queue if the delivery was stopped
+
+				q.mutex.Lock()
+				q.events = append(events, q.events...)
+				q.mutex.Unlock()
+
+				return
+			}
+		}
+
+		q.delivered = q.read
+
+		d.storeCursor(q)
+	}
+}
+
+/*
+readChanges queues the matching events of the change sets which follow the
+read cursor of a queue. Reading stops once the queue is full - events of a
+single change set which exceed the maximal queue size are stored as dead
+letters. Change sets which were removed from the change log before they were
+read are recorded as a dead letter without events. Returns true if there are
+further change sets to read.
+*/
+func (d *Dispatcher) readChanges(q *hookQueue) bool {
+	var overflow []*Event
+
+	full := func() bool {
+		return d.MaxQueueSize > 0 && q.size() >= d.MaxQueueSize
+	}
+
+	if full() {
+		return false
+	}
+
+	first, err := d.GM.FirstChangeSeq()
+
+	if err == nil && q.read+1 < first {
+		gapErr := fmt.Errorf("Change sets %v to %v were removed from the change log before they were read",
+			q.read+1, first-1)
+
+		d.log(fmt.Sprintf("Webhook %v missed events: %v", q.hook.Name, gapErr))
+		d.writeDeadLetter(q.hook, []*Event{}, gapErr, 0)
+
+		q.read = first - 1
+	}
+
+	changeSets, err := d.GM.FetchChanges(q.read, 0)
+
+	if err != nil {
+		d.log(fmt.Sprintf("Could not read changes for webhook %v: %v", q.hook.Name, err))
+		return false
+	}
+
+	for _, changeSet := range changeSets {
+
+		if full() {
+			break
+		}
+
+		for _, change := range changeSet.Changes {
+			if ev := changeEvent(change); ev != nil && q.hook.matches(ev) {
+				if full() {
+					overflow = append(overflow, ev)
+				} else {
+					q.push(ev)
+				}
+			}
+		}
+
+		q.read = changeSet.Seq
+	}
+
+	for len(overflow) > 0 {
+		n := d.BatchSize
+		if n <= 0 || n > len(overflow) {
+			n = len(overflow)
+		}
+
+		d.writeDeadLetter(q.hook, overflow[:n], fmt.Errorf("Webhook queue is full"), 0)
+		overflow = overflow[n:]
+	}
+
+	last, err := d.GM.LastChangeSeq()
+
+	return err == nil && q.read < last && !full()
+}
+
+/*
+send sends a batch of events to a webhook. Failed requests are retried with
+an exponential backoff - a dead letter is stored if all retries fail. Returns
+false if the queue was closed before the batch was delivered.
+*/
+func (d *Dispatcher) send(q *hookQueue, events []*Event) bool {
+	body, err := payload(q.hook, events)
+	if err != nil {
+		d.writeDeadLetter(q.hook, events, err, 0)
+		return true
+	}
+
+	// Cancel a running request once the queue is closed
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-q.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	client := &http.Client{Timeout: d.Timeout}
+
+	delay := d.RetryDelay
+
+	for attempt := 1; ; attempt++ {
+		var req *http.Request
+		var resp *http.Response
+
+		if req, err = http.NewRequest("POST", q.hook.URL, bytes.NewReader(body)); err != nil {
+			d.writeDeadLetter(q.hook, events, err, 0)
+			return true
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		if resp, err = client.Do(req.WithContext(ctx)); err == nil {
+			resp.Body.Close()
+
+			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+				return true
+			}
+
+			err = fmt.Errorf("Unexpected response status: %v", resp.Status)
+		}
+
+		if ctx.Err() != nil {
+			return false
+		}
+
+		if attempt > d.MaxRetries {
+			d.writeDeadLetter(q.hook, events, err, attempt)
+			return true
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-timer.C:
+		case <-q.stop:
+			timer.Stop()
+			return false
+		}
+
+		delay *= 2
+	}
+}
+
+/*
+storeCursor stores the change log cursor of the last delivered change set
+of a webhook.
+*/
+func (d *Dispatcher) storeCursor(q *hookQueue) {
+	if err := d.GM.SetChangeCursor(cursorName(q.hook.Name), q.delivered); err != nil {
+		d.log(fmt.Sprintf("Could not store cursor of webhook %v: %v", q.hook.Name, err))
+	}
+}
+
+/*
+log logs an error if a logger was given.
+*/
+func (d *Dispatcher) log(msg string) {
+	if d.Logger != nil {
+		d.Logger(msg)
+	}
+}
+
+/*
+writeDeadLetter stores a batch of events which could not be delivered.
+*/
+func (d *Dispatcher) writeDeadLetter(hook *Webhook, events []*Event, deliveryErr error, attempts int) {
+
+	body, err := payload(hook, events)
+	if err != nil {
+		body = []byte(fmt.Sprint(events))
+	}
+
+	now := time.Now()
+
+	node := data.NewGraphNode()
+
+	node.SetAttr(data.NodeKey, fmt.Sprintf("%v-%v-%v", hook.Name, now.UnixNano(),
+		atomic.AddUint64(&deadLetterCounter, 1)))
+	node.SetAttr(data.NodeKind, KindDeadLetter)
+	node.SetAttr("webhook", hook.Name)
+	node.SetAttr("url", hook.URL)
+	node.SetAttr("payload", string(body))
+	node.SetAttr("error", deliveryErr.Error())
+	node.SetAttr("attempts", attempts)
+	node.SetAttr("time", now.UTC().Format(time.RFC3339Nano))
+
+	if err := d.GM.StoreNode(SystemPartition, node); err != nil {
+		d.log(fmt.Sprintf("Could not store dead letter for webhook %v: %v", hook.Name, err))
+	}
+}
@@ -0,0 +1,547 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+/*
+Package webhook forwards graph events to external HTTP endpoints.
+
+A webhook is registered with a name, a target URL and optional filters for
+node and edge kinds, partitions and actions (created, updated or deleted).
+The Dispatcher reads committed change sets from the change log of the graph
+database and POSTs matching events as batched JSON payloads to the target URL:
+
+	{
+	  "webhook" : <name of the webhook>,
+	  "events"  : [
+	    {
+	      "event"    : <node.created, node.updated, node.deleted, edge.created, ...>,
+	      "part"     : <partition>,
+	      "kind"     : <kind>,
+	      "key"      : <key>,
+	      "data"     : <node or edge data>,
+	      "old_data" : <old values of changed attributes (updates only)>
+	    },
+	    ...
+	  ]
+	}
+
+Changes of rolled back transactions are never forwarded. The dispatcher does
+not use a graph rule for this reason - rules run inside a commit before it is
+known if the commit succeeds while change sets are only appended to the change
+log once a commit was flushed.
+
+Every webhook keeps a cursor into the change log which is stored once a batch
+was delivered - the delivery resumes from this cursor after a restart, so an
+event may be delivered more than once. Cursors are stored in the main database
+outside of the graph since storing them in the graph would produce a new graph
+version and change set for every delivered batch.
+
+Failed deliveries are retried with an exponential backoff. A batch which
+could not be delivered is stored as a dead letter. Events which exceed the
+maximal queue size of a webhook are stored as dead letters as well. Change
+sets which were removed from the change log before they were read are recorded
+as a dead letter without events. Webhooks and dead letters are stored as nodes
+in the system partition of the graph database.
+*/
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Fisch-Labs/FishDB/config"
+	"github.com/Fisch-Labs/FishDB/graph"
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/util"
+)
+
+/*
+SystemPartition is the partition which stores webhooks and dead letters.
+Events of this partition are never forwarded.
+*/
+const SystemPartition = graph.SystemPartition
+
+/*
+KindWebhook is the node kind of stored webhooks
+*/
+const KindWebhook = "Webhook"
+
+/*
+KindDeadLetter is the node kind of stored dead letters
+*/
+const KindDeadLetter = "WebhookDeadLetter"
+
+/*
+Webhook actions
+*/
+const (
+	ActionCreated = "created"
+	ActionUpdated = "updated"
+	ActionDeleted = "deleted"
+)
+
+/*
+Webhook models a registered webhook.
+*/
+type Webhook struct {
+	Name    string   `json:"name"`              // Unique name of the webhook
+	URL     string   `json:"url"`               // URL which receives the events
+	Kinds   []string `json:"kinds,omitempty"`   // Node and edge kinds to forward (all kinds if empty)
+	Parts   []string `json:"parts,omitempty"`   // Partitions to forward (all partitions if empty)
+	Actions []string `json:"actions,omitempty"` // Actions to forward (all actions if empty)
+}
+
+/*
+Event models a single forwarded graph event.
+*/
+type Event struct {
+	Event   string                 `json:"event"`              // Event name e.g. node.created
+	Part    string                 `json:"part"`               // Partition of the node or edge
+	Kind    string                 `json:"kind"`               // Kind of the node or edge
+	Key     string                 `json:"key"`                // Key of the node or edge
+	Data    map[string]interface{} `json:"data"`               // Data of the node or edge
+	OldData map[string]interface{} `json:"old_data,omitempty"` // Old values of changed attributes (nil if not set before)
+}
+
+/*
+DeadLetter models a batch of events which could not be delivered.
+*/
+type DeadLetter struct {
+	Key      string    `json:"key"`      // Key of the dead letter node
+	Webhook  string    `json:"webhook"`  // Name of the webhook
+	URL      string    `json:"url"`      // URL which could not be reached
+	Payload  string    `json:"payload"`  // JSON payload which could not be delivered
+	Error    string    `json:"error"`    // Last delivery error
+	Attempts int       `json:"attempts"` // Number of delivery attempts
+	Time     time.Time `json:"time"`     // Time when the delivery was given up
+}
+
+/*
+Dispatcher forwards committed graph changes to registered webhooks.
+*/
+type Dispatcher struct {
+	GM *graph.Manager // GraphManager which provides the changes and stores webhooks
+
+	BatchSize    int           // Maximal number of events in a single request
+	BatchDelay   time.Duration // Time to wait for more events before a request is sent
+	MaxRetries   int           // Number of retries for a failed request
+	RetryDelay   time.Duration // Delay before the first retry (doubled for every further retry)
+	Timeout      time.Duration // Timeout of a single request
+	MaxQueueSize int           // Maximal number of queued events of a webhook (0 for no limit)
+
+	Logger func(v ...interface{}) // Logger for errors which cannot be reported otherwise (optional)
+
+	queues map[string]*hookQueue // Event queues of all registered webhooks
+	mutex  *sync.RWMutex         // Mutex to protect the event queues
+}
+
+/*
+NewDispatcher returns a new webhook dispatcher which is configured with the
+current config.
+*/
+func NewDispatcher(gm *graph.Manager) *Dispatcher {
+	return &Dispatcher{
+		GM:           gm,
+		BatchSize:    int(config.Int(config.WebhookBatchSize)),
+		BatchDelay:   time.Duration(config.Int(config.WebhookBatchDelayMillis)) * time.Millisecond,
+		MaxRetries:   int(config.Int(config.WebhookMaxRetries)),
+		RetryDelay:   time.Duration(config.Int(config.WebhookRetryDelayMillis)) * time.Millisecond,
+		Timeout:      time.Duration(config.Int(config.WebhookTimeoutSeconds)) * time.Second,
+		MaxQueueSize: int(config.Int(config.WebhookMaxQueueSize)),
+		queues:       make(map[string]*hookQueue),
+		mutex:        &sync.RWMutex{},
+	}
+}
+
+/*
+Run loads all stored webhooks and starts their delivery from their stored
+cursors.
+*/
+func (d *Dispatcher) Run() error {
+
+	it, err := d.GM.NodeKeyIterator(SystemPartition, KindWebhook)
+
+	for err == nil && it != nil && it.HasNext() {
+		var node data.Node
+
+		key := it.Next()
+
+		if err = it.Error(); err == nil {
+			if node, err = d.GM.FetchNode(SystemPartition, key, KindWebhook); err == nil && node != nil {
+				// Webhooks without a stored cursor start with the next change
+
+				cursor, ok := d.GM.ChangeCursor(cursorName(key))
+				if !ok {
+					cursor, err = d.GM.LastChangeSeq()
+				}
+
+				if err == nil {
+					d.start(newHookQueue(nodeToWebhook(node), cursor))
+				}
+			}
+		}
+	}
+
+	return err
+}
+
+/*
+Close stops the delivery of all webhooks. Events which were not delivered
+are delivered from the stored cursors once the webhooks are started again.
+*/
+func (d *Dispatcher) Close() {
+	d.mutex.Lock()
+	queues := d.queues
+	d.queues = make(map[string]*hookQueue)
+	d.mutex.Unlock()
+
+	for _, q := range queues {
+		q.close()
+	}
+}
+
+/*
+Webhooks returns all registered webhooks sorted by name.
+*/
+func (d *Dispatcher) Webhooks() []*Webhook {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	res := make([]*Webhook, 0, len(d.queues))
+
+	for _, q := range d.queues {
+		res = append(res, q.hook)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+
+	return res
+}
+
+/*
+Webhook returns a registered webhook. Returns nil if the webhook does not exist.
+*/
+func (d *Dispatcher) Webhook(name string) *Webhook {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	if q, ok := d.queues[name]; ok {
+		return q.hook
+	}
+
+	return nil
+}
+
+/*
+Register registers a new webhook or replaces an existing webhook with the
+same name. The webhook is stored in the graph database. A new webhook
+forwards all changes which are committed after its registration - a replacing
+webhook takes over the pending events of the replaced webhook.
+*/
+func (d *Dispatcher) Register(hook *Webhook) error {
+	var q *hookQueue
+
+	if err := validateWebhook(hook); err != nil {
+		return err
+	}
+
+	// Stop the delivery of a replaced webhook and take over its pending events
+
+	d.mutex.Lock()
+	old, ok := d.queues[hook.Name]
+	delete(d.queues, hook.Name)
+	d.mutex.Unlock()
+
+	if ok {
+		old.close()
+		q = old.handOver(hook)
+
+	} else {
+		cursor, err := d.GM.LastChangeSeq()
+		if err != nil {
+			return err
+		}
+
+		q = newHookQueue(hook, cursor)
+	}
+
+	err := d.GM.SetChangeCursor(cursorName(hook.Name), q.delivered)
+	if err == nil {
+		err = d.GM.StoreNode(SystemPartition, webhookToNode(hook))
+	}
+
+	if err != nil {
+
+		// Continue the delivery of the replaced webhook
+
+		if ok {
+			d.start(old.handOver(old.hook))
+		}
+
+		return err
+	}
+
+	d.start(q)
+
+	return nil
+}
+
+/*
+Remove removes a registered webhook. Events which were not delivered are
+discarded.
+*/
+func (d *Dispatcher) Remove(name string) error {
+
+	d.mutex.Lock()
+	q, ok := d.queues[name]
+	delete(d.queues, name)
+	d.mutex.Unlock()
+
+	if !ok {
+		return &util.GraphError{
+			Type:   util.ErrInvalidData,
+			Detail: fmt.Sprintf("Unknown webhook %v", name),
+		}
+	}
+
+	q.close()
+
+	if _, err := d.GM.RemoveNode(SystemPartition, name, KindWebhook); err != nil {
+		return err
+	}
+
+	return d.GM.RemoveChangeCursor(cursorName(name))
+}
+
+/*
+DeadLetters returns all dead letters of a webhook ordered by their time.
+*/
+func (d *Dispatcher) DeadLetters(name string) ([]*DeadLetter, error) {
+	res := make([]*DeadLetter, 0)
+
+	it, err := d.GM.NodeKeyIterator(SystemPartition, KindDeadLetter)
+
+	for err == nil && it != nil && it.HasNext() {
+		var node data.Node
+
+		key := it.Next()
+
+		if err = it.Error(); err == nil {
+			if node, err = d.GM.FetchNode(SystemPartition, key, KindDeadLetter); err == nil &&
+				node != nil && node.Attr("webhook") == name {
+
+				res = append(res, nodeToDeadLetter(node))
+			}
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Time.Before(res[j].Time)
+	})
+
+	return res, err
+}
+
+/*
+RemoveDeadLetters removes all dead letters of a webhook. Returns the number
+of removed dead letters.
+*/
+func (d *Dispatcher) RemoveDeadLetters(name string) (int, error) {
+
+	letters, err := d.DeadLetters(name)
+	if err != nil {
+		return 0, err
+	}
+
+	trans := graph.NewGraphTrans(d.GM)
+
+	for _, letter := range letters {
+		if err := trans.RemoveNode(SystemPartition, letter.Key, KindDeadLetter); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(letters), trans.Commit()
+}
+
+/*
+start starts the delivery of a given webhook queue.
+*/
+func (d *Dispatcher) start(q *hookQueue) {
+
+	d.mutex.Lock()
+	d.queues[q.hook.Name] = q
+	d.mutex.Unlock()
+
+	go d.deliver(q)
+}
+
+/*
+matches checks if a given event should be forwarded by this webhook.
+*/
+func (w *Webhook) matches(ev *Event) bool {
+
+	contains := func(list []string, val string) bool {
+		if len(list) == 0 {
+			return true
+		}
+		for _, item := range list {
+			if item == val {
+				return true
+			}
+		}
+		return false
+	}
+
+	return contains(w.Kinds, ev.Kind) && contains(w.Parts, ev.Part) &&
+		contains(w.Actions, ev.Event[strings.Index(ev.Event, ".")+1:])
+}
+
+/*
+validateWebhook checks if a given webhook can be registered.
+*/
+func validateWebhook(hook *Webhook) error {
+
+	newError := func(detail string) error {
+		return &util.GraphError{Type: util.ErrInvalidData, Detail: detail}
+	}
+
+	if hook.Name == "" {
+		return newError("Webhook needs a name")
+	}
+
+	if u, err := url.Parse(hook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return newError(fmt.Sprintf("Invalid URL for webhook %v: %v", hook.Name, hook.URL))
+	}
+
+	for _, action := range hook.Actions {
+		if action != ActionCreated && action != ActionUpdated && action != ActionDeleted {
+			return newError(fmt.Sprintf("Invalid action for webhook %v: %v", hook.Name, action))
+		}
+	}
+
+	return nil
+}
+
+/*
+cursorName returns the name of the change log cursor of a webhook.
+*/
+func cursorName(name string) string {
+	return "webhook:" + name
+}
+
+/*
+webhookToNode converts a webhook into a node which can be stored.
+*/
+func webhookToNode(hook *Webhook) data.Node {
+	node := data.NewGraphNode()
+
+	node.SetAttr(data.NodeKey, hook.Name)
+	node.SetAttr(data.NodeKind, KindWebhook)
+	node.SetAttr("url", hook.URL)
+	node.SetAttr("kinds", strings.Join(hook.Kinds, ","))
+	node.SetAttr("parts", strings.Join(hook.Parts, ","))
+	node.SetAttr("actions", strings.Join(hook.Actions, ","))
+
+	return node
+}
+
+/*
+nodeToWebhook converts a stored node into a webhook.
+*/
+func nodeToWebhook(node data.Node) *Webhook {
+
+	split := func(attr string) []string {
+		if val := fmt.Sprint(node.Attr(attr)); val != "" && node.Attr(attr) != nil {
+			return strings.Split(val, ",")
+		}
+		return nil
+	}
+
+	return &Webhook{node.Key(), fmt.Sprint(node.Attr("url")), split("kinds"), split("parts"), split("actions")}
+}
+
+/*
+nodeToDeadLetter converts a stored node into a dead letter.
+*/
+func nodeToDeadLetter(node data.Node) *DeadLetter {
+	var attempts int
+
+	fmt.Sscan(fmt.Sprint(node.Attr("attempts")), &attempts)
+
+	t, _ := time.Parse(time.RFC3339Nano, fmt.Sprint(node.Attr("time")))
+
+	return &DeadLetter{node.Key(), fmt.Sprint(node.Attr("webhook")), fmt.Sprint(node.Attr("url")),
+		fmt.Sprint(node.Attr("payload")), fmt.Sprint(node.Attr("error")), attempts, t}
+}
+
+/*
+changeEvent converts the change of a node or edge into an event. Returns nil
+if the change should never be forwarded.
+*/
+func changeEvent(change *graph.Change) *Event {
+
+	if change.Part == SystemPartition {
+		return nil
+	}
+
+	entity := "node."
+	if change.IsEdge {
+		entity = "edge."
+	}
+
+	if change.Op == graph.ChangeDelete {
+		return &Event{entity + ActionDeleted, change.Part, change.Kind, change.Key,
+			change.Before.Data(), nil}
+
+	} else if change.Before == nil {
+		return &Event{entity + ActionCreated, change.Part, change.Kind, change.Key,
+			change.After.Data(), nil}
+	}
+
+	return &Event{entity + ActionUpdated, change.Part, change.Kind, change.Key,
+		change.After.Data(), oldValues(change.Before, change.After)}
+}
+
+/*
+oldValues returns the old values of all attributes which were changed by an
+update. Attributes which were not set before have a nil value.
+*/
+func oldValues(before data.Node, after data.Node) map[string]interface{} {
+	res := make(map[string]interface{})
+
+	for attr, val := range before.Data() {
+		if !reflect.DeepEqual(val, after.Attr(attr)) {
+			res[attr] = val
+		}
+	}
+
+	for attr := range after.Data() {
+		if _, ok := before.Data()[attr]; !ok {
+			res[attr] = nil
+		}
+	}
+
+	return res
+}
+
+/*
+payload creates the JSON payload for a batch of events.
+*/
+func payload(hook *Webhook, events []*Event) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"webhook": hook.Name,
+		"events":  events,
+	})
+}
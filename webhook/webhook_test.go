@@ -0,0 +1,543 @@
+/*
+ * FishDB
+ *
+// Copyright 2025 Fisch-labs
+ *
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Fisch-Labs/FishDB/config"
+	"github.com/Fisch-Labs/FishDB/graph"
+	"github.com/Fisch-Labs/FishDB/graph/data"
+	"github.com/Fisch-Labs/FishDB/graph/graphstorage"
+)
+
+func TestWebhooks(t *testing.T) {
+	config.LoadDefaultConfig()
+
+	var mutex sync.Mutex
+	var received []map[string]interface{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &payload)
+
+		mutex.Lock()
+		received = append(received, payload)
+		mutex.Unlock()
+	}))
+	defer srv.Close()
+
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := graph.NewGraphManager(mgs)
+
+	d := NewDispatcher(gm)
+	d.BatchDelay = 50 * time.Millisecond
+
+	if err := d.Run(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := d.Register(&Webhook{Name: "test", URL: "ftp://localhost"}); err == nil ||
+		err.Error() != "GraphError: Invalid data (Invalid URL for webhook test: ftp://localhost)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if err := d.Register(&Webhook{Name: "test", URL: srv.URL, Actions: []string{"foo"}}); err == nil ||
+		err.Error() != "GraphError: Invalid data (Invalid action for webhook test: foo)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if err := d.Register(&Webhook{Name: "test", URL: srv.URL, Kinds: []string{"Person"},
+		Actions: []string{ActionCreated, ActionUpdated}}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	constructNode := func(key string, kind string, name string) data.Node {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", kind)
+		node.SetAttr("name", name)
+		return node
+	}
+
+	// Changes of a rolled back transaction are never delivered
+
+	edge := data.NewGraphEdge()
+	edge.SetAttr("key", "5")
+	edge.SetAttr("kind", "Knows")
+	edge.SetAttr(data.EdgeEnd1Key, "5")
+	edge.SetAttr(data.EdgeEnd1Kind, "Person")
+	edge.SetAttr(data.EdgeEnd1Role, "from")
+	edge.SetAttr(data.EdgeEnd1Cascading, false)
+	edge.SetAttr(data.EdgeEnd2Key, "6")
+	edge.SetAttr(data.EdgeEnd2Kind, "Person")
+	edge.SetAttr(data.EdgeEnd2Role, "to")
+	edge.SetAttr(data.EdgeEnd2Cascading, false)
+
+	trans := graph.NewGraphTrans(gm)
+
+	trans.StoreNode("main", constructNode("5", "Person", "Hans"))
+	trans.StoreEdge("main", edge)
+
+	if err := trans.Commit(); err == nil {
+		t.Error("Commit should fail")
+		return
+	}
+
+	// Create and update nodes in a single transaction
+
+	trans = graph.NewGraphTrans(gm)
+
+	trans.StoreNode("main", constructNode("1", "Person", "John"))
+	trans.StoreNode("main", constructNode("2", "Person", "Jane"))
+	trans.StoreNode("main", constructNode("3", "Animal", "Rex"))
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.UpdateNode("main", constructNode("1", "Person", "Johnny")); err != nil {
+		t.Error(err)
+		return
+	}
+
+	seq, _ := gm.LastChangeSeq()
+
+	// Deletions are filtered out
+
+	if _, err := gm.RemoveNode("main", "2", "Person"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	waitFor := func(cond func() bool) bool {
+		for i := 0; i < 100; i++ {
+			mutex.Lock()
+			res := cond()
+			mutex.Unlock()
+
+			if res {
+				return true
+			}
+
+			time.Sleep(10 * time.Millisecond)
+		}
+		return false
+	}
+
+	if !waitFor(func() bool { return len(received) > 0 }) {
+		t.Error("No events were received")
+		return
+	}
+
+	mutex.Lock()
+	first := received[0]
+	mutex.Unlock()
+
+	events := first["events"].([]interface{})
+
+	if first["webhook"] != "test" || len(events) != 3 {
+		t.Error("Unexpected payload:", first)
+		return
+	}
+
+	// Old data contains only the changed attributes
+
+	if ev := events[2].(map[string]interface{}); ev["event"] != "node.updated" || ev["part"] != "main" ||
+		ev["kind"] != "Person" || ev["key"] != "1" ||
+		ev["data"].(map[string]interface{})["name"] != "Johnny" ||
+		len(ev["old_data"].(map[string]interface{})) != 1 ||
+		ev["old_data"].(map[string]interface{})["name"] != "John" {
+		t.Error("Unexpected event:", ev)
+		return
+	}
+
+	// The cursor of the webhook is stored once the events were delivered
+
+	if !waitFor(func() bool {
+		cursor, _ := gm.ChangeCursor(cursorName("test"))
+		return cursor >= seq
+	}) {
+		t.Error("Cursor was not stored")
+		return
+	}
+
+	// Storing the cursor does not change the graph
+
+	if node, err := gm.FetchNode(SystemPartition, "test", KindWebhook); err != nil ||
+		node.Attr("cursor") != nil {
+		t.Error("Unexpected result:", node, err)
+		return
+	}
+
+	// Webhooks are stored in the database and continue from their cursor
+
+	d.Close()
+
+	if err := gm.StoreNode("main", constructNode("4", "Person", "Hans")); err != nil {
+		t.Error(err)
+		return
+	}
+
+	d = NewDispatcher(gm)
+
+	if err := d.Run(); err != nil {
+		t.Error(err)
+		return
+	}
+	defer d.Close()
+
+	if res := d.Webhooks(); len(res) != 1 || res[0].Name != "test" || res[0].URL != srv.URL ||
+		strings.Join(res[0].Kinds, ",") != "Person" || len(res[0].Parts) != 0 ||
+		strings.Join(res[0].Actions, ",") != "created,updated" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if !waitFor(func() bool { return len(received) > 1 }) {
+		t.Error("No events were received")
+		return
+	}
+
+	mutex.Lock()
+	second := received[1]
+	mutex.Unlock()
+
+	if events := second["events"].([]interface{}); len(events) != 1 ||
+		events[0].(map[string]interface{})["event"] != "node.created" ||
+		events[0].(map[string]interface{})["key"] != "4" {
+		t.Error("Unexpected payload:", second)
+		return
+	}
+
+	if res := d.Webhook("foo"); res != nil {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if err := d.Remove("foo"); err == nil || err.Error() != "GraphError: Invalid data (Unknown webhook foo)" {
+		t.Error("Unexpected result:", err)
+		return
+	}
+
+	if err := d.Remove("test"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if res := d.Webhooks(); len(res) != 0 {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if n, err := gm.FetchNode(SystemPartition, "test", KindWebhook); n != nil || err != nil {
+		t.Error("Unexpected result:", n, err)
+		return
+	}
+
+	if _, ok := gm.ChangeCursor(cursorName("test")); ok {
+		t.Error("Cursor should be removed")
+		return
+	}
+}
+
+func TestWebhookDeadLetters(t *testing.T) {
+	config.LoadDefaultConfig()
+
+	var mutex sync.Mutex
+	var attempts int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		attempts++
+		mutex.Unlock()
+
+		http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := graph.NewGraphManager(mgs)
+
+	d := NewDispatcher(gm)
+	d.BatchDelay = 10 * time.Millisecond
+	d.RetryDelay = 5 * time.Millisecond
+	d.MaxRetries = 2
+
+	if err := d.Run(); err != nil {
+		t.Error(err)
+		return
+	}
+	defer d.Close()
+
+	if err := d.Register(&Webhook{Name: "test", URL: srv.URL, Parts: []string{"main"}}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	node := data.NewGraphNode()
+	node.SetAttr("key", "1")
+	node.SetAttr("kind", "Person")
+
+	if err := gm.StoreNode("other", node); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := gm.StoreNode("main", node); err != nil {
+		t.Error(err)
+		return
+	}
+
+	var letters []*DeadLetter
+
+	for i := 0; i < 100 && len(letters) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+
+		var err error
+		if letters, err = d.DeadLetters("test"); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	mutex.Lock()
+	n := attempts
+	mutex.Unlock()
+
+	if len(letters) != 1 || n != 3 || letters[0].Attempts != 3 || letters[0].URL != srv.URL ||
+		letters[0].Error != "Unexpected response status: 503 Service Unavailable" {
+		t.Error("Unexpected result:", letters, n)
+		return
+	}
+
+	var payload map[string]interface{}
+
+	if err := json.Unmarshal([]byte(letters[0].Payload), &payload); err != nil ||
+		len(payload["events"].([]interface{})) != 1 ||
+		payload["events"].([]interface{})[0].(map[string]interface{})["event"] != "node.created" {
+		t.Error("Unexpected payload:", letters[0].Payload, err)
+		return
+	}
+
+	if n, err := d.RemoveDeadLetters("test"); n != 1 || err != nil {
+		t.Error("Unexpected result:", n, err)
+		return
+	}
+
+	if res, err := d.DeadLetters("test"); len(res) != 0 || err != nil {
+		t.Error("Unexpected result:", res, err)
+		return
+	}
+
+	// Change sets which were removed before they were read are recorded
+
+	d.Close()
+
+	cursor, _ := gm.ChangeCursor(cursorName("test"))
+
+	node.SetAttr("key", "2")
+
+	if err := gm.StoreNode("main", node); err != nil {
+		t.Error(err)
+		return
+	}
+
+	last, _ := gm.LastChangeSeq()
+
+	if err := gm.TruncateChanges(last - 1); err != nil {
+		t.Error(err)
+		return
+	}
+
+	d = NewDispatcher(gm)
+	d.BatchDelay = 10 * time.Millisecond
+	d.RetryDelay = 5 * time.Millisecond
+	d.MaxRetries = 0
+
+	if err := d.Run(); err != nil {
+		t.Error(err)
+		return
+	}
+	defer d.Close()
+
+	for i := 0; i < 100 && len(letters) < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+
+		var err error
+		if letters, err = d.DeadLetters("test"); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	if len(letters) != 2 || letters[0].Error != fmt.Sprintf("Change sets %v to %v were removed "+
+		"from the change log before they were read", cursor+1, last-1) ||
+		letters[0].Payload != `{"events":[],"webhook":"test"}` ||
+		letters[1].Error != "Unexpected response status: 503 Service Unavailable" {
+		t.Error("Unexpected result:", letters)
+		return
+	}
+}
+
+func TestWebhookQueue(t *testing.T) {
+	config.LoadDefaultConfig()
+
+	var mutex sync.Mutex
+	var received []string
+
+	block := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+
+		// Only requests of the replacing webhook are answered
+
+		select {
+		case <-block:
+		case <-r.Context().Done():
+			return
+		}
+
+		if r.URL.Path != "/new" {
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &payload)
+
+		mutex.Lock()
+		for _, ev := range payload["events"].([]interface{}) {
+			received = append(received, fmt.Sprint(ev.(map[string]interface{})["key"]))
+		}
+		mutex.Unlock()
+	}))
+	defer srv.Close()
+
+	defer func() {
+		select {
+		case <-block:
+		default:
+			close(block)
+		}
+	}()
+
+	mgs := graphstorage.NewMemoryGraphStorage("mystorage")
+	gm := graph.NewGraphManager(mgs)
+
+	d := NewDispatcher(gm)
+	d.BatchDelay = 100 * time.Millisecond
+	d.MaxQueueSize = 2
+
+	if err := d.Run(); err != nil {
+		t.Error(err)
+		return
+	}
+	defer d.Close()
+
+	if err := d.Register(&Webhook{Name: "test", URL: srv.URL, Kinds: []string{"Person"}}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Events which exceed the queue size are stored as dead letters
+
+	trans := graph.NewGraphTrans(gm)
+
+	for _, key := range []string{"1", "2", "3", "4"} {
+		node := data.NewGraphNode()
+		node.SetAttr("key", key)
+		node.SetAttr("kind", "Person")
+		trans.StoreNode("main", node)
+	}
+
+	if err := trans.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	letters, err := d.DeadLetters("test")
+
+	for i := 0; i < 100 && err == nil && len(letters) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		letters, err = d.DeadLetters("test")
+	}
+
+	var payload map[string]interface{}
+
+	if err != nil || len(letters) != 1 || letters[0].Error != "Webhook queue is full" {
+		t.Error("Unexpected result:", letters, err)
+		return
+	} else if err := json.Unmarshal([]byte(letters[0].Payload), &payload); err != nil ||
+		len(payload["events"].([]interface{})) != 2 {
+		t.Error("Unexpected payload:", letters[0].Payload, err)
+		return
+	}
+
+	keys := []string{}
+	for _, ev := range payload["events"].([]interface{}) {
+		keys = append(keys, fmt.Sprint(ev.(map[string]interface{})["key"]))
+	}
+
+	// A replacing webhook takes over the pending events - the events of the
+	// replaced webhook are neither delivered nor stored as dead letters
+
+	time.Sleep(200 * time.Millisecond)
+
+	if err := d.Register(&Webhook{Name: "test", URL: srv.URL + "/new", Kinds: []string{"Person"}}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	close(block)
+
+	for i := 0; i < 100; i++ {
+		mutex.Lock()
+		n := len(received)
+		mutex.Unlock()
+
+		if n >= 2 {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mutex.Lock()
+	n := len(received)
+	keys = append(keys, received...)
+	mutex.Unlock()
+
+	sort.Strings(keys)
+
+	if res := fmt.Sprint(keys); n != 2 || res != "[1 2 3 4]" {
+		t.Error("Unexpected result:", res)
+		return
+	}
+
+	if letters, err := d.DeadLetters("test"); err != nil || len(letters) != 1 {
+		t.Error("Unexpected result:", letters, err)
+		return
+	}
+}